@@ -25,4 +25,8 @@ type Jobs interface {
 	CountByStatus(ctx context.Context, userID *int64) (map[models.JobStatus]int, error)
 	// Set creates or updates a job.
 	Set(ctx context.Context, key string, job *models.Job) error
+	// ListQueue retrieves pending jobs in scheduling order.
+	ListQueue(ctx context.Context) ([]*models.Job, error)
+	// SetPriority changes a pending job's priority, reporting false if it is not pending.
+	SetPriority(ctx context.Context, id int64, priority int) (bool, error)
 }
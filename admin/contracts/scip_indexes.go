@@ -0,0 +1,9 @@
+package contracts
+
+import "context"
+
+// SCIPIndexes defines the contract for admin access to stored raw SCIP indexes.
+type SCIPIndexes interface {
+	// Exists reports whether a version has a stored raw SCIP index.
+	Exists(ctx context.Context, userID int64, owner, repo string, versionID int64) (bool, error)
+}
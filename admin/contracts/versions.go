@@ -0,0 +1,13 @@
+package contracts
+
+import (
+	"context"
+
+	"github.com/zoobzio/vicky/models"
+)
+
+// Versions defines the contract for admin version operations.
+type Versions interface {
+	// Get retrieves a version by primary key.
+	Get(ctx context.Context, key string) (*models.Version, error)
+}
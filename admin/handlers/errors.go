@@ -1,6 +1,11 @@
 package handlers
 
-import "github.com/zoobzio/rocco"
+import (
+	"errors"
+
+	"github.com/zoobzio/check"
+	"github.com/zoobzio/rocco"
+)
 
 var (
 	// ErrUserNotFound indicates the requested user does not exist.
@@ -26,4 +31,43 @@ var (
 
 	// ErrJobNotCancellable indicates the job cannot be cancelled in its current state.
 	ErrJobNotCancellable = rocco.ErrBadRequest.WithMessage("job cannot be cancelled (already completed, failed, or cancelled)")
+
+	// ErrInvalidResume indicates the resume parameter is not a boolean.
+	ErrInvalidResume = rocco.ErrBadRequest.WithMessage("invalid resume parameter")
+
+	// ErrJobNotResumable indicates the job is still active and cannot be resumed.
+	ErrJobNotResumable = rocco.ErrBadRequest.WithMessage("only failed or cancelled jobs can be resumed")
+
+	// ErrJobNotQueued indicates the job has left the queue and its priority can no longer change.
+	ErrJobNotQueued = rocco.ErrBadRequest.WithMessage("only pending jobs can be reprioritized")
+
+	// ErrVersionNotFound indicates the requested version does not exist.
+	ErrVersionNotFound = rocco.ErrNotFound.WithMessage("version not found")
+
+	// ErrVersionNotRebuildable indicates the version has no completed ingestion to rebuild from.
+	ErrVersionNotRebuildable = rocco.ErrBadRequest.WithMessage("only ready versions can be rebuilt")
+
+	// ErrVersionNoIndex indicates the version has no stored SCIP index to rebuild from.
+	ErrVersionNoIndex = rocco.ErrUnprocessableEntity.WithMessage("version has no stored SCIP index; re-ingest it instead")
+
+	// ErrVersionBusy indicates a job for the version is already queued or running.
+	ErrVersionBusy = rocco.ErrConflict.WithMessage("version already has a pending or running job")
 )
+
+// validationError reports a request that failed its own Validate the way
+// rocco reports input validation: a 422 with the invalid fields. Rocco only
+// runs Validate itself for value receivers.
+func validationError(err error) error {
+	var errs check.Errors
+	if !errors.As(err, &errs) {
+		errs = check.Errors{err}
+	}
+	fields := make([]rocco.ValidationFieldError, 0, len(errs))
+	for _, e := range errs {
+		var fe *check.FieldError
+		if errors.As(e, &fe) {
+			fields = append(fields, rocco.ValidationFieldError{Field: fe.Field, Message: fe.Message})
+		}
+	}
+	return rocco.ErrValidationFailed.WithDetails(rocco.ValidationDetails{Fields: fields})
+}
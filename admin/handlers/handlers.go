@@ -23,6 +23,10 @@ func All() []rocco.Endpoint {
 		GetJob.WithAuthentication(),
 		CancelJob.WithAuthentication(),
 		RetryJob.WithAuthentication(),
+		SetJobPriority.WithAuthentication(),
 		GetJobStats.WithAuthentication(),
+
+		// Versions
+		RebuildVersion.WithAuthentication(),
 	}
 }
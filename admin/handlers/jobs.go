@@ -2,6 +2,7 @@ package handlers
 
 import (
 	"strconv"
+	"time"
 
 	"github.com/zoobzio/rocco"
 	"github.com/zoobzio/sum"
@@ -154,17 +155,32 @@ var CancelJob = rocco.POST("/admin/jobs/{id}/cancel", func(req *rocco.Request[ro
 	WithErrors(ErrJobNotFound, ErrJobNotCancellable)
 
 // RetryJob creates a new job for a failed/cancelled job's version.
+// With resume=true the new job inherits the original's checkpoint and
+// starts at the stage that failed.
 var RetryJob = rocco.POST("/admin/jobs/{id}/retry", func(req *rocco.Request[rocco.NoBody]) (wire.AdminJobResponse, error) {
 	jobsStore := sum.MustUse[admincontracts.Jobs](req.Context)
 
 	id := req.Params.Path["id"]
 
+	resume := false
+	if r := req.Params.Query["resume"]; r != "" {
+		parsed, err := strconv.ParseBool(r)
+		if err != nil {
+			return wire.AdminJobResponse{}, ErrInvalidResume
+		}
+		resume = parsed
+	}
+
 	// Get original job
 	originalJob, err := jobsStore.Get(req.Context, id)
 	if err != nil {
 		return wire.AdminJobResponse{}, ErrJobNotFound
 	}
 
+	if resume && originalJob.Status != models.JobStatusFailed && originalJob.Status != models.JobStatusCancelled {
+		return wire.AdminJobResponse{}, ErrJobNotResumable
+	}
+
 	// Create new pending job for same version
 	newJob := &models.Job{
 		VersionID:    originalJob.VersionID,
@@ -173,11 +189,19 @@ var RetryJob = rocco.POST("/admin/jobs/{id}/retry", func(req *rocco.Request[rocc
 		Owner:        originalJob.Owner,
 		RepoName:     originalJob.RepoName,
 		Tag:          originalJob.Tag,
-		Stage:        models.JobStageFetch,
+		Kind:         originalJob.Kind,
+		Stage:        originalJob.Kind.Stages()[0],
 		Status:       models.JobStatusPending,
 		Progress:     0,
 		ItemsTotal:   0,
 		ItemsProcessed: 0,
+		Priority:     originalJob.Priority,
+	}
+
+	if resume && originalJob.Checkpoint != nil {
+		checkpoint := *originalJob.Checkpoint
+		newJob.Checkpoint = &checkpoint
+		newJob.Stage = newJob.ResumeStage()
 	}
 
 	// Save new job
@@ -185,17 +209,18 @@ var RetryJob = rocco.POST("/admin/jobs/{id}/retry", func(req *rocco.Request[rocc
 		return wire.AdminJobResponse{}, err
 	}
 
-	// Emit job creation event
+	// Workers claim the job from the table; the event only wakes local listeners
 	events.Job.Created.Emit(req.Context, events.JobCreatedEvent{
 		Job: newJob,
 	})
 
 	return transformers.JobToAdminResponse(newJob), nil
 }).WithSummary("Retry job").
-	WithDescription("Creates a new pending job for the same version as the specified job. Used to retry failed or cancelled jobs.").
+	WithDescription("Creates a new pending job for the same version as the specified job. Used to retry failed or cancelled jobs. With resume=true the job skips stages the original completed and restarts at the one that failed.").
 	WithTags("Admin", "Jobs").
 	WithPathParams("id").
-	WithErrors(ErrJobNotFound).
+	WithQueryParams("resume").
+	WithErrors(ErrJobNotFound, ErrInvalidResume, ErrJobNotResumable).
 	WithSuccessStatus(201)
 
 // GetJobStats returns aggregate statistics for jobs.
@@ -218,23 +243,77 @@ var GetJobStats = rocco.GET("/admin/jobs/stats", func(req *rocco.Request[rocco.N
 		return wire.AdminJobStatsResponse{}, err
 	}
 
+	// Queue positions are global even when filtering by user
+	pending, err := jobsStore.ListQueue(req.Context)
+	if err != nil {
+		return wire.AdminJobStatsResponse{}, err
+	}
+	queue := transformers.JobsToAdminQueue(pending, time.Now(), userID)
+
 	// Build response
 	total := 0
 	for _, count := range counts {
 		total += count
 	}
 
+	longestWait := 0.0
+	for _, q := range queue {
+		longestWait = max(longestWait, q.WaitSeconds)
+	}
+
 	return wire.AdminJobStatsResponse{
-		TotalJobs:      total,
-		PendingJobs:    counts[models.JobStatusPending],
-		RunningJobs:    counts[models.JobStatusRunning],
-		CompletedJobs:  counts[models.JobStatusCompleted],
-		FailedJobs:     counts[models.JobStatusFailed],
-		CancellingJobs: counts[models.JobStatusCancelling],
-		CancelledJobs:  counts[models.JobStatusCancelled],
+		TotalJobs:          total,
+		PendingJobs:        counts[models.JobStatusPending],
+		RunningJobs:        counts[models.JobStatusRunning],
+		CompletedJobs:      counts[models.JobStatusCompleted],
+		FailedJobs:         counts[models.JobStatusFailed],
+		CancellingJobs:     counts[models.JobStatusCancelling],
+		CancelledJobs:      counts[models.JobStatusCancelled],
+		LongestWaitSeconds: longestWait,
+		Queue:              queue,
 	}, nil
 }).WithSummary("Get job statistics").
-	WithDescription("Returns aggregate statistics for jobs, optionally filtered by user_id.").
+	WithDescription("Returns aggregate statistics for jobs and the pending queue in scheduling order with each job's position and wait time, optionally filtered by user_id.").
 	WithTags("Admin", "Jobs").
 	WithQueryParams("user_id").
 	WithErrors(ErrInvalidUserID)
+
+// SetJobPriority changes the scheduling priority of a pending job.
+var SetJobPriority = rocco.POST("/admin/jobs/{id}/priority", func(req *rocco.Request[wire.AdminJobPriorityRequest]) (wire.AdminJobResponse, error) {
+	jobsStore := sum.MustUse[admincontracts.Jobs](req.Context)
+
+	id := req.Params.Path["id"]
+
+	if err := req.Body.Validate(); err != nil {
+		return wire.AdminJobResponse{}, validationError(err)
+	}
+
+	jobID, err := strconv.ParseInt(id, 10, 64)
+	if err != nil {
+		return wire.AdminJobResponse{}, ErrJobNotFound
+	}
+
+	if _, err := jobsStore.Get(req.Context, id); err != nil {
+		return wire.AdminJobResponse{}, ErrJobNotFound
+	}
+
+	updated, err := jobsStore.SetPriority(req.Context, jobID, req.Body.Priority)
+	if err != nil {
+		return wire.AdminJobResponse{}, err
+	}
+	if !updated {
+		return wire.AdminJobResponse{}, ErrJobNotQueued
+	}
+
+	// Retrieve updated job
+	job, err := jobsStore.Get(req.Context, id)
+	if err != nil {
+		return wire.AdminJobResponse{}, ErrJobNotFound
+	}
+
+	return transformers.JobToAdminResponse(job), nil
+}).WithSummary("Set job priority").
+	WithDescription("Raises or lowers a pending job's scheduling priority (-100 to 100). Higher priorities are claimed first; jobs of equal priority are shared fairly between users.").
+	WithTags("Admin", "Jobs").
+	WithPathParams("id").
+	WithErrors(ErrJobNotFound, ErrJobNotQueued, rocco.ErrValidationFailed)
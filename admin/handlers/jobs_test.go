@@ -0,0 +1,364 @@
+//go:build testing
+
+package handlers
+
+import (
+	"context"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/zoobzio/rocco"
+	rtesting "github.com/zoobzio/rocco/testing"
+	"github.com/zoobzio/sum"
+	sumtest "github.com/zoobzio/sum/testing"
+	admincontracts "github.com/zoobzio/vicky/admin/contracts"
+	"github.com/zoobzio/vicky/admin/wire"
+	"github.com/zoobzio/vicky/models"
+	"github.com/zoobzio/vicky/stores"
+)
+
+// MockAdminJobs implements admincontracts.Jobs with function-field overrides.
+type MockAdminJobs struct {
+	OnGet               func(ctx context.Context, key string) (*models.Job, error)
+	OnSet               func(ctx context.Context, key string, job *models.Job) error
+	OnListQueue         func(ctx context.Context) ([]*models.Job, error)
+	OnSetPriority       func(ctx context.Context, id int64, priority int) (bool, error)
+	OnCountByStatus     func(ctx context.Context, userID *int64) (map[models.JobStatus]int, error)
+	OnLatestByVersionID func(ctx context.Context, versionID int64) (*models.Job, error)
+}
+
+func (m *MockAdminJobs) Get(ctx context.Context, key string) (*models.Job, error) {
+	if m.OnGet != nil {
+		return m.OnGet(ctx, key)
+	}
+	return &models.Job{}, nil
+}
+
+func (m *MockAdminJobs) Set(ctx context.Context, key string, job *models.Job) error {
+	if m.OnSet != nil {
+		return m.OnSet(ctx, key, job)
+	}
+	return nil
+}
+
+func (m *MockAdminJobs) List(ctx context.Context, filter *stores.JobFilter, limit, offset int) ([]*models.Job, error) {
+	return nil, nil
+}
+
+func (m *MockAdminJobs) Count(ctx context.Context, filter *stores.JobFilter) (int, error) {
+	return 0, nil
+}
+
+func (m *MockAdminJobs) RequestCancellation(ctx context.Context, id int64) error {
+	return nil
+}
+
+func (m *MockAdminJobs) ListByStatus(ctx context.Context, userID int64, status models.JobStatus) ([]*models.Job, error) {
+	return nil, nil
+}
+
+func (m *MockAdminJobs) LatestByVersionID(ctx context.Context, versionID int64) (*models.Job, error) {
+	if m.OnLatestByVersionID != nil {
+		return m.OnLatestByVersionID(ctx, versionID)
+	}
+	return nil, nil
+}
+
+func (m *MockAdminJobs) CountByStatus(ctx context.Context, userID *int64) (map[models.JobStatus]int, error) {
+	if m.OnCountByStatus != nil {
+		return m.OnCountByStatus(ctx, userID)
+	}
+	return map[models.JobStatus]int{}, nil
+}
+
+func (m *MockAdminJobs) ListQueue(ctx context.Context) ([]*models.Job, error) {
+	if m.OnListQueue != nil {
+		return m.OnListQueue(ctx)
+	}
+	return nil, nil
+}
+
+func (m *MockAdminJobs) SetPriority(ctx context.Context, id int64, priority int) (bool, error) {
+	if m.OnSetPriority != nil {
+		return m.OnSetPriority(ctx, id, priority)
+	}
+	return true, nil
+}
+
+// setupAdminJobsTest sets up the registry for admin job handler tests.
+func setupAdminJobsTest(t *testing.T, jobsStore *MockAdminJobs) *rocco.Engine {
+	t.Helper()
+	sum.Reset()
+	k := sum.Start()
+
+	sum.Register[admincontracts.Jobs](k, jobsStore)
+
+	sum.Freeze(k)
+	t.Cleanup(sum.Reset)
+
+	_ = sumtest.TestContext(t)
+
+	identity := rtesting.NewMockIdentity("1000")
+	engine := rtesting.TestEngineWithAuth(func(_ context.Context, _ *http.Request) (rocco.Identity, error) {
+		return identity, nil
+	})
+	return engine
+}
+
+func failedJobAfter(stage models.JobStage) *models.Job {
+	return &models.Job{
+		ID:         7,
+		VersionID:  10,
+		Owner:      "testorg",
+		RepoName:   "testrepo",
+		Tag:        "v1.0.0",
+		Stage:      models.JobStageEmbed,
+		Status:     models.JobStatusFailed,
+		Checkpoint: &stage,
+	}
+}
+
+func TestRetryJob_FromStart(t *testing.T) {
+	var saved *models.Job
+	mj := &MockAdminJobs{
+		OnGet: func(ctx context.Context, key string) (*models.Job, error) {
+			return failedJobAfter(models.JobStageChunk), nil
+		},
+		OnSet: func(ctx context.Context, key string, job *models.Job) error {
+			saved = job
+			return nil
+		},
+	}
+
+	engine := setupAdminJobsTest(t, mj)
+	engine.WithHandlers(RetryJob)
+
+	capture := rtesting.ServeRequest(engine, "POST", "/admin/jobs/7/retry", nil)
+	rtesting.AssertStatus(t, capture, 201)
+
+	if saved == nil {
+		t.Fatal("expected new job to be saved")
+	}
+	if saved.Stage != models.JobStageFetch || saved.Checkpoint != nil {
+		t.Errorf("new job stage = %q checkpoint = %v, want fetch with no checkpoint", saved.Stage, saved.Checkpoint)
+	}
+}
+
+func TestRetryJob_Resume(t *testing.T) {
+	var saved *models.Job
+	mj := &MockAdminJobs{
+		OnGet: func(ctx context.Context, key string) (*models.Job, error) {
+			return failedJobAfter(models.JobStageChunk), nil
+		},
+		OnSet: func(ctx context.Context, key string, job *models.Job) error {
+			saved = job
+			return nil
+		},
+	}
+
+	engine := setupAdminJobsTest(t, mj)
+	engine.WithHandlers(RetryJob)
+
+	capture := rtesting.ServeRequest(engine, "POST", "/admin/jobs/7/retry?resume=true", nil)
+	rtesting.AssertStatus(t, capture, 201)
+
+	if saved == nil || saved.Checkpoint == nil || *saved.Checkpoint != models.JobStageChunk {
+		t.Fatalf("new job checkpoint = %v, want chunk", saved)
+	}
+	if saved.Stage != models.JobStageEmbed {
+		t.Errorf("new job stage = %q, want embed", saved.Stage)
+	}
+
+	var resp wire.AdminJobResponse
+	if err := capture.DecodeJSON(&resp); err != nil {
+		t.Fatalf("decode: %v", err)
+	}
+	if resp.Checkpoint == nil || *resp.Checkpoint != "chunk" {
+		t.Errorf("response checkpoint = %v, want chunk", resp.Checkpoint)
+	}
+}
+
+func TestRetryJob_KeepsKind(t *testing.T) {
+	var saved *models.Job
+	mj := &MockAdminJobs{
+		OnGet: func(ctx context.Context, key string) (*models.Job, error) {
+			job := failedJobAfter(models.JobStageParse)
+			job.Kind = models.JobKindRebuildSCIP
+			return job, nil
+		},
+		OnSet: func(ctx context.Context, key string, job *models.Job) error {
+			saved = job
+			return nil
+		},
+	}
+
+	engine := setupAdminJobsTest(t, mj)
+	engine.WithHandlers(RetryJob)
+
+	capture := rtesting.ServeRequest(engine, "POST", "/admin/jobs/7/retry", nil)
+	rtesting.AssertStatus(t, capture, 201)
+
+	if saved == nil || saved.Kind != models.JobKindRebuildSCIP || saved.Stage != models.JobStageParse {
+		t.Fatalf("new job = %+v, want a rebuild starting at parse", saved)
+	}
+}
+
+func TestRetryJob_ResumeRunningJob(t *testing.T) {
+	mj := &MockAdminJobs{
+		OnGet: func(ctx context.Context, key string) (*models.Job, error) {
+			job := failedJobAfter(models.JobStageChunk)
+			job.Status = models.JobStatusRunning
+			return job, nil
+		},
+		OnSet: func(ctx context.Context, key string, job *models.Job) error {
+			t.Error("running job should not be resumed")
+			return nil
+		},
+	}
+
+	engine := setupAdminJobsTest(t, mj)
+	engine.WithHandlers(RetryJob)
+
+	capture := rtesting.ServeRequest(engine, "POST", "/admin/jobs/7/retry?resume=true", nil)
+	rtesting.AssertStatus(t, capture, 400)
+}
+
+func TestRetryJob_InvalidResume(t *testing.T) {
+	engine := setupAdminJobsTest(t, &MockAdminJobs{})
+	engine.WithHandlers(RetryJob)
+
+	capture := rtesting.ServeRequest(engine, "POST", "/admin/jobs/7/retry?resume=maybe", nil)
+	rtesting.AssertStatus(t, capture, 400)
+}
+
+func TestRetryJob_KeepsPriority(t *testing.T) {
+	var saved *models.Job
+	mj := &MockAdminJobs{
+		OnGet: func(ctx context.Context, key string) (*models.Job, error) {
+			job := failedJobAfter(models.JobStageChunk)
+			job.Priority = 20
+			return job, nil
+		},
+		OnSet: func(ctx context.Context, key string, job *models.Job) error {
+			saved = job
+			return nil
+		},
+	}
+
+	engine := setupAdminJobsTest(t, mj)
+	engine.WithHandlers(RetryJob)
+
+	capture := rtesting.ServeRequest(engine, "POST", "/admin/jobs/7/retry", nil)
+	rtesting.AssertStatus(t, capture, 201)
+
+	if saved == nil || saved.Priority != 20 {
+		t.Errorf("new job = %+v, want priority 20", saved)
+	}
+}
+
+func TestSetJobPriority(t *testing.T) {
+	job := &models.Job{ID: 7, Status: models.JobStatusPending}
+	mj := &MockAdminJobs{
+		OnGet: func(ctx context.Context, key string) (*models.Job, error) {
+			return job, nil
+		},
+		OnSetPriority: func(ctx context.Context, id int64, priority int) (bool, error) {
+			if id != 7 {
+				t.Errorf("id = %d, want 7", id)
+			}
+			job.Priority = priority
+			return true, nil
+		},
+	}
+
+	engine := setupAdminJobsTest(t, mj)
+	engine.WithHandlers(SetJobPriority)
+
+	capture := rtesting.ServeRequest(engine, "POST", "/admin/jobs/7/priority", wire.AdminJobPriorityRequest{Priority: 50})
+	rtesting.AssertStatus(t, capture, 200)
+
+	var resp wire.AdminJobResponse
+	if err := capture.DecodeJSON(&resp); err != nil {
+		t.Fatalf("decode: %v", err)
+	}
+	if resp.Priority != 50 {
+		t.Errorf("Priority = %d, want 50", resp.Priority)
+	}
+}
+
+func TestSetJobPriority_NotPending(t *testing.T) {
+	mj := &MockAdminJobs{
+		OnSetPriority: func(ctx context.Context, id int64, priority int) (bool, error) {
+			return false, nil
+		},
+	}
+
+	engine := setupAdminJobsTest(t, mj)
+	engine.WithHandlers(SetJobPriority)
+
+	capture := rtesting.ServeRequest(engine, "POST", "/admin/jobs/7/priority", wire.AdminJobPriorityRequest{Priority: 10})
+	rtesting.AssertStatus(t, capture, 400)
+}
+
+func TestSetJobPriority_OutOfRange(t *testing.T) {
+	mj := &MockAdminJobs{
+		OnSetPriority: func(ctx context.Context, id int64, priority int) (bool, error) {
+			t.Error("out-of-range priority should be rejected")
+			return true, nil
+		},
+	}
+
+	engine := setupAdminJobsTest(t, mj)
+	engine.WithHandlers(SetJobPriority)
+
+	capture := rtesting.ServeRequest(engine, "POST", "/admin/jobs/7/priority", wire.AdminJobPriorityRequest{Priority: 500})
+	rtesting.AssertStatus(t, capture, 422)
+
+	var resp struct {
+		Details rocco.ValidationDetails `json:"details"`
+	}
+	if err := capture.DecodeJSON(&resp); err != nil {
+		t.Fatalf("decode: %v", err)
+	}
+	if len(resp.Details.Fields) != 1 || resp.Details.Fields[0].Field != "priority" {
+		t.Errorf("fields = %+v, want one error for priority", resp.Details.Fields)
+	}
+}
+
+func TestGetJobStats_Queue(t *testing.T) {
+	now := time.Now()
+	mj := &MockAdminJobs{
+		OnCountByStatus: func(ctx context.Context, userID *int64) (map[models.JobStatus]int, error) {
+			return map[models.JobStatus]int{models.JobStatusPending: 1}, nil
+		},
+		OnListQueue: func(ctx context.Context) ([]*models.Job, error) {
+			return []*models.Job{
+				{ID: 1, UserID: 1, CreatedAt: now.Add(-time.Minute)},
+				{ID: 2, UserID: 2, CreatedAt: now.Add(-10 * time.Minute)},
+				{ID: 3, UserID: 1, CreatedAt: now.Add(-2 * time.Minute)},
+			}, nil
+		},
+	}
+
+	engine := setupAdminJobsTest(t, mj)
+	engine.WithHandlers(GetJobStats)
+
+	capture := rtesting.ServeRequest(engine, "GET", "/admin/jobs/stats?user_id=1", nil)
+	rtesting.AssertStatus(t, capture, 200)
+
+	var resp wire.AdminJobStatsResponse
+	if err := capture.DecodeJSON(&resp); err != nil {
+		t.Fatalf("decode: %v", err)
+	}
+	if len(resp.Queue) != 2 {
+		t.Fatalf("queue = %d entries, want 2", len(resp.Queue))
+	}
+	// Positions stay global when filtering by user
+	if resp.Queue[0].Position != 1 || resp.Queue[1].Position != 3 {
+		t.Errorf("positions = %d, %d; want 1, 3", resp.Queue[0].Position, resp.Queue[1].Position)
+	}
+	if resp.LongestWaitSeconds < 120 || resp.LongestWaitSeconds > 600 {
+		t.Errorf("LongestWaitSeconds = %v, want about 120", resp.LongestWaitSeconds)
+	}
+}
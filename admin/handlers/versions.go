@@ -0,0 +1,75 @@
+package handlers
+
+import (
+	"github.com/zoobzio/rocco"
+	"github.com/zoobzio/sum"
+	admincontracts "github.com/zoobzio/vicky/admin/contracts"
+	"github.com/zoobzio/vicky/admin/transformers"
+	"github.com/zoobzio/vicky/admin/wire"
+	"github.com/zoobzio/vicky/api/events"
+	"github.com/zoobzio/vicky/models"
+)
+
+// RebuildVersion queues a job that re-derives a version's SCIP data and
+// symbols from its stored SCIP index, without fetching or re-indexing.
+var RebuildVersion = rocco.POST("/admin/versions/{id}/rebuild", func(req *rocco.Request[rocco.NoBody]) (wire.AdminJobResponse, error) {
+	versionsStore := sum.MustUse[admincontracts.Versions](req.Context)
+	jobsStore := sum.MustUse[admincontracts.Jobs](req.Context)
+	indexesStore := sum.MustUse[admincontracts.SCIPIndexes](req.Context)
+
+	id := req.Params.Path["id"]
+
+	version, err := versionsStore.Get(req.Context, id)
+	if err != nil {
+		return wire.AdminJobResponse{}, ErrVersionNotFound
+	}
+
+	// Staging versions and unfinished ingestions have nothing to rebuild from
+	if version.Status != models.VersionStatusReady || version.Staging() {
+		return wire.AdminJobResponse{}, ErrVersionNotRebuildable
+	}
+
+	// Versions ingested before indexes were kept, or whose index was lost,
+	// can only be re-ingested
+	exists, err := indexesStore.Exists(req.Context, version.UserID, version.Owner, version.RepoName, version.ID)
+	if err != nil {
+		return wire.AdminJobResponse{}, err
+	}
+	if !exists {
+		return wire.AdminJobResponse{}, ErrVersionNoIndex
+	}
+
+	// A rebuild swaps in the version's SCIP data, so it must not race another job
+	latest, err := jobsStore.LatestByVersionID(req.Context, version.ID)
+	if err == nil && latest != nil && (latest.Status == models.JobStatusPending || latest.Status == models.JobStatusRunning) {
+		return wire.AdminJobResponse{}, ErrVersionBusy
+	}
+
+	job := &models.Job{
+		VersionID:    version.ID,
+		RepositoryID: version.RepositoryID,
+		UserID:       version.UserID,
+		Owner:        version.Owner,
+		RepoName:     version.RepoName,
+		Tag:          models.RebuildTag(version.Tag, version.ID),
+		Kind:         models.JobKindRebuildSCIP,
+		Stage:        models.JobKindRebuildSCIP.Stages()[0],
+		Status:       models.JobStatusPending,
+	}
+
+	if err := jobsStore.Set(req.Context, "", job); err != nil {
+		return wire.AdminJobResponse{}, err
+	}
+
+	// Workers claim the job from the table; the event only wakes local listeners
+	events.Job.Created.Emit(req.Context, events.JobCreatedEvent{
+		Job: job,
+	})
+
+	return transformers.JobToAdminResponse(job), nil
+}).WithSummary("Rebuild version SCIP data").
+	WithDescription("Creates a pending job that rebuilds a ready version's SCIP symbols, occurrences, relationships, and derived symbols from the SCIP index stored at ingestion, then embeds the new symbols. The rebuilt data replaces the current data in one step once complete; until then the current data stays available. The source is not fetched or re-indexed and chunks are kept.").
+	WithTags("Admin", "Versions").
+	WithPathParams("id").
+	WithErrors(ErrVersionNotFound, ErrVersionNotRebuildable, ErrVersionNoIndex, ErrVersionBusy).
+	WithSuccessStatus(201)
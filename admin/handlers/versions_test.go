@@ -0,0 +1,192 @@
+//go:build testing
+
+package handlers
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"testing"
+
+	"github.com/zoobzio/rocco"
+	rtesting "github.com/zoobzio/rocco/testing"
+	"github.com/zoobzio/sum"
+	sumtest "github.com/zoobzio/sum/testing"
+	admincontracts "github.com/zoobzio/vicky/admin/contracts"
+	"github.com/zoobzio/vicky/models"
+)
+
+// MockAdminVersions implements admincontracts.Versions with function-field overrides.
+type MockAdminVersions struct {
+	OnGet func(ctx context.Context, key string) (*models.Version, error)
+}
+
+func (m *MockAdminVersions) Get(ctx context.Context, key string) (*models.Version, error) {
+	if m.OnGet != nil {
+		return m.OnGet(ctx, key)
+	}
+	return readyVersion(), nil
+}
+
+// MockAdminSCIPIndexes implements admincontracts.SCIPIndexes with function-field overrides.
+type MockAdminSCIPIndexes struct {
+	OnExists func(ctx context.Context, userID int64, owner, repo string, versionID int64) (bool, error)
+}
+
+func (m *MockAdminSCIPIndexes) Exists(ctx context.Context, userID int64, owner, repo string, versionID int64) (bool, error) {
+	if m.OnExists != nil {
+		return m.OnExists(ctx, userID, owner, repo, versionID)
+	}
+	return true, nil
+}
+
+// setupAdminVersionsTest sets up the registry for admin version handler tests.
+func setupAdminVersionsTest(t *testing.T, versionsStore *MockAdminVersions, jobsStore *MockAdminJobs, indexes ...*MockAdminSCIPIndexes) *rocco.Engine {
+	t.Helper()
+	sum.Reset()
+	k := sum.Start()
+
+	indexesStore := &MockAdminSCIPIndexes{}
+	if len(indexes) > 0 {
+		indexesStore = indexes[0]
+	}
+
+	sum.Register[admincontracts.Versions](k, versionsStore)
+	sum.Register[admincontracts.Jobs](k, jobsStore)
+	sum.Register[admincontracts.SCIPIndexes](k, indexesStore)
+
+	sum.Freeze(k)
+	t.Cleanup(sum.Reset)
+
+	_ = sumtest.TestContext(t)
+
+	identity := rtesting.NewMockIdentity("1000")
+	engine := rtesting.TestEngineWithAuth(func(_ context.Context, _ *http.Request) (rocco.Identity, error) {
+		return identity, nil
+	})
+	engine.WithHandlers(RebuildVersion)
+	return engine
+}
+
+func readyVersion() *models.Version {
+	return &models.Version{
+		ID:           10,
+		RepositoryID: 3,
+		UserID:       1000,
+		Owner:        "testorg",
+		RepoName:     "testrepo",
+		Tag:          "v1.0.0",
+		Status:       models.VersionStatusReady,
+	}
+}
+
+func TestRebuildVersion(t *testing.T) {
+	var saved *models.Job
+	mj := &MockAdminJobs{
+		OnSet: func(ctx context.Context, key string, job *models.Job) error {
+			saved = job
+			return nil
+		},
+	}
+
+	engine := setupAdminVersionsTest(t, &MockAdminVersions{}, mj)
+
+	capture := rtesting.ServeRequest(engine, "POST", "/admin/versions/10/rebuild", nil)
+	rtesting.AssertStatus(t, capture, 201)
+
+	if saved == nil {
+		t.Fatal("expected rebuild job to be saved")
+	}
+	if saved.Kind != models.JobKindRebuildSCIP || saved.Stage != models.JobStageParse {
+		t.Errorf("job kind = %q stage = %q, want rebuild_scip starting at parse", saved.Kind, saved.Stage)
+	}
+	if saved.VersionID != 10 || saved.Status != models.JobStatusPending {
+		t.Errorf("job = %+v, want a pending job for version 10", saved)
+	}
+	// The rebuild writes beside the live data and is swapped in at the end
+	if want := models.RebuildTag("v1.0.0", 10); saved.Tag != want {
+		t.Errorf("job tag = %q, want %q", saved.Tag, want)
+	}
+}
+
+func TestRebuildVersion_NoStoredIndex(t *testing.T) {
+	mx := &MockAdminSCIPIndexes{
+		OnExists: func(ctx context.Context, userID int64, owner, repo string, versionID int64) (bool, error) {
+			if userID != 1000 || owner != "testorg" || repo != "testrepo" || versionID != 10 {
+				t.Errorf("Exists(%d, %s, %s, %d), want version 10 of testorg/testrepo", userID, owner, repo, versionID)
+			}
+			return false, nil
+		},
+	}
+	mj := &MockAdminJobs{
+		OnSet: func(ctx context.Context, key string, job *models.Job) error {
+			t.Error("job created for a version without a stored index")
+			return nil
+		},
+	}
+
+	engine := setupAdminVersionsTest(t, &MockAdminVersions{}, mj, mx)
+
+	capture := rtesting.ServeRequest(engine, "POST", "/admin/versions/10/rebuild", nil)
+	rtesting.AssertStatus(t, capture, 422)
+}
+
+func TestRebuildVersion_NotFound(t *testing.T) {
+	mv := &MockAdminVersions{
+		OnGet: func(ctx context.Context, key string) (*models.Version, error) {
+			return nil, errors.New("not found")
+		},
+	}
+
+	engine := setupAdminVersionsTest(t, mv, &MockAdminJobs{})
+
+	capture := rtesting.ServeRequest(engine, "POST", "/admin/versions/10/rebuild", nil)
+	rtesting.AssertStatus(t, capture, 404)
+}
+
+func TestRebuildVersion_NotRebuildable(t *testing.T) {
+	replaces := int64(9)
+	cases := map[string]func(v *models.Version){
+		"ingesting": func(v *models.Version) { v.Status = models.VersionStatusIngesting },
+		"staging":   func(v *models.Version) { v.ReplacesID = &replaces },
+	}
+	for name, mutate := range cases {
+		t.Run(name, func(t *testing.T) {
+			mv := &MockAdminVersions{
+				OnGet: func(ctx context.Context, key string) (*models.Version, error) {
+					v := readyVersion()
+					mutate(v)
+					return v, nil
+				},
+			}
+			mj := &MockAdminJobs{
+				OnSet: func(ctx context.Context, key string, job *models.Job) error {
+					t.Error("job created for a version that cannot be rebuilt")
+					return nil
+				},
+			}
+
+			engine := setupAdminVersionsTest(t, mv, mj)
+
+			capture := rtesting.ServeRequest(engine, "POST", "/admin/versions/10/rebuild", nil)
+			rtesting.AssertStatus(t, capture, 400)
+		})
+	}
+}
+
+func TestRebuildVersion_Busy(t *testing.T) {
+	mj := &MockAdminJobs{
+		OnLatestByVersionID: func(ctx context.Context, versionID int64) (*models.Job, error) {
+			return &models.Job{ID: 7, VersionID: versionID, Status: models.JobStatusRunning}, nil
+		},
+		OnSet: func(ctx context.Context, key string, job *models.Job) error {
+			t.Error("job created while another is running")
+			return nil
+		},
+	}
+
+	engine := setupAdminVersionsTest(t, &MockAdminVersions{}, mj)
+
+	capture := rtesting.ServeRequest(engine, "POST", "/admin/versions/10/rebuild", nil)
+	rtesting.AssertStatus(t, capture, 409)
+}
@@ -1,12 +1,20 @@
 package transformers
 
 import (
+	"time"
+
 	"github.com/zoobzio/vicky/admin/wire"
 	"github.com/zoobzio/vicky/models"
 )
 
 // JobToAdminResponse transforms a Job model to an admin API response.
 func JobToAdminResponse(j *models.Job) wire.AdminJobResponse {
+	var checkpoint *string
+	if j.Checkpoint != nil {
+		cp := string(*j.Checkpoint)
+		checkpoint = &cp
+	}
+
 	return wire.AdminJobResponse{
 		ID:             j.ID,
 		VersionID:      j.VersionID,
@@ -15,8 +23,11 @@ func JobToAdminResponse(j *models.Job) wire.AdminJobResponse {
 		Owner:          j.Owner,
 		RepoName:       j.RepoName,
 		Tag:            j.Tag,
+		Kind:           string(j.Kind),
 		Stage:          string(j.Stage),
+		Checkpoint:     checkpoint,
 		Status:         string(j.Status),
+		Priority:       j.Priority,
 		Progress:       j.Progress,
 		Error:          j.Error,
 		ItemsTotal:     j.ItemsTotal,
@@ -41,3 +52,26 @@ func JobsToAdminList(jobs []*models.Job, total, limit, offset int) wire.AdminJob
 	}
 	return resp
 }
+
+// JobsToAdminQueue transforms pending jobs in scheduling order to queue entries.
+// Positions are counted across the whole queue; a non-nil userID keeps only
+// that user's entries.
+func JobsToAdminQueue(jobs []*models.Job, now time.Time, userID *int64) []wire.AdminQueuedJobResponse {
+	queue := make([]wire.AdminQueuedJobResponse, 0, len(jobs))
+	for i, j := range jobs {
+		if userID != nil && j.UserID != *userID {
+			continue
+		}
+		queue = append(queue, wire.AdminQueuedJobResponse{
+			ID:          j.ID,
+			UserID:      j.UserID,
+			Owner:       j.Owner,
+			RepoName:    j.RepoName,
+			Tag:         j.Tag,
+			Priority:    j.Priority,
+			Position:    i + 1,
+			WaitSeconds: now.Sub(j.CreatedAt).Seconds(),
+		})
+	}
+	return queue
+}
@@ -1,6 +1,11 @@
 package wire
 
-import "time"
+import (
+	"time"
+
+	"github.com/zoobzio/check"
+	"github.com/zoobzio/vicky/models"
+)
 
 // AdminJobResponse is the API response for admin job data.
 type AdminJobResponse struct {
@@ -11,8 +16,11 @@ type AdminJobResponse struct {
 	Owner          string     `json:"owner" description:"Repository owner" example:"octocat"`
 	RepoName       string     `json:"repo_name" description:"Repository name" example:"hello-world"`
 	Tag            string     `json:"tag" description:"Version tag" example:"v1.0.0"`
+	Kind           string     `json:"kind" description:"Which pipeline stages the job runs" example:"ingest"`
 	Stage          string     `json:"stage" description:"Current processing stage" example:"embed"`
+	Checkpoint     *string    `json:"checkpoint,omitempty" description:"Last stage completed successfully" example:"chunk"`
 	Status         string     `json:"status" description:"Job status" example:"running"`
+	Priority       int        `json:"priority" description:"Scheduling priority, higher runs first" example:"0"`
 	Progress       int        `json:"progress" description:"Percentage completion 0-100" example:"45"`
 	Error          *string    `json:"error,omitempty" description:"Error message if failed"`
 	ItemsTotal     int        `json:"items_total" description:"Total items to process"`
@@ -30,6 +38,10 @@ func (r AdminJobResponse) Clone() AdminJobResponse {
 		e := *r.Error
 		c.Error = &e
 	}
+	if r.Checkpoint != nil {
+		cp := *r.Checkpoint
+		c.Checkpoint = &cp
+	}
 	if r.StartedAt != nil {
 		s := *r.StartedAt
 		c.StartedAt = &s
@@ -63,16 +75,57 @@ func (r AdminJobListResponse) Clone() AdminJobListResponse {
 
 // AdminJobStatsResponse provides aggregate job statistics.
 type AdminJobStatsResponse struct {
-	TotalJobs      int `json:"total_jobs" description:"Total number of jobs"`
-	PendingJobs    int `json:"pending_jobs" description:"Jobs waiting to run"`
-	RunningJobs    int `json:"running_jobs" description:"Jobs currently running"`
-	CompletedJobs  int `json:"completed_jobs" description:"Successfully completed jobs"`
-	FailedJobs     int `json:"failed_jobs" description:"Failed jobs"`
-	CancellingJobs int `json:"cancelling_jobs" description:"Jobs marked for cancellation"`
-	CancelledJobs  int `json:"cancelled_jobs" description:"Cancelled jobs"`
+	TotalJobs          int                      `json:"total_jobs" description:"Total number of jobs"`
+	PendingJobs        int                      `json:"pending_jobs" description:"Jobs waiting to run"`
+	RunningJobs        int                      `json:"running_jobs" description:"Jobs currently running"`
+	CompletedJobs      int                      `json:"completed_jobs" description:"Successfully completed jobs"`
+	FailedJobs         int                      `json:"failed_jobs" description:"Failed jobs"`
+	CancellingJobs     int                      `json:"cancelling_jobs" description:"Jobs marked for cancellation"`
+	CancelledJobs      int                      `json:"cancelled_jobs" description:"Cancelled jobs"`
+	LongestWaitSeconds float64                  `json:"longest_wait_seconds" description:"How long the longest-waiting pending job has been queued"`
+	Queue              []AdminQueuedJobResponse `json:"queue" description:"Pending jobs in the order workers will claim them"`
 }
 
 // Clone returns a deep copy.
 func (r AdminJobStatsResponse) Clone() AdminJobStatsResponse {
+	c := r
+	if r.Queue != nil {
+		c.Queue = make([]AdminQueuedJobResponse, len(r.Queue))
+		copy(c.Queue, r.Queue)
+	}
+	return c
+}
+
+// AdminQueuedJobResponse is a pending job's place in the ingestion queue.
+type AdminQueuedJobResponse struct {
+	ID          int64   `json:"id" description:"Job ID" example:"12345"`
+	UserID      int64   `json:"user_id" description:"Owning user ID"`
+	Owner       string  `json:"owner" description:"Repository owner" example:"octocat"`
+	RepoName    string  `json:"repo_name" description:"Repository name" example:"hello-world"`
+	Tag         string  `json:"tag" description:"Version tag" example:"v1.0.0"`
+	Priority    int     `json:"priority" description:"Scheduling priority, higher runs first" example:"0"`
+	Position    int     `json:"position" description:"1-based position in the queue, ignoring concurrency limits" example:"3"`
+	WaitSeconds float64 `json:"wait_seconds" description:"Time since the job was queued" example:"42.5"`
+}
+
+// Clone returns a deep copy.
+func (r AdminQueuedJobResponse) Clone() AdminQueuedJobResponse {
+	return r
+}
+
+// AdminJobPriorityRequest is the request body for changing a job's priority.
+type AdminJobPriorityRequest struct {
+	Priority int `json:"priority" description:"Scheduling priority from -100 to 100, higher runs first" example:"10"`
+}
+
+// Validate validates the AdminJobPriorityRequest.
+func (r *AdminJobPriorityRequest) Validate() error {
+	return check.All(
+		check.Int(r.Priority, "priority").Between(models.MinJobPriority, models.MaxJobPriority).V(),
+	).Err()
+}
+
+// Clone returns a deep copy.
+func (r AdminJobPriorityRequest) Clone() AdminJobPriorityRequest {
 	return r
 }
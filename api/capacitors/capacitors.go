@@ -22,7 +22,12 @@ import (
 // Init initializes all capacitors with database-backed watchers.
 // Should be called after database connection is established.
 func Init(ctx context.Context, db *sql.DB, dsn string, ap *aperture.Aperture) error {
-	// Pipeline stage capacitors
+	// Pipeline capacitors
+	pipelineWatcher := NewDBWatcherWithDSN(db, dsn, DomainPipeline)
+	if err := InitPipeline(ctx, pipelineWatcher); err != nil {
+		return err
+	}
+
 	fetchWatcher := NewDBWatcherWithDSN(db, dsn, DomainFetch)
 	if err := InitFetch(ctx, fetchWatcher); err != nil {
 		return err
@@ -60,6 +65,7 @@ func Init(ctx context.Context, db *sql.DB, dsn string, ap *aperture.Aperture) er
 // Domain constants for config table.
 const (
 	// Pipeline stages
+	DomainPipeline  = "pipeline"
 	DomainFetch     = "fetch"
 	DomainParse     = "parse"
 	DomainChunk     = "chunk"
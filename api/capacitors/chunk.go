@@ -13,8 +13,9 @@ import (
 // Chunk holds operational settings for the chunk stage.
 // Hot-reloadable via flux.
 type Chunk struct {
-	Workers int           `json:"workers"` // pool concurrency
-	Timeout time.Duration `json:"timeout"` // per-document timeout
+	Workers     int           `json:"workers"`      // pool concurrency
+	Timeout     time.Duration `json:"timeout"`      // per-document timeout
+	ErrorBudget float64       `json:"error_budget"` // fraction of files allowed to fail; 0 fails on the first
 }
 
 // Validate checks Chunk configuration.
@@ -25,20 +26,23 @@ func (c Chunk) Validate() error {
 		check.Max(c.Workers, 100, "workers"),
 		check.DurationNonNegative(c.Timeout, "timeout"),
 		check.DurationMax(c.Timeout, 10*time.Minute, "timeout"),
+		check.Between(c.ErrorBudget, 0, 1, "error_budget"),
 	).Err()
 }
 
 // DefaultChunk returns Chunk configuration with sensible defaults.
 func DefaultChunk() Chunk {
 	return Chunk{
-		Workers: 8,
-		Timeout: 30 * time.Second,
+		Workers:     8,
+		Timeout:     30 * time.Second,
+		ErrorBudget: 0.05,
 	}
 }
 
 // applyChunk applies config to the chunk pool.
 func applyChunk(cfg Chunk) {
 	ingest.SetChunkConfig(cfg.Workers, cfg.Timeout)
+	ingest.SetChunkErrorBudget(cfg.ErrorBudget)
 }
 
 // InitChunk initializes the chunk capacitor with the given watcher.
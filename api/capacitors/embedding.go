@@ -8,41 +8,71 @@ import (
 	"github.com/zoobzio/check"
 	"github.com/zoobzio/flux"
 	"github.com/zoobzio/vicky/api/ingest"
+	"github.com/zoobzio/vicky/models"
 )
 
 // Embedding holds operational settings for the embed stage.
 // Hot-reloadable via flux.
 type Embedding struct {
-	Workers   int           `json:"workers"`    // pool concurrency
-	BatchSize int           `json:"batch_size"` // texts per API call
-	Timeout   time.Duration `json:"timeout"`    // per-batch timeout
+	Workers         int           `json:"workers"`          // pool concurrency
+	BatchSize       int           `json:"batch_size"`       // texts per API call
+	Timeout         time.Duration `json:"timeout"`          // per-batch timeout
+	DocumentVectors *bool         `json:"document_vectors"` // pool chunk vectors into document vectors
+	Cache           *bool         `json:"cache"`            // reuse embeddings of identical inputs
+
+	// Strategy names the enrichment applied to chunk text before embedding;
+	// it is recorded on every version ingested with it. Templates are Go
+	// text/templates per content type over ingest.EmbeddingInput, e.g.
+	// "{{.Path}} {{.Symbol}}\n{{.Signature}}\n{{.Content}}". Content types
+	// without a template embed the chunk content verbatim.
+	Strategy  string                        `json:"strategy"`
+	Templates map[models.ContentType]string `json:"templates"`
 }
 
 // Validate checks Embedding configuration.
 // Zero values are allowed and mean "use default".
 func (c Embedding) Validate() error {
-	return check.All(
-		check.NonNegative(c.Workers, "workers"),
-		check.Max(c.Workers, 100, "workers"),
-		check.NonNegative(c.BatchSize, "batch_size"),
-		check.Max(c.BatchSize, 1000, "batch_size"),
-		check.DurationNonNegative(c.Timeout, "timeout"),
-		check.DurationMax(c.Timeout, 10*time.Minute, "timeout"),
+	return check.Merge(
+		check.All(
+			check.NonNegative(c.Workers, "workers"),
+			check.Max(c.Workers, 100, "workers"),
+			check.NonNegative(c.BatchSize, "batch_size"),
+			check.Max(c.BatchSize, 1000, "batch_size"),
+			check.DurationNonNegative(c.Timeout, "timeout"),
+			check.DurationMax(c.Timeout, 10*time.Minute, "timeout"),
+			check.Str(c.Strategy, "strategy").When(len(c.Templates) > 0, func(b *check.StrBuilder) {
+				b.Required()
+			}).V(),
+		),
+		check.EachKey(c.Templates, func(contentType models.ContentType) *check.Validation {
+			return check.OneOfValues(contentType, models.ContentTypes, "templates")
+		}),
+		check.EachMapValue(c.Templates, func(text string) *check.Validation {
+			_, err := ingest.ParseEmbeddingTemplate(text)
+			return check.Equal(err == nil, true, "templates")
+		}),
 	).Err()
 }
 
 // DefaultEmbedding returns Embedding configuration with sensible defaults.
 func DefaultEmbedding() Embedding {
+	enabled := true
 	return Embedding{
-		Workers:   4,
-		BatchSize: 128,
-		Timeout:   30 * time.Second,
+		Workers:         4,
+		BatchSize:       128,
+		Timeout:         30 * time.Second,
+		DocumentVectors: &enabled,
+		Cache:           &enabled,
+		Strategy:        models.RawEmbeddingStrategy,
 	}
 }
 
 // applyEmbedding applies config to the embed pool.
 func applyEmbedding(cfg Embedding) {
 	ingest.SetEmbedConfig(cfg.Workers, cfg.BatchSize, cfg.Timeout)
+	ingest.SetDocumentVectors(cfg.DocumentVectors)
+	ingest.SetEmbeddingCache(cfg.Cache)
+	ingest.SetEmbeddingStrategy(cfg.Strategy, cfg.Templates)
 }
 
 // InitEmbedding initializes the embedding capacitor with the given watcher.
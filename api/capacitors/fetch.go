@@ -13,8 +13,9 @@ import (
 // Fetch holds operational settings for the fetch stage.
 // Hot-reloadable via flux.
 type Fetch struct {
-	Workers int           `json:"workers"` // pool concurrency
-	Timeout time.Duration `json:"timeout"` // per-file timeout
+	Workers     int           `json:"workers"`      // pool concurrency
+	Timeout     time.Duration `json:"timeout"`      // per-file timeout
+	ErrorBudget float64       `json:"error_budget"` // fraction of files allowed to fail; 0 fails on the first
 }
 
 // Validate checks Fetch configuration.
@@ -25,20 +26,23 @@ func (c Fetch) Validate() error {
 		check.Max(c.Workers, 100, "workers"),
 		check.DurationNonNegative(c.Timeout, "timeout"),
 		check.DurationMax(c.Timeout, 10*time.Minute, "timeout"),
+		check.Between(c.ErrorBudget, 0, 1, "error_budget"),
 	).Err()
 }
 
 // DefaultFetch returns Fetch configuration with sensible defaults.
 func DefaultFetch() Fetch {
 	return Fetch{
-		Workers: 8,
-		Timeout: 30 * time.Second,
+		Workers:     8,
+		Timeout:     30 * time.Second,
+		ErrorBudget: 0.05,
 	}
 }
 
 // applyFetch applies config to the fetch pool.
 func applyFetch(cfg Fetch) {
 	ingest.SetFetchConfig(cfg.Workers, cfg.Timeout)
+	ingest.SetFetchErrorBudget(cfg.ErrorBudget)
 }
 
 // InitFetch initializes the fetch capacitor with the given watcher.
@@ -13,8 +13,9 @@ import (
 // Parse holds operational settings for the parse stage.
 // Hot-reloadable via flux.
 type Parse struct {
-	Workers int           `json:"workers"` // pool concurrency
-	Timeout time.Duration `json:"timeout"` // per-document timeout
+	Workers     int           `json:"workers"`      // pool concurrency
+	Timeout     time.Duration `json:"timeout"`      // per-document timeout
+	ErrorBudget float64       `json:"error_budget"` // fraction of files allowed to fail; 0 fails on the first
 }
 
 // Validate checks Parse configuration.
@@ -25,20 +26,23 @@ func (c Parse) Validate() error {
 		check.Max(c.Workers, 100, "workers"),
 		check.DurationNonNegative(c.Timeout, "timeout"),
 		check.DurationMax(c.Timeout, 30*time.Minute, "timeout"),
+		check.Between(c.ErrorBudget, 0, 1, "error_budget"),
 	).Err()
 }
 
 // DefaultParse returns Parse configuration with sensible defaults.
 func DefaultParse() Parse {
 	return Parse{
-		Workers: 8,
-		Timeout: 60 * time.Second,
+		Workers:     8,
+		Timeout:     60 * time.Second,
+		ErrorBudget: 0.05,
 	}
 }
 
 // applyParse applies config to the parse pool.
 func applyParse(cfg Parse) {
 	ingest.SetParseConfig(cfg.Workers, cfg.Timeout)
+	ingest.SetParseErrorBudget(cfg.ErrorBudget)
 }
 
 // InitParse initializes the parse capacitor with the given watcher.
@@ -0,0 +1,101 @@
+package capacitors
+
+import (
+	"context"
+	"log"
+	"time"
+
+	"github.com/zoobzio/check"
+	"github.com/zoobzio/flux"
+	"github.com/zoobzio/vicky/api/ingest"
+)
+
+// Pipeline holds operational settings for the outer ingestion pipeline:
+// whole-stage timeouts, stage retries, how often a job may be claimed, and
+// how many jobs run at once, per process and per user or repository.
+// Hot-reloadable via flux.
+type Pipeline struct {
+	Workers               int           `json:"workers"`                // concurrent jobs per process
+	Retries               int           `json:"retries"`                // attempts per stage
+	UserConcurrency       int           `json:"user_concurrency"`       // running jobs per user
+	RepositoryConcurrency int           `json:"repository_concurrency"` // running jobs per repository
+	MaxAttempts           int           `json:"max_attempts"`           // claims per job before it fails
+	FetchTimeout          time.Duration `json:"fetch_timeout"`          // whole fetch stage
+	ParseTimeout          time.Duration `json:"parse_timeout"`          // whole parse stage
+	ChunkTimeout          time.Duration `json:"chunk_timeout"`          // whole chunk stage
+	EmbedTimeout          time.Duration `json:"embed_timeout"`          // whole embed stage
+	StoreTimeout          time.Duration `json:"store_timeout"`          // whole store stage
+}
+
+// Validate checks Pipeline configuration.
+// Zero values are allowed and mean "use default".
+func (c Pipeline) Validate() error {
+	return check.All(
+		check.NonNegative(c.Workers, "workers"),
+		check.Max(c.Workers, ingest.MaxWorkers, "workers"),
+		check.NonNegative(c.Retries, "retries"),
+		check.Max(c.Retries, 10, "retries"),
+		check.NonNegative(c.UserConcurrency, "user_concurrency"),
+		check.Max(c.UserConcurrency, ingest.MaxWorkers, "user_concurrency"),
+		check.NonNegative(c.RepositoryConcurrency, "repository_concurrency"),
+		check.Max(c.RepositoryConcurrency, ingest.MaxWorkers, "repository_concurrency"),
+		check.NonNegative(c.MaxAttempts, "max_attempts"),
+		check.Max(c.MaxAttempts, 100, "max_attempts"),
+		check.DurationNonNegative(c.FetchTimeout, "fetch_timeout"),
+		check.DurationMax(c.FetchTimeout, 6*time.Hour, "fetch_timeout"),
+		check.DurationNonNegative(c.ParseTimeout, "parse_timeout"),
+		check.DurationMax(c.ParseTimeout, 6*time.Hour, "parse_timeout"),
+		check.DurationNonNegative(c.ChunkTimeout, "chunk_timeout"),
+		check.DurationMax(c.ChunkTimeout, 6*time.Hour, "chunk_timeout"),
+		check.DurationNonNegative(c.EmbedTimeout, "embed_timeout"),
+		check.DurationMax(c.EmbedTimeout, 6*time.Hour, "embed_timeout"),
+		check.DurationNonNegative(c.StoreTimeout, "store_timeout"),
+		check.DurationMax(c.StoreTimeout, 6*time.Hour, "store_timeout"),
+	).Err()
+}
+
+// DefaultPipeline returns Pipeline configuration with sensible defaults.
+func DefaultPipeline() Pipeline {
+	return Pipeline{
+		Workers:               ingest.DefaultWorkers,
+		Retries:               ingest.DefaultRetries,
+		UserConcurrency:       ingest.DefaultUserConcurrency,
+		RepositoryConcurrency: ingest.DefaultRepositoryConcurrency,
+		MaxAttempts:           ingest.DefaultMaxAttempts,
+		FetchTimeout:          ingest.FetchTimeout,
+		ParseTimeout:          ingest.ParseTimeout,
+		ChunkTimeout:          ingest.ChunkTimeout,
+		EmbedTimeout:          ingest.EmbedTimeout,
+		StoreTimeout:          ingest.StoreTimeout,
+	}
+}
+
+// applyPipeline applies config to the pipeline wrappers and job workers.
+func applyPipeline(cfg Pipeline) {
+	ingest.SetWorkerCount(cfg.Workers)
+	ingest.SetStageRetries(cfg.Retries)
+	ingest.SetConcurrencyLimits(cfg.UserConcurrency, cfg.RepositoryConcurrency)
+	ingest.SetMaxAttempts(cfg.MaxAttempts)
+	ingest.SetStageTimeouts(cfg.FetchTimeout, cfg.ParseTimeout, cfg.ChunkTimeout, cfg.EmbedTimeout, cfg.StoreTimeout)
+}
+
+// InitPipeline initializes the pipeline capacitor with the given watcher.
+func InitPipeline(ctx context.Context, watcher flux.Watcher) error {
+	// Apply defaults
+	applyPipeline(DefaultPipeline())
+
+	c := flux.New[Pipeline](
+		watcher,
+		func(_ context.Context, _, curr Pipeline) error {
+			applyPipeline(curr)
+			return nil
+		},
+	)
+
+	go func() {
+		if err := c.Start(ctx); err != nil {
+			log.Printf("pipeline capacitor error: %v", err)
+		}
+	}()
+	return nil
+}
@@ -15,8 +15,12 @@ type Blobs interface {
 	PutBlob(ctx context.Context, userID int64, blob *models.Blob) error
 	// DeleteByPath removes a blob by its domain coordinates.
 	DeleteByPath(ctx context.Context, userID int64, owner, repo, tag, path string) error
+	// DeleteByVersion removes every blob in a version.
+	DeleteByVersion(ctx context.Context, userID int64, owner, repo, tag string) error
 	// ListByVersion returns object info for all blobs in a version.
 	ListByVersion(ctx context.Context, userID int64, owner, repo, tag string, limit int) ([]grub.ObjectInfo, error)
+	// ListPathsByVersion returns the path of every blob in a version.
+	ListPathsByVersion(ctx context.Context, userID int64, owner, repo, tag string) ([]string, error)
 	// ListByRepo returns object info for all blobs in a repository.
 	ListByRepo(ctx context.Context, userID int64, owner, repo string, limit int) ([]grub.ObjectInfo, error)
 }
@@ -8,7 +8,7 @@ import (
 
 // Chunker defines the contract for language-aware content chunking.
 // Language is a plain string to support values beyond models.Language
-// (e.g. "markdown" for documentation files).
+// (a models.ContentFormat such as "markdown" or "proto" for other files).
 type Chunker interface {
 	// Chunk splits file content into semantic chunks using a language-specific parser.
 	Chunk(ctx context.Context, language string, filename string, content []byte) ([]chunker.Result, error)
@@ -17,6 +17,12 @@ type Chunks interface {
 	ListByUserRepoAndTag(ctx context.Context, userID int64, owner, repoName, tag string) ([]*models.Chunk, error)
 	// ListByUserRepoTagAndPath retrieves all chunks for a document.
 	ListByUserRepoTagAndPath(ctx context.Context, userID int64, owner, repoName, tag, path string) ([]*models.Chunk, error)
+	// DeleteByDocument removes all chunks of a document.
+	DeleteByDocument(ctx context.Context, documentID int64) error
+	// ReplaceByDocument atomically replaces all chunks of a document with the given chunks.
+	ReplaceByDocument(ctx context.Context, documentID int64, chunks []*models.Chunk) error
+	// UpdateVectors writes the vectors of the given chunks in bulk.
+	UpdateVectors(ctx context.Context, chunks []*models.Chunk) error
 	// Search performs semantic search across chunks in a version.
 	Search(ctx context.Context, userID int64, owner, repoName, tag string, vector []float32, limit int) ([]*models.Chunk, error)
 	// SearchByKind performs semantic search filtered by chunk kind.
@@ -16,6 +16,8 @@ type Documents interface {
 	ListByUserRepoAndTag(ctx context.Context, userID int64, owner, repoName, tag string) ([]*models.Document, error)
 	// GetByUserRepoTagAndPath retrieves a document by natural identifiers.
 	GetByUserRepoTagAndPath(ctx context.Context, userID int64, owner, repoName, tag, path string) (*models.Document, error)
+	// DeleteByVersion removes every document of a version along with its chunks and SCIP rows.
+	DeleteByVersion(ctx context.Context, versionID int64) error
 	// FindSimilar finds documents similar to the given vector across a user's packages.
 	FindSimilar(ctx context.Context, userID int64, vector []float32, limit int) ([]*models.Document, error)
 	// FindSimilarInVersion finds documents similar to the given vector within a specific version.
@@ -12,4 +12,10 @@ type Embedder interface {
 
 	// Dimensions returns the vector dimensionality.
 	Dimensions() int
+
+	// Provider returns the embedding provider name.
+	Provider() string
+
+	// Model returns the embedding model name.
+	Model() string
 }
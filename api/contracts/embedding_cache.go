@@ -0,0 +1,15 @@
+package contracts
+
+import (
+	"context"
+
+	"github.com/zoobzio/vicky/models"
+)
+
+// EmbeddingCache defines the contract for content-addressed embedding storage.
+type EmbeddingCache interface {
+	// GetMany retrieves cached vectors by cache key; missing keys are absent from the result.
+	GetMany(ctx context.Context, keys []string) (map[string][]float32, error)
+	// PutMany stores embeddings for later reuse.
+	PutMany(ctx context.Context, entries []*models.EmbeddingCacheEntry) error
+}
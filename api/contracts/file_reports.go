@@ -0,0 +1,17 @@
+package contracts
+
+import (
+	"context"
+
+	"github.com/zoobzio/vicky/models"
+)
+
+// FileReports defines the contract for per-file ingestion report storage.
+type FileReports interface {
+	// Set creates or updates a report entry.
+	Set(ctx context.Context, key string, report *models.FileReport) error
+	// ListByJob retrieves every report entry for a job, ordered by path.
+	ListByJob(ctx context.Context, jobID int64) ([]*models.FileReport, error)
+	// ReplaceByJobAndStage atomically replaces a stage's report entries with the given entries.
+	ReplaceByJobAndStage(ctx context.Context, jobID int64, stage models.JobStage, reports []*models.FileReport) error
+}
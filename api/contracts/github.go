@@ -17,6 +17,10 @@ type GitHub interface {
 	GetFileContent(ctx context.Context, token, owner, repo, path, ref string) (*github.FileContent, error)
 
 	// GetFileContentBatch retrieves multiple files concurrently.
-	// Skips files that fail and returns successful results.
+	// Returns successful results along with a joined *github.FileError per failed file.
 	GetFileContentBatch(ctx context.Context, token, owner, repo, ref string, paths []string) ([]*github.FileContent, error)
+
+	// StreamArchive downloads the repository tarball at ref in a single request.
+	// Files accepted by include are passed to fn as they are read.
+	StreamArchive(ctx context.Context, token, owner, repo, ref string, include func(path string, size int64) bool, fn func(*github.FileContent) error) error
 }
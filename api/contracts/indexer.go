@@ -12,7 +12,8 @@ import (
 type Indexer interface {
 	// Index runs the appropriate SCIP indexer for the given request.
 	// The implementation fetches source from blob storage, runs the indexer,
-	// and returns the raw SCIP index data.
+	// and returns a reader over the raw SCIP index data, which the caller
+	// must close.
 	Index(ctx context.Context, req indexer.Request) (*indexer.Result, error)
 
 	// Supports returns true if this indexer supports the given language.
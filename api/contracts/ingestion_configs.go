@@ -14,6 +14,6 @@ type IngestionConfigs interface {
 	// Set creates or updates a config.
 	Set(ctx context.Context, key string, config *models.IngestionConfig) error
 
-	// GetByRepositoryID retrieves the config for a repository.
-	GetByRepositoryID(ctx context.Context, repositoryID int64) (*models.IngestionConfig, error)
+	// ListByRepositoryID retrieves a repository's configs, one per language root.
+	ListByRepositoryID(ctx context.Context, repositoryID int64) ([]*models.IngestionConfig, error)
 }
@@ -2,6 +2,7 @@ package contracts
 
 import (
 	"context"
+	"time"
 
 	"github.com/zoobzio/vicky/models"
 )
@@ -26,8 +27,24 @@ type Jobs interface {
 	// ListByStatus retrieves jobs with a specific status for a user.
 	ListByStatus(ctx context.Context, userID int64, status models.JobStatus) ([]*models.Job, error)
 
-	// UpdateProgress updates the job's stage, progress percentage, and items processed.
-	UpdateProgress(ctx context.Context, id int64, stage models.JobStage, progress int, itemsProcessed int) error
+	// UpdateProgress updates the job's stage, progress percentage, and item counts.
+	UpdateProgress(ctx context.Context, id int64, stage models.JobStage, progress int, itemsTotal int, itemsProcessed int) error
+
+	// RequestCancellation marks a pending or running job for cancellation.
+	RequestCancellation(ctx context.Context, id int64) error
+
+	// Claim leases the next pending or abandoned job in fair-scheduling order to a
+	// worker, skipping users and repositories at their limits, or returns nil if none.
+	Claim(ctx context.Context, workerID string, lease time.Duration, limits models.JobLimits) (*models.Job, error)
+
+	// Heartbeat extends a worker's lease on a job, reporting false if the lease was lost.
+	Heartbeat(ctx context.Context, id int64, workerID string, lease time.Duration) (bool, error)
+
+	// Release returns a leased job to the queue.
+	Release(ctx context.Context, id int64, workerID string) error
+
+	// Checkpoint records the last stage the job completed.
+	Checkpoint(ctx context.Context, id int64, stage models.JobStage) error
 
 	// Start marks the job as running and sets the started timestamp.
 	Start(ctx context.Context, id int64) error
@@ -12,6 +12,8 @@ type Repositories interface {
 	Get(ctx context.Context, key string) (*models.Repository, error)
 	// Set creates or updates a repository.
 	Set(ctx context.Context, key string, repo *models.Repository) error
+	// Register creates a repository and its ingestion configs together.
+	Register(ctx context.Context, repo *models.Repository, configs []*models.IngestionConfig) error
 	// ListByUserID retrieves all repositories for a user.
 	ListByUserID(ctx context.Context, userID int64) ([]*models.Repository, error)
 	// GetByUserAndGitHubID retrieves a repository by user and GitHub repo ID.
@@ -0,0 +1,17 @@
+package contracts
+
+import (
+	"context"
+	"io"
+)
+
+// SCIPIndexes defines the contract for storing raw SCIP indexes per version,
+// so SCIP data can be rebuilt without re-running an indexer.
+type SCIPIndexes interface {
+	// Put stores a version's raw SCIP index read from r, replacing any earlier one.
+	Put(ctx context.Context, userID int64, owner, repo string, versionID int64, r io.Reader, size int64) error
+	// Open returns a reader over a version's raw SCIP index, which the caller must close.
+	Open(ctx context.Context, userID int64, owner, repo string, versionID int64) (io.ReadSeekCloser, error)
+	// Delete removes a version's raw SCIP index.
+	Delete(ctx context.Context, userID int64, owner, repo string, versionID int64) error
+}
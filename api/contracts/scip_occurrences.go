@@ -14,6 +14,8 @@ type SCIPOccurrences interface {
 	Set(ctx context.Context, key string, occurrence *models.SCIPOccurrence) error
 	// ListByUserRepoAndTag retrieves all SCIP occurrences for a version.
 	ListByUserRepoAndTag(ctx context.Context, userID int64, owner, repoName, tag string) ([]*models.SCIPOccurrence, error)
+	// DeleteByUserRepoAndTag removes all SCIP occurrences for a version.
+	DeleteByUserRepoAndTag(ctx context.Context, userID int64, owner, repoName, tag string) error
 	// ListByDocument retrieves all SCIP occurrences for a document.
 	ListByDocument(ctx context.Context, documentID int64) ([]*models.SCIPOccurrence, error)
 	// ListBySymbol retrieves all occurrences of a specific symbol within a version.
@@ -12,8 +12,12 @@ type SCIPSymbols interface {
 	Get(ctx context.Context, key string) (*models.SCIPSymbol, error)
 	// Set creates or updates a SCIP symbol.
 	Set(ctx context.Context, key string, symbol *models.SCIPSymbol) error
+	// InsertBatch writes one document's SCIP symbols, relationships, and occurrences together.
+	InsertBatch(ctx context.Context, batch *models.SCIPBatch) error
 	// ListByUserRepoAndTag retrieves all SCIP symbols for a version.
 	ListByUserRepoAndTag(ctx context.Context, userID int64, owner, repoName, tag string) ([]*models.SCIPSymbol, error)
+	// DeleteByUserRepoAndTag removes all SCIP symbols and their relationships for a version.
+	DeleteByUserRepoAndTag(ctx context.Context, userID int64, owner, repoName, tag string) error
 	// ListByDocument retrieves all SCIP symbols for a document.
 	ListByDocument(ctx context.Context, documentID int64) ([]*models.SCIPSymbol, error)
 	// GetBySymbol retrieves a SCIP symbol by its qualified identifier within a version.
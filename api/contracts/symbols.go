@@ -16,6 +16,10 @@ type Symbols interface {
 	ListByUserRepoAndTag(ctx context.Context, userID int64, owner, repoName, tag string) ([]*models.Symbol, error)
 	// ListExportedByUserRepoAndTag retrieves all exported symbols for a version.
 	ListExportedByUserRepoAndTag(ctx context.Context, userID int64, owner, repoName, tag string) ([]*models.Symbol, error)
+	// DeleteByUserRepoAndTag removes all symbols for a version.
+	DeleteByUserRepoAndTag(ctx context.Context, userID int64, owner, repoName, tag string) error
+	// UpdateVectors writes the vectors of the given symbols in bulk.
+	UpdateVectors(ctx context.Context, symbols []*models.Symbol) error
 	// FindRelated finds symbols related to the given document vector.
 	FindRelated(ctx context.Context, userID int64, owner, repoName, tag string, docVector []float32, limit int) ([]*models.Symbol, error)
 	// FindRelatedExported finds exported symbols related to the given document vector.
@@ -12,10 +12,18 @@ type Versions interface {
 	Get(ctx context.Context, key string) (*models.Version, error)
 	// Set creates or updates a version.
 	Set(ctx context.Context, key string, version *models.Version) error
-	// ListByUserAndRepo retrieves all versions for a repository.
+	// ListByUserAndRepo retrieves all live versions for a repository.
 	ListByUserAndRepo(ctx context.Context, userID int64, owner, repoName string) ([]*models.Version, error)
-	// GetByUserRepoAndTag retrieves a version by natural identifiers.
+	// GetByUserRepoAndTag retrieves the live version by natural identifiers.
 	GetByUserRepoAndTag(ctx context.Context, userID int64, owner, repoName, tag string) (*models.Version, error)
+	// GetStaging retrieves the staging version replacing a live version, or nil.
+	GetStaging(ctx context.Context, liveID int64) (*models.Version, error)
+	// Promote swaps a staging version in for the live version it replaces.
+	Promote(ctx context.Context, id int64) (*models.Version, error)
+	// ReplaceSCIP swaps the SCIP rows and symbols a rebuild wrote under dataTag in for a live version's current ones.
+	ReplaceSCIP(ctx context.Context, id int64, dataTag string) error
 	// UpdateStatus updates the ingestion status of a version.
 	UpdateStatus(ctx context.Context, id int64, status models.VersionStatus, versionErr *string) (*models.Version, error)
+	// UpdateEmbeddingStrategy records how the version's chunks are enriched before embedding.
+	UpdateEmbeddingStrategy(ctx context.Context, id int64, strategy *models.EmbeddingStrategy) error
 }
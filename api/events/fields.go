@@ -35,8 +35,10 @@ var (
 	ReasonKey = capitan.NewStringKey("reason")
 
 	// Identifiers
-	SymbolKey  = capitan.NewStringKey("symbol")
-	ChunkIDKey = capitan.NewInt64Key("chunk_id")
+	SymbolKey   = capitan.NewStringKey("symbol")
+	SymbolIDKey = capitan.NewInt64Key("symbol_id")
+	ChunkIDKey  = capitan.NewInt64Key("chunk_id")
+	WorkerKey   = capitan.NewStringKey("worker")
 )
 
 // Operational signals for debug/error logging within pipeline stages.
@@ -50,15 +52,33 @@ var (
 	ParseDocumentCreatedSignal = capitan.NewSignal("vicky.ingest.parse.document.created", "Document row created")
 	ParseSymbolStoredSignal    = capitan.NewSignal("vicky.ingest.parse.symbol.stored", "Symbol stored")
 	ParseSymbolErrorSignal     = capitan.NewSignal("vicky.ingest.parse.symbol.error", "Failed to store symbol")
-	ParseOccurrenceErrorSignal = capitan.NewSignal("vicky.ingest.parse.occurrence.error", "Failed to store occurrence")
-	ParseRelationshipErrorSignal = capitan.NewSignal("vicky.ingest.parse.relationship.error", "Failed to store relationship")
+	ParseStoreErrorSignal      = capitan.NewSignal("vicky.ingest.parse.store.error", "Failed to store a document's SCIP data")
+	ParseIndexStoreErrorSignal = capitan.NewSignal("vicky.ingest.parse.index.store.error", "Failed to keep the raw SCIP index")
 
 	// Chunk stage operations
 	ChunkBlobErrorSignal    = capitan.NewSignal("vicky.ingest.chunk.blob.error", "Failed to fetch blob for chunking")
 	ChunkSkippedSignal      = capitan.NewSignal("vicky.ingest.chunk.skipped", "Document skipped - no chunker available")
 	ChunkProcessErrorSignal = capitan.NewSignal("vicky.ingest.chunk.process.error", "Failed to chunk document")
-	ChunkStoreErrorSignal   = capitan.NewSignal("vicky.ingest.chunk.store.error", "Failed to store chunk")
+	ChunkStoreErrorSignal   = capitan.NewSignal("vicky.ingest.chunk.store.error", "Failed to store a document's chunks")
 
 	// Embed stage operations
-	EmbedChunkErrorSignal = capitan.NewSignal("vicky.ingest.embed.chunk.error", "Failed to update chunk with embedding")
+	EmbedChunkErrorSignal  = capitan.NewSignal("vicky.ingest.embed.chunk.error", "Failed to update chunks with embeddings")
+	EmbedSymbolErrorSignal = capitan.NewSignal("vicky.ingest.embed.symbol.error", "Failed to update symbols with embeddings")
+	EmbedCacheErrorSignal  = capitan.NewSignal("vicky.ingest.embed.cache.error", "Embedding cache unavailable; embedding without it")
+
+	// Store stage operations
+	StoreBlobCleanupErrorSignal  = capitan.NewSignal("vicky.ingest.store.blob.cleanup.error", "Failed to move blobs after version swap")
+	StoreIndexCleanupErrorSignal = capitan.NewSignal("vicky.ingest.store.index.cleanup.error", "Failed to remove the replaced version's SCIP index")
+
+	// Worker queue operations
+	WorkerClaimErrorSignal     = capitan.NewSignal("vicky.ingest.worker.claim.error", "Failed to claim job from queue")
+	WorkerHeartbeatErrorSignal = capitan.NewSignal("vicky.ingest.worker.heartbeat.error", "Failed to renew job lease")
+	WorkerLeaseLostSignal      = capitan.NewSignal("vicky.ingest.worker.lease.lost", "Job lease reclaimed by another worker")
+	WorkerJobReleasedSignal    = capitan.NewSignal("vicky.ingest.worker.job.released", "Job returned to queue on shutdown")
+
+	// Progress reporting
+	ProgressErrorSignal = capitan.NewSignal("vicky.ingest.progress.error", "Failed to persist job progress")
+
+	// File reports
+	ReportErrorSignal = capitan.NewSignal("vicky.ingest.report.error", "Failed to persist file report")
 )
@@ -52,9 +52,19 @@ type FetchEvent struct {
 	VersionID    int64  `json:"version_id"`
 	CommitSHA    string `json:"commit_sha"`
 	ByteCount    int64  `json:"byte_count,omitempty"`
+	ReusedCount  int    `json:"reused_count,omitempty"`
+	FailedCount  int    `json:"failed_count,omitempty"`
 	Error        string `json:"error,omitempty"`
 }
 
+// FetchFileEvent is emitted per-file during fetching.
+type FetchFileEvent struct {
+	RepositoryID int64  `json:"repository_id"`
+	VersionID    int64  `json:"version_id"`
+	FilePath     string `json:"file_path"`
+	Reason       string `json:"reason,omitempty"`
+}
+
 // ParseEvent is emitted during the parse stage.
 type ParseEvent struct {
 	RepositoryID   int64  `json:"repository_id"`
@@ -81,17 +91,35 @@ type ChunkEvent struct {
 	RepositoryID int64  `json:"repository_id"`
 	VersionID    int64  `json:"version_id"`
 	ChunkCount   int    `json:"chunk_count,omitempty"`
+	ReusedCount  int    `json:"reused_count,omitempty"`
 	Error        string `json:"error,omitempty"`
 }
 
 // EmbedStageEvent is emitted during the embed stage.
 type EmbedStageEvent struct {
-	RepositoryID int64         `json:"repository_id"`
-	VersionID    int64         `json:"version_id"`
-	ChunkCount   int           `json:"chunk_count,omitempty"`
-	BatchCount   int           `json:"batch_count,omitempty"`
-	Duration     time.Duration `json:"duration,omitempty"`
-	Error        string        `json:"error,omitempty"`
+	RepositoryID  int64         `json:"repository_id"`
+	VersionID     int64         `json:"version_id"`
+	ChunkCount    int           `json:"chunk_count,omitempty"`
+	SymbolCount   int           `json:"symbol_count,omitempty"`
+	DocumentCount int           `json:"document_count,omitempty"`
+	BatchCount    int           `json:"batch_count,omitempty"`
+	ReusedCount   int           `json:"reused_count,omitempty"`
+	CacheHits     int           `json:"cache_hits,omitempty"`
+	CacheMisses   int           `json:"cache_misses,omitempty"`
+	Duration      time.Duration `json:"duration,omitempty"`
+	Error         string        `json:"error,omitempty"`
+}
+
+// EmbedCacheEvent reports how many embedding inputs in a stage were served
+// from the embedding cache instead of the provider.
+type EmbedCacheEvent struct {
+	RepositoryID int64   `json:"repository_id"`
+	VersionID    int64   `json:"version_id"`
+	Provider     string  `json:"provider"`
+	Model        string  `json:"model"`
+	Hits         int     `json:"hits"`
+	Misses       int     `json:"misses"`
+	HitRate      float64 `json:"hit_rate"`
 }
 
 // EmbedBatchEvent is emitted per batch during embedding.
@@ -106,12 +134,13 @@ type EmbedBatchEvent struct {
 
 // StoreEvent is emitted during the store stage.
 type StoreEvent struct {
-	RepositoryID int64         `json:"repository_id"`
-	VersionID    int64         `json:"version_id"`
-	ChunkCount   int           `json:"chunk_count,omitempty"`
-	SymbolCount  int           `json:"symbol_count,omitempty"`
-	Duration     time.Duration `json:"duration,omitempty"`
-	Error        string        `json:"error,omitempty"`
+	RepositoryID      int64         `json:"repository_id"`
+	VersionID         int64         `json:"version_id"`
+	ReplacedVersionID int64         `json:"replaced_version_id,omitempty"`
+	ChunkCount        int           `json:"chunk_count,omitempty"`
+	SymbolCount       int           `json:"symbol_count,omitempty"`
+	Duration          time.Duration `json:"duration,omitempty"`
+	Error             string        `json:"error,omitempty"`
 }
 
 // Ingest lifecycle signals.
@@ -124,9 +153,10 @@ var (
 
 // Fetch stage signals.
 var (
-	FetchStartedSignal   = capitan.NewSignal("vicky.ingest.fetch.started", "Repository fetch initiated")
-	FetchCompletedSignal = capitan.NewSignal("vicky.ingest.fetch.completed", "Repository fetch completed")
-	FetchFailedSignal    = capitan.NewSignal("vicky.ingest.fetch.failed", "Repository fetch failed")
+	FetchStartedSignal    = capitan.NewSignal("vicky.ingest.fetch.started", "Repository fetch initiated")
+	FetchCompletedSignal  = capitan.NewSignal("vicky.ingest.fetch.completed", "Repository fetch completed")
+	FetchFailedSignal     = capitan.NewSignal("vicky.ingest.fetch.failed", "Repository fetch failed")
+	FetchFileFailedSignal = capitan.NewSignal("vicky.ingest.fetch.file.failed", "File could not be fetched or stored")
 )
 
 // Parse stage signals.
@@ -151,6 +181,7 @@ var (
 	EmbedCompletedSignal      = capitan.NewSignal("vicky.ingest.embed.completed", "Embedding generation completed")
 	EmbedFailedSignal         = capitan.NewSignal("vicky.ingest.embed.failed", "Embedding generation failed")
 	EmbedBatchCompletedSignal = capitan.NewSignal("vicky.ingest.embed.batch.completed", "Embedding batch completed")
+	EmbedCacheReportedSignal  = capitan.NewSignal("vicky.ingest.embed.cache.reported", "Embedding cache hit rate for a stage")
 )
 
 // Store stage signals.
@@ -162,13 +193,15 @@ var (
 
 // fetchEvents provides access to fetch stage events.
 var fetchEvents = struct {
-	Started   sum.Event[FetchEvent]
-	Completed sum.Event[FetchEvent]
-	Failed    sum.Event[FetchEvent]
+	Started    sum.Event[FetchEvent]
+	Completed  sum.Event[FetchEvent]
+	Failed     sum.Event[FetchEvent]
+	FileFailed sum.Event[FetchFileEvent]
 }{
-	Started:   sum.NewDebugEvent[FetchEvent](FetchStartedSignal),
-	Completed: sum.NewDebugEvent[FetchEvent](FetchCompletedSignal),
-	Failed:    sum.NewErrorEvent[FetchEvent](FetchFailedSignal),
+	Started:    sum.NewDebugEvent[FetchEvent](FetchStartedSignal),
+	Completed:  sum.NewDebugEvent[FetchEvent](FetchCompletedSignal),
+	Failed:     sum.NewErrorEvent[FetchEvent](FetchFailedSignal),
+	FileFailed: sum.NewWarnEvent[FetchFileEvent](FetchFileFailedSignal),
 }
 
 // parseEvents provides access to parse stage events.
@@ -203,11 +236,13 @@ var embedEvents = struct {
 	Completed      sum.Event[EmbedStageEvent]
 	Failed         sum.Event[EmbedStageEvent]
 	BatchCompleted sum.Event[EmbedBatchEvent]
+	Cache          sum.Event[EmbedCacheEvent]
 }{
 	Started:        sum.NewDebugEvent[EmbedStageEvent](EmbedStartedSignal),
 	Completed:      sum.NewDebugEvent[EmbedStageEvent](EmbedCompletedSignal),
 	Failed:         sum.NewErrorEvent[EmbedStageEvent](EmbedFailedSignal),
 	BatchCompleted: sum.NewDebugEvent[EmbedBatchEvent](EmbedBatchCompletedSignal),
+	Cache:          sum.NewInfoEvent[EmbedCacheEvent](EmbedCacheReportedSignal),
 }
 
 // storeEvents provides access to store stage events.
@@ -229,9 +264,10 @@ var Ingest = struct {
 	Cancelled sum.Event[IngestCancelledEvent]
 
 	Fetch struct {
-		Started   sum.Event[FetchEvent]
-		Completed sum.Event[FetchEvent]
-		Failed    sum.Event[FetchEvent]
+		Started    sum.Event[FetchEvent]
+		Completed  sum.Event[FetchEvent]
+		Failed     sum.Event[FetchEvent]
+		FileFailed sum.Event[FetchFileEvent]
 	}
 	Parse struct {
 		Started       sum.Event[ParseEvent]
@@ -250,6 +286,7 @@ var Ingest = struct {
 		Completed      sum.Event[EmbedStageEvent]
 		Failed         sum.Event[EmbedStageEvent]
 		BatchCompleted sum.Event[EmbedBatchEvent]
+		Cache          sum.Event[EmbedCacheEvent]
 	}
 	Store struct {
 		Started   sum.Event[StoreEvent]
@@ -1,6 +1,11 @@
 package handlers
 
-import "github.com/zoobzio/rocco"
+import (
+	"errors"
+
+	"github.com/zoobzio/check"
+	"github.com/zoobzio/rocco"
+)
 
 // Handler errors using rocco's built-in error types.
 var (
@@ -8,7 +13,34 @@ var (
 	ErrVersionNotFound    = rocco.ErrNotFound.WithMessage("version not found or not ingested")
 	ErrMissingQuery       = rocco.ErrBadRequest.WithMessage("query parameter 'q' is required")
 	ErrInvalidLimit       = rocco.ErrBadRequest.WithMessage("limit must be between 1 and 100")
+	ErrInvalidKind        = rocco.ErrBadRequest.WithMessage("query parameter 'kind' is not a chunk kind")
 	ErrMissingSymbol      = rocco.ErrBadRequest.WithMessage("query parameter 'symbol' is required")
 	ErrKeyNotFound        = rocco.ErrNotFound.WithMessage("api key not found")
 	ErrKeyForbidden       = rocco.ErrForbidden.WithMessage("api key belongs to another user")
+	ErrIngestInProgress   = rocco.ErrConflict.WithMessage("an ingestion for this version is already in progress")
+	ErrJobNotFound        = rocco.ErrNotFound.WithMessage("job not found")
+	ErrJobForbidden       = rocco.ErrForbidden.WithMessage("job belongs to another user")
+	ErrJobNotCancellable  = rocco.ErrBadRequest.WithMessage("job cannot be cancelled (already completed, failed, or cancelled)")
+	ErrRefNotFound        = rocco.ErrNotFound.WithMessage("ref not found or repository not accessible")
+	ErrInvalidCommitSHA   = rocco.ErrBadRequest.WithMessage("query parameter 'commit_sha' must be a 40-character commit SHA")
+	ErrInvalidIndex       = rocco.ErrBadRequest.WithMessage("request body is not a SCIP index with at least one document")
+	ErrIndexTooLarge      = rocco.ErrPayloadTooLarge.WithMessage("scip index exceeds the upload size limit")
 )
+
+// validationError reports a request that failed its own Validate the way
+// rocco reports input validation: a 422 with the invalid fields. Rocco only
+// runs Validate itself for value receivers.
+func validationError(err error) error {
+	var errs check.Errors
+	if !errors.As(err, &errs) {
+		errs = check.Errors{err}
+	}
+	fields := make([]rocco.ValidationFieldError, 0, len(errs))
+	for _, e := range errs {
+		var fe *check.FieldError
+		if errors.As(e, &fe) {
+			fields = append(fields, rocco.ValidationFieldError{Field: fe.Field, Message: fe.Message})
+		}
+	}
+	return rocco.ErrValidationFailed.WithDetails(rocco.ValidationDetails{Fields: fields})
+}
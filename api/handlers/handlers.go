@@ -14,11 +14,20 @@ func All() []rocco.Endpoint {
 		ListRepositories,
 		RegisterRepository,
 		GetRepository,
+		PreviewIngestion,
 
 		// Versions
 		ListVersions,
 		GetVersion,
 		TriggerIngest,
+		UploadIndex,
+		StreamProgress,
+
+		// Jobs
+		ListJobs,
+		GetJob,
+		GetJobReport,
+		CancelJob,
 
 		// Search
 		SearchChunks,
@@ -0,0 +1,246 @@
+package handlers
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/zoobzio/rocco"
+	"github.com/zoobzio/sum"
+	"github.com/zoobzio/vicky/api/contracts"
+	"github.com/zoobzio/vicky/api/transformers"
+	"github.com/zoobzio/vicky/api/wire"
+	"github.com/zoobzio/vicky/models"
+)
+
+// Progress stream configuration.
+const (
+	// progressPollInterval is how often a progress stream re-reads the job.
+	// Workers may run in another process, so the jobs table is the source of truth.
+	progressPollInterval = time.Second
+
+	// progressKeepAlive is how long a stream may stay silent before a
+	// keep-alive comment is sent to hold the connection open.
+	progressKeepAlive = 15 * time.Second
+)
+
+// ListJobs returns the authenticated user's ingestion jobs.
+var ListJobs = rocco.GET("/jobs", func(req *rocco.Request[rocco.NoBody]) (wire.JobListResponse, error) {
+	jobs := sum.MustUse[contracts.Jobs](req.Context)
+
+	userID, err := strconv.ParseInt(req.Identity.ID(), 10, 64)
+	if err != nil {
+		return wire.JobListResponse{}, err
+	}
+
+	var list []*models.Job
+	if status := req.Params.Query["status"]; status != "" {
+		list, err = jobs.ListByStatus(req.Context, userID, models.JobStatus(status))
+	} else {
+		list, err = jobs.ListByUser(req.Context, userID)
+	}
+	if err != nil {
+		return wire.JobListResponse{}, err
+	}
+
+	return transformers.JobsToList(list), nil
+}).WithQueryParams("status").
+	WithSummary("List jobs").
+	WithDescription("Returns the authenticated user's ingestion jobs, newest first, optionally filtered by status.").
+	WithTags("Jobs").
+	WithAuthentication()
+
+// GetJob returns one of the authenticated user's ingestion jobs.
+var GetJob = rocco.GET("/jobs/{id}", func(req *rocco.Request[rocco.NoBody]) (wire.JobResponse, error) {
+	jobs := sum.MustUse[contracts.Jobs](req.Context)
+
+	userID, err := strconv.ParseInt(req.Identity.ID(), 10, 64)
+	if err != nil {
+		return wire.JobResponse{}, err
+	}
+
+	job, err := jobs.Get(req.Context, req.Params.Path["id"])
+	if err != nil {
+		return wire.JobResponse{}, ErrJobNotFound
+	}
+
+	if job.UserID != userID {
+		return wire.JobResponse{}, ErrJobForbidden
+	}
+
+	return transformers.JobToResponse(job), nil
+}).WithPathParams("id").
+	WithSummary("Get job").
+	WithDescription("Returns an ingestion job's stage, status and progress.").
+	WithTags("Jobs").
+	WithErrors(ErrJobNotFound, ErrJobForbidden).
+	WithAuthentication()
+
+// CancelJob requests cancellation of one of the authenticated user's jobs.
+var CancelJob = rocco.POST("/jobs/{id}/cancel", func(req *rocco.Request[rocco.NoBody]) (wire.JobResponse, error) {
+	jobs := sum.MustUse[contracts.Jobs](req.Context)
+
+	userID, err := strconv.ParseInt(req.Identity.ID(), 10, 64)
+	if err != nil {
+		return wire.JobResponse{}, err
+	}
+
+	id := req.Params.Path["id"]
+
+	job, err := jobs.Get(req.Context, id)
+	if err != nil {
+		return wire.JobResponse{}, ErrJobNotFound
+	}
+
+	if job.UserID != userID {
+		return wire.JobResponse{}, ErrJobForbidden
+	}
+
+	if err := jobs.RequestCancellation(req.Context, job.ID); err != nil {
+		return wire.JobResponse{}, ErrJobNotCancellable
+	}
+
+	// Retrieve updated job
+	job, err = jobs.Get(req.Context, id)
+	if err != nil {
+		return wire.JobResponse{}, ErrJobNotFound
+	}
+
+	return transformers.JobToResponse(job), nil
+}).WithPathParams("id").
+	WithSummary("Cancel job").
+	WithDescription("Requests cancellation of a pending or running job. The worker aborts at the next stage boundary.").
+	WithTags("Jobs").
+	WithErrors(ErrJobNotFound, ErrJobForbidden, ErrJobNotCancellable).
+	WithAuthentication()
+
+// GetJobReport returns the per-file ingestion report for one of the
+// authenticated user's jobs.
+var GetJobReport = rocco.GET("/jobs/{id}/report", func(req *rocco.Request[rocco.NoBody]) (wire.JobReportResponse, error) {
+	jobs := sum.MustUse[contracts.Jobs](req.Context)
+	reports := sum.MustUse[contracts.FileReports](req.Context)
+
+	userID, err := strconv.ParseInt(req.Identity.ID(), 10, 64)
+	if err != nil {
+		return wire.JobReportResponse{}, err
+	}
+
+	job, err := jobs.Get(req.Context, req.Params.Path["id"])
+	if err != nil {
+		return wire.JobReportResponse{}, ErrJobNotFound
+	}
+
+	if job.UserID != userID {
+		return wire.JobReportResponse{}, ErrJobForbidden
+	}
+
+	entries, err := reports.ListByJob(req.Context, job.ID)
+	if err != nil {
+		return wire.JobReportResponse{}, err
+	}
+
+	outcome := models.FileOutcome(req.Params.Query["outcome"])
+	return transformers.FileReportsToJobReport(job.ID, entries, outcome), nil
+}).WithPathParams("id").
+	WithQueryParams("outcome").
+	WithSummary("Get job report").
+	WithDescription("Returns every file the job considered: included, excluded (with the pattern or rule that excluded it), skipped by the chunker, or failed (with the reason). Optionally filtered by outcome.").
+	WithTags("Jobs").
+	WithErrors(ErrJobNotFound, ErrJobForbidden).
+	WithAuthentication()
+
+// StreamProgress streams a version's ingestion progress as Server-Sent Events.
+// An event is sent whenever the version's latest job changes; the stream
+// ends once that job completes, fails or is cancelled.
+var StreamProgress = rocco.NewStreamHandler[rocco.NoBody, wire.JobProgressResponse](
+	"stream-progress",
+	http.MethodGet,
+	"/repositories/{owner}/{repo}/versions/{tag}/progress",
+	func(req *rocco.Request[rocco.NoBody], stream rocco.Stream[wire.JobProgressResponse]) error {
+		versions := sum.MustUse[contracts.Versions](req.Context)
+		jobs := sum.MustUse[contracts.Jobs](req.Context)
+
+		userID, err := strconv.ParseInt(req.Identity.ID(), 10, 64)
+		if err != nil {
+			return err
+		}
+
+		owner := req.Params.Path["owner"]
+		repoName := req.Params.Path["repo"]
+		tag := req.Params.Path["tag"]
+
+		version, err := versions.GetByUserRepoAndTag(req.Context, userID, owner, repoName, tag)
+		if err != nil {
+			return ErrVersionNotFound
+		}
+
+		// A re-ingest runs against the staging version that will replace this one
+		staging, err := versions.GetStaging(req.Context, version.ID)
+		if err != nil {
+			return err
+		}
+		if staging != nil {
+			version = staging
+		}
+
+		ticker := time.NewTicker(progressPollInterval)
+		defer ticker.Stop()
+
+		var last *models.Job
+		var idle time.Duration
+
+		for {
+			job, err := jobs.LatestByVersionID(req.Context, version.ID)
+			switch {
+			case err == nil && progressChanged(last, job):
+				if err := stream.Send(transformers.JobToProgress(job)); err != nil {
+					return err
+				}
+				if jobFinished(job.Status) {
+					return nil
+				}
+				last = job
+				idle = 0
+			case idle >= progressKeepAlive:
+				if err := stream.SendComment("keep-alive"); err != nil {
+					return err
+				}
+				idle = 0
+			}
+
+			select {
+			case <-stream.Done():
+				return nil
+			case <-ticker.C:
+				idle += progressPollInterval
+			}
+		}
+	},
+).WithPathParams("owner", "repo", "tag").
+	WithSummary("Stream ingestion progress").
+	WithDescription("Streams the version's ingestion progress as Server-Sent Events. Each event carries the job's stage, status, progress and item counts; the stream closes once the job completes, fails or is cancelled.").
+	WithTags("Versions", "Jobs").
+	WithErrors(ErrVersionNotFound).
+	WithAuthentication()
+
+// progressChanged reports whether job's progress differs from the last job
+// sent. Heartbeats move updated_at without any progress, so it is not compared.
+func progressChanged(last, job *models.Job) bool {
+	if last == nil {
+		return true
+	}
+	return job.Stage != last.Stage ||
+		job.Status != last.Status ||
+		job.Progress != last.Progress ||
+		job.ItemsProcessed != last.ItemsProcessed ||
+		job.ItemsTotal != last.ItemsTotal
+}
+
+// jobFinished reports whether a job status is terminal.
+func jobFinished(status models.JobStatus) bool {
+	switch status {
+	case models.JobStatusCompleted, models.JobStatusFailed, models.JobStatusCancelled:
+		return true
+	}
+	return false
+}
@@ -0,0 +1,336 @@
+//go:build testing
+
+package handlers
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	rtesting "github.com/zoobzio/rocco/testing"
+	"github.com/zoobzio/vicky/api/wire"
+	"github.com/zoobzio/vicky/models"
+	vickytest "github.com/zoobzio/vicky/testing"
+)
+
+func TestListJobs(t *testing.T) {
+	mj := &vickytest.MockJobs{
+		OnListByUser: func(ctx context.Context, userID int64) ([]*models.Job, error) {
+			if userID != 1000 {
+				t.Errorf("userID = %d, want 1000", userID)
+			}
+			return []*models.Job{vickytest.NewJob(t)}, nil
+		},
+	}
+
+	engine := vickytest.SetupHandlerTest(t, vickytest.WithJobs(mj))
+	engine.WithHandlers(ListJobs)
+
+	capture := rtesting.ServeRequest(engine, "GET", "/jobs", nil)
+	rtesting.AssertStatus(t, capture, 200)
+
+	var resp wire.JobListResponse
+	if err := capture.DecodeJSON(&resp); err != nil {
+		t.Fatalf("decode: %v", err)
+	}
+	if len(resp.Jobs) != 1 {
+		t.Errorf("len = %d, want 1", len(resp.Jobs))
+	}
+}
+
+func TestListJobs_ByStatus(t *testing.T) {
+	var gotStatus models.JobStatus
+	mj := &vickytest.MockJobs{
+		OnListByStatus: func(ctx context.Context, userID int64, status models.JobStatus) ([]*models.Job, error) {
+			gotStatus = status
+			return nil, nil
+		},
+	}
+
+	engine := vickytest.SetupHandlerTest(t, vickytest.WithJobs(mj))
+	engine.WithHandlers(ListJobs)
+
+	capture := rtesting.ServeRequest(engine, "GET", "/jobs?status=running", nil)
+	rtesting.AssertStatus(t, capture, 200)
+
+	if gotStatus != models.JobStatusRunning {
+		t.Errorf("status = %q, want %q", gotStatus, models.JobStatusRunning)
+	}
+}
+
+func TestGetJob(t *testing.T) {
+	job := vickytest.NewJob(t)
+	mj := &vickytest.MockJobs{
+		OnGet: func(ctx context.Context, key string) (*models.Job, error) {
+			return job, nil
+		},
+	}
+
+	engine := vickytest.SetupHandlerTest(t, vickytest.WithJobs(mj))
+	engine.WithHandlers(GetJob)
+
+	capture := rtesting.ServeRequest(engine, "GET", "/jobs/1", nil)
+	rtesting.AssertStatus(t, capture, 200)
+
+	var resp wire.JobResponse
+	if err := capture.DecodeJSON(&resp); err != nil {
+		t.Fatalf("decode: %v", err)
+	}
+	if resp.ID != job.ID {
+		t.Errorf("ID = %d, want %d", resp.ID, job.ID)
+	}
+}
+
+func TestGetJob_Forbidden(t *testing.T) {
+	job := vickytest.NewJob(t)
+	job.UserID = 2000
+	mj := &vickytest.MockJobs{
+		OnGet: func(ctx context.Context, key string) (*models.Job, error) {
+			return job, nil
+		},
+	}
+
+	engine := vickytest.SetupHandlerTest(t, vickytest.WithJobs(mj))
+	engine.WithHandlers(GetJob)
+
+	capture := rtesting.ServeRequest(engine, "GET", "/jobs/1", nil)
+	rtesting.AssertStatus(t, capture, 403)
+}
+
+func TestGetJob_NotFound(t *testing.T) {
+	mj := &vickytest.MockJobs{
+		OnGet: func(ctx context.Context, key string) (*models.Job, error) {
+			return nil, errors.New("not found")
+		},
+	}
+
+	engine := vickytest.SetupHandlerTest(t, vickytest.WithJobs(mj))
+	engine.WithHandlers(GetJob)
+
+	capture := rtesting.ServeRequest(engine, "GET", "/jobs/99", nil)
+	rtesting.AssertStatus(t, capture, 404)
+}
+
+func TestGetJobReport(t *testing.T) {
+	job := vickytest.NewJob(t)
+	rule := "vendor/**"
+	mj := &vickytest.MockJobs{
+		OnGet: func(ctx context.Context, key string) (*models.Job, error) {
+			return job, nil
+		},
+	}
+	mr := &vickytest.MockFileReports{
+		OnListByJob: func(ctx context.Context, jobID int64) ([]*models.FileReport, error) {
+			if jobID != job.ID {
+				t.Errorf("jobID = %d, want %d", jobID, job.ID)
+			}
+			return []*models.FileReport{
+				{Path: "main.go", Stage: models.JobStageFetch, Outcome: models.FileOutcomeIncluded},
+				{Path: "vendor/x.go", Stage: models.JobStageFetch, Outcome: models.FileOutcomeExcluded, Rule: &rule},
+			}, nil
+		},
+	}
+
+	engine := vickytest.SetupHandlerTest(t, vickytest.WithJobs(mj), vickytest.WithFileReports(mr))
+	engine.WithHandlers(GetJobReport)
+
+	capture := rtesting.ServeRequest(engine, "GET", "/jobs/1/report?outcome=excluded", nil)
+	rtesting.AssertStatus(t, capture, 200)
+
+	var resp wire.JobReportResponse
+	if err := capture.DecodeJSON(&resp); err != nil {
+		t.Fatalf("decode: %v", err)
+	}
+	if resp.Included != 1 || resp.Excluded != 1 {
+		t.Errorf("counts = %d/%d, want 1/1", resp.Included, resp.Excluded)
+	}
+	if len(resp.Files) != 1 || resp.Files[0].Path != "vendor/x.go" {
+		t.Fatalf("files = %+v, want only vendor/x.go", resp.Files)
+	}
+	if resp.Files[0].Rule == nil || *resp.Files[0].Rule != "vendor/**" {
+		t.Errorf("rule = %v, want vendor/**", resp.Files[0].Rule)
+	}
+}
+
+func TestGetJobReport_Forbidden(t *testing.T) {
+	job := vickytest.NewJob(t)
+	job.UserID = 2000
+	mj := &vickytest.MockJobs{
+		OnGet: func(ctx context.Context, key string) (*models.Job, error) {
+			return job, nil
+		},
+	}
+	mr := &vickytest.MockFileReports{
+		OnListByJob: func(ctx context.Context, jobID int64) ([]*models.FileReport, error) {
+			t.Error("report should not be read for another user's job")
+			return nil, nil
+		},
+	}
+
+	engine := vickytest.SetupHandlerTest(t, vickytest.WithJobs(mj), vickytest.WithFileReports(mr))
+	engine.WithHandlers(GetJobReport)
+
+	capture := rtesting.ServeRequest(engine, "GET", "/jobs/1/report", nil)
+	rtesting.AssertStatus(t, capture, 403)
+}
+
+func TestCancelJob(t *testing.T) {
+	job := vickytest.NewJob(t)
+	var cancelled int64
+	mj := &vickytest.MockJobs{
+		OnGet: func(ctx context.Context, key string) (*models.Job, error) {
+			return job, nil
+		},
+		OnRequestCancellation: func(ctx context.Context, id int64) error {
+			cancelled = id
+			job.Status = models.JobStatusCancelling
+			return nil
+		},
+	}
+
+	engine := vickytest.SetupHandlerTest(t, vickytest.WithJobs(mj))
+	engine.WithHandlers(CancelJob)
+
+	capture := rtesting.ServeRequest(engine, "POST", "/jobs/1/cancel", nil)
+	rtesting.AssertStatus(t, capture, 200)
+
+	if cancelled != job.ID {
+		t.Errorf("cancelled = %d, want %d", cancelled, job.ID)
+	}
+
+	var resp wire.JobResponse
+	if err := capture.DecodeJSON(&resp); err != nil {
+		t.Fatalf("decode: %v", err)
+	}
+	if resp.Status != models.JobStatusCancelling {
+		t.Errorf("Status = %q, want %q", resp.Status, models.JobStatusCancelling)
+	}
+}
+
+func TestCancelJob_Forbidden(t *testing.T) {
+	job := vickytest.NewJob(t)
+	job.UserID = 2000
+	mj := &vickytest.MockJobs{
+		OnGet: func(ctx context.Context, key string) (*models.Job, error) {
+			return job, nil
+		},
+		OnRequestCancellation: func(ctx context.Context, id int64) error {
+			t.Error("cancellation requested for another user's job")
+			return nil
+		},
+	}
+
+	engine := vickytest.SetupHandlerTest(t, vickytest.WithJobs(mj))
+	engine.WithHandlers(CancelJob)
+
+	capture := rtesting.ServeRequest(engine, "POST", "/jobs/1/cancel", nil)
+	rtesting.AssertStatus(t, capture, 403)
+}
+
+func TestCancelJob_NotCancellable(t *testing.T) {
+	job := vickytest.NewJob(t)
+	mj := &vickytest.MockJobs{
+		OnGet: func(ctx context.Context, key string) (*models.Job, error) {
+			return job, nil
+		},
+		OnRequestCancellation: func(ctx context.Context, id int64) error {
+			return errors.New("job cannot be cancelled")
+		},
+	}
+
+	engine := vickytest.SetupHandlerTest(t, vickytest.WithJobs(mj))
+	engine.WithHandlers(CancelJob)
+
+	capture := rtesting.ServeRequest(engine, "POST", "/jobs/1/cancel", nil)
+	rtesting.AssertStatus(t, capture, 400)
+}
+
+func TestStreamProgress_EndsWhenJobFinishes(t *testing.T) {
+	version := vickytest.NewVersion(t)
+	mv := &vickytest.MockVersions{
+		OnGetByUserRepoAndTag: func(ctx context.Context, userID int64, owner, repoName, tag string) (*models.Version, error) {
+			return version, nil
+		},
+	}
+
+	job := vickytest.NewJob(t)
+	job.Status = models.JobStatusCompleted
+	job.Progress = 100
+	mj := &vickytest.MockJobs{
+		OnLatestByVersionID: func(ctx context.Context, versionID int64) (*models.Job, error) {
+			if versionID != version.ID {
+				t.Errorf("versionID = %d, want %d", versionID, version.ID)
+			}
+			return job, nil
+		},
+	}
+
+	engine := vickytest.SetupHandlerTest(t, vickytest.WithVersions(mv), vickytest.WithJobs(mj))
+	engine.WithHandlers(StreamProgress)
+
+	capture := rtesting.ServeRequest(engine, "GET", "/repositories/testorg/testrepo/versions/v1.0.0/progress", nil)
+	rtesting.AssertStatus(t, capture, 200)
+
+	if ct := capture.ContentType(); ct != "text/event-stream" {
+		t.Errorf("Content-Type = %q, want text/event-stream", ct)
+	}
+	body := capture.BodyString()
+	if n := strings.Count(body, "data: "); n != 1 {
+		t.Fatalf("events = %d, want 1\n%s", n, body)
+	}
+	if !strings.Contains(body, `"status":"completed"`) || !strings.Contains(body, `"progress":100`) {
+		t.Errorf("unexpected event: %s", body)
+	}
+}
+
+func TestStreamProgress_FollowsStagingVersion(t *testing.T) {
+	live := vickytest.NewVersion(t)
+	staging := vickytest.NewVersion(t)
+	staging.ID = 11
+	staging.ReplacesID = &live.ID
+
+	mv := &vickytest.MockVersions{
+		OnGetByUserRepoAndTag: func(ctx context.Context, userID int64, owner, repoName, tag string) (*models.Version, error) {
+			return live, nil
+		},
+		OnGetStaging: func(ctx context.Context, liveID int64) (*models.Version, error) {
+			return staging, nil
+		},
+	}
+
+	var polled int64
+	mj := &vickytest.MockJobs{
+		OnLatestByVersionID: func(ctx context.Context, versionID int64) (*models.Job, error) {
+			polled = versionID
+			return &models.Job{ID: 2, VersionID: versionID, Status: models.JobStatusFailed}, nil
+		},
+	}
+
+	engine := vickytest.SetupHandlerTest(t, vickytest.WithVersions(mv), vickytest.WithJobs(mj))
+	engine.WithHandlers(StreamProgress)
+
+	capture := rtesting.ServeRequest(engine, "GET", "/repositories/testorg/testrepo/versions/v1.0.0/progress", nil)
+	rtesting.AssertStatus(t, capture, 200)
+
+	if polled != staging.ID {
+		t.Errorf("polled version = %d, want staging %d", polled, staging.ID)
+	}
+}
+
+func TestProgressChanged(t *testing.T) {
+	last := &models.Job{ID: 1, Stage: models.JobStageEmbed, Status: models.JobStatusRunning, Progress: 40, ItemsTotal: 10, ItemsProcessed: 4}
+
+	heartbeat := *last
+	heartbeat.UpdatedAt = last.UpdatedAt.Add(time.Minute)
+	if progressChanged(last, &heartbeat) {
+		t.Error("heartbeat reported as progress")
+	}
+
+	total := *last
+	total.ItemsTotal = 12
+	if !progressChanged(last, &total) {
+		t.Error("new items total not reported")
+	}
+}
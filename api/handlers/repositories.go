@@ -1,12 +1,14 @@
 package handlers
 
 import (
+	"fmt"
 	"strconv"
 
 	"github.com/zoobzio/rocco"
 	"github.com/zoobzio/sum"
 	"github.com/zoobzio/vicky/api/wire"
 	"github.com/zoobzio/vicky/api/contracts"
+	"github.com/zoobzio/vicky/api/ingest"
 	"github.com/zoobzio/vicky/models"
 	"github.com/zoobzio/vicky/api/transformers"
 )
@@ -34,36 +36,35 @@ var ListRepositories = rocco.GET("/repositories", func(req *rocco.Request[rocco.
 // RegisterRepository registers a new repository for ingestion.
 var RegisterRepository = rocco.POST("/repositories", func(req *rocco.Request[wire.RegisterRepositoryRequest]) (wire.RepositoryResponse, error) {
 	repos := sum.MustUse[contracts.Repositories](req.Context)
-	configs := sum.MustUse[contracts.IngestionConfigs](req.Context)
+
+	if err := req.Body.Validate(); err != nil {
+		return wire.RepositoryResponse{}, validationError(err)
+	}
 
 	userID, err := strconv.ParseInt(req.Identity.ID(), 10, 64)
 	if err != nil {
 		return wire.RepositoryResponse{}, err
 	}
 
-	// Create repository
 	repo := &models.Repository{UserID: userID}
 	transformers.ApplyRepositoryRegistration(req.Body, repo)
 
-	if err := repos.Set(req.Context, "", repo); err != nil {
-		return wire.RepositoryResponse{}, err
+	// An ingestion config per language root, stored with the repository
+	configs := make([]*models.IngestionConfig, len(req.Body.Configs))
+	for i, c := range req.Body.Configs {
+		configs[i] = &models.IngestionConfig{UserID: userID}
+		transformers.ApplyIngestionConfigRequest(c, configs[i])
 	}
 
-	// Create ingestion config
-	config := &models.IngestionConfig{
-		RepositoryID: repo.ID,
-		UserID:       userID,
-	}
-	transformers.ApplyIngestionConfigRequest(req.Body.Config, config)
-
-	if err := configs.Set(req.Context, "", config); err != nil {
+	if err := repos.Register(req.Context, repo, configs); err != nil {
 		return wire.RepositoryResponse{}, err
 	}
 
 	return transformers.RepositoryToResponse(repo), nil
 }).WithSummary("Register repository").
-	WithDescription("Registers a GitHub repository for ingestion with configuration.").
+	WithDescription("Registers a GitHub repository for ingestion with a configuration per language root.").
 	WithTags("Repositories").
+	WithErrors(rocco.ErrValidationFailed).
 	WithAuthentication().
 	WithSuccessStatus(201)
 
@@ -91,3 +92,59 @@ var GetRepository = rocco.GET("/repositories/{owner}/{repo}", func(req *rocco.Re
 	WithTags("Repositories").
 	WithErrors(ErrRepositoryNotFound).
 	WithAuthentication()
+
+// PreviewIngestion dry-runs a candidate ingestion config against a ref's tree.
+// Nothing is fetched beyond the tree listing and the repository's ignore
+// files, and nothing is stored.
+var PreviewIngestion = rocco.POST("/repositories/{owner}/{repo}/preview", func(req *rocco.Request[wire.PreviewRequest]) (wire.PreviewResponse, error) {
+	users := sum.MustUse[contracts.Users](req.Context)
+	gh := sum.MustUse[contracts.GitHub](req.Context)
+
+	if err := req.Body.Validate(); err != nil {
+		return wire.PreviewResponse{}, validationError(err)
+	}
+
+	user, err := users.Get(req.Context, req.Identity.ID())
+	if err != nil {
+		return wire.PreviewResponse{}, err
+	}
+
+	owner := req.Params.Path["owner"]
+	repoName := req.Params.Path["repo"]
+
+	tree, err := gh.GetTree(req.Context, user.AccessToken, owner, repoName, req.Body.Ref)
+	if err != nil {
+		return wire.PreviewResponse{}, ErrRefNotFound
+	}
+
+	configs := make([]*models.IngestionConfig, len(req.Body.Configs))
+	for i, c := range req.Body.Configs {
+		configs[i] = &models.IngestionConfig{}
+		transformers.ApplyIngestionConfigRequest(c, configs[i])
+	}
+
+	// Ignore files in excluded directories cannot change the outcome, so
+	// only the others are fetched; one that fails is previewed without rules
+	classifier := ingest.NewClassifier(models.ProjectConfigs(configs), nil)
+	ignore, failed := ingest.LoadIgnoreFiles(req.Context, user.AccessToken, owner, repoName, req.Body.Ref, classifier.IgnoreFiles(tree))
+	classifier = classifier.WithIgnore(ignore)
+
+	files := make([]models.FileClassification, 0, len(tree))
+	for _, entry := range tree {
+		if entry.Type != "blob" {
+			continue
+		}
+		fc := classifier.Classify(entry.Path, entry.Size)
+		if err, ok := failed[entry.Path]; ok {
+			fc.Reason = fmt.Sprintf("ignore file could not be read, its rules were not applied: %v", err)
+		}
+		files = append(files, fc)
+	}
+
+	return transformers.ClassificationsToPreview(req.Body.Ref, files), nil
+}).WithPathParams("owner", "repo").
+	WithSummary("Preview ingestion config").
+	WithDescription("Lists the ref's tree and applies the candidate configs' language roots, size limits, exclude and include patterns, the repository's .gitignore and .vickyignore files, and extension rules without ingesting anything. Files are grouped by decision with counts and byte totals.").
+	WithTags("Repositories").
+	WithErrors(ErrRefNotFound, rocco.ErrValidationFailed).
+	WithAuthentication()
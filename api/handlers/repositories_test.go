@@ -4,12 +4,15 @@ package handlers
 
 import (
 	"context"
+	"errors"
+	"strings"
 	"testing"
 
 	rtesting "github.com/zoobzio/rocco/testing"
 	vickytest "github.com/zoobzio/vicky/testing"
 	"github.com/zoobzio/vicky/models"
 	"github.com/zoobzio/vicky/api/wire"
+	"github.com/zoobzio/vicky/external/github"
 )
 
 func TestListRepositories(t *testing.T) {
@@ -36,22 +39,16 @@ func TestListRepositories(t *testing.T) {
 }
 
 func TestRegisterRepository(t *testing.T) {
+	var saved []*models.IngestionConfig
 	mr := &vickytest.MockRepositories{
-		OnSet: func(ctx context.Context, key string, repo *models.Repository) error {
+		OnRegister: func(ctx context.Context, repo *models.Repository, configs []*models.IngestionConfig) error {
 			repo.ID = 1
-			return nil
-		},
-	}
-	mc := &vickytest.MockIngestionConfigs{
-		OnSet: func(ctx context.Context, key string, config *models.IngestionConfig) error {
+			saved = configs
 			return nil
 		},
 	}
 
-	engine := vickytest.SetupHandlerTest(t,
-		vickytest.WithRepositories(mr),
-		vickytest.WithIngestionConfigs(mc),
-	)
+	engine := vickytest.SetupHandlerTest(t, vickytest.WithRepositories(mr))
 	engine.WithHandlers(RegisterRepository)
 
 	body := wire.RegisterRepositoryRequest{
@@ -61,7 +58,10 @@ func TestRegisterRepository(t *testing.T) {
 		FullName:      "testorg/testrepo",
 		DefaultBranch: "main",
 		HTMLURL:       "https://github.com/testorg/testrepo",
-		Config:        wire.IngestionConfigRequest{Language: "go"},
+		Configs: []wire.IngestionConfigRequest{
+			{Language: "go"},
+			{Language: "typescript", Root: "web/"},
+		},
 	}
 
 	capture := rtesting.ServeRequest(engine, "POST", "/repositories", body)
@@ -71,8 +71,56 @@ func TestRegisterRepository(t *testing.T) {
 	if err := capture.DecodeJSON(&resp); err != nil {
 		t.Fatalf("decode: %v", err)
 	}
-	if resp.Owner != "testorg" {
-		t.Errorf("Owner = %q, want %q", resp.Owner, "testorg")
+	if resp.Owner != "testorg" || resp.ID != 1 {
+		t.Errorf("response = %q/%d, want testorg/1", resp.Owner, resp.ID)
+	}
+
+	if len(saved) != 2 {
+		t.Fatalf("saved %d configs, want one per language root", len(saved))
+	}
+	if saved[0].Root != "" || saved[1].Root != "web" || saved[1].Language != models.LanguageTypeScript {
+		t.Errorf("roots = %q/%q, want the repository root and web", saved[0].Root, saved[1].Root)
+	}
+}
+
+func TestRegisterRepository_InvalidConfigs(t *testing.T) {
+	tests := []struct {
+		name    string
+		configs []wire.IngestionConfigRequest
+	}{
+		{"duplicate root", []wire.IngestionConfigRequest{{Language: "go"}, {Language: "go", Root: "./"}}},
+		{"root outside the repository", []wire.IngestionConfigRequest{{Language: "go", Root: "../other"}}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			registered := false
+			mr := &vickytest.MockRepositories{
+				OnRegister: func(ctx context.Context, repo *models.Repository, configs []*models.IngestionConfig) error {
+					registered = true
+					return nil
+				},
+			}
+
+			engine := vickytest.SetupHandlerTest(t, vickytest.WithRepositories(mr))
+			engine.WithHandlers(RegisterRepository)
+
+			body := wire.RegisterRepositoryRequest{
+				GitHubID:      123,
+				Owner:         "testorg",
+				Name:          "testrepo",
+				FullName:      "testorg/testrepo",
+				DefaultBranch: "main",
+				HTMLURL:       "https://github.com/testorg/testrepo",
+				Configs:       tt.configs,
+			}
+
+			capture := rtesting.ServeRequest(engine, "POST", "/repositories", body)
+			rtesting.AssertStatus(t, capture, 422)
+			if registered {
+				t.Error("invalid repository was registered")
+			}
+		})
 	}
 }
 
@@ -115,3 +163,298 @@ func TestGetRepository_NotFound(t *testing.T) {
 	capture := rtesting.ServeRequest(engine, "GET", "/repositories/testorg/nonexistent", nil)
 	rtesting.AssertStatus(t, capture, 404)
 }
+
+func TestPreviewIngestion(t *testing.T) {
+	var gotRef string
+	mg := &vickytest.MockGitHub{
+		OnGetTree: func(ctx context.Context, token, owner, repo, ref string) ([]github.TreeEntry, error) {
+			gotRef = ref
+			return []github.TreeEntry{
+				{Path: "main.go", Type: "blob", Size: 100},
+				{Path: "README.md", Type: "blob", Size: 40},
+				{Path: "vendor/lib.go", Type: "blob", Size: 50},
+				{Path: "gen/big.go", Type: "blob", Size: 600},
+				{Path: "logo.png", Type: "blob", Size: 10},
+				{Path: "cmd", Type: "tree"},
+			}, nil
+		},
+	}
+
+	engine := vickytest.SetupHandlerTest(t,
+		vickytest.WithUsers(&vickytest.MockUsers{}),
+		vickytest.WithGitHub(mg),
+	)
+	engine.WithHandlers(PreviewIngestion)
+
+	maxSize := int64(500)
+	body := wire.PreviewRequest{
+		Ref: "v1.0.0",
+		Configs: []wire.IngestionConfigRequest{{
+			Language:    "go",
+			IncludeDocs: true,
+			MaxFileSize: &maxSize,
+		}},
+	}
+
+	capture := rtesting.ServeRequest(engine, "POST", "/repositories/testorg/testrepo/preview", body)
+	rtesting.AssertStatus(t, capture, 200)
+
+	var resp wire.PreviewResponse
+	if err := capture.DecodeJSON(&resp); err != nil {
+		t.Fatalf("decode: %v", err)
+	}
+	if gotRef != "v1.0.0" {
+		t.Errorf("ref = %q, want v1.0.0", gotRef)
+	}
+	if resp.IncludedCount != 2 || resp.IncludedBytes != 140 {
+		t.Errorf("included = %d files / %d bytes, want 2 / 140", resp.IncludedCount, resp.IncludedBytes)
+	}
+
+	counts := make(map[models.FileDecision]int)
+	for _, g := range resp.Groups {
+		counts[g.Decision] = g.Count
+	}
+	want := map[models.FileDecision]int{
+		models.FileDecisionCode:        1,
+		models.FileDecisionDocs:        1,
+		models.FileDecisionExcluded:    1,
+		models.FileDecisionTooLarge:    1,
+		models.FileDecisionUnsupported: 1,
+	}
+	for d, n := range want {
+		if counts[d] != n {
+			t.Errorf("%s count = %d, want %d", d, counts[d], n)
+		}
+	}
+}
+
+func TestPreviewIngestion_LanguageRoots(t *testing.T) {
+	mg := &vickytest.MockGitHub{
+		OnGetTree: func(ctx context.Context, token, owner, repo, ref string) ([]github.TreeEntry, error) {
+			return []github.TreeEntry{
+				{Path: "main.go", Type: "blob", Size: 100},
+				{Path: "web/src/app.ts", Type: "blob", Size: 50},
+				{Path: "web/node_modules/lib/index.js", Type: "blob", Size: 20},
+				{Path: "scripts/build.ts", Type: "blob", Size: 30},
+			}, nil
+		},
+	}
+
+	engine := vickytest.SetupHandlerTest(t,
+		vickytest.WithUsers(&vickytest.MockUsers{}),
+		vickytest.WithGitHub(mg),
+	)
+	engine.WithHandlers(PreviewIngestion)
+
+	body := wire.PreviewRequest{
+		Ref: "main",
+		Configs: []wire.IngestionConfigRequest{
+			{Language: "go"},
+			{Language: "typescript", Root: "web"},
+		},
+	}
+
+	capture := rtesting.ServeRequest(engine, "POST", "/repositories/testorg/testrepo/preview", body)
+	rtesting.AssertStatus(t, capture, 200)
+
+	var resp wire.PreviewResponse
+	if err := capture.DecodeJSON(&resp); err != nil {
+		t.Fatalf("decode: %v", err)
+	}
+
+	decisions := make(map[string]models.FileDecision)
+	for _, g := range resp.Groups {
+		for _, f := range g.Files {
+			decisions[f.Path] = g.Decision
+		}
+	}
+	want := map[string]models.FileDecision{
+		"main.go":                       models.FileDecisionCode,
+		"web/src/app.ts":                models.FileDecisionCode,
+		"web/node_modules/lib/index.js": models.FileDecisionExcluded,
+		"scripts/build.ts":              models.FileDecisionUnsupported,
+	}
+	for path, d := range want {
+		if decisions[path] != d {
+			t.Errorf("%s = %s, want %s", path, decisions[path], d)
+		}
+	}
+}
+
+func TestPreviewIngestion_IgnoreFiles(t *testing.T) {
+	mg := &vickytest.MockGitHub{
+		OnGetTree: func(ctx context.Context, token, owner, repo, ref string) ([]github.TreeEntry, error) {
+			return []github.TreeEntry{
+				{Path: ".vickyignore", Type: "blob", Size: 10},
+				{Path: "main.go", Type: "blob", Size: 100},
+				{Path: "mocks/store.go", Type: "blob", Size: 50},
+			}, nil
+		},
+		OnGetFileContentBatch: func(ctx context.Context, token, owner, repo, ref string, paths []string) ([]*github.FileContent, error) {
+			return []*github.FileContent{{Path: ".vickyignore", Content: []byte("mocks/\n")}}, nil
+		},
+	}
+
+	engine := vickytest.SetupHandlerTest(t,
+		vickytest.WithUsers(&vickytest.MockUsers{}),
+		vickytest.WithGitHub(mg),
+	)
+	engine.WithHandlers(PreviewIngestion)
+
+	body := wire.PreviewRequest{
+		Ref:     "main",
+		Configs: []wire.IngestionConfigRequest{{Language: "go"}},
+	}
+
+	capture := rtesting.ServeRequest(engine, "POST", "/repositories/testorg/testrepo/preview", body)
+	rtesting.AssertStatus(t, capture, 200)
+
+	var resp wire.PreviewResponse
+	if err := capture.DecodeJSON(&resp); err != nil {
+		t.Fatalf("decode: %v", err)
+	}
+	for _, g := range resp.Groups {
+		if g.Decision != models.FileDecisionExcluded {
+			continue
+		}
+		if len(g.Files) != 1 || g.Files[0].Path != "mocks/store.go" {
+			t.Fatalf("excluded = %+v, want mocks/store.go", g.Files)
+		}
+		if f := g.Files[0]; f.Rule == nil || *f.Rule != "mocks/" || f.Reason == nil || *f.Reason != "matches .vickyignore line 1" {
+			t.Errorf("rule/reason = %v/%v, want the .vickyignore rule", f.Rule, f.Reason)
+		}
+	}
+}
+
+func TestPreviewIngestion_IgnoreFileFailures(t *testing.T) {
+	var requested []string
+	mg := &vickytest.MockGitHub{
+		OnGetTree: func(ctx context.Context, token, owner, repo, ref string) ([]github.TreeEntry, error) {
+			return []github.TreeEntry{
+				{Path: ".gitignore", Type: "blob", Size: 10},
+				{Path: "vendor/lib/.gitignore", Type: "blob", Size: 10},
+				{Path: "main.go", Type: "blob", Size: 100},
+			}, nil
+		},
+		OnGetFileContentBatch: func(ctx context.Context, token, owner, repo, ref string, paths []string) ([]*github.FileContent, error) {
+			requested = paths
+			return nil, &github.FileError{Path: ".gitignore", Err: errors.New("rate limited")}
+		},
+	}
+
+	engine := vickytest.SetupHandlerTest(t,
+		vickytest.WithUsers(&vickytest.MockUsers{}),
+		vickytest.WithGitHub(mg),
+	)
+	engine.WithHandlers(PreviewIngestion)
+
+	body := wire.PreviewRequest{
+		Ref:     "main",
+		Configs: []wire.IngestionConfigRequest{{Language: "go"}},
+	}
+
+	capture := rtesting.ServeRequest(engine, "POST", "/repositories/testorg/testrepo/preview", body)
+	rtesting.AssertStatus(t, capture, 200)
+
+	if len(requested) != 1 || requested[0] != ".gitignore" {
+		t.Errorf("requested = %v, want .gitignore only; vendor/ is excluded", requested)
+	}
+
+	var resp wire.PreviewResponse
+	if err := capture.DecodeJSON(&resp); err != nil {
+		t.Fatalf("decode: %v", err)
+	}
+	if resp.IncludedCount != 1 {
+		t.Errorf("included = %d, want 1", resp.IncludedCount)
+	}
+	for _, g := range resp.Groups {
+		for _, f := range g.Files {
+			if f.Path == ".gitignore" && (f.Reason == nil || !strings.Contains(*f.Reason, "rate limited")) {
+				t.Errorf(".gitignore reason = %v, want the fetch error", f.Reason)
+			}
+		}
+	}
+}
+
+func TestPreviewIngestion_RefNotFound(t *testing.T) {
+	mg := &vickytest.MockGitHub{
+		OnGetTree: func(ctx context.Context, token, owner, repo, ref string) ([]github.TreeEntry, error) {
+			return nil, errors.New("404 Not Found")
+		},
+	}
+
+	engine := vickytest.SetupHandlerTest(t,
+		vickytest.WithUsers(&vickytest.MockUsers{}),
+		vickytest.WithGitHub(mg),
+	)
+	engine.WithHandlers(PreviewIngestion)
+
+	body := wire.PreviewRequest{
+		Ref:     "missing",
+		Configs: []wire.IngestionConfigRequest{{Language: "go"}},
+	}
+
+	capture := rtesting.ServeRequest(engine, "POST", "/repositories/testorg/testrepo/preview", body)
+	rtesting.AssertStatus(t, capture, 404)
+}
+
+func TestPreviewIngestion_InvalidConfigs(t *testing.T) {
+	fetched := false
+	mg := &vickytest.MockGitHub{
+		OnGetTree: func(ctx context.Context, token, owner, repo, ref string) ([]github.TreeEntry, error) {
+			fetched = true
+			return nil, nil
+		},
+	}
+
+	engine := vickytest.SetupHandlerTest(t,
+		vickytest.WithUsers(&vickytest.MockUsers{}),
+		vickytest.WithGitHub(mg),
+	)
+	engine.WithHandlers(PreviewIngestion)
+
+	body := wire.PreviewRequest{
+		Ref:     "main",
+		Configs: []wire.IngestionConfigRequest{{Language: "go", Root: "cmd"}, {Language: "go", Root: "cmd/"}},
+	}
+
+	capture := rtesting.ServeRequest(engine, "POST", "/repositories/testorg/testrepo/preview", body)
+	rtesting.AssertStatus(t, capture, 422)
+	if fetched {
+		t.Error("tree fetched for an invalid preview")
+	}
+}
+
+func TestRegisterRepository_InvalidRootPaths(t *testing.T) {
+	for _, rootPaths := range [][]string{{"../shared"}, {"/abs"}, {"app", "lib/../../x"}} {
+		registered := false
+		mr := &vickytest.MockRepositories{
+			OnRegister: func(ctx context.Context, repo *models.Repository, configs []*models.IngestionConfig) error {
+				registered = true
+				return nil
+			},
+		}
+
+		engine := vickytest.SetupHandlerTest(t, vickytest.WithRepositories(mr))
+		engine.WithHandlers(RegisterRepository)
+
+		body := wire.RegisterRepositoryRequest{
+			GitHubID:      123,
+			Owner:         "testorg",
+			Name:          "testrepo",
+			FullName:      "testorg/testrepo",
+			DefaultBranch: "main",
+			HTMLURL:       "https://github.com/testorg/testrepo",
+			Configs:       []wire.IngestionConfigRequest{{Language: "typescript", Root: "web", RootPaths: rootPaths}},
+		}
+
+		capture := rtesting.ServeRequest(engine, "POST", "/repositories", body)
+		rtesting.AssertStatus(t, capture, 422)
+		if registered {
+			t.Errorf("root_paths %v: repository registered", rootPaths)
+		}
+		if !strings.Contains(capture.BodyString(), "root_paths") {
+			t.Errorf("root_paths %v: body = %s, want the root_paths field", rootPaths, capture.BodyString())
+		}
+	}
+}
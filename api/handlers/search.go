@@ -1,6 +1,7 @@
 package handlers
 
 import (
+	"slices"
 	"strconv"
 
 	"github.com/zoobzio/rocco"
@@ -37,13 +38,23 @@ var SearchChunks = rocco.GET("/search/{owner}/{repo}/{tag}", func(req *rocco.Req
 		}
 	}
 
+	kind := models.ChunkKind(req.Params.Query["kind"])
+	if kind != "" && !slices.Contains(models.ChunkKinds, kind) {
+		return wire.SearchResponse{}, ErrInvalidKind
+	}
+
 	vectors, err := embedder.EmbedQuery(req.Context, []string{query})
 	if err != nil {
 		return wire.SearchResponse{}, err
 	}
 	queryVector := vectors[0]
 
-	results, err := chunks.Search(req.Context, userID, owner, repoName, tag, queryVector, limit)
+	var results []*models.Chunk
+	if kind != "" {
+		results, err = chunks.SearchByKind(req.Context, userID, owner, repoName, tag, kind, queryVector, limit)
+	} else {
+		results, err = chunks.Search(req.Context, userID, owner, repoName, tag, queryVector, limit)
+	}
 	if err != nil {
 		return wire.SearchResponse{}, err
 	}
@@ -52,9 +63,9 @@ var SearchChunks = rocco.GET("/search/{owner}/{repo}/{tag}", func(req *rocco.Req
 }).WithPathParams("owner", "repo", "tag").
 	WithQueryParams("q", "limit", "kind").
 	WithSummary("Search chunks").
-	WithDescription("Performs semantic search across code and documentation chunks.").
+	WithDescription("Performs semantic search across code, documentation, and schema chunks, optionally of one kind such as function, section, message, endpoint, or table.").
 	WithTags("Search").
-	WithErrors(ErrMissingQuery).
+	WithErrors(ErrMissingQuery, ErrInvalidKind).
 	WithAuthentication()
 
 // SearchSymbols finds symbols related to a query.
@@ -50,6 +50,33 @@ func TestSearchChunks_MissingQuery(t *testing.T) {
 	rtesting.AssertStatus(t, capture, 400)
 }
 
+func TestSearchChunks_Kind(t *testing.T) {
+	var searched models.ChunkKind
+	mc := &vickytest.MockChunks{
+		OnSearch: func(ctx context.Context, userID int64, owner, repoName, tag string, vector []float32, limit int) ([]*models.Chunk, error) {
+			t.Error("unfiltered search for a kind")
+			return nil, nil
+		},
+		OnSearchByKind: func(ctx context.Context, userID int64, owner, repoName, tag string, kind models.ChunkKind, vector []float32, limit int) ([]*models.Chunk, error) {
+			searched = kind
+			return vickytest.NewChunks(t, 1), nil
+		},
+	}
+	me := &vickytest.MockEmbedder{}
+
+	engine := vickytest.SetupHandlerTest(t, vickytest.WithChunks(mc), vickytest.WithEmbedder(me))
+	engine.WithHandlers(SearchChunks)
+
+	capture := rtesting.ServeRequest(engine, "GET", "/search/testorg/testrepo/v1.0.0?q=users&kind=endpoint", nil)
+	rtesting.AssertStatus(t, capture, 200)
+	if searched != models.ChunkKindEndpoint {
+		t.Errorf("searched kind = %q, want %q", searched, models.ChunkKindEndpoint)
+	}
+
+	capture = rtesting.ServeRequest(engine, "GET", "/search/testorg/testrepo/v1.0.0?q=users&kind=widget", nil)
+	rtesting.AssertStatus(t, capture, 400)
+}
+
 func TestSearchSymbols(t *testing.T) {
 	sym := &models.Symbol{
 		ID:        1,
@@ -1,6 +1,9 @@
 package handlers
 
 import (
+	"context"
+	"fmt"
+	"io"
 	"strconv"
 
 	"github.com/zoobzio/rocco"
@@ -10,6 +13,7 @@ import (
 	"github.com/zoobzio/vicky/models"
 	"github.com/zoobzio/vicky/api/transformers"
 	"github.com/zoobzio/vicky/api/wire"
+	vickyScip "github.com/zoobzio/vicky/internal/scip"
 )
 
 // ListVersions returns all versions for a repository.
@@ -64,9 +68,40 @@ var GetVersion = rocco.GET("/repositories/{owner}/{repo}/versions/{tag}", func(r
 
 // TriggerIngest initiates ingestion for a version.
 var TriggerIngest = rocco.POST("/repositories/{owner}/{repo}/versions/{tag}", func(req *rocco.Request[wire.IngestRequest]) (wire.VersionResponse, error) {
-	versions := sum.MustUse[contracts.Versions](req.Context)
-	repos := sum.MustUse[contracts.Repositories](req.Context)
-	jobs := sum.MustUse[contracts.Jobs](req.Context)
+	userID, err := strconv.ParseInt(req.Identity.ID(), 10, 64)
+	if err != nil {
+		return wire.VersionResponse{}, err
+	}
+
+	owner := req.Params.Path["owner"]
+	repoName := req.Params.Path["repo"]
+	tag := req.Params.Path["tag"]
+
+	version, err := prepareVersion(req.Context, userID, owner, repoName, tag, req.Body)
+	if err != nil {
+		return wire.VersionResponse{}, err
+	}
+
+	if err := queueIngest(req.Context, version, models.JobKindIngest); err != nil {
+		return wire.VersionResponse{}, err
+	}
+
+	return transformers.VersionToResponse(version), nil
+}).WithPathParams("owner", "repo", "tag").
+	WithSummary("Trigger ingestion").
+	WithDescription("Initiates ingestion for a repository version. Re-ingesting an existing tag builds a replacement that is swapped in once complete; the current data stays available until then.").
+	WithTags("Versions").
+	WithErrors(ErrRepositoryNotFound, ErrIngestInProgress).
+	WithAuthentication().
+	WithSuccessStatus(202)
+
+// maxIndexUploadSize caps the size of an uploaded SCIP index.
+const maxIndexUploadSize = 2 << 30
+
+// UploadIndex initiates ingestion for a version using a SCIP index built by
+// the caller, typically CI, instead of running the indexer.
+var UploadIndex = rocco.POST("/repositories/{owner}/{repo}/versions/{tag}/scip", func(req *rocco.Request[rocco.NoBody]) (wire.VersionResponse, error) {
+	scipIndexes := sum.MustUse[contracts.SCIPIndexes](req.Context)
 
 	userID, err := strconv.ParseInt(req.Identity.ID(), 10, 64)
 	if err != nil {
@@ -77,51 +112,196 @@ var TriggerIngest = rocco.POST("/repositories/{owner}/{repo}/versions/{tag}", fu
 	repoName := req.Params.Path["repo"]
 	tag := req.Params.Path["tag"]
 
+	ingest := wire.IngestRequest{CommitSHA: req.Params.Query["commit_sha"]}
+	if err := ingest.Validate(); err != nil {
+		return wire.VersionResponse{}, ErrInvalidCommitSHA
+	}
+
+	// Reject unknown repositories and busy tags before reading the upload
+	if _, err := planVersion(req.Context, userID, owner, repoName, tag); err != nil {
+		return wire.VersionResponse{}, err
+	}
+
+	// The body is the raw index; rocco leaves it unread for NoBody handlers
+	index, size, err := spoolIndex(req.Request.Body)
+	if err != nil {
+		return wire.VersionResponse{}, err
+	}
+	defer func() { _ = index.Close() }()
+
+	// Plan again: another ingestion may have started during the upload
+	version, err := prepareVersion(req.Context, userID, owner, repoName, tag, ingest)
+	if err != nil {
+		return wire.VersionResponse{}, err
+	}
+
+	if err := scipIndexes.Put(req.Context, userID, owner, repoName, version.ID, index, size); err != nil {
+		return wire.VersionResponse{}, err
+	}
+
+	if err := queueIngest(req.Context, version, models.JobKindIngestUpload); err != nil {
+		return wire.VersionResponse{}, err
+	}
+
+	return transformers.VersionToResponse(version), nil
+}).WithPathParams("owner", "repo", "tag").
+	WithQueryParams("commit_sha").
+	WithSummary("Upload SCIP index").
+	WithDescription("Initiates ingestion for a repository version from a prebuilt SCIP index sent as the raw request body (application/octet-stream). The source at commit_sha is fetched, chunked, and embedded as usual, but the uploaded index is parsed instead of running the indexer. Re-uploading an existing tag builds a replacement like a re-ingestion.").
+	WithTags("Versions").
+	WithErrors(ErrRepositoryNotFound, ErrIngestInProgress, ErrInvalidCommitSHA, ErrInvalidIndex, ErrIndexTooLarge).
+	WithAuthentication().
+	WithSuccessStatus(202)
+
+// spoolIndex copies an uploaded index to a temporary file and checks that it
+// decodes as a SCIP index with at least one document. The returned file is
+// removed on Close.
+func spoolIndex(body io.Reader) (*vickyScip.Spool, int64, error) {
+	index, err := vickyScip.NewSpool("vicky-upload-*.scip")
+	if err != nil {
+		return nil, 0, fmt.Errorf("spool scip index: %w", err)
+	}
+
+	size, err := io.Copy(index, io.LimitReader(body, maxIndexUploadSize+1))
+	if err != nil {
+		_ = index.Close()
+		return nil, 0, fmt.Errorf("spool scip index: %w", err)
+	}
+	if size > maxIndexUploadSize {
+		_ = index.Close()
+		return nil, 0, ErrIndexTooLarge
+	}
+
+	if _, err := index.Seek(0, io.SeekStart); err != nil {
+		_ = index.Close()
+		return nil, 0, fmt.Errorf("spool scip index: %w", err)
+	}
+	docs, err := vickyScip.New().CountDocuments(index)
+	if err != nil || docs == 0 {
+		_ = index.Close()
+		return nil, 0, ErrInvalidIndex
+	}
+
+	if _, err := index.Seek(0, io.SeekStart); err != nil {
+		_ = index.Close()
+		return nil, 0, fmt.Errorf("spool scip index: %w", err)
+	}
+	return index, size, nil
+}
+
+// prepareVersion finds the repository and writes the pending version an
+// ingestion of the tag builds. Re-ingesting a tag builds a staging version
+// that replaces the live one once the pipeline finishes, so readers keep the
+// complete dataset meanwhile.
+func prepareVersion(ctx context.Context, userID int64, owner, repoName, tag string, ingest wire.IngestRequest) (*models.Version, error) {
+	versions := sum.MustUse[contracts.Versions](ctx)
+
+	version, err := planVersion(ctx, userID, owner, repoName, tag)
+	if err != nil {
+		return nil, err
+	}
+	version.Status = models.VersionStatusPending
+	transformers.ApplyIngestRequest(ingest, version)
+
+	key := ""
+	if version.ID != 0 {
+		key = strconv.FormatInt(version.ID, 10)
+	}
+	if err := versions.Set(ctx, key, version); err != nil {
+		return nil, err
+	}
+	return version, nil
+}
+
+// planVersion finds the repository and the version an ingestion of the tag
+// would build, without writing anything. It fails if the repository is not
+// registered or the tag is already being ingested.
+func planVersion(ctx context.Context, userID int64, owner, repoName, tag string) (*models.Version, error) {
+	versions := sum.MustUse[contracts.Versions](ctx)
+	repos := sum.MustUse[contracts.Repositories](ctx)
+
 	// Find the repository
-	repo, err := repos.GetByUserOwnerAndName(req.Context, userID, owner, repoName)
+	repo, err := repos.GetByUserOwnerAndName(ctx, userID, owner, repoName)
+	if err != nil {
+		return nil, ErrRepositoryNotFound
+	}
+
+	live, err := versions.GetByUserRepoAndTag(ctx, userID, owner, repoName, tag)
 	if err != nil {
-		return wire.VersionResponse{}, ErrRepositoryNotFound
+		// Create pending version
+		return &models.Version{
+			RepositoryID: repo.ID,
+			UserID:       userID,
+			Owner:        owner,
+			RepoName:     repoName,
+			Tag:          tag,
+		}, nil
 	}
 
-	// Create pending version
-	version := &models.Version{
+	if ingestActive(ctx, live.ID) {
+		return nil, ErrIngestInProgress
+	}
+
+	staging, err := versions.GetStaging(ctx, live.ID)
+	if err != nil {
+		return nil, err
+	}
+	if staging != nil {
+		// Rebuild a staging version left behind by a failed run
+		if ingestActive(ctx, staging.ID) {
+			return nil, ErrIngestInProgress
+		}
+		staging.Error = nil
+		return staging, nil
+	}
+
+	return &models.Version{
 		RepositoryID: repo.ID,
 		UserID:       userID,
 		Owner:        owner,
 		RepoName:     repoName,
 		Tag:          tag,
-		Status:       models.VersionStatusPending,
-	}
-	transformers.ApplyIngestRequest(req.Body, version)
+		ReplacesID:   &live.ID,
+	}, nil
+}
 
-	if err := versions.Set(req.Context, "", version); err != nil {
-		return wire.VersionResponse{}, err
-	}
+// queueIngest creates the pending job that ingests a prepared version.
+func queueIngest(ctx context.Context, version *models.Version, kind models.JobKind) error {
+	jobs := sum.MustUse[contracts.Jobs](ctx)
 
 	// Create pending job for async processing
 	job := &models.Job{
 		VersionID:    version.ID,
-		RepositoryID: repo.ID,
-		UserID:       userID,
-		Owner:        owner,
-		RepoName:     repoName,
-		Tag:          tag,
+		RepositoryID: version.RepositoryID,
+		UserID:       version.UserID,
+		Owner:        version.Owner,
+		RepoName:     version.RepoName,
+		Tag:          version.DataTag(),
+		Kind:         kind,
 		Stage:        models.JobStageFetch,
 		Status:       models.JobStatusPending,
 	}
 
-	if err := jobs.Set(req.Context, "", job); err != nil {
-		return wire.VersionResponse{}, err
+	if err := jobs.Set(ctx, "", job); err != nil {
+		return err
 	}
 
-	// Emit event to trigger async ingestion via worker pool
-	events.Job.Created.Emit(req.Context, events.JobCreatedEvent{Job: job})
+	// The job row is the queue entry; the event just wakes a local worker early
+	events.Job.Created.Emit(ctx, events.JobCreatedEvent{Job: job})
+	return nil
+}
 
-	return transformers.VersionToResponse(version), nil
-}).WithPathParams("owner", "repo", "tag").
-	WithSummary("Trigger ingestion").
-	WithDescription("Initiates ingestion for a repository version.").
-	WithTags("Versions").
-	WithErrors(ErrRepositoryNotFound).
-	WithAuthentication().
-	WithSuccessStatus(202)
+// ingestActive reports whether a version's latest job is still queued or running.
+func ingestActive(ctx context.Context, versionID int64) bool {
+	jobs := sum.MustUse[contracts.Jobs](ctx)
+
+	job, err := jobs.LatestByVersionID(ctx, versionID)
+	if err != nil {
+		return false
+	}
+	switch job.Status {
+	case models.JobStatusPending, models.JobStatusRunning, models.JobStatusCancelling:
+		return true
+	}
+	return false
+}
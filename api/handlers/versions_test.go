@@ -3,13 +3,18 @@
 package handlers
 
 import (
+	"bytes"
 	"context"
+	"errors"
+	"io"
 	"testing"
 
+	scipproto "github.com/sourcegraph/scip/bindings/go/scip"
 	rtesting "github.com/zoobzio/rocco/testing"
 	vickytest "github.com/zoobzio/vicky/testing"
 	"github.com/zoobzio/vicky/models"
 	"github.com/zoobzio/vicky/api/wire"
+	"google.golang.org/protobuf/proto"
 )
 
 func TestListVersions(t *testing.T) {
@@ -113,3 +118,197 @@ func TestTriggerIngest_RepoNotFound(t *testing.T) {
 	rtesting.AssertStatus(t, capture, 404)
 }
 
+
+func TestTriggerIngest_Reingest(t *testing.T) {
+	live := vickytest.NewVersion(t)
+	live.Status = models.VersionStatusReady
+
+	var created *models.Version
+	mv := &vickytest.MockVersions{
+		OnGetByUserRepoAndTag: func(ctx context.Context, userID int64, owner, repoName, tag string) (*models.Version, error) {
+			return live, nil
+		},
+		OnSet: func(ctx context.Context, key string, version *models.Version) error {
+			version.ID = 42
+			created = version
+			return nil
+		},
+	}
+	var job *models.Job
+	mj := &vickytest.MockJobs{
+		OnLatestByVersionID: func(ctx context.Context, versionID int64) (*models.Job, error) {
+			return &models.Job{VersionID: versionID, Status: models.JobStatusCompleted}, nil
+		},
+		OnSet: func(ctx context.Context, key string, j *models.Job) error {
+			job = j
+			return nil
+		},
+	}
+
+	engine := vickytest.SetupHandlerTest(t,
+		vickytest.WithRepositories(&vickytest.MockRepositories{}),
+		vickytest.WithVersions(mv),
+		vickytest.WithJobs(mj),
+	)
+	engine.WithHandlers(TriggerIngest)
+
+	body := wire.IngestRequest{CommitSHA: "bbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbb"}
+	capture := rtesting.ServeRequest(engine, "POST", "/repositories/testorg/testrepo/versions/v1.0.0", body)
+	rtesting.AssertStatus(t, capture, 202)
+
+	if created == nil || created.ReplacesID == nil || *created.ReplacesID != live.ID {
+		t.Fatalf("created version = %+v, want staging version replacing %d", created, live.ID)
+	}
+	if created.Tag != "v1.0.0" {
+		t.Errorf("staging Tag = %q, want v1.0.0", created.Tag)
+	}
+	if job == nil || job.VersionID != 42 || job.Tag != models.StagingTag("v1.0.0", 42) {
+		t.Errorf("job = %+v, want staging job for version 42", job)
+	}
+}
+
+func TestTriggerIngest_InProgress(t *testing.T) {
+	mv := &vickytest.MockVersions{
+		OnGetByUserRepoAndTag: func(ctx context.Context, userID int64, owner, repoName, tag string) (*models.Version, error) {
+			return vickytest.NewVersion(t), nil
+		},
+		OnGetStaging: func(ctx context.Context, liveID int64) (*models.Version, error) {
+			replaces := liveID
+			return &models.Version{ID: 42, Tag: "v1.0.0", ReplacesID: &replaces}, nil
+		},
+		OnSet: func(ctx context.Context, key string, version *models.Version) error {
+			t.Error("no version should be written while an ingestion is running")
+			return nil
+		},
+	}
+	mj := &vickytest.MockJobs{
+		OnLatestByVersionID: func(ctx context.Context, versionID int64) (*models.Job, error) {
+			if versionID == 42 {
+				return &models.Job{VersionID: versionID, Status: models.JobStatusRunning}, nil
+			}
+			return &models.Job{VersionID: versionID, Status: models.JobStatusCompleted}, nil
+		},
+	}
+
+	engine := vickytest.SetupHandlerTest(t,
+		vickytest.WithRepositories(&vickytest.MockRepositories{}),
+		vickytest.WithVersions(mv),
+		vickytest.WithJobs(mj),
+	)
+	engine.WithHandlers(TriggerIngest)
+
+	body := wire.IngestRequest{CommitSHA: "bbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbb"}
+	capture := rtesting.ServeRequest(engine, "POST", "/repositories/testorg/testrepo/versions/v1.0.0", body)
+	rtesting.AssertStatus(t, capture, 409)
+}
+
+// serveUpload posts a raw index body to the upload endpoint.
+func serveUpload(t *testing.T, opts []vickytest.RegistryOption, path string, body []byte) *rtesting.ResponseCapture {
+	t.Helper()
+	engine := vickytest.SetupHandlerTest(t, opts...)
+	engine.WithHandlers(UploadIndex)
+
+	req := rtesting.NewRequestBuilder("POST", path).
+		WithBody(bytes.NewReader(body)).
+		WithHeader("Content-Type", "application/octet-stream").
+		Build()
+	capture := rtesting.NewResponseCapture()
+	engine.Router().ServeHTTP(capture, req)
+	return capture
+}
+
+func TestUploadIndex(t *testing.T) {
+	index, err := proto.Marshal(&scipproto.Index{
+		Metadata:  &scipproto.Metadata{ToolInfo: &scipproto.ToolInfo{Name: "scip-go"}},
+		Documents: []*scipproto.Document{{RelativePath: "main.go"}},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	mv := &vickytest.MockVersions{
+		OnSet: func(ctx context.Context, key string, version *models.Version) error {
+			version.ID = 42
+			return nil
+		},
+	}
+	var job *models.Job
+	mj := &vickytest.MockJobs{
+		OnSet: func(ctx context.Context, key string, j *models.Job) error {
+			job = j
+			return nil
+		},
+	}
+	var stored []byte
+	var storedVersion int64
+	mx := &vickytest.MockSCIPIndexes{
+		OnPut: func(ctx context.Context, userID int64, owner, repo string, versionID int64, r io.Reader, size int64) error {
+			storedVersion = versionID
+			stored, err = io.ReadAll(r)
+			return err
+		},
+	}
+
+	capture := serveUpload(t, []vickytest.RegistryOption{
+		vickytest.WithRepositories(&vickytest.MockRepositories{}),
+		vickytest.WithVersions(mv),
+		vickytest.WithJobs(mj),
+		vickytest.WithSCIPIndexes(mx),
+	}, "/repositories/testorg/testrepo/versions/v2.0.0/scip?commit_sha=aaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaa", index)
+	rtesting.AssertStatus(t, capture, 202)
+
+	var resp wire.VersionResponse
+	if err := capture.DecodeJSON(&resp); err != nil {
+		t.Fatalf("decode: %v", err)
+	}
+	if resp.CommitSHA != "aaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaa" {
+		t.Errorf("CommitSHA = %q, want the uploaded commit", resp.CommitSHA)
+	}
+	if !bytes.Equal(stored, index) || storedVersion != 42 {
+		t.Errorf("stored %d bytes under version %d, want the %d-byte upload under 42", len(stored), storedVersion, len(index))
+	}
+	if job == nil || job.Kind != models.JobKindIngestUpload || job.VersionID != 42 {
+		t.Errorf("job = %+v, want an upload ingestion of version 42", job)
+	}
+}
+
+func TestUploadIndex_InvalidCommitSHA(t *testing.T) {
+	capture := serveUpload(t, []vickytest.RegistryOption{
+		vickytest.WithSCIPIndexes(&vickytest.MockSCIPIndexes{}),
+	}, "/repositories/testorg/testrepo/versions/v2.0.0/scip?commit_sha=abc", []byte("x"))
+	rtesting.AssertStatus(t, capture, 400)
+}
+
+func TestUploadIndex_NotSCIP(t *testing.T) {
+	mv := &vickytest.MockVersions{
+		OnSet: func(ctx context.Context, key string, version *models.Version) error {
+			t.Error("version written for an invalid upload")
+			return nil
+		},
+	}
+
+	capture := serveUpload(t, []vickytest.RegistryOption{
+		vickytest.WithRepositories(&vickytest.MockRepositories{}),
+		vickytest.WithVersions(mv),
+		vickytest.WithJobs(&vickytest.MockJobs{}),
+		vickytest.WithSCIPIndexes(&vickytest.MockSCIPIndexes{}),
+	}, "/repositories/testorg/testrepo/versions/v2.0.0/scip?commit_sha=aaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaa", []byte("not a scip index"))
+	rtesting.AssertStatus(t, capture, 400)
+}
+
+func TestUploadIndex_UnknownRepositoryNotRead(t *testing.T) {
+	mr := &vickytest.MockRepositories{
+		OnGetByUserOwnerAndName: func(ctx context.Context, userID int64, owner, name string) (*models.Repository, error) {
+			return nil, errors.New("not found")
+		},
+	}
+
+	// Not a SCIP index: a 404 rather than a 400 shows the body was never read
+	capture := serveUpload(t, []vickytest.RegistryOption{
+		vickytest.WithRepositories(mr),
+		vickytest.WithVersions(&vickytest.MockVersions{}),
+		vickytest.WithJobs(&vickytest.MockJobs{}),
+		vickytest.WithSCIPIndexes(&vickytest.MockSCIPIndexes{}),
+	}, "/repositories/testorg/testrepo/versions/v2.0.0/scip?commit_sha=aaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaa", []byte("not a scip index"))
+	rtesting.AssertStatus(t, capture, 404)
+}
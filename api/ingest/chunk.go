@@ -63,8 +63,15 @@ type chunkWork struct {
 	Path        string
 	ContentType models.ContentType
 
+	// Document is updated with the blob SHA once chunked, marking it
+	// reusable by later versions. Optional.
+	Document *models.Document
+
 	// Result counter (shared across goroutines)
 	ChunkCount *atomic.Int64
+
+	// Report records documents no chunker handles. Optional.
+	Report *fileReport
 }
 
 func (w *chunkWork) Clone() *chunkWork {
@@ -88,12 +95,9 @@ func processChunkDoc(ctx context.Context, w *chunkWork) (*chunkWork, error) {
 		)
 		return w, fmt.Errorf("blob %s: %w", w.Path, err)
 	}
+	sha := obj.Data.SHA
 
-	// Determine chisel language
 	lang := w.Language
-	if w.ContentType == models.ContentTypeDocs {
-		lang = "markdown"
-	}
 
 	if !chunker.Supports(lang) {
 		capitan.Debug(ctx, events.ChunkSkippedSignal,
@@ -101,7 +105,10 @@ func processChunkDoc(ctx context.Context, w *chunkWork) (*chunkWork, error) {
 			events.PathKey.Field(w.Path),
 			events.LanguageKey.Field(lang),
 		)
-		return w, nil
+		if w.Report != nil {
+			w.Report.Add(w.Path, models.FileOutcomeSkipped, "", fmt.Sprintf("no chunker for %s", lang), 0)
+		}
+		return w, recordBlobSHA(ctx, w, sha)
 	}
 
 	// Chunk the content
@@ -115,14 +122,14 @@ func processChunkDoc(ctx context.Context, w *chunkWork) (*chunkWork, error) {
 		return w, fmt.Errorf("chunk %s: %w", w.Path, err)
 	}
 
-	// Persist each chunk
-	for _, r := range results {
+	batch := make([]*models.Chunk, len(results))
+	for i, r := range results {
 		var symbol *string
 		if r.Symbol != "" {
 			symbol = &r.Symbol
 		}
 
-		chunk := &models.Chunk{
+		batch[i] = &models.Chunk{
 			DocumentID: w.DocumentID,
 			UserID:     w.UserID,
 			Owner:      w.Owner,
@@ -136,22 +143,47 @@ func processChunkDoc(ctx context.Context, w *chunkWork) (*chunkWork, error) {
 			Context:    r.Context,
 			Content:    r.Content,
 		}
+	}
 
-		if err := chunks.Set(ctx, "", chunk); err != nil {
-			capitan.Error(ctx, events.ChunkStoreErrorSignal,
-				events.JobIDKey.Field(w.JobID),
-				events.PathKey.Field(w.Path),
-				events.ErrorKey.Field(err),
-			)
-			return w, fmt.Errorf("store chunk in %s: %w", w.Path, err)
-		}
+	// Replace chunks left by an interrupted earlier attempt in one
+	// transaction, so a failure never leaves a partly chunked document
+	if err := chunks.ReplaceByDocument(ctx, w.DocumentID, batch); err != nil {
+		capitan.Error(ctx, events.ChunkStoreErrorSignal,
+			events.JobIDKey.Field(w.JobID),
+			events.PathKey.Field(w.Path),
+			events.ErrorKey.Field(err),
+		)
+		return w, fmt.Errorf("store chunks in %s: %w", w.Path, err)
+	}
 
-		if w.ChunkCount != nil {
-			w.ChunkCount.Add(1)
-		}
+	if w.ChunkCount != nil {
+		w.ChunkCount.Add(int64(len(batch)))
+	}
+
+	return w, recordBlobSHA(ctx, w, sha)
+}
+
+// chunkLanguage returns the chunker language of a document: the language of
+// a code file, or the content format of any other.
+func chunkLanguage(configs []*models.IngestionConfig, d *models.Document) string {
+	if d.ContentType == models.ContentTypeCode {
+		return string(languageForPath(configs, d.Path))
 	}
+	return string(formatForPath(configs, d.Path))
+}
 
-	return w, nil
+// recordBlobSHA stores the source blob SHA on the chunked document.
+func recordBlobSHA(ctx context.Context, w *chunkWork, sha string) error {
+	if w.Document == nil || sha == "" {
+		return nil
+	}
+	documents := sum.MustUse[contracts.Documents](ctx)
+
+	w.Document.BlobSHA = &sha
+	if err := documents.Set(ctx, idToKey(w.Document.ID), w.Document); err != nil {
+		return fmt.Errorf("record blob sha %s: %w", w.Path, err)
+	}
+	return nil
 }
 
 // chunkStage chunks content into embeddable segments.
@@ -168,8 +200,8 @@ func chunkStage(ctx context.Context, job *models.Job) (*models.Job, error) {
 	documents := sum.MustUse[contracts.Documents](ctx)
 	configs := sum.MustUse[contracts.IngestionConfigs](ctx)
 
-	// Get config for language
-	config, err := configs.GetByRepositoryID(ctx, job.RepositoryID)
+	// Language roots decide each file's chunker language
+	repoConfigs, err := configs.ListByRepositoryID(ctx, job.RepositoryID)
 	if err != nil {
 		return job, err
 	}
@@ -182,26 +214,40 @@ func chunkStage(ctx context.Context, job *models.Job) (*models.Job, error) {
 
 	job.ItemsTotal = len(docs)
 
-	if len(docs) == 0 {
+	// Documents reused from the previous version already carry a blob SHA
+	// and their chunks; only new or changed files need chunking.
+	pending := make([]*models.Document, 0, len(docs))
+	for _, d := range docs {
+		if d.BlobSHA == nil {
+			pending = append(pending, d)
+		}
+	}
+	reused := len(docs) - len(pending)
+
+	tracker := trackProgress(ctx, job, len(docs))
+	tracker.Add(ctx, reused)
+
+	if len(pending) == 0 {
 		events.Ingest.Chunk.Completed.Emit(ctx, events.ChunkEvent{
 			RepositoryID: job.RepositoryID,
 			VersionID:    job.VersionID,
 			ChunkCount:   0,
+			ReusedCount:  reused,
 		})
+		tracker.Finish(ctx, len(docs))
 		return job, nil
 	}
 
+	report := newFileReport(job, models.JobStageChunk)
+	defer report.Save(ctx)
+
 	// Process documents concurrently via long-lived pool
 	var (
 		wg          sync.WaitGroup
-		errOnce     sync.Once
-		firstErr    error
 		totalChunks atomic.Int64
 	)
 
-	language := string(config.Language)
-
-	for _, doc := range docs {
+	for _, doc := range pending {
 		wg.Add(1)
 		go func(d *models.Document) {
 			defer wg.Done()
@@ -211,31 +257,38 @@ func chunkStage(ctx context.Context, job *models.Job) (*models.Job, error) {
 				Owner:       job.Owner,
 				RepoName:    job.RepoName,
 				Tag:         job.Tag,
-				Language:    language,
+				Language:    chunkLanguage(repoConfigs, d),
 				JobID:       job.ID,
 				DocumentID:  d.ID,
 				Path:        d.Path,
 				ContentType: d.ContentType,
+				Document:    d,
 				ChunkCount:  &totalChunks,
+				Report:      report,
 			})
 			if err != nil {
-				errOnce.Do(func() { firstErr = err })
+				report.Fail(d.Path, err)
+				return
 			}
+			tracker.Add(ctx, 1)
 		}(doc)
 	}
 
 	wg.Wait()
 
-	if firstErr != nil {
-		return job, firstErr
+	// Tolerate a share of failed documents rather than failing the whole ingest
+	failed, firstErr := report.Failed()
+	if err := chunkErrorBudget.Check(models.JobStageChunk, failed, len(pending), firstErr); err != nil {
+		return job, err
 	}
 
-	job.ItemsProcessed = len(docs)
+	tracker.Finish(ctx, len(docs))
 
 	events.Ingest.Chunk.Completed.Emit(ctx, events.ChunkEvent{
 		RepositoryID: job.RepositoryID,
 		VersionID:    job.VersionID,
 		ChunkCount:   int(totalChunks.Load()),
+		ReusedCount:  reused,
 	})
 
 	return job, nil
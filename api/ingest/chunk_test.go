@@ -4,6 +4,7 @@ package ingest
 
 import (
 	"context"
+	"encoding/json"
 	"fmt"
 	"strings"
 	"sync"
@@ -45,15 +46,17 @@ func TestChunkStage(t *testing.T) {
 		},
 	}
 	mc := &vickytest.MockChunks{
-		OnSet: func(ctx context.Context, key string, chunk *models.Chunk) error {
+		OnReplaceByDocument: func(ctx context.Context, documentID int64, chunks []*models.Chunk) error {
 			mu.Lock()
 			defer mu.Unlock()
-			chunkCount++
+			chunkCount += len(chunks)
 			return nil
 		},
 	}
 
 	ctx := vickytest.SetupRegistry(t,
+		vickytest.WithJobs(&vickytest.MockJobs{}),
+		vickytest.WithFileReports(&vickytest.MockFileReports{}),
 		vickytest.WithDocuments(md),
 		vickytest.WithBlobs(mb),
 		vickytest.WithChunker(mch),
@@ -93,6 +96,8 @@ func TestChunkStage_NoDocs(t *testing.T) {
 	}
 
 	ctx := vickytest.SetupRegistry(t,
+		vickytest.WithJobs(&vickytest.MockJobs{}),
+		vickytest.WithFileReports(&vickytest.MockFileReports{}),
 		vickytest.WithDocuments(md),
 		vickytest.WithBlobs(&vickytest.MockBlobs{}),
 		vickytest.WithChunker(&vickytest.MockChunker{}),
@@ -135,6 +140,8 @@ func TestChunkStage_ChunkerNotSupported(t *testing.T) {
 	}
 
 	ctx := vickytest.SetupRegistry(t,
+		vickytest.WithJobs(&vickytest.MockJobs{}),
+		vickytest.WithFileReports(&vickytest.MockFileReports{}),
 		vickytest.WithDocuments(md),
 		vickytest.WithBlobs(mb),
 		vickytest.WithChunker(mch),
@@ -153,6 +160,80 @@ func TestChunkStage_ChunkerNotSupported(t *testing.T) {
 	}
 }
 
+func TestChunkStage_LanguagePerFile(t *testing.T) {
+	readme := vickytest.NewDocument(t, 3, "README.md")
+	readme.ContentType = models.ContentTypeDocs
+	proto := vickytest.NewDocument(t, 4, "proto/user.proto")
+	proto.ContentType = models.ContentTypeSchema
+	spec := vickytest.NewDocument(t, 5, "web/api.yaml")
+	spec.ContentType = models.ContentTypeSchema
+	docs := []*models.Document{
+		vickytest.NewDocument(t, 1, "main.go"),
+		vickytest.NewDocument(t, 2, "web/src/app.ts"),
+		readme,
+		proto,
+		spec,
+	}
+
+	md := &vickytest.MockDocuments{
+		OnListByUserRepoAndTag: func(ctx context.Context, userID int64, owner, repoName, tag string) ([]*models.Document, error) {
+			return docs, nil
+		},
+	}
+	mb := &vickytest.MockBlobs{
+		OnGetByPath: func(ctx context.Context, userID int64, owner, repo, tag, path string) (*grub.Object[models.Blob], error) {
+			return &grub.Object[models.Blob]{Key: path, Data: models.Blob{Path: path, Content: "content"}}, nil
+		},
+	}
+	var mu sync.Mutex
+	languages := make(map[string]string)
+	mch := &vickytest.MockChunker{
+		OnChunk: func(ctx context.Context, language string, filename string, content []byte) ([]chunker.Result, error) {
+			mu.Lock()
+			defer mu.Unlock()
+			languages[filename] = language
+			return nil, nil
+		},
+	}
+	mc := &vickytest.MockIngestionConfigs{
+		OnListByRepositoryID: func(ctx context.Context, repositoryID int64) ([]*models.IngestionConfig, error) {
+			return []*models.IngestionConfig{
+				{Language: models.LanguageGo},
+				{Language: models.LanguageTypeScript, Root: "web", ContentFormats: json.RawMessage(`{".yaml": "openapi"}`)},
+			}, nil
+		},
+	}
+
+	ctx := vickytest.SetupRegistry(t,
+		vickytest.WithJobs(&vickytest.MockJobs{}),
+		vickytest.WithFileReports(&vickytest.MockFileReports{}),
+		vickytest.WithDocuments(md),
+		vickytest.WithBlobs(mb),
+		vickytest.WithChunker(mch),
+		vickytest.WithChunks(&vickytest.MockChunks{}),
+		vickytest.WithIngestionConfigs(mc),
+	)
+
+	if _, err := chunkStage(ctx, vickytest.NewJob(t)); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+	want := map[string]string{
+		"main.go":          "go",
+		"web/src/app.ts":   "typescript",
+		"README.md":        "markdown",
+		"proto/user.proto": "proto",
+		"web/api.yaml":     "openapi",
+	}
+	for path, lang := range want {
+		if languages[path] != lang {
+			t.Errorf("%s chunked as %q, want %q", path, languages[path], lang)
+		}
+	}
+}
+
 func TestChunkStage_BlobFetchError(t *testing.T) {
 	docs := []*models.Document{
 		vickytest.NewDocument(t, 1, "main.go"),
@@ -170,6 +251,8 @@ func TestChunkStage_BlobFetchError(t *testing.T) {
 	}
 
 	ctx := vickytest.SetupRegistry(t,
+		vickytest.WithJobs(&vickytest.MockJobs{}),
+		vickytest.WithFileReports(&vickytest.MockFileReports{}),
 		vickytest.WithDocuments(md),
 		vickytest.WithBlobs(mb),
 		vickytest.WithChunker(&vickytest.MockChunker{}),
@@ -213,12 +296,14 @@ func TestChunkStage_ChunkStoreError(t *testing.T) {
 		},
 	}
 	mc := &vickytest.MockChunks{
-		OnSet: func(ctx context.Context, key string, chunk *models.Chunk) error {
+		OnReplaceByDocument: func(ctx context.Context, documentID int64, chunks []*models.Chunk) error {
 			return fmt.Errorf("disk full")
 		},
 	}
 
 	ctx := vickytest.SetupRegistry(t,
+		vickytest.WithJobs(&vickytest.MockJobs{}),
+		vickytest.WithFileReports(&vickytest.MockFileReports{}),
 		vickytest.WithDocuments(md),
 		vickytest.WithBlobs(mb),
 		vickytest.WithChunker(mch),
@@ -235,3 +320,115 @@ func TestChunkStage_ChunkStoreError(t *testing.T) {
 		t.Errorf("error = %q, want it to contain %q", err.Error(), "store chunk")
 	}
 }
+
+func TestChunkStage_SkipsReusedDocuments(t *testing.T) {
+	reused := vickytest.NewDocument(t, 1, "main.go")
+	sha := "sha-main"
+	reused.BlobSHA = &sha
+	changed := vickytest.NewDocument(t, 2, "utils.go")
+
+	md := &vickytest.MockDocuments{
+		OnListByUserRepoAndTag: func(ctx context.Context, userID int64, owner, repoName, tag string) ([]*models.Document, error) {
+			return []*models.Document{reused, changed}, nil
+		},
+	}
+
+	var mu sync.Mutex
+	var chunkedPaths []string
+	mb := &vickytest.MockBlobs{
+		OnGetByPath: func(ctx context.Context, userID int64, owner, repo, tag, path string) (*grub.Object[models.Blob], error) {
+			return &grub.Object[models.Blob]{
+				Key:  path,
+				Data: models.Blob{Path: path, Content: "package main", SHA: "sha-utils"},
+			}, nil
+		},
+	}
+	mch := &vickytest.MockChunker{
+		OnChunk: func(ctx context.Context, language string, filename string, content []byte) ([]chunker.Result, error) {
+			mu.Lock()
+			defer mu.Unlock()
+			chunkedPaths = append(chunkedPaths, filename)
+			return nil, nil
+		},
+	}
+
+	ctx := vickytest.SetupRegistry(t,
+		vickytest.WithJobs(&vickytest.MockJobs{}),
+		vickytest.WithFileReports(&vickytest.MockFileReports{}),
+		vickytest.WithDocuments(md),
+		vickytest.WithBlobs(mb),
+		vickytest.WithChunker(mch),
+		vickytest.WithChunks(&vickytest.MockChunks{}),
+		vickytest.WithIngestionConfigs(&vickytest.MockIngestionConfigs{}),
+	)
+
+	result, err := chunkStage(ctx, vickytest.NewJob(t))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if len(chunkedPaths) != 1 || chunkedPaths[0] != "utils.go" {
+		t.Errorf("chunked paths = %v, want [utils.go]", chunkedPaths)
+	}
+	if result.ItemsProcessed != 2 {
+		t.Errorf("ItemsProcessed = %d, want 2", result.ItemsProcessed)
+	}
+	if changed.BlobSHA == nil || *changed.BlobSHA != "sha-utils" {
+		t.Errorf("changed document BlobSHA = %v, want sha-utils", changed.BlobSHA)
+	}
+}
+
+func TestChunkStage_ClearsStaleChunks(t *testing.T) {
+	var mu sync.Mutex
+	var events []string
+
+	md := &vickytest.MockDocuments{
+		OnListByUserRepoAndTag: func(ctx context.Context, userID int64, owner, repoName, tag string) ([]*models.Document, error) {
+			return []*models.Document{vickytest.NewDocument(t, 1, "main.go")}, nil
+		},
+	}
+	mb := &vickytest.MockBlobs{
+		OnGetByPath: func(ctx context.Context, userID int64, owner, repo, tag, path string) (*grub.Object[models.Blob], error) {
+			return &grub.Object[models.Blob]{Data: models.Blob{Path: path, Content: "package main"}}, nil
+		},
+	}
+	mch := &vickytest.MockChunker{
+		OnChunk: func(ctx context.Context, language string, filename string, content []byte) ([]chunker.Result, error) {
+			return []chunker.Result{{Content: "package main", Kind: models.ChunkKindModule}}, nil
+		},
+	}
+	mc := &vickytest.MockChunks{
+		OnDeleteByDocument: func(ctx context.Context, documentID int64) error {
+			mu.Lock()
+			defer mu.Unlock()
+			events = append(events, fmt.Sprintf("delete %d", documentID))
+			return nil
+		},
+		OnReplaceByDocument: func(ctx context.Context, documentID int64, chunks []*models.Chunk) error {
+			mu.Lock()
+			defer mu.Unlock()
+			events = append(events, fmt.Sprintf("replace %d with %d", documentID, len(chunks)))
+			return nil
+		},
+	}
+
+	ctx := vickytest.SetupRegistry(t,
+		vickytest.WithJobs(&vickytest.MockJobs{}),
+		vickytest.WithFileReports(&vickytest.MockFileReports{}),
+		vickytest.WithDocuments(md),
+		vickytest.WithBlobs(mb),
+		vickytest.WithChunker(mch),
+		vickytest.WithChunks(mc),
+		vickytest.WithIngestionConfigs(&vickytest.MockIngestionConfigs{}),
+	)
+
+	if _, err := chunkStage(ctx, vickytest.NewJob(t)); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+	if len(events) != 1 || events[0] != "replace 1 with 1" {
+		t.Errorf("events = %v, want [replace 1 with 1]", events)
+	}
+}
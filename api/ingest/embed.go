@@ -24,8 +24,9 @@ var embedBatchID = pipz.NewIdentity("embed-batch", "Process single embedding bat
 
 // Long-lived pool and configuration.
 var (
-	embedPool      *pipz.WorkerPool[*embedWork]
-	embedBatchSize atomic.Int32
+	embedPool       *pipz.WorkerPool[*embedWork]
+	embedBatchSize  atomic.Int32
+	documentVectors atomic.Bool
 )
 
 // Default configuration.
@@ -33,10 +34,13 @@ const (
 	defaultEmbedWorkers   = 4
 	defaultEmbedBatchSize = 128
 	defaultEmbedTimeout   = 30 * time.Second
+
+	defaultDocumentVectors = true
 )
 
 func init() {
 	embedBatchSize.Store(defaultEmbedBatchSize)
+	documentVectors.Store(defaultDocumentVectors)
 	embedPool = pipz.NewWorkerPool(EmbedPoolID, defaultEmbedWorkers,
 		pipz.Apply(embedBatchID, processEmbedBatch),
 	).WithTimeout(defaultEmbedTimeout)
@@ -56,12 +60,28 @@ func SetEmbedConfig(workers int, batchSize int, timeout time.Duration) {
 	}
 }
 
+// SetDocumentVectors toggles document-level embeddings; nil keeps the
+// current setting. Called by capacitor when config changes.
+func SetDocumentVectors(enabled *bool) {
+	if enabled != nil {
+		documentVectors.Store(*enabled)
+	}
+}
+
 // embedWork carries batch data for parallel embedding.
+// A batch holds either chunks or symbols.
 type embedWork struct {
 	// Batch data
 	Batch    []*models.Chunk
+	Symbols  []*models.Symbol
 	BatchIdx int
 
+	// Enricher renders each chunk's embedding input
+	Enricher *enricher
+
+	// Cache counts cache hits and misses across the stage
+	Cache *cacheStats
+
 	// Context for storing results
 	JobID int64
 }
@@ -72,44 +92,69 @@ func (w *embedWork) Clone() *embedWork {
 	return &c
 }
 
-// processEmbedBatch handles a single batch of chunks.
+// processEmbedBatch handles a single batch of chunks or symbols.
 func processEmbedBatch(ctx context.Context, w *embedWork) (*embedWork, error) {
 	embedder := sum.MustUse[contracts.Embedder](ctx)
-	chunks := sum.MustUse[contracts.Chunks](ctx)
 
-	// Extract content strings
-	texts := make([]string, len(w.Batch))
-	for i, chunk := range w.Batch {
-		texts[i] = chunk.Content
+	// Render embedding input; stored content is left untouched
+	texts := make([]string, 0, len(w.Batch)+len(w.Symbols))
+	for _, chunk := range w.Batch {
+		text, err := w.Enricher.Text(chunk)
+		if err != nil {
+			return w, fmt.Errorf("embed batch %d: %w", w.BatchIdx, err)
+		}
+		texts = append(texts, text)
+	}
+	for _, sym := range w.Symbols {
+		texts = append(texts, symbolText(sym))
 	}
 
-	// Generate embeddings
-	vectors, err := embedder.Embed(ctx, texts)
+	// Generate embeddings, sending only inputs missing from the cache
+	vectors, err := embedCached(ctx, w, embedder, texts)
 	if err != nil {
-		return w, fmt.Errorf("embed batch %d: %w", w.BatchIdx, err)
+		return w, err
 	}
 
-	if len(vectors) != len(w.Batch) {
-		return w, fmt.Errorf("embed batch %d: expected %d vectors, got %d", w.BatchIdx, len(w.Batch), len(vectors))
+	if len(w.Symbols) > 0 {
+		return w, storeSymbolVectors(ctx, w, vectors)
 	}
 
-	// Update chunk vectors
+	chunks := sum.MustUse[contracts.Chunks](ctx)
+
+	// Write the batch's chunk vectors together
 	for i, chunk := range w.Batch {
 		chunk.Vector = vectors[i]
-		if err := chunks.Set(ctx, idToKey(chunk.ID), chunk); err != nil {
-			capitan.Error(ctx, events.EmbedChunkErrorSignal,
-				events.JobIDKey.Field(w.JobID),
-				events.ChunkIDKey.Field(chunk.ID),
-				events.ErrorKey.Field(err),
-			)
-			return w, fmt.Errorf("update chunk %d: %w", chunk.ID, err)
-		}
+	}
+	if err := chunks.UpdateVectors(ctx, w.Batch); err != nil {
+		capitan.Error(ctx, events.EmbedChunkErrorSignal,
+			events.JobIDKey.Field(w.JobID),
+			events.ErrorKey.Field(err),
+		)
+		return w, fmt.Errorf("update chunk vectors in batch %d: %w", w.BatchIdx, err)
 	}
 
 	return w, nil
 }
 
-// embedStage generates vector embeddings for chunks.
+// storeSymbolVectors writes embedded vectors back to a batch of symbols.
+func storeSymbolVectors(ctx context.Context, w *embedWork, vectors [][]float32) error {
+	symbols := sum.MustUse[contracts.Symbols](ctx)
+
+	for i, sym := range w.Symbols {
+		sym.Vector = vectors[i]
+	}
+	if err := symbols.UpdateVectors(ctx, w.Symbols); err != nil {
+		capitan.Error(ctx, events.EmbedSymbolErrorSignal,
+			events.JobIDKey.Field(w.JobID),
+			events.ErrorKey.Field(err),
+		)
+		return fmt.Errorf("update symbol vectors in batch %d: %w", w.BatchIdx, err)
+	}
+
+	return nil
+}
+
+// embedStage generates vector embeddings for chunks and symbols.
 func embedStage(ctx context.Context, job *models.Job) (*models.Job, error) {
 	events.Ingest.Embed.Started.Emit(ctx, events.EmbedStageEvent{
 		RepositoryID: job.RepositoryID,
@@ -119,8 +164,11 @@ func embedStage(ctx context.Context, job *models.Job) (*models.Job, error) {
 	// Update job stage
 	job.Stage = models.JobStageEmbed
 
-	// Resolve chunks store
+	// Resolve stores
 	chunks := sum.MustUse[contracts.Chunks](ctx)
+	symbols := sum.MustUse[contracts.Symbols](ctx)
+	versions := sum.MustUse[contracts.Versions](ctx)
+	configs := sum.MustUse[contracts.IngestionConfigs](ctx)
 
 	// List all chunks for this version
 	allChunks, err := chunks.ListByUserRepoAndTag(ctx, job.UserID, job.Owner, job.RepoName, job.Tag)
@@ -128,46 +176,87 @@ func embedStage(ctx context.Context, job *models.Job) (*models.Job, error) {
 		return job, fmt.Errorf("list chunks: %w", err)
 	}
 
-	job.ItemsTotal = len(allChunks)
+	// List all symbols for this version
+	allSymbols, err := symbols.ListByUserRepoAndTag(ctx, job.UserID, job.Owner, job.RepoName, job.Tag)
+	if err != nil {
+		return job, fmt.Errorf("list symbols: %w", err)
+	}
 
-	if len(allChunks) == 0 {
-		events.Ingest.Embed.Completed.Emit(ctx, events.EmbedStageEvent{
-			RepositoryID: job.RepositoryID,
-			VersionID:    job.VersionID,
-			ChunkCount:   0,
-		})
-		return job, nil
+	job.ItemsTotal = len(allChunks) + len(allSymbols)
+
+	// Embed with the strategy recorded when the version was fetched, so a
+	// config change mid-job (or before a resume) can't mix strategies
+	version, err := versions.Get(ctx, idToKey(job.VersionID))
+	if err != nil {
+		return job, fmt.Errorf("get version: %w", err)
+	}
+	strategy, err := version.GetEmbeddingStrategy()
+	if err != nil {
+		return job, fmt.Errorf("embedding strategy: %w", err)
+	}
+	repoConfigs, err := configs.ListByRepositoryID(ctx, job.RepositoryID)
+	if err != nil {
+		return job, fmt.Errorf("list ingestion configs: %w", err)
+	}
+	enrich, err := newEnricher(strategy, repoConfigs, allSymbols)
+	if err != nil {
+		return job, fmt.Errorf("embedding strategy %s: %w", strategy.Name, err)
 	}
 
+	// Chunks copied from the previous version keep their vectors
+	pending := make([]*models.Chunk, 0, len(allChunks))
+	for _, c := range allChunks {
+		if len(c.Vector) == 0 {
+			pending = append(pending, c)
+		}
+	}
+	reused := len(allChunks) - len(pending)
+
+	pendingSymbols := make([]*models.Symbol, 0, len(allSymbols))
+	for _, s := range allSymbols {
+		if len(s.Vector) == 0 {
+			pendingSymbols = append(pendingSymbols, s)
+		}
+	}
+	reused += len(allSymbols) - len(pendingSymbols)
+
+	tracker := trackProgress(ctx, job, job.ItemsTotal)
+	tracker.Add(ctx, reused)
+
 	// Create batches using current config
 	batchSize := int(embedBatchSize.Load())
-	batches := batchChunks(allChunks, batchSize)
+	stats := &cacheStats{}
+	var work []*embedWork
+	for _, b := range batchChunks(pending, batchSize) {
+		work = append(work, &embedWork{Batch: b, BatchIdx: len(work), Enricher: enrich, Cache: stats, JobID: job.ID})
+	}
+	for _, b := range batchSymbols(pendingSymbols, batchSize) {
+		work = append(work, &embedWork{Symbols: b, BatchIdx: len(work), Cache: stats, JobID: job.ID})
+	}
 
 	// Process batches concurrently via long-lived pool
 	var (
-		wg          sync.WaitGroup
-		errOnce     sync.Once
-		firstErr    error
+		wg            sync.WaitGroup
+		errOnce       sync.Once
+		firstErr      error
 		totalEmbedded atomic.Int64
+		totalSymbols  atomic.Int64
 	)
 
-	for i, batch := range batches {
+	for _, w := range work {
 		wg.Add(1)
-		go func(idx int, b []*models.Chunk) {
+		go func(w *embedWork) {
 			defer wg.Done()
 
-			_, err := embedPool.Process(ctx, &embedWork{
-				Batch:    b,
-				BatchIdx: idx,
-				JobID:    job.ID,
-			})
-			if err != nil {
+			if _, err := embedPool.Process(ctx, w); err != nil {
 				errOnce.Do(func() { firstErr = err })
 				return
 			}
 
-			totalEmbedded.Add(int64(len(b)))
-		}(i, batch)
+			totalEmbedded.Add(int64(len(w.Batch)))
+			totalSymbols.Add(int64(len(w.Symbols)))
+			tracker.Add(ctx, len(w.Batch)+len(w.Symbols))
+		}(w)
 	}
 
 	wg.Wait()
@@ -176,18 +265,120 @@ func embedStage(ctx context.Context, job *models.Job) (*models.Job, error) {
 		return job, firstErr
 	}
 
-	job.ItemsProcessed = int(totalEmbedded.Load())
+	embedded := int(totalEmbedded.Load())
+	embeddedSymbols := int(totalSymbols.Load())
+	tracker.Finish(ctx, embedded+embeddedSymbols+reused)
+
+	// Document vectors are pooled from the chunk vectors, so no extra API calls
+	var pooled int
+	if documentVectors.Load() {
+		pooled, err = poolDocumentVectors(ctx, job, allChunks)
+		if err != nil {
+			return job, err
+		}
+	}
+
+	hits, misses := int(stats.hits.Load()), int(stats.misses.Load())
+	if hits+misses > 0 {
+		embedder := sum.MustUse[contracts.Embedder](ctx)
+		events.Ingest.Embed.Cache.Emit(ctx, events.EmbedCacheEvent{
+			RepositoryID: job.RepositoryID,
+			VersionID:    job.VersionID,
+			Provider:     embedder.Provider(),
+			Model:        embedder.Model(),
+			Hits:         hits,
+			Misses:       misses,
+			HitRate:      stats.HitRate(),
+		})
+	}
 
 	events.Ingest.Embed.Completed.Emit(ctx, events.EmbedStageEvent{
-		RepositoryID: job.RepositoryID,
-		VersionID:    job.VersionID,
-		ChunkCount:   job.ItemsProcessed,
-		BatchCount:   len(batches),
+		RepositoryID:  job.RepositoryID,
+		VersionID:     job.VersionID,
+		ChunkCount:    embedded,
+		SymbolCount:   embeddedSymbols,
+		DocumentCount: pooled,
+		BatchCount:    len(work),
+		ReusedCount:   reused,
+		CacheHits:     hits,
+		CacheMisses:   misses,
 	})
 
 	return job, nil
 }
 
+// poolDocumentVectors sets each document's vector to the mean of its chunk
+// vectors. Documents that already carry a vector (copied from the previous
+// version) are left alone. Returns the number of documents updated.
+func poolDocumentVectors(ctx context.Context, job *models.Job, chunks []*models.Chunk) (int, error) {
+	if len(chunks) == 0 {
+		return 0, nil
+	}
+
+	documents := sum.MustUse[contracts.Documents](ctx)
+
+	docs, err := documents.ListByUserRepoAndTag(ctx, job.UserID, job.Owner, job.RepoName, job.Tag)
+	if err != nil {
+		return 0, fmt.Errorf("list documents: %w", err)
+	}
+
+	byDocument := make(map[int64][]*models.Chunk, len(docs))
+	for _, c := range chunks {
+		byDocument[c.DocumentID] = append(byDocument[c.DocumentID], c)
+	}
+
+	var pooled int
+	for _, doc := range docs {
+		if len(doc.Vector) > 0 {
+			continue
+		}
+
+		vector := meanVector(byDocument[doc.ID])
+		if vector == nil {
+			continue
+		}
+
+		doc.Vector = vector
+		if err := documents.Set(ctx, idToKey(doc.ID), doc); err != nil {
+			return pooled, fmt.Errorf("update document %d: %w", doc.ID, err)
+		}
+		pooled++
+	}
+
+	return pooled, nil
+}
+
+// meanVector averages the vectors of the given chunks. Chunks without a
+// vector, or whose dimensions differ from the first, are ignored.
+// Returns nil when no chunk has a vector.
+func meanVector(chunks []*models.Chunk) []float32 {
+	var total []float32
+	var n int
+	for _, c := range chunks {
+		if len(c.Vector) == 0 {
+			continue
+		}
+		if total == nil {
+			total = make([]float32, len(c.Vector))
+		}
+		if len(c.Vector) != len(total) {
+			continue
+		}
+		for i, v := range c.Vector {
+			total[i] += v
+		}
+		n++
+	}
+
+	if n == 0 {
+		return nil
+	}
+	for i := range total {
+		total[i] /= float32(n)
+	}
+	return total
+}
+
 // batchChunks splits a chunk slice into batches of the given size.
 func batchChunks(chunks []*models.Chunk, size int) [][]*models.Chunk {
 	var batches [][]*models.Chunk
@@ -200,3 +391,16 @@ func batchChunks(chunks []*models.Chunk, size int) [][]*models.Chunk {
 	}
 	return batches
 }
+
+// batchSymbols splits a symbol slice into batches of the given size.
+func batchSymbols(symbols []*models.Symbol, size int) [][]*models.Symbol {
+	var batches [][]*models.Symbol
+	for i := 0; i < len(symbols); i += size {
+		end := i + size
+		if end > len(symbols) {
+			end = len(symbols)
+		}
+		batches = append(batches, symbols[i:end])
+	}
+	return batches
+}
@@ -0,0 +1,144 @@
+package ingest
+
+import (
+	"context"
+	"fmt"
+	"sync/atomic"
+
+	"github.com/zoobzio/capitan"
+	"github.com/zoobzio/sum"
+	"github.com/zoobzio/vicky/api/contracts"
+	"github.com/zoobzio/vicky/api/events"
+	"github.com/zoobzio/vicky/models"
+)
+
+// embeddingCache toggles the content-addressed embedding cache.
+var embeddingCache atomic.Bool
+
+const defaultEmbeddingCache = true
+
+func init() {
+	embeddingCache.Store(defaultEmbeddingCache)
+}
+
+// SetEmbeddingCache toggles the embedding cache; nil keeps the current
+// setting. Called by capacitor when config changes.
+func SetEmbeddingCache(enabled *bool) {
+	if enabled != nil {
+		embeddingCache.Store(*enabled)
+	}
+}
+
+// cacheStats counts embedding inputs served from the cache and sent to the
+// provider across every batch of a stage.
+type cacheStats struct {
+	hits   atomic.Int64
+	misses atomic.Int64
+}
+
+// HitRate returns the fraction of inputs served from the cache.
+func (s *cacheStats) HitRate() float64 {
+	hits, misses := s.hits.Load(), s.misses.Load()
+	if hits+misses == 0 {
+		return 0
+	}
+	return float64(hits) / float64(hits+misses)
+}
+
+// embedCached embeds texts, serving inputs the same model has embedded before
+// from the cache and sending only misses to the provider. Duplicate inputs
+// within the batch are sent once. The cache only saves work: when it cannot
+// be read or written the batch is embedded and stored without it.
+func embedCached(ctx context.Context, w *embedWork, embedder contracts.Embedder, texts []string) ([][]float32, error) {
+	if !embeddingCache.Load() {
+		return embedTexts(ctx, w, embedder, texts)
+	}
+
+	cache := sum.MustUse[contracts.EmbeddingCache](ctx)
+	provider, model, dims := embedder.Provider(), embedder.Model(), embedder.Dimensions()
+
+	keys := make([]string, len(texts))
+	for i, text := range texts {
+		keys[i] = models.EmbeddingCacheKey(text, provider, model, dims)
+	}
+
+	cached, err := cache.GetMany(ctx, keys)
+	if err != nil {
+		capitan.Warn(ctx, events.EmbedCacheErrorSignal,
+			events.JobIDKey.Field(w.JobID),
+			events.ErrorKey.Field(err),
+		)
+		cached = nil
+	}
+
+	// Collect distinct misses in input order
+	vectors := make([][]float32, len(texts))
+	missIdx := make(map[string]int)
+	var missTexts []string
+	var hits int
+	for i, key := range keys {
+		if v, ok := cached[key]; ok {
+			vectors[i] = v
+			hits++
+			continue
+		}
+		if _, ok := missIdx[key]; !ok {
+			missIdx[key] = len(missTexts)
+			missTexts = append(missTexts, texts[i])
+		}
+	}
+
+	if w.Cache != nil {
+		w.Cache.hits.Add(int64(hits))
+		w.Cache.misses.Add(int64(len(texts) - hits))
+	}
+
+	if len(missTexts) == 0 {
+		return vectors, nil
+	}
+
+	embedded, err := embedTexts(ctx, w, embedder, missTexts)
+	if err != nil {
+		return nil, err
+	}
+
+	// Entries are written in input order so concurrent batches sharing
+	// inputs lock rows in the same order
+	entries := make([]*models.EmbeddingCacheEntry, 0, len(missTexts))
+	for i, key := range keys {
+		if vectors[i] != nil {
+			continue
+		}
+		j := missIdx[key]
+		vectors[i] = embedded[j]
+		if j == len(entries) {
+			entries = append(entries, &models.EmbeddingCacheEntry{
+				Key:        key,
+				Provider:   provider,
+				Model:      model,
+				Dimensions: dims,
+				Vector:     embedded[j],
+			})
+		}
+	}
+	if err := cache.PutMany(ctx, entries); err != nil {
+		capitan.Warn(ctx, events.EmbedCacheErrorSignal,
+			events.JobIDKey.Field(w.JobID),
+			events.ErrorKey.Field(err),
+		)
+	}
+
+	return vectors, nil
+}
+
+// embedTexts sends texts to the provider and checks it returned one vector each.
+func embedTexts(ctx context.Context, w *embedWork, embedder contracts.Embedder, texts []string) ([][]float32, error) {
+	vectors, err := embedder.Embed(ctx, texts)
+	if err != nil {
+		return nil, fmt.Errorf("embed batch %d: %w", w.BatchIdx, err)
+	}
+	if len(vectors) != len(texts) {
+		return nil, fmt.Errorf("embed batch %d: expected %d vectors, got %d", w.BatchIdx, len(texts), len(vectors))
+	}
+	return vectors, nil
+}
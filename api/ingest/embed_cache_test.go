@@ -0,0 +1,224 @@
+//go:build testing
+
+package ingest
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+
+	vickytest "github.com/zoobzio/vicky/testing"
+
+	"github.com/zoobzio/vicky/models"
+)
+
+func TestEmbedStage_CacheServesHits(t *testing.T) {
+	chunks := vickytest.NewChunks(t, 3)
+	me := &vickytest.MockEmbedder{}
+	cachedKey := models.EmbeddingCacheKey(chunks[0].Content, me.Provider(), me.Model(), me.Dimensions())
+
+	var mu sync.Mutex
+	var sent []string
+	var stored []*models.EmbeddingCacheEntry
+	setChunks := make(map[int64][]float32)
+
+	me.OnEmbed = func(ctx context.Context, texts []string) ([][]float32, error) {
+		mu.Lock()
+		defer mu.Unlock()
+		sent = append(sent, texts...)
+		vectors := make([][]float32, len(texts))
+		for i := range texts {
+			vectors[i] = []float32{0.5, 0.5, 0.5}
+		}
+		return vectors, nil
+	}
+	mec := &vickytest.MockEmbeddingCache{
+		OnGetMany: func(ctx context.Context, keys []string) (map[string][]float32, error) {
+			return map[string][]float32{cachedKey: {0.1, 0.2, 0.3}}, nil
+		},
+		OnPutMany: func(ctx context.Context, entries []*models.EmbeddingCacheEntry) error {
+			mu.Lock()
+			defer mu.Unlock()
+			stored = append(stored, entries...)
+			return nil
+		},
+	}
+	mc := &vickytest.MockChunks{
+		OnListByUserRepoAndTag: func(ctx context.Context, userID int64, owner, repoName, tag string) ([]*models.Chunk, error) {
+			return chunks, nil
+		},
+		OnUpdateVectors: func(ctx context.Context, batch []*models.Chunk) error {
+			mu.Lock()
+			defer mu.Unlock()
+			for _, chunk := range batch {
+				setChunks[chunk.ID] = chunk.Vector
+			}
+			return nil
+		},
+	}
+
+	ctx := vickytest.SetupRegistry(t,
+		vickytest.WithJobs(&vickytest.MockJobs{}),
+		vickytest.WithChunks(mc),
+		vickytest.WithEmbedder(me),
+		vickytest.WithEmbeddingCache(mec),
+		vickytest.WithVersions(&vickytest.MockVersions{}),
+		vickytest.WithIngestionConfigs(&vickytest.MockIngestionConfigs{}),
+		vickytest.WithSymbols(&vickytest.MockSymbols{}),
+		vickytest.WithDocuments(&vickytest.MockDocuments{}),
+	)
+
+	if _, err := embedStage(ctx, vickytest.NewJob(t)); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if len(sent) != 2 || sent[0] != chunks[1].Content || sent[1] != chunks[2].Content {
+		t.Errorf("sent to provider = %q, want only the two misses", sent)
+	}
+	if v := setChunks[1]; len(v) != 3 || v[0] != 0.1 {
+		t.Errorf("chunk 1 vector = %v, want the cached vector", v)
+	}
+	if v := setChunks[2]; len(v) != 3 || v[0] != 0.5 {
+		t.Errorf("chunk 2 vector = %v, want the embedded vector", v)
+	}
+
+	if len(stored) != 2 {
+		t.Fatalf("cached %d entries, want 2", len(stored))
+	}
+	for _, e := range stored {
+		if e.Provider != "mock" || e.Model != "mock-model" || e.Dimensions != 3 {
+			t.Errorf("entry identity = %s/%s/%d, want mock/mock-model/3", e.Provider, e.Model, e.Dimensions)
+		}
+		if e.Key == cachedKey {
+			t.Error("cache hit was written back")
+		}
+	}
+}
+
+func TestEmbedCached_DeduplicatesMisses(t *testing.T) {
+	var sent []string
+	me := &vickytest.MockEmbedder{
+		OnEmbed: func(ctx context.Context, texts []string) ([][]float32, error) {
+			sent = texts
+			vectors := make([][]float32, len(texts))
+			for i := range texts {
+				vectors[i] = []float32{float32(i)}
+			}
+			return vectors, nil
+		},
+	}
+	var stored []*models.EmbeddingCacheEntry
+	mec := &vickytest.MockEmbeddingCache{
+		OnPutMany: func(ctx context.Context, entries []*models.EmbeddingCacheEntry) error {
+			stored = entries
+			return nil
+		},
+	}
+	ctx := vickytest.SetupRegistry(t, vickytest.WithEmbeddingCache(mec))
+	stats := &cacheStats{}
+
+	vectors, err := embedCached(ctx, &embedWork{Cache: stats}, me, []string{"a", "b", "a"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if len(sent) != 2 {
+		t.Errorf("sent %q, want each distinct input once", sent)
+	}
+	if len(stored) != 2 || stored[0].Key != models.EmbeddingCacheKey("a", "mock", "mock-model", 3) {
+		t.Errorf("stored %d entries, want 2 in input order", len(stored))
+	}
+	if vectors[0][0] != 0 || vectors[1][0] != 1 || vectors[2][0] != 0 {
+		t.Errorf("vectors = %v, want duplicates to share a vector", vectors)
+	}
+	if stats.hits.Load() != 0 || stats.misses.Load() != 3 {
+		t.Errorf("hits/misses = %d/%d, want 0/3", stats.hits.Load(), stats.misses.Load())
+	}
+}
+
+func TestEmbedCached_AllHits(t *testing.T) {
+	me := &vickytest.MockEmbedder{
+		OnEmbed: func(ctx context.Context, texts []string) ([][]float32, error) {
+			t.Error("provider called although every input was cached")
+			return nil, nil
+		},
+	}
+	mec := &vickytest.MockEmbeddingCache{
+		OnGetMany: func(ctx context.Context, keys []string) (map[string][]float32, error) {
+			cached := make(map[string][]float32, len(keys))
+			for _, k := range keys {
+				cached[k] = []float32{1}
+			}
+			return cached, nil
+		},
+	}
+	ctx := vickytest.SetupRegistry(t, vickytest.WithEmbeddingCache(mec))
+	stats := &cacheStats{}
+
+	if _, err := embedCached(ctx, &embedWork{Cache: stats}, me, []string{"a", "b"}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if stats.HitRate() != 1 {
+		t.Errorf("HitRate = %v, want 1", stats.HitRate())
+	}
+}
+
+func TestEmbedCached_CacheErrorFallsBack(t *testing.T) {
+	var sent int
+	me := &vickytest.MockEmbedder{
+		OnEmbed: func(ctx context.Context, texts []string) ([][]float32, error) {
+			sent += len(texts)
+			return make([][]float32, len(texts)), nil
+		},
+	}
+	mec := &vickytest.MockEmbeddingCache{
+		OnGetMany: func(ctx context.Context, keys []string) (map[string][]float32, error) {
+			return nil, errors.New("cache down")
+		},
+		OnPutMany: func(ctx context.Context, entries []*models.EmbeddingCacheEntry) error {
+			return errors.New("cache down")
+		},
+	}
+	ctx := vickytest.SetupRegistry(t, vickytest.WithEmbeddingCache(mec))
+
+	vectors, err := embedCached(ctx, &embedWork{}, me, []string{"a", "b"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if sent != 2 || len(vectors) != 2 {
+		t.Errorf("sent %d, got %d vectors; want both inputs embedded", sent, len(vectors))
+	}
+}
+
+func TestEmbedCached_Disabled(t *testing.T) {
+	embeddingCache.Store(false)
+	t.Cleanup(func() { embeddingCache.Store(defaultEmbeddingCache) })
+
+	mec := &vickytest.MockEmbeddingCache{
+		OnGetMany: func(ctx context.Context, keys []string) (map[string][]float32, error) {
+			t.Error("cache read while disabled")
+			return nil, nil
+		},
+	}
+	ctx := vickytest.SetupRegistry(t, vickytest.WithEmbeddingCache(mec))
+	stats := &cacheStats{}
+
+	if _, err := embedCached(ctx, &embedWork{Cache: stats}, &vickytest.MockEmbedder{}, []string{"a"}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if stats.hits.Load()+stats.misses.Load() != 0 {
+		t.Error("disabled cache recorded hits or misses")
+	}
+}
+
+func TestSetEmbeddingCache_NilKeepsSetting(t *testing.T) {
+	t.Cleanup(func() { embeddingCache.Store(defaultEmbeddingCache) })
+
+	disabled := false
+	SetEmbeddingCache(&disabled)
+	SetEmbeddingCache(nil)
+	if embeddingCache.Load() {
+		t.Error("cache re-enabled by an unset setting")
+	}
+}
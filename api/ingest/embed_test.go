@@ -79,8 +79,14 @@ func TestEmbedStage_NoChunks(t *testing.T) {
 	me := &vickytest.MockEmbedder{}
 
 	ctx := vickytest.SetupRegistry(t,
+		vickytest.WithJobs(&vickytest.MockJobs{}),
 		vickytest.WithChunks(mc),
 		vickytest.WithEmbedder(me),
+		vickytest.WithEmbeddingCache(&vickytest.MockEmbeddingCache{}),
+		vickytest.WithVersions(&vickytest.MockVersions{}),
+		vickytest.WithIngestionConfigs(&vickytest.MockIngestionConfigs{}),
+		vickytest.WithSymbols(&vickytest.MockSymbols{}),
+		vickytest.WithDocuments(&vickytest.MockDocuments{}),
 	)
 	job := vickytest.NewJob(t)
 
@@ -108,11 +114,13 @@ func TestEmbedStage_SingleBatch(t *testing.T) {
 		OnListByUserRepoAndTag: func(ctx context.Context, userID int64, owner, repoName, tag string) ([]*models.Chunk, error) {
 			return chunks, nil
 		},
-		OnSet: func(ctx context.Context, key string, chunk *models.Chunk) error {
+		OnUpdateVectors: func(ctx context.Context, batch []*models.Chunk) error {
 			mu.Lock()
 			defer mu.Unlock()
-			setCalls++
-			setChunks[chunk.ID] = chunk.Vector
+			for _, chunk := range batch {
+				setCalls++
+				setChunks[chunk.ID] = chunk.Vector
+			}
 			return nil
 		},
 	}
@@ -131,8 +139,14 @@ func TestEmbedStage_SingleBatch(t *testing.T) {
 	}
 
 	ctx := vickytest.SetupRegistry(t,
+		vickytest.WithJobs(&vickytest.MockJobs{}),
 		vickytest.WithChunks(mc),
 		vickytest.WithEmbedder(me),
+		vickytest.WithEmbeddingCache(&vickytest.MockEmbeddingCache{}),
+		vickytest.WithVersions(&vickytest.MockVersions{}),
+		vickytest.WithIngestionConfigs(&vickytest.MockIngestionConfigs{}),
+		vickytest.WithSymbols(&vickytest.MockSymbols{}),
+		vickytest.WithDocuments(&vickytest.MockDocuments{}),
 	)
 	job := vickytest.NewJob(t)
 
@@ -145,7 +159,7 @@ func TestEmbedStage_SingleBatch(t *testing.T) {
 		t.Errorf("Embed called %d times, want 1", embedCalls)
 	}
 	if setCalls != 3 {
-		t.Errorf("Set called %d times, want 3", setCalls)
+		t.Errorf("vectors written = %d, want 3", setCalls)
 	}
 	if result.ItemsTotal != 3 {
 		t.Errorf("ItemsTotal = %d, want 3", result.ItemsTotal)
@@ -173,10 +187,10 @@ func TestEmbedStage_MultipleBatches(t *testing.T) {
 		OnListByUserRepoAndTag: func(ctx context.Context, userID int64, owner, repoName, tag string) ([]*models.Chunk, error) {
 			return chunks, nil
 		},
-		OnSet: func(ctx context.Context, key string, chunk *models.Chunk) error {
+		OnUpdateVectors: func(ctx context.Context, batch []*models.Chunk) error {
 			mu.Lock()
 			defer mu.Unlock()
-			setCalls++
+			setCalls += len(batch)
 			return nil
 		},
 	}
@@ -195,8 +209,14 @@ func TestEmbedStage_MultipleBatches(t *testing.T) {
 	}
 
 	ctx := vickytest.SetupRegistry(t,
+		vickytest.WithJobs(&vickytest.MockJobs{}),
 		vickytest.WithChunks(mc),
 		vickytest.WithEmbedder(me),
+		vickytest.WithEmbeddingCache(&vickytest.MockEmbeddingCache{}),
+		vickytest.WithVersions(&vickytest.MockVersions{}),
+		vickytest.WithIngestionConfigs(&vickytest.MockIngestionConfigs{}),
+		vickytest.WithSymbols(&vickytest.MockSymbols{}),
+		vickytest.WithDocuments(&vickytest.MockDocuments{}),
 	)
 	job := vickytest.NewJob(t)
 
@@ -210,7 +230,7 @@ func TestEmbedStage_MultipleBatches(t *testing.T) {
 		t.Errorf("Embed called %d times, want 2", embedCalls)
 	}
 	if setCalls != 200 {
-		t.Errorf("Set called %d times, want 200", setCalls)
+		t.Errorf("vectors written = %d, want 200", setCalls)
 	}
 	if result.ItemsTotal != 200 {
 		t.Errorf("ItemsTotal = %d, want 200", result.ItemsTotal)
@@ -236,8 +256,14 @@ func TestEmbedStage_EmbedError(t *testing.T) {
 	}
 
 	ctx := vickytest.SetupRegistry(t,
+		vickytest.WithJobs(&vickytest.MockJobs{}),
 		vickytest.WithChunks(mc),
 		vickytest.WithEmbedder(me),
+		vickytest.WithEmbeddingCache(&vickytest.MockEmbeddingCache{}),
+		vickytest.WithVersions(&vickytest.MockVersions{}),
+		vickytest.WithIngestionConfigs(&vickytest.MockIngestionConfigs{}),
+		vickytest.WithSymbols(&vickytest.MockSymbols{}),
+		vickytest.WithDocuments(&vickytest.MockDocuments{}),
 	)
 	job := vickytest.NewJob(t)
 
@@ -267,8 +293,14 @@ func TestEmbedStage_VectorCountMismatch(t *testing.T) {
 	}
 
 	ctx := vickytest.SetupRegistry(t,
+		vickytest.WithJobs(&vickytest.MockJobs{}),
 		vickytest.WithChunks(mc),
 		vickytest.WithEmbedder(me),
+		vickytest.WithEmbeddingCache(&vickytest.MockEmbeddingCache{}),
+		vickytest.WithVersions(&vickytest.MockVersions{}),
+		vickytest.WithIngestionConfigs(&vickytest.MockIngestionConfigs{}),
+		vickytest.WithSymbols(&vickytest.MockSymbols{}),
+		vickytest.WithDocuments(&vickytest.MockDocuments{}),
 	)
 	job := vickytest.NewJob(t)
 
@@ -288,7 +320,7 @@ func TestEmbedStage_ChunkSetError(t *testing.T) {
 		OnListByUserRepoAndTag: func(ctx context.Context, userID int64, owner, repoName, tag string) ([]*models.Chunk, error) {
 			return chunks, nil
 		},
-		OnSet: func(ctx context.Context, key string, chunk *models.Chunk) error {
+		OnUpdateVectors: func(ctx context.Context, batch []*models.Chunk) error {
 			return fmt.Errorf("disk full")
 		},
 	}
@@ -304,8 +336,14 @@ func TestEmbedStage_ChunkSetError(t *testing.T) {
 	}
 
 	ctx := vickytest.SetupRegistry(t,
+		vickytest.WithJobs(&vickytest.MockJobs{}),
 		vickytest.WithChunks(mc),
 		vickytest.WithEmbedder(me),
+		vickytest.WithEmbeddingCache(&vickytest.MockEmbeddingCache{}),
+		vickytest.WithVersions(&vickytest.MockVersions{}),
+		vickytest.WithIngestionConfigs(&vickytest.MockIngestionConfigs{}),
+		vickytest.WithSymbols(&vickytest.MockSymbols{}),
+		vickytest.WithDocuments(&vickytest.MockDocuments{}),
 	)
 	job := vickytest.NewJob(t)
 
@@ -317,3 +355,233 @@ func TestEmbedStage_ChunkSetError(t *testing.T) {
 		t.Errorf("error = %q, want it to contain %q", err.Error(), "update chunk")
 	}
 }
+
+func TestEmbedStage_SkipsEmbeddedChunks(t *testing.T) {
+	chunks := vickytest.NewChunks(t, 3)
+	chunks[0].Vector = []float32{0.5, 0.5, 0.5}
+
+	var mu sync.Mutex
+	var embeddedTexts int
+
+	mc := &vickytest.MockChunks{
+		OnListByUserRepoAndTag: func(ctx context.Context, userID int64, owner, repoName, tag string) ([]*models.Chunk, error) {
+			return chunks, nil
+		},
+	}
+	me := &vickytest.MockEmbedder{
+		OnEmbed: func(ctx context.Context, texts []string) ([][]float32, error) {
+			mu.Lock()
+			defer mu.Unlock()
+			embeddedTexts += len(texts)
+			vectors := make([][]float32, len(texts))
+			for i := range texts {
+				vectors[i] = []float32{0.1, 0.2, 0.3}
+			}
+			return vectors, nil
+		},
+	}
+
+	ctx := vickytest.SetupRegistry(t,
+		vickytest.WithJobs(&vickytest.MockJobs{}),
+		vickytest.WithChunks(mc),
+		vickytest.WithEmbedder(me),
+		vickytest.WithEmbeddingCache(&vickytest.MockEmbeddingCache{}),
+		vickytest.WithVersions(&vickytest.MockVersions{}),
+		vickytest.WithIngestionConfigs(&vickytest.MockIngestionConfigs{}),
+		vickytest.WithSymbols(&vickytest.MockSymbols{}),
+		vickytest.WithDocuments(&vickytest.MockDocuments{}),
+	)
+
+	result, err := embedStage(ctx, vickytest.NewJob(t))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if embeddedTexts != 2 {
+		t.Errorf("embedded %d texts, want 2", embeddedTexts)
+	}
+	if result.ItemsProcessed != 3 {
+		t.Errorf("ItemsProcessed = %d, want 3", result.ItemsProcessed)
+	}
+}
+
+func TestEmbedStage_Symbols(t *testing.T) {
+	chunks := vickytest.NewChunks(t, 2)
+	symbols := []*models.Symbol{
+		{ID: 1, Kind: models.SymbolKindFunction, QualifiedName: "pkg.New"},
+		{ID: 2, Kind: models.SymbolKindType, QualifiedName: "pkg.Client", Vector: []float32{0.5}},
+	}
+
+	var mu sync.Mutex
+	var texts []string
+	updated := make(map[int64][]float32)
+
+	mc := &vickytest.MockChunks{
+		OnListByUserRepoAndTag: func(ctx context.Context, userID int64, owner, repoName, tag string) ([]*models.Chunk, error) {
+			return chunks, nil
+		},
+	}
+	ms := &vickytest.MockSymbols{
+		OnListByUserRepoAndTag: func(ctx context.Context, userID int64, owner, repoName, tag string) ([]*models.Symbol, error) {
+			return symbols, nil
+		},
+		OnUpdateVectors: func(ctx context.Context, batch []*models.Symbol) error {
+			mu.Lock()
+			defer mu.Unlock()
+			for _, symbol := range batch {
+				updated[symbol.ID] = symbol.Vector
+			}
+			return nil
+		},
+	}
+	me := &vickytest.MockEmbedder{
+		OnEmbed: func(ctx context.Context, batch []string) ([][]float32, error) {
+			mu.Lock()
+			defer mu.Unlock()
+			texts = append(texts, batch...)
+			vectors := make([][]float32, len(batch))
+			for i := range batch {
+				vectors[i] = []float32{0.1}
+			}
+			return vectors, nil
+		},
+	}
+
+	ctx := vickytest.SetupRegistry(t,
+		vickytest.WithJobs(&vickytest.MockJobs{}),
+		vickytest.WithChunks(mc),
+		vickytest.WithEmbedder(me),
+		vickytest.WithEmbeddingCache(&vickytest.MockEmbeddingCache{}),
+		vickytest.WithVersions(&vickytest.MockVersions{}),
+		vickytest.WithIngestionConfigs(&vickytest.MockIngestionConfigs{}),
+		vickytest.WithSymbols(ms),
+		vickytest.WithDocuments(&vickytest.MockDocuments{}),
+	)
+
+	result, err := embedStage(ctx, vickytest.NewJob(t))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if result.ItemsTotal != 4 || result.ItemsProcessed != 4 {
+		t.Errorf("items = %d/%d, want 4/4", result.ItemsProcessed, result.ItemsTotal)
+	}
+	if len(texts) != 3 {
+		t.Errorf("embedded %d texts, want 3", len(texts))
+	}
+	if len(updated) != 1 || updated[1] == nil {
+		t.Errorf("updated symbols = %v, want only symbol 1", updated)
+	}
+}
+
+func TestEmbedStage_DocumentVectors(t *testing.T) {
+	chunks := vickytest.NewChunks(t, 3)
+	chunks[0].DocumentID = 1
+	chunks[1].DocumentID = 1
+	chunks[2].DocumentID = 2
+	chunks[2].Vector = []float32{0.5, 0.5}
+
+	reused := vickytest.NewDocument(t, 2, "reused.go")
+	reused.Vector = []float32{0.9, 0.9}
+
+	mc := &vickytest.MockChunks{
+		OnListByUserRepoAndTag: func(ctx context.Context, userID int64, owner, repoName, tag string) ([]*models.Chunk, error) {
+			return chunks, nil
+		},
+	}
+	me := &vickytest.MockEmbedder{
+		OnEmbed: func(ctx context.Context, texts []string) ([][]float32, error) {
+			return [][]float32{{1, 0}, {0, 1}}, nil
+		},
+	}
+
+	updated := make(map[int64][]float32)
+	md := &vickytest.MockDocuments{
+		OnListByUserRepoAndTag: func(ctx context.Context, userID int64, owner, repoName, tag string) ([]*models.Document, error) {
+			return []*models.Document{vickytest.NewDocument(t, 1, "main.go"), reused}, nil
+		},
+		OnSet: func(ctx context.Context, key string, doc *models.Document) error {
+			updated[doc.ID] = doc.Vector
+			return nil
+		},
+	}
+
+	ctx := vickytest.SetupRegistry(t,
+		vickytest.WithJobs(&vickytest.MockJobs{}),
+		vickytest.WithChunks(mc),
+		vickytest.WithEmbedder(me),
+		vickytest.WithEmbeddingCache(&vickytest.MockEmbeddingCache{}),
+		vickytest.WithVersions(&vickytest.MockVersions{}),
+		vickytest.WithIngestionConfigs(&vickytest.MockIngestionConfigs{}),
+		vickytest.WithSymbols(&vickytest.MockSymbols{}),
+		vickytest.WithDocuments(md),
+	)
+
+	if _, err := embedStage(ctx, vickytest.NewJob(t)); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if len(updated) != 1 {
+		t.Fatalf("updated %d documents, want 1", len(updated))
+	}
+	if v := updated[1]; len(v) != 2 || v[0] != 0.5 || v[1] != 0.5 {
+		t.Errorf("document 1 vector = %v, want mean [0.5 0.5]", v)
+	}
+}
+
+func TestEmbedStage_DocumentVectorsDisabled(t *testing.T) {
+	documentVectors.Store(false)
+	t.Cleanup(func() { documentVectors.Store(defaultDocumentVectors) })
+
+	mc := &vickytest.MockChunks{
+		OnListByUserRepoAndTag: func(ctx context.Context, userID int64, owner, repoName, tag string) ([]*models.Chunk, error) {
+			return vickytest.NewChunks(t, 2), nil
+		},
+	}
+
+	// Documents is not registered: resolving it would panic
+	ctx := vickytest.SetupRegistry(t,
+		vickytest.WithJobs(&vickytest.MockJobs{}),
+		vickytest.WithChunks(mc),
+		vickytest.WithEmbedder(&vickytest.MockEmbedder{}),
+		vickytest.WithEmbeddingCache(&vickytest.MockEmbeddingCache{}),
+		vickytest.WithVersions(&vickytest.MockVersions{}),
+		vickytest.WithIngestionConfigs(&vickytest.MockIngestionConfigs{}),
+		vickytest.WithSymbols(&vickytest.MockSymbols{}),
+	)
+
+	if _, err := embedStage(ctx, vickytest.NewJob(t)); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+}
+
+func TestMeanVector(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name    string
+		vectors [][]float32
+		want    []float32
+	}{
+		{"none", nil, nil},
+		{"no vectors", [][]float32{nil, {}}, nil},
+		{"single", [][]float32{{1, 2}}, []float32{1, 2}},
+		{"mean", [][]float32{{1, 0}, {0, 1}, nil}, []float32{0.5, 0.5}},
+		{"mismatched dims ignored", [][]float32{{2, 2}, {1, 1, 1}}, []float32{2, 2}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			chunks := make([]*models.Chunk, len(tt.vectors))
+			for i, v := range tt.vectors {
+				chunks[i] = &models.Chunk{Vector: v}
+			}
+
+			got := meanVector(chunks)
+			if fmt.Sprint(got) != fmt.Sprint(tt.want) || (got == nil) != (tt.want == nil) {
+				t.Errorf("meanVector() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
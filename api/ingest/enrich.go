@@ -0,0 +1,132 @@
+package ingest
+
+import (
+	"fmt"
+	"strings"
+	"sync/atomic"
+	"text/template"
+
+	"github.com/zoobzio/vicky/models"
+)
+
+// embeddingStrategy is the strategy new versions are ingested with, set by the capacitor.
+var embeddingStrategy atomic.Pointer[models.EmbeddingStrategy]
+
+func init() {
+	embeddingStrategy.Store(models.DefaultEmbeddingStrategy())
+}
+
+// embeddingFuncs are available to embedding templates.
+var embeddingFuncs = template.FuncMap{
+	"join": strings.Join,
+}
+
+// EmbeddingInput is the data an embedding template renders.
+type EmbeddingInput struct {
+	Path      string   // file path within the repository
+	Language  string   // source language, or the content format of docs and schemas
+	Kind      string   // chunk kind, e.g. function or section
+	Symbol    string   // function or type name, if any
+	Context   []string // parent chain for nested symbols
+	Signature string   // signature of the chunk's symbol, if indexed
+	Content   string   // raw chunk content
+}
+
+// ParseEmbeddingTemplate compiles an embedding template.
+func ParseEmbeddingTemplate(text string) (*template.Template, error) {
+	return template.New("embedding").Funcs(embeddingFuncs).Parse(text)
+}
+
+// SetEmbeddingStrategy updates the strategy new versions are ingested with.
+// Called by capacitor when config changes. An empty name or a template that
+// does not compile is ignored.
+func SetEmbeddingStrategy(name string, templates map[models.ContentType]string) {
+	if name == "" {
+		return
+	}
+	for _, text := range templates {
+		if _, err := ParseEmbeddingTemplate(text); err != nil {
+			return
+		}
+	}
+	s := &models.EmbeddingStrategy{Name: name, Templates: templates}
+	embeddingStrategy.Store(s.Clone())
+}
+
+// CurrentEmbeddingStrategy returns the strategy new versions are ingested with.
+func CurrentEmbeddingStrategy() *models.EmbeddingStrategy {
+	return embeddingStrategy.Load().Clone()
+}
+
+// enricher renders the embedding input for a version's chunks. Stored chunk
+// content is never changed.
+type enricher struct {
+	templates map[models.ContentType]*template.Template
+	configs   []*models.IngestionConfig   // language roots, for each chunk's language
+	symbols   map[string][]*models.Symbol // by file path
+}
+
+// newEnricher compiles a strategy for one version. The repository's configs
+// supply each chunk's language and symbols the signature of its symbol.
+func newEnricher(strategy *models.EmbeddingStrategy, configs []*models.IngestionConfig, symbols []*models.Symbol) (*enricher, error) {
+	e := &enricher{
+		templates: make(map[models.ContentType]*template.Template, len(strategy.Templates)),
+		configs:   configs,
+		symbols:   make(map[string][]*models.Symbol),
+	}
+	for contentType, text := range strategy.Templates {
+		tmpl, err := ParseEmbeddingTemplate(text)
+		if err != nil {
+			return nil, fmt.Errorf("%s template: %w", contentType, err)
+		}
+		e.templates[contentType] = tmpl
+	}
+	for _, s := range symbols {
+		if s.Signature != nil {
+			e.symbols[s.FilePath] = append(e.symbols[s.FilePath], s)
+		}
+	}
+	return e, nil
+}
+
+// Text returns the text embedded for a chunk: its content rendered through
+// the template for the chunk's content type, or the content verbatim.
+func (e *enricher) Text(c *models.Chunk) (string, error) {
+	contentType := contentTypeForPath(e.configs, c.Path)
+	tmpl, ok := e.templates[contentType]
+	if !ok {
+		return c.Content, nil
+	}
+
+	in := EmbeddingInput{
+		Path:     c.Path,
+		Language: string(languageForPath(e.configs, c.Path)),
+		Kind:     string(c.Kind),
+		Context:  c.Context,
+		Content:  c.Content,
+	}
+	if contentType != models.ContentTypeCode {
+		in.Language = string(formatForPath(e.configs, c.Path))
+	}
+	if c.Symbol != nil {
+		in.Symbol = *c.Symbol
+		in.Signature = e.signature(c)
+	}
+
+	var b strings.Builder
+	if err := tmpl.Execute(&b, in); err != nil {
+		return "", fmt.Errorf("render %s: %w", c.Path, err)
+	}
+	return b.String(), nil
+}
+
+// signature finds the indexed signature of the chunk's symbol: a symbol of
+// the same name in the same file that starts within the chunk.
+func (e *enricher) signature(c *models.Chunk) string {
+	for _, s := range e.symbols[c.Path] {
+		if s.Name == *c.Symbol && s.StartLine >= c.StartLine && s.StartLine <= c.EndLine {
+			return *s.Signature
+		}
+	}
+	return ""
+}
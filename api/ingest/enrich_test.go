@@ -0,0 +1,143 @@
+//go:build testing
+
+package ingest
+
+import (
+	"context"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/zoobzio/vicky/models"
+	vickytest "github.com/zoobzio/vicky/testing"
+)
+
+func TestEnricher_Text(t *testing.T) {
+	strategy := &models.EmbeddingStrategy{
+		Name: "contextual",
+		Templates: map[models.ContentType]string{
+			models.ContentTypeCode: `{{.Language}} {{.Path}} {{.Symbol}} [{{join .Context " > "}}]
+{{.Signature}}
+{{.Content}}`,
+		},
+	}
+	sig := "func (s *UserService) GetUser(id int64) *User"
+	symbols := []*models.Symbol{
+		{Name: "GetUser", FilePath: "main.go", StartLine: 40, Signature: &sig},
+		{Name: "GetUser", FilePath: "main.go", StartLine: 3, Signature: strPtr("func GetUser()")},
+	}
+
+	e, err := newEnricher(strategy, []*models.IngestionConfig{{Language: models.LanguageGo}}, symbols)
+	if err != nil {
+		t.Fatalf("newEnricher: %v", err)
+	}
+
+	chunk := vickytest.NewChunk(t, 1, "return s.users[id]")
+	chunk.StartLine, chunk.EndLine = 40, 45
+	chunk.Symbol = strPtr("GetUser")
+	chunk.Context = []string{"type UserService", "method GetUser"}
+
+	got, err := e.Text(chunk)
+	if err != nil {
+		t.Fatalf("Text: %v", err)
+	}
+	want := "go main.go GetUser [type UserService > method GetUser]\n" + sig + "\nreturn s.users[id]"
+	if got != want {
+		t.Errorf("Text() = %q, want %q", got, want)
+	}
+	if chunk.Content != "return s.users[id]" {
+		t.Error("chunk content was modified")
+	}
+
+	// Docs have no template, so they embed verbatim
+	doc := vickytest.NewChunk(t, 2, "# Guide")
+	doc.Path = "docs/guide.md"
+	if got, _ := e.Text(doc); got != "# Guide" {
+		t.Errorf("docs Text() = %q, want verbatim content", got)
+	}
+}
+
+func TestSetEmbeddingStrategy(t *testing.T) {
+	t.Cleanup(func() { embeddingStrategy.Store(models.DefaultEmbeddingStrategy()) })
+
+	SetEmbeddingStrategy("path", map[models.ContentType]string{models.ContentTypeCode: "{{.Path}}\n{{.Content}}"})
+	SetEmbeddingStrategy("broken", map[models.ContentType]string{models.ContentTypeCode: "{{.Path"}) // ignored
+	SetEmbeddingStrategy("", nil)                                                                    // ignored
+
+	if got := CurrentEmbeddingStrategy(); got.Name != "path" {
+		t.Errorf("strategy = %q, want path", got.Name)
+	}
+}
+
+func TestEmbedStage_UsesRecordedStrategy(t *testing.T) {
+	version := vickytest.NewVersion(t)
+	if err := version.SetEmbeddingStrategy(&models.EmbeddingStrategy{
+		Name:      "path",
+		Templates: map[models.ContentType]string{models.ContentTypeCode: "{{.Path}}: {{.Content}}"},
+	}); err != nil {
+		t.Fatal(err)
+	}
+
+	var mu sync.Mutex
+	var embedded []string
+	var stored []string
+
+	mc := &vickytest.MockChunks{
+		OnListByUserRepoAndTag: func(ctx context.Context, userID int64, owner, repoName, tag string) ([]*models.Chunk, error) {
+			return vickytest.NewChunks(t, 2), nil
+		},
+		OnUpdateVectors: func(ctx context.Context, batch []*models.Chunk) error {
+			mu.Lock()
+			defer mu.Unlock()
+			for _, chunk := range batch {
+				stored = append(stored, chunk.Content)
+			}
+			return nil
+		},
+	}
+	me := &vickytest.MockEmbedder{
+		OnEmbed: func(ctx context.Context, texts []string) ([][]float32, error) {
+			mu.Lock()
+			defer mu.Unlock()
+			embedded = append(embedded, texts...)
+			vectors := make([][]float32, len(texts))
+			for i := range vectors {
+				vectors[i] = []float32{1}
+			}
+			return vectors, nil
+		},
+	}
+	mv := &vickytest.MockVersions{
+		OnGet: func(ctx context.Context, key string) (*models.Version, error) {
+			return version, nil
+		},
+	}
+
+	ctx := vickytest.SetupRegistry(t,
+		vickytest.WithJobs(&vickytest.MockJobs{}),
+		vickytest.WithChunks(mc),
+		vickytest.WithEmbedder(me),
+		vickytest.WithEmbeddingCache(&vickytest.MockEmbeddingCache{}),
+		vickytest.WithVersions(mv),
+		vickytest.WithIngestionConfigs(&vickytest.MockIngestionConfigs{}),
+		vickytest.WithSymbols(&vickytest.MockSymbols{}),
+		vickytest.WithDocuments(&vickytest.MockDocuments{}),
+	)
+
+	if _, err := embedStage(ctx, vickytest.NewJob(t)); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+	for _, text := range embedded {
+		if !strings.HasPrefix(text, "main.go: chunk content") {
+			t.Errorf("embedded %q, want path-prefixed content", text)
+		}
+	}
+	for _, content := range stored {
+		if strings.HasPrefix(content, "main.go") {
+			t.Errorf("stored content %q was enriched", content)
+		}
+	}
+}
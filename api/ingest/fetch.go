@@ -2,8 +2,11 @@ package ingest
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"maps"
 	"path/filepath"
+	"slices"
 	"strings"
 	"sync"
 	"sync/atomic"
@@ -14,6 +17,8 @@ import (
 	"github.com/zoobzio/sum"
 	"github.com/zoobzio/vicky/api/contracts"
 	"github.com/zoobzio/vicky/api/events"
+	"github.com/zoobzio/vicky/external/github"
+	"github.com/zoobzio/vicky/internal/gitignore"
 	"github.com/zoobzio/vicky/models"
 )
 
@@ -31,6 +36,10 @@ var fetchPool *pipz.WorkerPool[*fetchWork]
 const (
 	defaultFetchWorkers = 8
 	defaultFetchTimeout = 30 * time.Second
+
+	// maxFetchInflight bounds how many files, streamed or reused, are
+	// handed to the pool at once; streamed ones are held in memory.
+	maxFetchInflight = 64
 )
 
 func init() {
@@ -54,21 +63,462 @@ func SetFetchConfig(workers int, timeout time.Duration) {
 var languageExtensions = map[models.Language][]string{
 	models.LanguageGo:         {".go"},
 	models.LanguageTypeScript: {".ts", ".tsx", ".js", ".jsx"},
+	models.LanguagePython:     {".py", ".pyi"},
+}
+
+// Project manifests by language, matched against file names. Indexers need
+// them to resolve a project although they are not ingested themselves.
+var languageManifests = map[models.Language][]string{
+	models.LanguageGo:         {"go.mod", "go.sum", "go.work", "go.work.sum"},
+	models.LanguageTypeScript: {"package.json", "package-lock.json", "yarn.lock", "pnpm-lock.yaml", "pnpm-workspace.yaml", "tsconfig*.json", "jsconfig.json"},
+	models.LanguagePython:     {"pyproject.toml", "setup.cfg", "requirements*.txt"},
+}
+
+// ignoreFiles are the repository files whose gitignore-style rules exclude
+// files beneath them.
+var ignoreFiles = []string{".gitignore", ".vickyignore"}
+
+// Classifier decides whether a repository's configs ingest each file of its
+// tree, and why not. Shared by the fetch stage and the config preview.
+type Classifier struct {
+	projects []*projectRules // deepest root first
+	ignore   *gitignore.Matcher
+}
+
+// projectRules is a config with its patterns compiled.
+type projectRules struct {
+	config  *models.IngestionConfig
+	exclude *gitignore.Matcher // defaults, then the config's exclude patterns
+	include *gitignore.Matcher // nil when every file is allowed
+	formats models.ContentFormatMap
+}
+
+// NewClassifier compiles the configs' patterns. ignore holds the rules of
+// the repository's ignore files and may be nil.
+func NewClassifier(configs []*models.IngestionConfig, ignore *gitignore.Matcher) *Classifier {
+	c := &Classifier{ignore: ignore}
+	for _, config := range byRootDepth(configs) {
+		p := &projectRules{
+			config:  config,
+			exclude: gitignore.New(ruleSourceDefault, models.DefaultExcludePatterns),
+			formats: contentFormats(config),
+		}
+		p.exclude.Add(ruleSourceExclude, "", config.ExcludePatterns)
+		if len(config.IncludePatterns) > 0 {
+			p.include = gitignore.New(models.ExcludeRuleInclude, config.IncludePatterns)
+		}
+		c.projects = append(c.projects, p)
+	}
+	return c
+}
+
+// Sources of config rules.
+const (
+	ruleSourceDefault = "default"
+	ruleSourceExclude = "exclude_patterns"
+)
+
+// Classify decides whether a file is ingested. Each config whose root
+// contains the file classifies it, deepest root first, and the first to
+// include it owns it; otherwise the deepest root's decision stands.
+func (c *Classifier) Classify(path string, size int64) models.FileClassification {
+	var first *models.FileClassification
+	for _, p := range c.projects {
+		if !p.config.Contains(path) {
+			continue
+		}
+		fc := c.classifyFor(p, path, size)
+		if fc.Decision.Included() {
+			return fc
+		}
+		if first == nil {
+			first = &fc
+		}
+	}
+	if first != nil {
+		return *first
+	}
+	return models.FileClassification{
+		Path:     path,
+		Size:     size,
+		Decision: models.FileDecisionUnsupported,
+		Rule:     models.ExcludeRuleRoot,
+		Reason:   "outside every language root",
+	}
+}
+
+// classifyFor classifies a file against one config. Size is checked first,
+// then exclude rules, then include patterns, then the extension. The
+// config's rules, relative to its root, take precedence over the
+// repository's ignore files, so a negated pattern can re-include a file.
+func (c *Classifier) classifyFor(p *projectRules, path string, size int64) models.FileClassification {
+	config := p.config
+	fc := models.FileClassification{Path: path, Size: size}
+
+	if size > config.MaxFileSize {
+		fc.Decision = models.FileDecisionTooLarge
+		fc.Rule = models.ExcludeRuleMaxFileSize
+		fc.Reason = fmt.Sprintf("%d bytes exceeds the %d byte limit", size, config.MaxFileSize)
+		return fc
+	}
+
+	rel := config.RelativePath(path)
+	rule, excluded := p.exclude.Match(rel)
+	if rule == nil {
+		rule, excluded = c.ignore.Match(path)
+	}
+	if excluded {
+		fc.Decision = models.FileDecisionExcluded
+		fc.Rule = rule.Pattern
+		fc.Reason = ruleReason(rule)
+		return fc
+	}
+
+	if p.include != nil {
+		if _, ok := p.include.Match(rel); !ok {
+			fc.Decision = models.FileDecisionExcluded
+			fc.Rule = models.ExcludeRuleInclude
+			fc.Reason = "matches no include pattern"
+			return fc
+		}
+	}
+
+	ext := strings.ToLower(filepath.Ext(path))
+	format := p.formats.For(path)
+	switch {
+	case containsExt(languageExtensions[config.Language], ext):
+		fc.Decision = models.FileDecisionCode
+		fc.Language = config.Language
+	case config.IncludeDocs && format != "":
+		fc.Decision = models.FileDecisionDocs
+		if format.ContentType() == models.ContentTypeSchema {
+			fc.Decision = models.FileDecisionSchema
+		}
+		fc.Format = format
+	default:
+		fc.Decision = models.FileDecisionUnsupported
+		fc.Rule = models.ExcludeRuleExtension
+		fc.Reason = fmt.Sprintf("extension %q is not ingested for %s", ext, config.Language)
+		if format != "" {
+			fc.Reason = fmt.Sprintf("%s files are disabled by include_docs", format)
+		}
+	}
+	return fc
+}
+
+// ruleReason explains which rule excluded a file.
+func ruleReason(rule *gitignore.Rule) string {
+	switch rule.Source {
+	case ruleSourceDefault:
+		return "matches default exclude pattern"
+	case ruleSourceExclude:
+		return "matches exclude pattern"
+	default:
+		return fmt.Sprintf("matches %s line %d", rule.Source, rule.Line)
+	}
+}
+
+// isManifest reports whether a file is a project manifest, or the explicit
+// tsconfig, of a config whose root contains it. Manifests are stored for the
+// indexers despite rules naming them, such as go.sum, but not from excluded
+// directories such as node_modules.
+func (c *Classifier) isManifest(path string) bool {
+	name := filepath.Base(path)
+	for _, p := range c.projects {
+		if !p.config.Contains(path) {
+			continue
+		}
+		rel := p.config.RelativePath(path)
+		if !matchesName(languageManifests[p.config.Language], name) && !isTsConfig(p.config, rel) {
+			continue
+		}
+		if !excludedByDirectory(p.exclude, rel, name) && !excludedByDirectory(c.ignore, path, name) {
+			return true
+		}
+	}
+	return false
+}
+
+// excludedByDirectory reports whether m excludes a file through a rule for
+// a directory above it rather than one that names the file.
+func excludedByDirectory(m *gitignore.Matcher, path, name string) bool {
+	rule, ok := m.Match(path)
+	return ok && !rule.MatchesName(name)
+}
+
+// WithIgnore returns a copy of the classifier that applies ignore, the rules
+// of the repository's ignore files, in place of its own.
+func (c *Classifier) WithIgnore(ignore *gitignore.Matcher) *Classifier {
+	return &Classifier{projects: c.projects, ignore: ignore}
+}
+
+// IgnoreFiles lists the .gitignore and .vickyignore files of a tree whose
+// rules can apply to a file the configs ingest, shallowest first. Files in
+// directories the rules already exclude are left out: nothing beneath them
+// is ingested, whatever they say.
+func (c *Classifier) IgnoreFiles(tree []github.TreeEntry) []string {
+	var paths []string
+	for _, entry := range tree {
+		if entry.Type == "blob" && slices.Contains(ignoreFiles, filepath.Base(entry.Path)) && c.ignoreFileApplies(entry.Path) {
+			paths = append(paths, entry.Path)
+		}
+	}
+	sortIgnoreFiles(paths)
+	return paths
+}
+
+// ignoreFileApplies reports whether an ignore file's directory may hold a
+// file some config ingests.
+func (c *Classifier) ignoreFileApplies(path string) bool {
+	name := filepath.Base(path)
+	dir := filepath.Dir(path)
+	for _, p := range c.projects {
+		if !p.config.Contains(path) {
+			// A root beneath the file's directory gets its rules too
+			if dir == "." || strings.HasPrefix(p.config.Root+"/", dir+"/") {
+				return true
+			}
+			continue
+		}
+		if !excludedByDirectory(p.exclude, p.config.RelativePath(path), name) && !excludedByDirectory(c.ignore, path, name) {
+			return true
+		}
+	}
+	return false
+}
+
+// sortIgnoreFiles orders ignore files shallowest first, and .gitignore
+// before .vickyignore in the same directory, so later rules take precedence.
+func sortIgnoreFiles(paths []string) {
+	slices.SortFunc(paths, func(a, b string) int {
+		if d := strings.Count(a, "/") - strings.Count(b, "/"); d != 0 {
+			return d
+		}
+		return strings.Compare(a, b)
+	})
+}
+
+// compileIgnoreFiles compiles the rules of the ignore files read so far, in
+// the order of paths. Files without content contribute no rules.
+func compileIgnoreFiles(paths []string, contents map[string]string) *gitignore.Matcher {
+	ignore := &gitignore.Matcher{}
+	for _, p := range paths {
+		if content, ok := contents[p]; ok {
+			ignore.AddFile(p, content)
+		}
+	}
+	return ignore
+}
+
+// LoadIgnoreFiles fetches ignore files listed by Classifier.IgnoreFiles one
+// by one and compiles their rules, for callers that don't download the
+// archive. A file that cannot be fetched contributes no rules; its error is
+// returned by path rather than failing the load.
+func LoadIgnoreFiles(ctx context.Context, token, owner, repo, ref string, paths []string) (*gitignore.Matcher, map[string]error) {
+	gh := sum.MustUse[contracts.GitHub](ctx)
+
+	if len(paths) == 0 {
+		return &gitignore.Matcher{}, nil
+	}
+
+	files, err := gh.GetFileContentBatch(ctx, token, owner, repo, ref, paths)
+	contents := make(map[string]string, len(files))
+	for _, f := range files {
+		contents[f.Path] = string(f.Content)
+	}
+
+	var failed map[string]error
+	for _, p := range paths {
+		if _, ok := contents[p]; ok {
+			continue
+		}
+		if failed == nil {
+			failed = make(map[string]error)
+		}
+		failed[p] = fileError(err, p)
+	}
+	return compileIgnoreFiles(paths, contents), failed
+}
+
+// fileError picks the error for path out of a batch fetch's joined errors.
+func fileError(err error, path string) error {
+	if joined, ok := err.(interface{ Unwrap() []error }); ok {
+		for _, e := range joined.Unwrap() {
+			var fe *github.FileError
+			if errors.As(e, &fe) && fe.Path == path {
+				return fe.Err
+			}
+		}
+	}
+	if err == nil {
+		return errors.New("not returned by GitHub")
+	}
+	return err
+}
+
+// archiveIgnore collects a commit's ignore files as its archive streams
+// past. Archive order only roughly puts a directory's ignore files before
+// its other files, so a file is classified once every ignore file in the
+// directories above it has been read: only those can apply to it.
+type archiveIgnore struct {
+	base       *Classifier
+	paths      []string // shallowest first
+	contents   map[string]string
+	done       map[string]bool // read, or known to be missing
+	classifier *Classifier
+}
+
+func newArchiveIgnore(base *Classifier, paths []string) *archiveIgnore {
+	a := &archiveIgnore{
+		base:     base,
+		paths:    paths,
+		contents: make(map[string]string, len(paths)),
+		done:     make(map[string]bool, len(paths)),
+	}
+	a.classifier = base.WithIgnore(&gitignore.Matcher{})
+	return a
+}
+
+// Has reports whether path is one of the ignore files being collected.
+func (a *archiveIgnore) Has(path string) bool {
+	return slices.Contains(a.paths, path)
+}
+
+// Add records an ignore file read from the archive.
+func (a *archiveIgnore) Add(path, content string) {
+	a.contents[path] = content
+	a.done[path] = true
+	a.classifier = a.base.WithIgnore(compileIgnoreFiles(a.paths, a.contents))
+}
+
+// Ready reports whether every ignore file that can apply to path is done.
+func (a *archiveIgnore) Ready(path string) bool {
+	for _, p := range a.paths {
+		if a.done[p] {
+			continue
+		}
+		if dir := filepath.Dir(p); dir == "." || strings.HasPrefix(path, dir+"/") {
+			return false
+		}
+	}
+	return true
+}
+
+// Finish marks the ignore files the archive lacked as done, without rules,
+// and returns them.
+func (a *archiveIgnore) Finish() []string {
+	var missing []string
+	for _, p := range a.paths {
+		if !a.done[p] {
+			a.done[p] = true
+			missing = append(missing, p)
+		}
+	}
+	return missing
+}
+
+// Classifier returns the classifier with every ignore file read so far. It
+// classifies a file as the final one would once Ready reports true for it.
+func (a *archiveIgnore) Classifier() *Classifier {
+	return a.classifier
+}
+
+// languageForPath returns the language a code file is chunked and embedded
+// as: that of the deepest config whose root contains the file and whose
+// language claims its extension, falling back to the extension alone for
+// files no config claims.
+func languageForPath(configs []*models.IngestionConfig, path string) models.Language {
+	ext := strings.ToLower(filepath.Ext(path))
+	for _, config := range byRootDepth(configs) {
+		if config.Contains(path) && containsExt(languageExtensions[config.Language], ext) {
+			return config.Language
+		}
+	}
+	for lang, exts := range languageExtensions {
+		if containsExt(exts, ext) {
+			return lang
+		}
+	}
+	return ""
+}
+
+// formatForPath returns the content format a non-code file is chunked and
+// embedded as: that of the deepest config whose root contains the file,
+// falling back to the default formats for files no config contains.
+func formatForPath(configs []*models.IngestionConfig, path string) models.ContentFormat {
+	for _, config := range byRootDepth(configs) {
+		if config.Contains(path) {
+			return contentFormats(config).For(path)
+		}
+	}
+	return models.DefaultContentFormats.For(path)
+}
+
+// contentFormats returns a config's content formats, or the defaults when
+// they do not parse; the API only stores formats that do.
+func contentFormats(config *models.IngestionConfig) models.ContentFormatMap {
+	formats, err := config.GetContentFormats()
+	if err != nil {
+		return models.DefaultContentFormats
+	}
+	return formats
+}
+
+// isTsConfig reports whether rel is the tsconfig a TypeScript config names.
+func isTsConfig(config *models.IngestionConfig, rel string) bool {
+	ts, err := config.GetTypeScriptConfig()
+	return err == nil && ts.TsConfigPath != "" && filepath.ToSlash(filepath.Clean(ts.TsConfigPath)) == rel
+}
+
+// matchesName reports whether name matches any of patterns.
+func matchesName(patterns []string, name string) bool {
+	for _, pattern := range patterns {
+		if ok, _ := filepath.Match(pattern, name); ok {
+			return true
+		}
+	}
+	return false
+}
+
+// byRootDepth returns the configs ordered deepest root first. Roots that
+// contain the same file are prefixes of one another, so longer is deeper.
+func byRootDepth(configs []*models.IngestionConfig) []*models.IngestionConfig {
+	sorted := slices.Clone(configs)
+	slices.SortStableFunc(sorted, func(a, b *models.IngestionConfig) int {
+		return len(b.Root) - len(a.Root)
+	})
+	return sorted
 }
 
 // fetchWork carries file data for parallel blob storage.
 type fetchWork struct {
 	// Context
-	UserID   int64
-	Owner    string
-	Repo     string
-	Tag      string
-	Language string
-	JobID    int64
+	UserID    int64
+	Owner     string
+	Repo      string
+	Tag       string
+	Language  string
+	JobID     int64
+	VersionID int64
 
 	// File data
-	Path    string
-	Content []byte
+	Path        string
+	SHA         string
+	Content     []byte
+	ContentType models.ContentType
+
+	// Previous is the unchanged document from the last ready version.
+	// When set, the file is copied rather than stored from Content.
+	Previous *models.Document
+
+	// Manifest marks a project file only the indexers read; its blob is
+	// stored without a document.
+	Manifest bool
+
+	// KeepVectors copies the previous chunk and document vectors along with
+	// a reused file. False when the previous version used another embedding
+	// strategy, so the embed stage re-embeds the file.
+	KeepVectors bool
 }
 
 func (w *fetchWork) Clone() *fetchWork {
@@ -78,7 +528,12 @@ func (w *fetchWork) Clone() *fetchWork {
 
 // processFetchFile stores a single file blob.
 func processFetchFile(ctx context.Context, w *fetchWork) (*fetchWork, error) {
+	if w.Previous != nil {
+		return reuseFile(ctx, w)
+	}
+
 	blobs := sum.MustUse[contracts.Blobs](ctx)
+	documents := sum.MustUse[contracts.Documents](ctx)
 
 	blob := &models.Blob{
 		Path:     w.Path,
@@ -87,6 +542,7 @@ func processFetchFile(ctx context.Context, w *fetchWork) (*fetchWork, error) {
 		Owner:    w.Owner,
 		Repo:     w.Repo,
 		Tag:      w.Tag,
+		SHA:      w.SHA,
 	}
 
 	if err := blobs.PutBlob(ctx, w.UserID, blob); err != nil {
@@ -97,11 +553,33 @@ func processFetchFile(ctx context.Context, w *fetchWork) (*fetchWork, error) {
 		)
 		return w, fmt.Errorf("store blob %s: %w", w.Path, err)
 	}
+	if w.Manifest {
+		return w, nil
+	}
+
+	// Every fetched file gets a document so it is chunked and embedded
+	// whether or not an indexer reports it. The blob SHA is recorded by
+	// the chunk stage once the document has been chunked.
+	doc := &models.Document{
+		VersionID:   w.VersionID,
+		UserID:      w.UserID,
+		Owner:       w.Owner,
+		RepoName:    w.Repo,
+		Tag:         w.Tag,
+		Path:        w.Path,
+		ContentType: w.ContentType,
+		ContentHash: contentHash(w.Path, w.Tag),
+	}
+	if err := documents.Set(ctx, "", doc); err != nil {
+		return w, fmt.Errorf("create document %s: %w", w.Path, err)
+	}
 
 	return w, nil
 }
 
 // fetchStage fetches repository content from GitHub.
+// The tree decides which files to ingest; their contents come from a single
+// tarball download of the commit.
 func fetchStage(ctx context.Context, job *models.Job) (*models.Job, error) {
 	events.Ingest.Fetch.Started.Emit(ctx, events.FetchEvent{
 		RepositoryID: job.RepositoryID,
@@ -110,9 +588,10 @@ func fetchStage(ctx context.Context, job *models.Job) (*models.Job, error) {
 
 	// Resolve dependencies from registry
 	users := sum.MustUse[contracts.Users](ctx)
-	github := sum.MustUse[contracts.GitHub](ctx)
+	gh := sum.MustUse[contracts.GitHub](ctx)
 	configs := sum.MustUse[contracts.IngestionConfigs](ctx)
 	versions := sum.MustUse[contracts.Versions](ctx)
+	documents := sum.MustUse[contracts.Documents](ctx)
 
 	// Update job stage
 	job.Stage = models.JobStageFetch
@@ -123,11 +602,13 @@ func fetchStage(ctx context.Context, job *models.Job) (*models.Job, error) {
 		return job, err
 	}
 
-	// Get ingestion config
-	config, err := configs.GetByRepositoryID(ctx, job.RepositoryID)
+	// Get the ingestion config of every project root; the tree is
+	// restricted to them
+	listed, err := configs.ListByRepositoryID(ctx, job.RepositoryID)
 	if err != nil {
 		return job, err
 	}
+	repoConfigs := models.ProjectConfigs(listed)
 
 	// Get version for commit SHA
 	version, err := versions.Get(ctx, idToKey(job.VersionID))
@@ -136,99 +617,319 @@ func fetchStage(ctx context.Context, job *models.Job) (*models.Job, error) {
 	}
 
 	// Fetch repository tree
-	tree, err := github.GetTree(ctx, user.AccessToken, job.Owner, job.RepoName, version.CommitSHA)
+	tree, err := gh.GetTree(ctx, user.AccessToken, job.Owner, job.RepoName, version.CommitSHA)
 	if err != nil {
 		return job, err
 	}
 
-	// Filter files based on config
-	var filePaths []string
-	excludePatterns := config.AllExcludePatterns()
-	allowedExts := languageExtensions[config.Language]
+	// Snapshot the embedding strategy; the embed stage uses the recorded one
+	strategy := CurrentEmbeddingStrategy()
+	if err := versions.UpdateEmbeddingStrategy(ctx, job.VersionID, strategy); err != nil {
+		return job, fmt.Errorf("record embedding strategy: %w", err)
+	}
+
+	// Files whose blob SHA is unchanged since the last ready version are reused
+	prevVersion, previous, err := previousDocuments(ctx, job)
+	if err != nil {
+		return job, fmt.Errorf("load previous version: %w", err)
+	}
+
+	// Reused vectors are only comparable if they were embedded the same way
+	keepVectors := false
+	if prevVersion != nil {
+		prevStrategy, err := prevVersion.GetEmbeddingStrategy()
+		keepVectors = err == nil && prevStrategy.Equal(strategy)
+	}
+
+	// The repository's own ignore files at this commit apply alongside the
+	// configs. They are read from the archive, so the files to read are
+	// picked without them: their rules only ever exclude more
+	candidates := NewClassifier(repoConfigs, nil)
+	ignore := newArchiveIgnore(candidates, candidates.IgnoreFiles(tree))
+
+	// Every file the tree lists is reported, including the ones filtered out
+	report := newFileReport(job, models.JobStageFetch)
+	defer report.Save(ctx)
+
+	// streamed maps the files to read from the archive to their blob SHA:
+	// changed candidates, and the excluded project files the indexers need
+	entries := make(map[string]github.TreeEntry)
+	streamed := make(map[string]string)
+	manifests := make(map[string]string)
+	total := 0
 
 	for _, entry := range tree {
 		if entry.Type != "blob" {
 			continue
 		}
-		if entry.Size > config.MaxFileSize {
-			continue
-		}
-		if matchesAnyPattern(entry.Path, excludePatterns) {
+		entries[entry.Path] = entry
+
+		if !candidates.Classify(entry.Path, entry.Size).Decision.Included() {
+			if candidates.isManifest(entry.Path) {
+				manifests[entry.Path] = entry.SHA
+			}
 			continue
 		}
+		total++
 
-		ext := strings.ToLower(filepath.Ext(entry.Path))
-		isCode := containsExt(allowedExts, ext)
-		isDocs := config.IncludeDocs && containsExt(docExtensions, ext)
-
-		if isCode || isDocs {
-			filePaths = append(filePaths, entry.Path)
+		if prev, ok := previous[entry.Path]; ok && entry.SHA != "" && *prev.BlobSHA == entry.SHA {
+			continue
 		}
+		streamed[entry.Path] = entry.SHA
 	}
 
-	job.ItemsTotal = len(filePaths)
-
-	if len(filePaths) == 0 {
-		events.Ingest.Fetch.Completed.Emit(ctx, events.FetchEvent{
-			RepositoryID: job.RepositoryID,
-			VersionID:    job.VersionID,
-			ByteCount:    0,
-		})
-		return job, nil
+	// Manifests are only worth storing alongside some file to index
+	if total > 0 {
+		maps.Copy(streamed, manifests)
 	}
 
-	// Fetch file contents in batch
-	contents, err := github.GetFileContentBatch(ctx, user.AccessToken, job.Owner, job.RepoName, version.CommitSHA, filePaths)
-	if err != nil {
-		return job, err
+	// Start from a clean version so a rerun doesn't duplicate documents;
+	// chunks and SCIP rows cascade with them
+	if err := documents.DeleteByVersion(ctx, job.VersionID); err != nil {
+		return job, fmt.Errorf("clear documents: %w", err)
 	}
 
+	// The total is settled once the ignore files have been read
+	job.ItemsTotal = total
+	tracker := trackProgress(ctx, job, total)
+
 	// Process files concurrently via long-lived pool
 	var (
-		wg       sync.WaitGroup
-		errOnce  sync.Once
-		firstErr error
-		stored   atomic.Int64
+		wg     sync.WaitGroup
+		stored atomic.Int64
+		reused int
 	)
 
-	language := string(config.Language)
+	process := func(w *fetchWork) {
+		defer wg.Done()
+		defer tracker.Add(ctx, 1)
 
-	for _, content := range contents {
-		wg.Add(1)
-		go func(path string, data []byte) {
-			defer wg.Done()
-
-			_, err := fetchPool.Process(ctx, &fetchWork{
-				UserID:   job.UserID,
-				Owner:    job.Owner,
-				Repo:     job.RepoName,
-				Tag:      job.Tag,
-				Language: language,
-				JobID:    job.ID,
-				Path:     path,
-				Content:  data,
+		if _, err := fetchPool.Process(ctx, w); err != nil {
+			events.Ingest.Fetch.FileFailed.Emit(ctx, events.FetchFileEvent{
+				RepositoryID: job.RepositoryID,
+				VersionID:    job.VersionID,
+				FilePath:     w.Path,
+				Reason:       err.Error(),
+			})
+			report.Fail(w.Path, err)
+			return
+		}
+
+		stored.Add(1)
+
+		var reason string
+		if w.Previous != nil {
+			reason = "unchanged since the previous version"
+		}
+		report.Add(w.Path, models.FileOutcomeIncluded, "", reason, entries[w.Path].Size)
+	}
+
+	// Manifests stay out of the progress count and the report; without one
+	// an indexer resolves less, so a failure is not a file failure
+	storeManifest := func(w *fetchWork) {
+		defer wg.Done()
+		if _, err := fetchPool.Process(ctx, w); err != nil {
+			events.Ingest.Fetch.FileFailed.Emit(ctx, events.FetchFileEvent{
+				RepositoryID: job.RepositoryID,
+				VersionID:    job.VersionID,
+				FilePath:     w.Path,
+				Reason:       err.Error(),
+			})
+		}
+	}
+
+	inflight := make(chan struct{}, maxFetchInflight)
+
+	// Stream changed files, manifests, and ignore files from the commit tarball
+	seen := make(map[string]bool, len(streamed))
+	var missingIgnore []string
+	if total > 0 && (len(streamed) > 0 || len(ignore.paths) > 0) {
+		// dispatch hands a read file to the pool once its ignore files are in
+		dispatch := func(content *github.FileContent) error {
+			classifier := ignore.Classifier()
+			c := classifier.Classify(content.Path, entries[content.Path].Size)
+			manifest := !c.Decision.Included()
+			if manifest && !classifier.isManifest(content.Path) {
+				// Excluded by an ignore file; reported with the rest
+				return nil
+			}
+
+			select {
+			case inflight <- struct{}{}:
+			case <-ctx.Done():
+				return ctx.Err()
+			}
+
+			wg.Add(1)
+			if manifest {
+				go func() {
+					defer func() { <-inflight }()
+					storeManifest(&fetchWork{
+						UserID:   job.UserID,
+						Owner:    job.Owner,
+						Repo:     job.RepoName,
+						Tag:      job.Tag,
+						JobID:    job.ID,
+						Path:     content.Path,
+						SHA:      streamed[content.Path],
+						Content:  content.Content,
+						Manifest: true,
+					})
+				}()
+				return nil
+			}
+			go func() {
+				defer func() { <-inflight }()
+				process(&fetchWork{
+					UserID:      job.UserID,
+					Owner:       job.Owner,
+					Repo:        job.RepoName,
+					Tag:         job.Tag,
+					Language:    string(c.Language),
+					JobID:       job.ID,
+					VersionID:   job.VersionID,
+					Path:        content.Path,
+					SHA:         streamed[content.Path],
+					Content:     content.Content,
+					ContentType: c.ContentType(),
+				})
+			}()
+			return nil
+		}
+
+		// pending holds files read before an ignore file above them
+		var pending []*github.FileContent
+		release := func() error {
+			held := pending
+			pending = nil
+			for _, content := range held {
+				if !ignore.Ready(content.Path) {
+					pending = append(pending, content)
+					continue
+				}
+				if err := dispatch(content); err != nil {
+					return err
+				}
+			}
+			return nil
+		}
+
+		include := func(path string, _ int64) bool {
+			_, ok := streamed[path]
+			return ok || ignore.Has(path)
+		}
+
+		err := gh.StreamArchive(ctx, user.AccessToken, job.Owner, job.RepoName, version.CommitSHA, include,
+			func(content *github.FileContent) error {
+				if ignore.Has(content.Path) {
+					ignore.Add(content.Path, string(content.Content))
+					if err := release(); err != nil {
+						return err
+					}
+				}
+				if _, ok := streamed[content.Path]; !ok {
+					return nil
+				}
+
+				seen[content.Path] = true
+				if !ignore.Ready(content.Path) {
+					pending = append(pending, content)
+					return nil
+				}
+				return dispatch(content)
 			})
-			if err != nil {
-				errOnce.Do(func() { firstErr = err })
-				return
+		if err == nil {
+			// Ignore files missing from the archive (e.g. symlinks) have no rules
+			missingIgnore = ignore.Finish()
+			err = release()
+		}
+		if err != nil {
+			wg.Wait()
+			return job, fmt.Errorf("stream archive: %w", err)
+		}
+	}
+
+	// With every ignore file read, settle what the tree's other files are:
+	// report the excluded ones, reuse the unchanged ones, and note the
+	// changed ones the archive lacked
+	for _, path := range missingIgnore {
+		report.Add(path, models.FileOutcomeSkipped, "", "missing from archive; its rules were not applied", entries[path].Size)
+	}
+	classifier := ignore.Classifier()
+	total = 0
+	for _, entry := range tree {
+		if entry.Type != "blob" {
+			continue
+		}
+
+		c := classifier.Classify(entry.Path, entry.Size)
+		if !c.Decision.Included() {
+			if !slices.Contains(missingIgnore, entry.Path) {
+				report.Add(entry.Path, models.FileOutcomeExcluded, c.Rule, c.Reason, entry.Size)
+			}
+			continue
+		}
+		total++
+
+		if _, ok := streamed[entry.Path]; ok {
+			if !seen[entry.Path] {
+				events.Ingest.Fetch.FileFailed.Emit(ctx, events.FetchFileEvent{
+					RepositoryID: job.RepositoryID,
+					VersionID:    job.VersionID,
+					FilePath:     entry.Path,
+					Reason:       "missing from archive",
+				})
+				report.Add(entry.Path, models.FileOutcomeSkipped, "", "missing from archive", entry.Size)
 			}
+			continue
+		}
+
+		select {
+		case inflight <- struct{}{}:
+		case <-ctx.Done():
+			wg.Wait()
+			return job, ctx.Err()
+		}
 
-			stored.Add(1)
-		}(content.Path, content.Content)
+		prev := previous[entry.Path]
+		reused++
+		wg.Add(1)
+		go func() {
+			defer func() { <-inflight }()
+			process(&fetchWork{
+				UserID:      job.UserID,
+				Owner:       job.Owner,
+				Repo:        job.RepoName,
+				Tag:         job.Tag,
+				Language:    string(c.Language),
+				JobID:       job.ID,
+				VersionID:   job.VersionID,
+				Path:        prev.Path,
+				SHA:         *prev.BlobSHA,
+				Previous:    prev,
+				KeepVectors: keepVectors,
+			})
+		}()
 	}
+	job.ItemsTotal = total
+	tracker.SetTotal(ctx, total)
 
 	wg.Wait()
 
-	if firstErr != nil {
-		return job, firstErr
+	// Tolerate a share of failed files rather than failing the whole ingest
+	failed, firstErr := report.Failed()
+	if err := fetchErrorBudget.Check(models.JobStageFetch, failed, job.ItemsTotal, firstErr); err != nil {
+		return job, err
 	}
 
-	job.ItemsProcessed = int(stored.Load())
+	tracker.Finish(ctx, int(stored.Load()))
 
 	events.Ingest.Fetch.Completed.Emit(ctx, events.FetchEvent{
 		RepositoryID: job.RepositoryID,
 		VersionID:    job.VersionID,
 		ByteCount:    int64(job.ItemsProcessed),
+		ReusedCount:  reused,
+		FailedCount:  failed,
 	})
 
 	return job, nil
@@ -14,6 +14,21 @@ import (
 	vickytest "github.com/zoobzio/vicky/testing"
 )
 
+// streamFiles returns an OnStreamArchive stub serving the given files.
+func streamFiles(files map[string]string) func(ctx context.Context, token, owner, repo, ref string, include func(string, int64) bool, fn func(*github.FileContent) error) error {
+	return func(ctx context.Context, token, owner, repo, ref string, include func(string, int64) bool, fn func(*github.FileContent) error) error {
+		for path, content := range files {
+			if !include(path, int64(len(content))) {
+				continue
+			}
+			if err := fn(&github.FileContent{Path: path, Content: []byte(content)}); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+}
+
 func TestFetchStage(t *testing.T) {
 	version := vickytest.NewVersion(t)
 
@@ -27,15 +42,17 @@ func TestFetchStage(t *testing.T) {
 				{Path: "src", Type: "tree"},                            // not a blob
 				{Path: "huge.go", Type: "blob", Size: 2 * 1024 * 1024}, // too large
 				{Path: "utils.go", Type: "blob", Size: 80},
+				{Path: "proto/user.proto", Type: "blob", Size: 60},
 			}, nil
 		},
-		OnGetFileContentBatch: func(ctx context.Context, token, owner, repo, ref string, paths []string) ([]*github.FileContent, error) {
-			result := make([]*github.FileContent, len(paths))
-			for i, p := range paths {
-				result[i] = &github.FileContent{Path: p, Content: []byte("package main")}
-			}
-			return result, nil
-		},
+		OnStreamArchive: streamFiles(map[string]string{
+			"main.go":          "package main",
+			"README.md":        "# Readme",
+			"vendor/lib.go":    "package lib",
+			"huge.go":          "package main",
+			"utils.go":         "package main",
+			"proto/user.proto": "message User {}",
+		}),
 	}
 	mc := &vickytest.MockIngestionConfigs{}
 	mv := &vickytest.MockVersions{
@@ -55,12 +72,26 @@ func TestFetchStage(t *testing.T) {
 		},
 	}
 
+	var docMu sync.Mutex
+	docTypes := make(map[string]models.ContentType)
+	md := &vickytest.MockDocuments{
+		OnSet: func(ctx context.Context, key string, doc *models.Document) error {
+			docMu.Lock()
+			defer docMu.Unlock()
+			docTypes[doc.Path] = doc.ContentType
+			return nil
+		},
+	}
+
 	ctx := vickytest.SetupRegistry(t,
+		vickytest.WithJobs(&vickytest.MockJobs{}),
+		vickytest.WithFileReports(&vickytest.MockFileReports{}),
 		vickytest.WithUsers(mu),
 		vickytest.WithGitHub(mg),
 		vickytest.WithIngestionConfigs(mc),
 		vickytest.WithVersions(mv),
 		vickytest.WithBlobs(mb),
+		vickytest.WithDocuments(md),
 	)
 
 	job := vickytest.NewJob(t)
@@ -73,18 +104,18 @@ func TestFetchStage(t *testing.T) {
 		t.Errorf("Stage = %q, want %q", result.Stage, models.JobStageFetch)
 	}
 
-	// main.go, README.md (docs included), utils.go should pass; vendor excluded, tree skipped, huge excluded
-	if result.ItemsTotal != 3 {
-		t.Errorf("ItemsTotal = %d, want 3", result.ItemsTotal)
+	// main.go, README.md (docs included), utils.go and the proto schema should pass; vendor excluded, tree skipped, huge excluded
+	if result.ItemsTotal != 4 {
+		t.Errorf("ItemsTotal = %d, want 4", result.ItemsTotal)
 	}
-	if result.ItemsProcessed != 3 {
-		t.Errorf("ItemsProcessed = %d, want 3", result.ItemsProcessed)
+	if result.ItemsProcessed != 4 {
+		t.Errorf("ItemsProcessed = %d, want 4", result.ItemsProcessed)
 	}
 
 	// Verify stored paths
 	putMu.Lock()
 	defer putMu.Unlock()
-	wantPaths := map[string]bool{"main.go": true, "README.md": true, "utils.go": true}
+	wantPaths := map[string]bool{"main.go": true, "README.md": true, "utils.go": true, "proto/user.proto": true}
 	for _, p := range putPaths {
 		if !wantPaths[p] {
 			t.Errorf("unexpected blob stored: %s", p)
@@ -94,6 +125,22 @@ func TestFetchStage(t *testing.T) {
 	for p := range wantPaths {
 		t.Errorf("expected blob not stored: %s", p)
 	}
+
+	// Every stored file gets a document, docs included
+	docMu.Lock()
+	defer docMu.Unlock()
+	if len(docTypes) != 4 {
+		t.Errorf("documents created = %d, want 4", len(docTypes))
+	}
+	if docTypes["README.md"] != models.ContentTypeDocs {
+		t.Errorf("README.md content type = %q, want %q", docTypes["README.md"], models.ContentTypeDocs)
+	}
+	if docTypes["main.go"] != models.ContentTypeCode {
+		t.Errorf("main.go content type = %q, want %q", docTypes["main.go"], models.ContentTypeCode)
+	}
+	if docTypes["proto/user.proto"] != models.ContentTypeSchema {
+		t.Errorf("proto/user.proto content type = %q, want %q", docTypes["proto/user.proto"], models.ContentTypeSchema)
+	}
 }
 
 func TestFetchStage_EmptyTree(t *testing.T) {
@@ -111,11 +158,14 @@ func TestFetchStage_EmptyTree(t *testing.T) {
 	}
 
 	ctx := vickytest.SetupRegistry(t,
+		vickytest.WithJobs(&vickytest.MockJobs{}),
+		vickytest.WithFileReports(&vickytest.MockFileReports{}),
 		vickytest.WithUsers(&vickytest.MockUsers{}),
 		vickytest.WithGitHub(mg),
 		vickytest.WithIngestionConfigs(&vickytest.MockIngestionConfigs{}),
 		vickytest.WithVersions(mv),
 		vickytest.WithBlobs(&vickytest.MockBlobs{}),
+		vickytest.WithDocuments(&vickytest.MockDocuments{}),
 	)
 
 	job := vickytest.NewJob(t)
@@ -136,11 +186,14 @@ func TestFetchStage_GetUserError(t *testing.T) {
 	}
 
 	ctx := vickytest.SetupRegistry(t,
+		vickytest.WithJobs(&vickytest.MockJobs{}),
+		vickytest.WithFileReports(&vickytest.MockFileReports{}),
 		vickytest.WithUsers(mu),
 		vickytest.WithGitHub(&vickytest.MockGitHub{}),
 		vickytest.WithIngestionConfigs(&vickytest.MockIngestionConfigs{}),
 		vickytest.WithVersions(&vickytest.MockVersions{}),
 		vickytest.WithBlobs(&vickytest.MockBlobs{}),
+		vickytest.WithDocuments(&vickytest.MockDocuments{}),
 	)
 
 	job := vickytest.NewJob(t)
@@ -162,9 +215,7 @@ func TestFetchStage_BlobStorageError(t *testing.T) {
 				{Path: "main.go", Type: "blob", Size: 100},
 			}, nil
 		},
-		OnGetFileContentBatch: func(ctx context.Context, token, owner, repo, ref string, paths []string) ([]*github.FileContent, error) {
-			return []*github.FileContent{{Path: "main.go", Content: []byte("package main")}}, nil
-		},
+		OnStreamArchive: streamFiles(map[string]string{"main.go": "package main"}),
 	}
 	mv := &vickytest.MockVersions{
 		OnGet: func(ctx context.Context, key string) (*models.Version, error) {
@@ -178,11 +229,14 @@ func TestFetchStage_BlobStorageError(t *testing.T) {
 	}
 
 	ctx := vickytest.SetupRegistry(t,
+		vickytest.WithJobs(&vickytest.MockJobs{}),
+		vickytest.WithFileReports(&vickytest.MockFileReports{}),
 		vickytest.WithUsers(&vickytest.MockUsers{}),
 		vickytest.WithGitHub(mg),
 		vickytest.WithIngestionConfigs(&vickytest.MockIngestionConfigs{}),
 		vickytest.WithVersions(mv),
 		vickytest.WithBlobs(mb),
+		vickytest.WithDocuments(&vickytest.MockDocuments{}),
 	)
 
 	job := vickytest.NewJob(t)
@@ -194,3 +248,275 @@ func TestFetchStage_BlobStorageError(t *testing.T) {
 		t.Errorf("error = %q, want it to contain %q", err.Error(), "store blob")
 	}
 }
+
+func TestFetchStage_MissingFromArchive(t *testing.T) {
+	version := vickytest.NewVersion(t)
+
+	mg := &vickytest.MockGitHub{
+		OnGetTree: func(ctx context.Context, token, owner, repo, ref string) ([]github.TreeEntry, error) {
+			return []github.TreeEntry{
+				{Path: "main.go", Type: "blob", Size: 100},
+				{Path: "link.go", Type: "blob", Size: 10},
+			}, nil
+		},
+		OnStreamArchive: streamFiles(map[string]string{"main.go": "package main"}),
+	}
+	mv := &vickytest.MockVersions{
+		OnGet: func(ctx context.Context, key string) (*models.Version, error) {
+			return version, nil
+		},
+	}
+
+	ctx := vickytest.SetupRegistry(t,
+		vickytest.WithJobs(&vickytest.MockJobs{}),
+		vickytest.WithFileReports(&vickytest.MockFileReports{}),
+		vickytest.WithUsers(&vickytest.MockUsers{}),
+		vickytest.WithGitHub(mg),
+		vickytest.WithIngestionConfigs(&vickytest.MockIngestionConfigs{}),
+		vickytest.WithVersions(mv),
+		vickytest.WithBlobs(&vickytest.MockBlobs{}),
+		vickytest.WithDocuments(&vickytest.MockDocuments{}),
+	)
+
+	result, err := fetchStage(ctx, vickytest.NewJob(t))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if result.ItemsTotal != 2 {
+		t.Errorf("ItemsTotal = %d, want 2", result.ItemsTotal)
+	}
+	if result.ItemsProcessed != 1 {
+		t.Errorf("ItemsProcessed = %d, want 1", result.ItemsProcessed)
+	}
+}
+
+func TestFetchStage_Manifests(t *testing.T) {
+	version := vickytest.NewVersion(t)
+
+	files := map[string]string{
+		"main.go":           "package main",
+		"go.mod":            "module example.com/app",
+		"go.sum":            "",           // excluded by pattern, still a manifest
+		"vendor/lib/go.mod": "module lib", // in an excluded directory
+		"package.json":      "{}",         // not a Go manifest
+	}
+	mg := &vickytest.MockGitHub{
+		OnGetTree: func(ctx context.Context, token, owner, repo, ref string) ([]github.TreeEntry, error) {
+			var tree []github.TreeEntry
+			for path, content := range files {
+				tree = append(tree, github.TreeEntry{Path: path, Type: "blob", Size: int64(len(content))})
+			}
+			return tree, nil
+		},
+		OnStreamArchive: streamFiles(files),
+	}
+	mv := &vickytest.MockVersions{
+		OnGet: func(ctx context.Context, key string) (*models.Version, error) {
+			return version, nil
+		},
+	}
+
+	var mu sync.Mutex
+	blobs := make(map[string]bool)
+	documents := make(map[string]bool)
+	mb := &vickytest.MockBlobs{
+		OnPutBlob: func(ctx context.Context, userID int64, blob *models.Blob) error {
+			mu.Lock()
+			defer mu.Unlock()
+			blobs[blob.Path] = true
+			return nil
+		},
+	}
+	md := &vickytest.MockDocuments{
+		OnSet: func(ctx context.Context, key string, doc *models.Document) error {
+			mu.Lock()
+			defer mu.Unlock()
+			documents[doc.Path] = true
+			return nil
+		},
+	}
+
+	ctx := vickytest.SetupRegistry(t,
+		vickytest.WithJobs(&vickytest.MockJobs{}),
+		vickytest.WithFileReports(&vickytest.MockFileReports{}),
+		vickytest.WithUsers(&vickytest.MockUsers{}),
+		vickytest.WithGitHub(mg),
+		vickytest.WithIngestionConfigs(&vickytest.MockIngestionConfigs{}),
+		vickytest.WithVersions(mv),
+		vickytest.WithBlobs(mb),
+		vickytest.WithDocuments(md),
+	)
+
+	result, err := fetchStage(ctx, vickytest.NewJob(t))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if result.ItemsTotal != 1 {
+		t.Errorf("ItemsTotal = %d, want only main.go counted", result.ItemsTotal)
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+	for path, want := range map[string]bool{"main.go": true, "go.mod": true, "go.sum": true, "vendor/lib/go.mod": false, "package.json": false} {
+		if blobs[path] != want {
+			t.Errorf("%s stored = %v, want %v", path, blobs[path], want)
+		}
+	}
+	if len(documents) != 1 || !documents["main.go"] {
+		t.Errorf("documents = %v, want main.go only", documents)
+	}
+}
+
+func TestFetchStage_IgnoreFiles(t *testing.T) {
+	version := vickytest.NewVersion(t)
+
+	// Map order shuffles the archive, so files often arrive before the
+	// ignore files above them
+	files := map[string]string{
+		".gitignore":                "gen/\n",
+		"main.go":                   "package main",
+		"gen/types.go":              "package gen",
+		"web/.vickyignore":          "*.go\n",
+		"web/tools.go":              "package web",
+		"web/app.go":                "package web",
+		"node_modules/x/.gitignore": "!*.go\n",
+	}
+	mg := &vickytest.MockGitHub{
+		OnGetTree: func(ctx context.Context, token, owner, repo, ref string) ([]github.TreeEntry, error) {
+			var tree []github.TreeEntry
+			for path, content := range files {
+				tree = append(tree, github.TreeEntry{Path: path, Type: "blob", Size: int64(len(content))})
+			}
+			return tree, nil
+		},
+		OnGetFileContentBatch: func(ctx context.Context, token, owner, repo, ref string, paths []string) ([]*github.FileContent, error) {
+			t.Errorf("ignore files fetched one by one: %v", paths)
+			return nil, nil
+		},
+		OnStreamArchive: streamFiles(files),
+	}
+	mv := &vickytest.MockVersions{
+		OnGet: func(ctx context.Context, key string) (*models.Version, error) {
+			return version, nil
+		},
+	}
+	var mu sync.Mutex
+	var stored []string
+	mb := &vickytest.MockBlobs{
+		OnPutBlob: func(ctx context.Context, userID int64, blob *models.Blob) error {
+			mu.Lock()
+			defer mu.Unlock()
+			stored = append(stored, blob.Path)
+			return nil
+		},
+	}
+
+	ctx := vickytest.SetupRegistry(t,
+		vickytest.WithJobs(&vickytest.MockJobs{}),
+		vickytest.WithFileReports(&vickytest.MockFileReports{}),
+		vickytest.WithUsers(&vickytest.MockUsers{}),
+		vickytest.WithGitHub(mg),
+		vickytest.WithIngestionConfigs(&vickytest.MockIngestionConfigs{}),
+		vickytest.WithVersions(mv),
+		vickytest.WithBlobs(mb),
+		vickytest.WithDocuments(&vickytest.MockDocuments{}),
+	)
+
+	for range 10 {
+		stored = nil
+		job, err := fetchStage(ctx, vickytest.NewJob(t))
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+
+		mu.Lock()
+		if len(stored) != 1 || stored[0] != "main.go" {
+			t.Errorf("stored = %v, want only main.go", stored)
+		}
+		mu.Unlock()
+		if job.ItemsTotal != 1 {
+			t.Errorf("ItemsTotal = %d, want 1", job.ItemsTotal)
+		}
+	}
+}
+
+func TestFetchStage_IgnoreFileMissingFromArchive(t *testing.T) {
+	version := vickytest.NewVersion(t)
+
+	mg := &vickytest.MockGitHub{
+		OnGetTree: func(ctx context.Context, token, owner, repo, ref string) ([]github.TreeEntry, error) {
+			return []github.TreeEntry{
+				{Path: ".gitignore", Type: "blob", Size: 10},
+				{Path: "gen/types.go", Type: "blob", Size: 11},
+			}, nil
+		},
+		// A symlinked .gitignore is not a regular file in the archive
+		OnStreamArchive: streamFiles(map[string]string{"gen/types.go": "package gen"}),
+	}
+	mv := &vickytest.MockVersions{
+		OnGet: func(ctx context.Context, key string) (*models.Version, error) {
+			return version, nil
+		},
+	}
+	mr, saved := recordReports()
+
+	ctx := vickytest.SetupRegistry(t,
+		vickytest.WithJobs(&vickytest.MockJobs{}),
+		vickytest.WithFileReports(mr),
+		vickytest.WithUsers(&vickytest.MockUsers{}),
+		vickytest.WithGitHub(mg),
+		vickytest.WithIngestionConfigs(&vickytest.MockIngestionConfigs{}),
+		vickytest.WithVersions(mv),
+		vickytest.WithBlobs(&vickytest.MockBlobs{}),
+		vickytest.WithDocuments(&vickytest.MockDocuments{}),
+	)
+
+	if _, err := fetchStage(ctx, vickytest.NewJob(t)); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	entries := saved()
+	if e := entries[".gitignore"]; e == nil || e.Outcome != models.FileOutcomeSkipped {
+		t.Errorf(".gitignore report = %+v, want skipped", e)
+	}
+	if e := entries["gen/types.go"]; e == nil || e.Outcome != models.FileOutcomeIncluded {
+		t.Errorf("gen/types.go report = %+v, want included", e)
+	}
+}
+
+func TestFetchStage_ArchiveError(t *testing.T) {
+	version := vickytest.NewVersion(t)
+
+	mg := &vickytest.MockGitHub{
+		OnGetTree: func(ctx context.Context, token, owner, repo, ref string) ([]github.TreeEntry, error) {
+			return []github.TreeEntry{{Path: "main.go", Type: "blob", Size: 100}}, nil
+		},
+		OnStreamArchive: func(ctx context.Context, token, owner, repo, ref string, include func(string, int64) bool, fn func(*github.FileContent) error) error {
+			return fmt.Errorf("archive unavailable")
+		},
+	}
+	mv := &vickytest.MockVersions{
+		OnGet: func(ctx context.Context, key string) (*models.Version, error) {
+			return version, nil
+		},
+	}
+
+	ctx := vickytest.SetupRegistry(t,
+		vickytest.WithJobs(&vickytest.MockJobs{}),
+		vickytest.WithFileReports(&vickytest.MockFileReports{}),
+		vickytest.WithUsers(&vickytest.MockUsers{}),
+		vickytest.WithGitHub(mg),
+		vickytest.WithIngestionConfigs(&vickytest.MockIngestionConfigs{}),
+		vickytest.WithVersions(mv),
+		vickytest.WithBlobs(&vickytest.MockBlobs{}),
+		vickytest.WithDocuments(&vickytest.MockDocuments{}),
+	)
+
+	_, err := fetchStage(ctx, vickytest.NewJob(t))
+	if err == nil {
+		t.Fatal("expected error, got nil")
+	}
+	if !strings.Contains(err.Error(), "archive unavailable") {
+		t.Errorf("error = %q, want it to contain %q", err.Error(), "archive unavailable")
+	}
+}
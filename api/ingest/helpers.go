@@ -1,55 +1,18 @@
 package ingest
 
 import (
-	"path/filepath"
+	"crypto/sha256"
+	"encoding/hex"
 	"strconv"
-	"strings"
 
 	"github.com/zoobzio/vicky/models"
 )
 
-// Documentation extensions.
-var docExtensions = []string{".md", ".mdx", ".markdown"}
-
 // idToKey converts an int64 ID to a string key.
 func idToKey(id int64) string {
 	return strconv.FormatInt(id, 10)
 }
 
-// matchesAnyPattern checks if a path matches any of the given glob patterns.
-func matchesAnyPattern(path string, patterns []string) bool {
-	for _, pattern := range patterns {
-		// Handle ** prefix patterns
-		if strings.HasPrefix(pattern, "**") {
-			// Match pattern against any suffix
-			suffix := strings.TrimPrefix(pattern, "**")
-			suffix = strings.TrimPrefix(suffix, "/")
-			if matched, _ := filepath.Match(suffix, filepath.Base(path)); matched {
-				return true
-			}
-		}
-
-		// Handle directory patterns (e.g., "vendor/**")
-		if strings.HasSuffix(pattern, "/**") {
-			prefix := strings.TrimSuffix(pattern, "/**")
-			if strings.HasPrefix(path, prefix+"/") || path == prefix {
-				return true
-			}
-		}
-
-		// Standard glob match
-		if matched, _ := filepath.Match(pattern, path); matched {
-			return true
-		}
-
-		// Also try matching against base name for simple patterns
-		if matched, _ := filepath.Match(pattern, filepath.Base(path)); matched {
-			return true
-		}
-	}
-	return false
-}
-
 // containsExt checks if an extension is in the list.
 func containsExt(exts []string, ext string) bool {
 	for _, e := range exts {
@@ -60,11 +23,20 @@ func containsExt(exts []string, ext string) bool {
 	return false
 }
 
-// contentTypeForPath determines the content type based on file extension.
-func contentTypeForPath(path string) models.ContentType {
-	ext := strings.ToLower(filepath.Ext(path))
-	if containsExt(docExtensions, ext) {
-		return models.ContentTypeDocs
+// contentTypeForPath determines the content type of a file: code for a
+// language's extensions, then that of its content format, then code.
+func contentTypeForPath(configs []*models.IngestionConfig, path string) models.ContentType {
+	if languageForPath(configs, path) != "" {
+		return models.ContentTypeCode
+	}
+	if format := formatForPath(configs, path); format != "" {
+		return format.ContentType()
 	}
 	return models.ContentTypeCode
 }
+
+// contentHash derives a document's content hash from its path and version tag.
+func contentHash(path, tag string) string {
+	h := sha256.Sum256([]byte(path + ":" + tag))
+	return hex.EncodeToString(h[:])
+}
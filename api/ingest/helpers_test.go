@@ -3,9 +3,11 @@
 package ingest
 
 import (
+	"encoding/json"
 	"math"
 	"testing"
 
+	"github.com/zoobzio/vicky/internal/gitignore"
 	"github.com/zoobzio/vicky/models"
 )
 
@@ -35,40 +37,6 @@ func TestIdToKey(t *testing.T) {
 	}
 }
 
-func TestMatchesAnyPattern(t *testing.T) {
-	t.Parallel()
-
-	tests := []struct {
-		name     string
-		path     string
-		patterns []string
-		want     bool
-	}{
-		{"vendor dir", "vendor/pkg/foo.go", []string{"vendor/**"}, true},
-		{"no match vendor", "src/main.go", []string{"vendor/**"}, false},
-		{"minified js", "src/foo.min.js", []string{"*.min.js"}, true},
-		{"non-minified js", "src/app.js", []string{"*.min.js"}, false},
-		{"glob md root", "README.md", []string{"**/*.md"}, true},
-		{"glob md nested", "docs/guide.md", []string{"**/*.md"}, true},
-		{"empty patterns", "main.go", []string{}, false},
-		{"github dir", ".github/workflows/ci.yml", []string{".github/**"}, true},
-		{"node_modules", "node_modules/pkg/index.js", []string{"node_modules/**"}, true},
-		{"multiple patterns first", "vendor/x.go", []string{"vendor/**", "*.test.js"}, true},
-		{"multiple patterns second", "foo.test.js", []string{"vendor/**", "*.test.js"}, true},
-		{"multiple patterns none", "src/app.go", []string{"vendor/**", "*.test.js"}, false},
-	}
-
-	for _, tt := range tests {
-		t.Run(tt.name, func(t *testing.T) {
-			t.Parallel()
-			got := matchesAnyPattern(tt.path, tt.patterns)
-			if got != tt.want {
-				t.Errorf("matchesAnyPattern(%q, %v) = %v, want %v", tt.path, tt.patterns, got, tt.want)
-			}
-		})
-	}
-}
-
 func TestContainsExt(t *testing.T) {
 	t.Parallel()
 
@@ -111,15 +79,251 @@ func TestContentTypeForPath(t *testing.T) {
 		{"makefile", "Makefile", models.ContentTypeCode},
 		{"nested code", "src/pkg/handler.go", models.ContentTypeCode},
 		{"nested docs", "docs/api/overview.md", models.ContentTypeDocs},
+		{"restructuredtext", "docs/index.rst", models.ContentTypeDocs},
+		{"asciidoc", "guide.adoc", models.ContentTypeDocs},
+		{"text", "NOTES.txt", models.ContentTypeDocs},
+		{"proto", "proto/user.proto", models.ContentTypeSchema},
+		{"sql", "migrations/001_init.sql", models.ContentTypeSchema},
+		{"openapi", "api/openapi.yaml", models.ContentTypeSchema},
+		{"plain yaml", "deploy/values.yaml", models.ContentTypeCode},
+		{"configured format", "web/api.yaml", models.ContentTypeSchema},
+		{"code wins over a format", "web/main.go", models.ContentTypeCode},
 	}
 
+	configs := []*models.IngestionConfig{
+		{Language: models.LanguageGo},
+		{Language: models.LanguageTypeScript, Root: "web", ContentFormats: json.RawMessage(`{".yaml": "openapi", ".go": "text"}`)},
+	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
 			t.Parallel()
-			got := contentTypeForPath(tt.path)
+			got := contentTypeForPath(configs, tt.path)
 			if got != tt.want {
 				t.Errorf("contentTypeForPath(%q) = %q, want %q", tt.path, got, tt.want)
 			}
 		})
 	}
 }
+
+func TestClassifier(t *testing.T) {
+	t.Parallel()
+
+	config := &models.IngestionConfig{
+		Language:        models.LanguageGo,
+		IncludeDocs:     true,
+		MaxFileSize:     1000,
+		ExcludePatterns: []string{"gen/**"},
+	}
+	noDocs := *config
+	noDocs.IncludeDocs = false
+	python := *config
+	python.Language = models.LanguagePython
+	formats := *config
+	formats.ContentFormats = json.RawMessage(`{".yaml": "openapi", ".txt": ""}`)
+
+	tests := []struct {
+		name     string
+		config   *models.IngestionConfig
+		path     string
+		size     int64
+		decision models.FileDecision
+		rule     string
+	}{
+		{"code", config, "main.go", 10, models.FileDecisionCode, ""},
+		{"docs", config, "README.md", 10, models.FileDecisionDocs, ""},
+		{"default pattern", config, "vendor/x.go", 10, models.FileDecisionExcluded, "vendor/**"},
+		{"config pattern", config, "gen/x.go", 10, models.FileDecisionExcluded, "gen/**"},
+		{"too large wins over pattern", config, "gen/big.go", 2000, models.FileDecisionTooLarge, models.ExcludeRuleMaxFileSize},
+		{"unsupported extension", config, "logo.png", 10, models.FileDecisionUnsupported, models.ExcludeRuleExtension},
+		{"docs disabled", &noDocs, "README.md", 10, models.FileDecisionUnsupported, models.ExcludeRuleExtension},
+		{"python", &python, "pkg/app.py", 10, models.FileDecisionCode, ""},
+		{"python stub", &python, "pkg/app.pyi", 10, models.FileDecisionCode, ""},
+		{"python bytecode", &python, "pkg/__pycache__/app.cpython-312.pyc", 10, models.FileDecisionExcluded, "*.pyc"},
+		{"go file in python repo", &python, "main.go", 10, models.FileDecisionUnsupported, models.ExcludeRuleExtension},
+		{"restructuredtext", config, "docs/index.rst", 10, models.FileDecisionDocs, ""},
+		{"text", config, "docs/notes.txt", 10, models.FileDecisionDocs, ""},
+		{"proto", config, "proto/user.proto", 10, models.FileDecisionSchema, ""},
+		{"sql", config, "migrations/001_init.sql", 10, models.FileDecisionSchema, ""},
+		{"openapi", config, "api/openapi.yaml", 10, models.FileDecisionSchema, ""},
+		{"plain yaml", config, "deploy/values.yaml", 10, models.FileDecisionUnsupported, models.ExcludeRuleExtension},
+		{"schema disabled", &noDocs, "proto/user.proto", 10, models.FileDecisionUnsupported, models.ExcludeRuleExtension},
+		{"configured format", &formats, "deploy/values.yaml", 10, models.FileDecisionSchema, ""},
+		{"dropped format", &formats, "docs/notes.txt", 10, models.FileDecisionUnsupported, models.ExcludeRuleExtension},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			got := NewClassifier([]*models.IngestionConfig{tt.config}, nil).Classify(tt.path, tt.size)
+			if got.Decision != tt.decision || got.Rule != tt.rule {
+				t.Errorf("Classify(%q) = %s/%q, want %s/%q", tt.path, got.Decision, got.Rule, tt.decision, tt.rule)
+			}
+		})
+	}
+}
+
+func TestClassifier_Formats(t *testing.T) {
+	t.Parallel()
+
+	config := &models.IngestionConfig{Language: models.LanguageGo, IncludeDocs: true, MaxFileSize: 1000}
+	if got := NewClassifier([]*models.IngestionConfig{config}, nil).Classify("proto/user.proto", 10); got.Format != models.ContentFormatProto || got.ContentType() != models.ContentTypeSchema {
+		t.Errorf("Classify = %s/%s, want a proto schema", got.Format, got.ContentType())
+	}
+	if got := NewClassifier([]*models.IngestionConfig{config}, nil).Classify("main.go", 10); got.Format != "" || got.ContentType() != models.ContentTypeCode {
+		t.Errorf("Classify = %q/%s, want code without a format", got.Format, got.ContentType())
+	}
+
+	noDocs := *config
+	noDocs.IncludeDocs = false
+	if got := NewClassifier([]*models.IngestionConfig{&noDocs}, nil).Classify("docs/index.rst", 10); got.Reason != "rst files are disabled by include_docs" {
+		t.Errorf("Reason = %q, want include_docs named", got.Reason)
+	}
+}
+
+func TestClassifier_LanguageRoots(t *testing.T) {
+	t.Parallel()
+
+	configs := []*models.IngestionConfig{
+		{Language: models.LanguageGo, IncludeDocs: true, MaxFileSize: 1000},
+		{Language: models.LanguageTypeScript, Root: "web", MaxFileSize: 1000, ExcludePatterns: []string{"generated/**"}},
+	}
+
+	tests := []struct {
+		name     string
+		path     string
+		decision models.FileDecision
+		rule     string
+		language models.Language
+	}{
+		{"go at the repository root", "main.go", models.FileDecisionCode, "", models.LanguageGo},
+		{"typescript under its root", "web/src/app.ts", models.FileDecisionCode, "", models.LanguageTypeScript},
+		{"go under the typescript root", "web/tools/gen.go", models.FileDecisionCode, "", models.LanguageGo},
+		{"typescript outside its root", "scripts/build.ts", models.FileDecisionUnsupported, models.ExcludeRuleExtension, ""},
+		{"defaults relative to the root", "web/node_modules/lib/index.js", models.FileDecisionExcluded, "node_modules/**", ""},
+		{"patterns relative to the root", "web/generated/api.ts", models.FileDecisionExcluded, "generated/**", ""},
+		{"docs from the root that includes them", "web/README.md", models.FileDecisionDocs, "", ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			got := NewClassifier(configs, nil).Classify(tt.path, 10)
+			if got.Decision != tt.decision || got.Rule != tt.rule || got.Language != tt.language {
+				t.Errorf("Classify(%q) = %s/%q/%s, want %s/%q/%s",
+					tt.path, got.Decision, got.Rule, got.Language, tt.decision, tt.rule, tt.language)
+			}
+		})
+	}
+
+	// Without a config at the repository root, files outside every root are unsupported
+	got := NewClassifier(configs[1:], nil).Classify("main.go", 10)
+	if got.Decision != models.FileDecisionUnsupported || got.Rule != models.ExcludeRuleRoot {
+		t.Errorf("outside every root = %s/%q, want unsupported/%q", got.Decision, got.Rule, models.ExcludeRuleRoot)
+	}
+
+	// Root paths restrict the tree to their prefixes
+	payments := models.ProjectConfigs([]*models.IngestionConfig{
+		{Language: models.LanguageGo, MaxFileSize: 1000, RootPaths: []string{"services/payments"}},
+	})
+	if got := NewClassifier(payments, nil).Classify("services/payments/api.go", 10); got.Decision != models.FileDecisionCode {
+		t.Errorf("under a root path = %s, want code", got.Decision)
+	}
+	if got := NewClassifier(payments, nil).Classify("services/search/api.go", 10); got.Rule != models.ExcludeRuleRoot {
+		t.Errorf("outside the root paths = %s/%q, want rule %q", got.Decision, got.Rule, models.ExcludeRuleRoot)
+	}
+}
+
+func TestClassifier_Rules(t *testing.T) {
+	t.Parallel()
+
+	ignore := &gitignore.Matcher{}
+	ignore.AddFile(".gitignore", "*.gen.go\ntmp/\n")
+	ignore.AddFile("web/.vickyignore", "fixtures/\n")
+
+	configs := []*models.IngestionConfig{
+		{Language: models.LanguageGo, MaxFileSize: 1000, ExcludePatterns: []string{"**/testdata/**", "!api/keep.gen.go"}},
+		{Language: models.LanguageTypeScript, Root: "web", MaxFileSize: 1000, IncludePatterns: []string{"src/", "*.config.ts"}},
+	}
+	classifier := NewClassifier(configs, ignore)
+
+	tests := []struct {
+		name     string
+		path     string
+		decision models.FileDecision
+		rule     string
+		reason   string
+	}{
+		{"double star in the middle", "pkg/a/testdata/x.go", models.FileDecisionExcluded, "**/testdata/**", "matches exclude pattern"},
+		{"default pattern", "vendor/x.go", models.FileDecisionExcluded, "vendor/**", "matches default exclude pattern"},
+		{"gitignore", "api/types.gen.go", models.FileDecisionExcluded, "*.gen.go", "matches .gitignore line 1"},
+		{"config re-includes over gitignore", "api/keep.gen.go", models.FileDecisionCode, "", ""},
+		{"gitignore directory", "tmp/scratch.go", models.FileDecisionExcluded, "tmp/", "matches .gitignore line 2"},
+		{"vickyignore in a subdirectory", "web/src/fixtures/data.ts", models.FileDecisionExcluded, "fixtures/", "matches web/.vickyignore line 1"},
+		{"include allowlist", "web/src/app.ts", models.FileDecisionCode, "", ""},
+		{"include by name", "web/vite.config.ts", models.FileDecisionCode, "", ""},
+		{"outside the allowlist", "web/scripts/build.ts", models.FileDecisionExcluded, models.ExcludeRuleInclude, "matches no include pattern"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			got := classifier.Classify(tt.path, 10)
+			if got.Decision != tt.decision || got.Rule != tt.rule || got.Reason != tt.reason {
+				t.Errorf("Classify(%q) = %s/%q/%q, want %s/%q/%q",
+					tt.path, got.Decision, got.Rule, got.Reason, tt.decision, tt.rule, tt.reason)
+			}
+		})
+	}
+}
+
+func TestClassifier_Manifests(t *testing.T) {
+	t.Parallel()
+
+	ignore := &gitignore.Matcher{}
+	ignore.AddFile(".gitignore", "go.work\nthird_party/\n")
+
+	configs := []*models.IngestionConfig{
+		{Language: models.LanguageGo},
+		{Language: models.LanguageTypeScript, Root: "web", LanguageConfig: json.RawMessage(`{"tsconfig_path": "config/ts.json"}`)},
+	}
+	classifier := NewClassifier(configs, ignore)
+
+	for path, want := range map[string]bool{
+		"go.mod":                          true,
+		"go.sum":                          true, // excluded by name only
+		"go.work":                         true, // ignored by name only
+		"vendor/x/go.mod":                 false,
+		"third_party/lib/go.mod":          false,
+		"web/package.json":                true,
+		"web/node_modules/x/package.json": false,
+		"web/config/ts.json":              true,
+		"package.json":                    false, // no TypeScript root here
+	} {
+		if got := classifier.isManifest(path); got != want {
+			t.Errorf("isManifest(%q) = %v, want %v", path, got, want)
+		}
+	}
+}
+
+func TestLanguageForPath(t *testing.T) {
+	t.Parallel()
+
+	configs := []*models.IngestionConfig{
+		{Language: models.LanguageGo},
+		{Language: models.LanguagePython, Root: "ml"},
+		{Language: models.LanguageTypeScript, Root: "web"},
+	}
+
+	tests := map[string]models.Language{
+		"cmd/main.go":     models.LanguageGo,
+		"ml/train.py":     models.LanguagePython,
+		"web/src/app.tsx": models.LanguageTypeScript,
+		"tools/lint.py":   models.LanguagePython, // claimed by no root, chosen by extension
+		"README.md":       "",
+	}
+	for path, want := range tests {
+		if got := languageForPath(configs, path); got != want {
+			t.Errorf("languageForPath(%q) = %q, want %q", path, got, want)
+		}
+	}
+}
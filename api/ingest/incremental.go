@@ -0,0 +1,111 @@
+package ingest
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/zoobzio/sum"
+	"github.com/zoobzio/vicky/api/contracts"
+	"github.com/zoobzio/vicky/models"
+)
+
+// previousDocuments returns the repository's most recent ready version and
+// its documents, keyed by path. Only documents with a recorded blob SHA are
+// included, since nothing else can be compared against the new tree.
+// Returns nils when there is no earlier ready version.
+func previousDocuments(ctx context.Context, job *models.Job) (*models.Version, map[string]*models.Document, error) {
+	versions := sum.MustUse[contracts.Versions](ctx)
+
+	all, err := versions.ListByUserAndRepo(ctx, job.UserID, job.Owner, job.RepoName)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	var prev *models.Version
+	for _, v := range all {
+		if v.ID == job.VersionID || v.Status != models.VersionStatusReady {
+			continue
+		}
+		if prev == nil || v.CreatedAt.After(prev.CreatedAt) {
+			prev = v
+		}
+	}
+	if prev == nil {
+		return nil, nil, nil
+	}
+
+	documents := sum.MustUse[contracts.Documents](ctx)
+
+	docs, err := documents.ListByUserRepoAndTag(ctx, job.UserID, job.Owner, job.RepoName, prev.Tag)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	byPath := make(map[string]*models.Document, len(docs))
+	for _, d := range docs {
+		if d.BlobSHA != nil {
+			byPath[d.Path] = d
+		}
+	}
+	return prev, byPath, nil
+}
+
+// reuseFile copies an unchanged file into the job's version: the blob (the
+// indexer still needs the full tree), the document row, and its chunks with
+// their vectors, so the chunk and embed stages can skip it. Without
+// KeepVectors the vectors are dropped and only the chunk stage is skipped.
+func reuseFile(ctx context.Context, w *fetchWork) (*fetchWork, error) {
+	blobs := sum.MustUse[contracts.Blobs](ctx)
+	documents := sum.MustUse[contracts.Documents](ctx)
+	chunks := sum.MustUse[contracts.Chunks](ctx)
+
+	prev := w.Previous
+	now := time.Now()
+
+	obj, err := blobs.GetByPath(ctx, w.UserID, w.Owner, w.Repo, prev.Tag, prev.Path)
+	if err != nil {
+		return w, fmt.Errorf("reuse blob %s: %w", w.Path, err)
+	}
+
+	blob := obj.Data
+	blob.Tag = w.Tag
+	blob.SHA = w.SHA
+	if err := blobs.PutBlob(ctx, w.UserID, &blob); err != nil {
+		return w, fmt.Errorf("store blob %s: %w", w.Path, err)
+	}
+
+	doc := prev.Clone()
+	doc.ID = 0
+	doc.VersionID = w.VersionID
+	doc.Tag = w.Tag
+	doc.ContentHash = contentHash(doc.Path, w.Tag)
+	doc.CreatedAt = now
+	if !w.KeepVectors {
+		doc.Vector = nil
+	}
+	if err := documents.Set(ctx, "", &doc); err != nil {
+		return w, fmt.Errorf("reuse document %s: %w", w.Path, err)
+	}
+
+	prevChunks, err := chunks.ListByUserRepoTagAndPath(ctx, w.UserID, w.Owner, w.Repo, prev.Tag, prev.Path)
+	if err != nil {
+		return w, fmt.Errorf("list chunks %s: %w", w.Path, err)
+	}
+
+	for _, pc := range prevChunks {
+		chunk := pc.Clone()
+		chunk.ID = 0
+		chunk.DocumentID = doc.ID
+		chunk.Tag = w.Tag
+		chunk.CreatedAt = now
+		if !w.KeepVectors {
+			chunk.Vector = nil
+		}
+		if err := chunks.Set(ctx, "", &chunk); err != nil {
+			return w, fmt.Errorf("reuse chunk in %s: %w", w.Path, err)
+		}
+	}
+
+	return w, nil
+}
@@ -0,0 +1,330 @@
+//go:build testing
+
+package ingest
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/zoobzio/grub"
+	"github.com/zoobzio/vicky/external/github"
+	"github.com/zoobzio/vicky/models"
+	vickytest "github.com/zoobzio/vicky/testing"
+)
+
+func strPtr(s string) *string { return &s }
+
+func TestPreviousDocuments(t *testing.T) {
+	now := time.Now()
+	mv := &vickytest.MockVersions{
+		OnListByUserAndRepo: func(ctx context.Context, userID int64, owner, repoName string) ([]*models.Version, error) {
+			return []*models.Version{
+				{ID: 1, Tag: "v0.8.0", Status: models.VersionStatusReady, CreatedAt: now.Add(-2 * time.Hour)},
+				{ID: 2, Tag: "v0.9.0", Status: models.VersionStatusReady, CreatedAt: now.Add(-time.Hour)},
+				{ID: 3, Tag: "v0.9.1", Status: models.VersionStatusFailed, CreatedAt: now.Add(-time.Minute)},
+				{ID: 10, Tag: "v1.0.0", Status: models.VersionStatusIngesting, CreatedAt: now},
+			}, nil
+		},
+	}
+
+	var listedTag string
+	md := &vickytest.MockDocuments{
+		OnListByUserRepoAndTag: func(ctx context.Context, userID int64, owner, repoName, tag string) ([]*models.Document, error) {
+			listedTag = tag
+			withSHA := vickytest.NewDocument(t, 1, "main.go")
+			withSHA.BlobSHA = strPtr("sha-main")
+			return []*models.Document{withSHA, vickytest.NewDocument(t, 2, "legacy.go")}, nil
+		},
+	}
+
+	ctx := vickytest.SetupRegistry(t, vickytest.WithVersions(mv), vickytest.WithDocuments(md))
+
+	prev, docs, err := previousDocuments(ctx, vickytest.NewJob(t))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if prev == nil || prev.ID != 2 {
+		t.Errorf("previous version = %v, want 2", prev)
+	}
+	if listedTag != "v0.9.0" {
+		t.Errorf("listed documents for tag %q, want v0.9.0", listedTag)
+	}
+	if len(docs) != 1 || docs["main.go"] == nil {
+		t.Errorf("docs = %v, want only main.go", docs)
+	}
+}
+
+func TestPreviousDocuments_NoReadyVersion(t *testing.T) {
+	mv := &vickytest.MockVersions{
+		OnListByUserAndRepo: func(ctx context.Context, userID int64, owner, repoName string) ([]*models.Version, error) {
+			return []*models.Version{{ID: 10, Status: models.VersionStatusIngesting}}, nil
+		},
+	}
+
+	ctx := vickytest.SetupRegistry(t, vickytest.WithVersions(mv))
+
+	prev, docs, err := previousDocuments(ctx, vickytest.NewJob(t))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if prev != nil || docs != nil {
+		t.Errorf("docs = %v, want nil", docs)
+	}
+}
+
+func TestReuseFile(t *testing.T) {
+	prev := vickytest.NewDocument(t, 7, "main.go")
+	prev.Tag = "v0.9.0"
+	prev.VersionID = 9
+	prev.BlobSHA = strPtr("sha-main")
+
+	var stored *models.Blob
+	mb := &vickytest.MockBlobs{
+		OnGetByPath: func(ctx context.Context, userID int64, owner, repo, tag, path string) (*grub.Object[models.Blob], error) {
+			if tag != "v0.9.0" {
+				t.Errorf("GetByPath tag = %q, want v0.9.0", tag)
+			}
+			return &grub.Object[models.Blob]{
+				Data: models.Blob{Path: path, Content: "package main", Owner: owner, Repo: repo, Tag: tag},
+			}, nil
+		},
+		OnPutBlob: func(ctx context.Context, userID int64, blob *models.Blob) error {
+			stored = blob
+			return nil
+		},
+	}
+
+	var doc *models.Document
+	md := &vickytest.MockDocuments{
+		OnSet: func(ctx context.Context, key string, d *models.Document) error {
+			d.ID = 70
+			doc = d
+			return nil
+		},
+	}
+
+	var copied []*models.Chunk
+	mc := &vickytest.MockChunks{
+		OnListByUserRepoTagAndPath: func(ctx context.Context, userID int64, owner, repoName, tag, path string) ([]*models.Chunk, error) {
+			c := vickytest.NewChunk(t, 5, "func main() {}")
+			c.Tag = tag
+			c.DocumentID = 7
+			c.Vector = []float32{0.1, 0.2}
+			return []*models.Chunk{c}, nil
+		},
+		OnSet: func(ctx context.Context, key string, chunk *models.Chunk) error {
+			copied = append(copied, chunk)
+			return nil
+		},
+	}
+
+	ctx := vickytest.SetupRegistry(t,
+		vickytest.WithJobs(&vickytest.MockJobs{}),
+		vickytest.WithFileReports(&vickytest.MockFileReports{}),
+		vickytest.WithBlobs(mb),
+		vickytest.WithDocuments(md),
+		vickytest.WithChunks(mc),
+	)
+
+	_, err := reuseFile(ctx, &fetchWork{
+		UserID:      1000,
+		Owner:       "testorg",
+		Repo:        "testrepo",
+		Tag:         "v1.0.0",
+		VersionID:   10,
+		Path:        "main.go",
+		SHA:         "sha-main",
+		Previous:    prev,
+		KeepVectors: true,
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if stored == nil || stored.Tag != "v1.0.0" || stored.SHA != "sha-main" {
+		t.Errorf("stored blob = %+v, want tag v1.0.0 with sha", stored)
+	}
+	if doc == nil || doc.VersionID != 10 || doc.Tag != "v1.0.0" {
+		t.Fatalf("document = %+v, want version 10 tag v1.0.0", doc)
+	}
+	if prev.Tag != "v0.9.0" {
+		t.Error("previous document was modified")
+	}
+	if len(copied) != 1 {
+		t.Fatalf("copied %d chunks, want 1", len(copied))
+	}
+	if copied[0].ID != 0 || copied[0].DocumentID != 70 || copied[0].Tag != "v1.0.0" {
+		t.Errorf("copied chunk = %+v, want new row under document 70", copied[0])
+	}
+	if len(copied[0].Vector) != 2 {
+		t.Error("copied chunk lost its vector")
+	}
+}
+
+func TestFetchStage_ReusesUnchangedFiles(t *testing.T) {
+	version := vickytest.NewVersion(t)
+
+	mg := &vickytest.MockGitHub{
+		OnGetTree: func(ctx context.Context, token, owner, repo, ref string) ([]github.TreeEntry, error) {
+			return []github.TreeEntry{
+				{Path: "main.go", Type: "blob", Size: 100, SHA: "sha-main"},
+				{Path: "utils.go", Type: "blob", Size: 80, SHA: "sha-utils-new"},
+			}, nil
+		},
+		OnStreamArchive: func(ctx context.Context, token, owner, repo, ref string, include func(string, int64) bool, fn func(*github.FileContent) error) error {
+			if include("main.go", 12) {
+				t.Error("unchanged main.go should not be read from the archive")
+			}
+			return streamFiles(map[string]string{"utils.go": "package main"})(ctx, token, owner, repo, ref, include, fn)
+		},
+	}
+	mv := &vickytest.MockVersions{
+		OnGet: func(ctx context.Context, key string) (*models.Version, error) {
+			return version, nil
+		},
+		OnListByUserAndRepo: func(ctx context.Context, userID int64, owner, repoName string) ([]*models.Version, error) {
+			return []*models.Version{{ID: 9, Tag: "v0.9.0", Status: models.VersionStatusReady}}, nil
+		},
+	}
+	md := &vickytest.MockDocuments{
+		OnListByUserRepoAndTag: func(ctx context.Context, userID int64, owner, repoName, tag string) ([]*models.Document, error) {
+			m := vickytest.NewDocument(t, 1, "main.go")
+			m.BlobSHA = strPtr("sha-main")
+			u := vickytest.NewDocument(t, 2, "utils.go")
+			u.BlobSHA = strPtr("sha-utils-old")
+			return []*models.Document{m, u}, nil
+		},
+	}
+
+	var mu sync.Mutex
+	stored := make(map[string]string)
+	mb := &vickytest.MockBlobs{
+		OnGetByPath: func(ctx context.Context, userID int64, owner, repo, tag, path string) (*grub.Object[models.Blob], error) {
+			return &grub.Object[models.Blob]{Data: models.Blob{Path: path, Tag: tag}}, nil
+		},
+		OnPutBlob: func(ctx context.Context, userID int64, blob *models.Blob) error {
+			mu.Lock()
+			defer mu.Unlock()
+			stored[blob.Path] = blob.SHA
+			return nil
+		},
+	}
+
+	ctx := vickytest.SetupRegistry(t,
+		vickytest.WithJobs(&vickytest.MockJobs{}),
+		vickytest.WithFileReports(&vickytest.MockFileReports{}),
+		vickytest.WithUsers(&vickytest.MockUsers{}),
+		vickytest.WithGitHub(mg),
+		vickytest.WithIngestionConfigs(&vickytest.MockIngestionConfigs{}),
+		vickytest.WithVersions(mv),
+		vickytest.WithDocuments(md),
+		vickytest.WithChunks(&vickytest.MockChunks{}),
+		vickytest.WithBlobs(mb),
+	)
+
+	result, err := fetchStage(ctx, vickytest.NewJob(t))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if result.ItemsTotal != 2 || result.ItemsProcessed != 2 {
+		t.Errorf("items = %d/%d, want 2/2", result.ItemsProcessed, result.ItemsTotal)
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+	if stored["main.go"] != "sha-main" {
+		t.Errorf("main.go blob sha = %q, want sha-main", stored["main.go"])
+	}
+	if stored["utils.go"] != "sha-utils-new" {
+		t.Errorf("utils.go blob sha = %q, want sha-utils-new", stored["utils.go"])
+	}
+}
+
+func TestFetchStage_DropsVectorsForNewStrategy(t *testing.T) {
+	SetEmbeddingStrategy("path", map[models.ContentType]string{models.ContentTypeCode: "{{.Path}}\n{{.Content}}"})
+	t.Cleanup(func() { embeddingStrategy.Store(models.DefaultEmbeddingStrategy()) })
+
+	mg := &vickytest.MockGitHub{
+		OnGetTree: func(ctx context.Context, token, owner, repo, ref string) ([]github.TreeEntry, error) {
+			return []github.TreeEntry{{Path: "main.go", Type: "blob", Size: 100, SHA: "sha-main"}}, nil
+		},
+		OnStreamArchive: streamFiles(nil),
+	}
+
+	var recorded *models.EmbeddingStrategy
+	mv := &vickytest.MockVersions{
+		OnGet: func(ctx context.Context, key string) (*models.Version, error) {
+			return vickytest.NewVersion(t), nil
+		},
+		OnListByUserAndRepo: func(ctx context.Context, userID int64, owner, repoName string) ([]*models.Version, error) {
+			// Recorded before strategies existed, so embedded raw
+			return []*models.Version{{ID: 9, Tag: "v0.9.0", Status: models.VersionStatusReady}}, nil
+		},
+		OnUpdateEmbeddingStrategy: func(ctx context.Context, id int64, strategy *models.EmbeddingStrategy) error {
+			recorded = strategy
+			return nil
+		},
+	}
+	md := &vickytest.MockDocuments{
+		OnListByUserRepoAndTag: func(ctx context.Context, userID int64, owner, repoName, tag string) ([]*models.Document, error) {
+			m := vickytest.NewDocument(t, 1, "main.go")
+			m.BlobSHA = strPtr("sha-main")
+			m.Vector = []float32{0.5}
+			return []*models.Document{m}, nil
+		},
+	}
+
+	var mu sync.Mutex
+	var copied []*models.Chunk
+	mc := &vickytest.MockChunks{
+		OnListByUserRepoTagAndPath: func(ctx context.Context, userID int64, owner, repoName, tag, path string) ([]*models.Chunk, error) {
+			c := vickytest.NewChunk(t, 5, "func main() {}")
+			c.Vector = []float32{0.1, 0.2}
+			return []*models.Chunk{c}, nil
+		},
+		OnSet: func(ctx context.Context, key string, chunk *models.Chunk) error {
+			mu.Lock()
+			defer mu.Unlock()
+			copied = append(copied, chunk)
+			return nil
+		},
+	}
+	mb := &vickytest.MockBlobs{
+		OnGetByPath: func(ctx context.Context, userID int64, owner, repo, tag, path string) (*grub.Object[models.Blob], error) {
+			return &grub.Object[models.Blob]{Data: models.Blob{Path: path, Tag: tag}}, nil
+		},
+	}
+
+	ctx := vickytest.SetupRegistry(t,
+		vickytest.WithJobs(&vickytest.MockJobs{}),
+		vickytest.WithFileReports(&vickytest.MockFileReports{}),
+		vickytest.WithUsers(&vickytest.MockUsers{}),
+		vickytest.WithGitHub(mg),
+		vickytest.WithIngestionConfigs(&vickytest.MockIngestionConfigs{}),
+		vickytest.WithVersions(mv),
+		vickytest.WithDocuments(md),
+		vickytest.WithChunks(mc),
+		vickytest.WithBlobs(mb),
+	)
+
+	if _, err := fetchStage(ctx, vickytest.NewJob(t)); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if recorded == nil || recorded.Name != "path" {
+		t.Errorf("recorded strategy = %+v, want path", recorded)
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+	if len(copied) != 1 {
+		t.Fatalf("copied %d chunks, want 1", len(copied))
+	}
+	if copied[0].Vector != nil {
+		t.Error("chunk embedded with the raw strategy kept its vector")
+	}
+}
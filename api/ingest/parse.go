@@ -2,14 +2,15 @@ package ingest
 
 import (
 	"context"
-	"crypto/sha256"
-	"encoding/hex"
+	"errors"
 	"fmt"
+	"io"
 	"sync"
 	"time"
 
 	"github.com/sourcegraph/scip/bindings/go/scip"
 	"github.com/zoobzio/capitan"
+	"github.com/zoobzio/grub"
 	"github.com/zoobzio/pipz"
 	"github.com/zoobzio/sum"
 	"github.com/zoobzio/vicky/api/contracts"
@@ -71,11 +72,10 @@ func (w *parseWork) Clone() *parseWork {
 	return &c
 }
 
-// processParseDoc processes a single SCIP document's symbols and occurrences.
+// processParseDoc writes a single SCIP document's symbols, relationships,
+// and occurrences in one bulk transaction.
 func processParseDoc(ctx context.Context, w *parseWork) (*parseWork, error) {
 	scipSymbols := sum.MustUse[contracts.SCIPSymbols](ctx)
-	scipOccurrences := sum.MustUse[contracts.SCIPOccurrences](ctx)
-	scipRelationships := sum.MustUse[contracts.SCIPRelationships](ctx)
 
 	meta := vickyScip.FileMeta{
 		DocumentMeta: vickyScip.DocumentMeta{
@@ -88,41 +88,36 @@ func processParseDoc(ctx context.Context, w *parseWork) (*parseWork, error) {
 		Path:       w.Document.RelativePath,
 	}
 
-	// Process symbols and their relationships
-	for _, sym := range w.Document.Symbols {
+	batch := &models.SCIPBatch{
+		Symbols:       make([]*models.SCIPSymbol, len(w.Document.Symbols)),
+		Relationships: make([][]*models.SCIPRelationship, len(w.Document.Symbols)),
+		Occurrences:   make([]*models.SCIPOccurrence, len(w.Document.Occurrences)),
+	}
+
+	// Convert symbols and their relationships
+	for i, sym := range w.Document.Symbols {
 		symbol := vickyScip.ConvertSymbol(sym, meta)
-		if err := scipSymbols.Set(ctx, "", &symbol); err != nil {
-			capitan.Error(ctx, events.ParseSymbolErrorSignal,
-				events.JobIDKey.Field(w.JobID),
-				events.SymbolKey.Field(sym.Symbol),
-				events.ErrorKey.Field(err),
-			)
-			continue
-		}
+		batch.Symbols[i] = &symbol
 
 		for _, rel := range sym.Relationships {
 			relationship := vickyScip.ConvertRelationship(rel)
-			relationship.SCIPSymbolID = symbol.ID
-			if err := scipRelationships.Set(ctx, "", &relationship); err != nil {
-				capitan.Error(ctx, events.ParseRelationshipErrorSignal,
-					events.JobIDKey.Field(w.JobID),
-					events.ErrorKey.Field(err),
-				)
-				continue
-			}
+			batch.Relationships[i] = append(batch.Relationships[i], &relationship)
 		}
 	}
 
-	// Process occurrences
-	for _, occ := range w.Document.Occurrences {
+	// Convert occurrences
+	for i, occ := range w.Document.Occurrences {
 		occurrence := vickyScip.ConvertOccurrence(occ, meta)
-		if err := scipOccurrences.Set(ctx, "", &occurrence); err != nil {
-			capitan.Error(ctx, events.ParseOccurrenceErrorSignal,
-				events.JobIDKey.Field(w.JobID),
-				events.ErrorKey.Field(err),
-			)
-			continue
-		}
+		batch.Occurrences[i] = &occurrence
+	}
+
+	if err := scipSymbols.InsertBatch(ctx, batch); err != nil {
+		capitan.Error(ctx, events.ParseStoreErrorSignal,
+			events.JobIDKey.Field(w.JobID),
+			events.PathKey.Field(w.Document.RelativePath),
+			events.ErrorKey.Field(err),
+		)
+		return w, fmt.Errorf("store scip data %s: %w", w.Document.RelativePath, err)
 	}
 
 	return w, nil
@@ -140,35 +135,257 @@ func parseStage(ctx context.Context, job *models.Job) (*models.Job, error) {
 	job.Stage = models.JobStageParse
 
 	// Resolve dependencies
+	documents := sum.MustUse[contracts.Documents](ctx)
+	scipSymbols := sum.MustUse[contracts.SCIPSymbols](ctx)
+	scipOccurrences := sum.MustUse[contracts.SCIPOccurrences](ctx)
+	symbols := sum.MustUse[contracts.Symbols](ctx)
+
+	// Uploads and rebuilds read the version's stored index instead of
+	// running the indexer
+	var index io.ReadSeekCloser
+	var err error
+	if job.Kind.StoredIndex() {
+		index, err = openStoredIndex(ctx, job)
+	} else {
+		index, err = runIndexer(ctx, job)
+	}
+	if err != nil {
+		return job, err
+	}
+	if index == nil {
+		return job, nil
+	}
+	defer func() { _ = index.Close() }()
+
+	// Count documents up front for progress; the index is read twice rather
+	// than held in memory
+	parser := vickyScip.New()
+	total, err := parser.CountDocuments(index)
+	if err != nil {
+		return job, fmt.Errorf("parse scip index: %w", err)
+	}
+	if _, err := index.Seek(0, io.SeekStart); err != nil {
+		return job, fmt.Errorf("rewind scip index: %w", err)
+	}
+
+	// Clear SCIP data and symbols from an earlier attempt so a rerun doesn't duplicate them
+	if err := scipSymbols.DeleteByUserRepoAndTag(ctx, job.UserID, job.Owner, job.RepoName, job.Tag); err != nil {
+		return job, fmt.Errorf("clear scip symbols: %w", err)
+	}
+	if err := scipOccurrences.DeleteByUserRepoAndTag(ctx, job.UserID, job.Owner, job.RepoName, job.Tag); err != nil {
+		return job, fmt.Errorf("clear scip occurrences: %w", err)
+	}
+	if err := symbols.DeleteByUserRepoAndTag(ctx, job.UserID, job.Owner, job.RepoName, job.Tag); err != nil {
+		return job, fmt.Errorf("clear symbols: %w", err)
+	}
+
+	// Fetched and reused files already have documents
+	docTag, err := documentTag(ctx, job)
+	if err != nil {
+		return job, err
+	}
+	existing, err := documents.ListByUserRepoAndTag(ctx, job.UserID, job.Owner, job.RepoName, docTag)
+	if err != nil {
+		return job, fmt.Errorf("list documents: %w", err)
+	}
+
+	docIDs := make(map[string]int64, len(existing))
+	for _, d := range existing {
+		docIDs[d.Path] = d.ID
+	}
+
+	tracker := trackProgress(ctx, job, total)
+
+	report := newFileReport(job, models.JobStageParse)
+	defer report.Save(ctx)
+
+	writer := newSymbolWriter(job)
+
+	// Decode one document at a time and hand each to the long-lived pool.
+	// In-flight documents are capped at the pool's size so decoding never
+	// runs far ahead of the database writes.
+	var wg sync.WaitGroup
+	inFlight := make(chan struct{}, parsePool.GetWorkerCount())
+	processed := 0
+
+	err = parser.ParseStream(ctx, index, func(ctx context.Context, doc *scip.Document) error {
+		// Document rows are created sequentially as documents are decoded
+		docID, ok := docIDs[doc.RelativePath]
+		if !ok {
+			d := &models.Document{
+				VersionID:   job.VersionID,
+				UserID:      job.UserID,
+				Owner:       job.Owner,
+				RepoName:    job.RepoName,
+				Tag:         docTag,
+				Path:        doc.RelativePath,
+				ContentType: contentTypeForPath(nil, doc.RelativePath),
+				ContentHash: contentHash(doc.RelativePath, docTag),
+			}
+
+			if err := documents.Set(ctx, "", d); err != nil {
+				return fmt.Errorf("create document %s: %w", doc.RelativePath, err)
+			}
+
+			docID = d.ID
+			docIDs[doc.RelativePath] = docID
+		}
+		processed++
+
+		// Derive searchable symbols while the document is decoded
+		if err := writer.Add(ctx, doc); err != nil {
+			return err
+		}
+
+		select {
+		case inFlight <- struct{}{}:
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+
+		wg.Add(1)
+		go func(d *scip.Document, id int64) {
+			defer wg.Done()
+			defer func() { <-inFlight }()
+
+			_, err := parsePool.Process(ctx, &parseWork{
+				UserID:     job.UserID,
+				Owner:      job.Owner,
+				RepoName:   job.RepoName,
+				Tag:        job.Tag,
+				JobID:      job.ID,
+				DocumentID: id,
+				Document:   d,
+			})
+			if err != nil {
+				report.Fail(d.RelativePath, err)
+				return
+			}
+			tracker.Add(ctx, 1)
+		}(doc, docID)
+
+		return nil
+	})
+
+	wg.Wait()
+
+	if err != nil {
+		return job, err
+	}
+
+	if processed == 0 {
+		return job, nil
+	}
+
+	// Tolerate a share of failed documents rather than failing the whole ingest
+	failed, firstErr := report.Failed()
+	if err := parseErrorBudget.Check(models.JobStageParse, failed, processed, firstErr); err != nil {
+		return job, err
+	}
+
+	// Link symbols to parents now that every document's symbols are in
+	symbolCount, err := writer.Link(ctx)
+	if err != nil {
+		return job, err
+	}
+
+	tracker.Finish(ctx, processed)
+
+	events.Ingest.Parse.Completed.Emit(ctx, events.ParseEvent{
+		RepositoryID:   job.RepositoryID,
+		VersionID:      job.VersionID,
+		FileCount:      len(docIDs),
+		SymbolCount:    symbolCount,
+		ProcessedFiles: processed,
+	})
+
+	return job, nil
+}
+
+// documentTag returns the tag of the documents a job's SCIP rows belong to.
+// A rebuild writes its rows under a private tag but keeps the version's
+// documents.
+func documentTag(ctx context.Context, job *models.Job) (string, error) {
+	if job.Kind != models.JobKindRebuildSCIP {
+		return job.Tag, nil
+	}
+
+	versions := sum.MustUse[contracts.Versions](ctx)
+	version, err := versions.Get(ctx, idToKey(job.VersionID))
+	if err != nil {
+		return "", fmt.Errorf("get version: %w", err)
+	}
+	return version.DataTag(), nil
+}
+
+// runIndexer runs the SCIP indexer for every language root of the
+// repository and keeps the raw index for later rebuilds. Returns nil when no
+// indexer supports any of the roots' languages.
+func runIndexer(ctx context.Context, job *models.Job) (io.ReadSeekCloser, error) {
 	idx := sum.MustUse[contracts.Indexer](ctx)
 	configs := sum.MustUse[contracts.IngestionConfigs](ctx)
 	versions := sum.MustUse[contracts.Versions](ctx)
-	documents := sum.MustUse[contracts.Documents](ctx)
 
-	// Get config for language
-	config, err := configs.GetByRepositoryID(ctx, job.RepositoryID)
+	// Get the config of every language root
+	repoConfigs, err := configs.ListByRepositoryID(ctx, job.RepositoryID)
 	if err != nil {
-		return job, err
+		return nil, err
 	}
 
-	// Check if indexer supports this language
-	if !idx.Supports(config.Language) {
+	// Each project root is indexed on its own. Skip roots whose language
+	// has no indexer
+	var targets []*models.IngestionConfig
+	for _, config := range models.ProjectConfigs(repoConfigs) {
+		if idx.Supports(config.Language) {
+			targets = append(targets, config)
+			continue
+		}
 		events.Ingest.Parse.FileSkipped.Emit(ctx, events.ParseFileEvent{
 			RepositoryID: job.RepositoryID,
 			VersionID:    job.VersionID,
+			FilePath:     config.Root,
 			Language:     string(config.Language),
 			Reason:       "no indexer configured",
 		})
-		return job, nil
+	}
+	if len(targets) == 0 {
+		return nil, nil
 	}
 
 	// Get version for commit SHA
 	version, err := versions.Get(ctx, idToKey(job.VersionID))
 	if err != nil {
-		return job, err
+		return nil, err
 	}
 
-	// Build index request
+	// A single root at the top of the repository needs no merging
+	var index io.ReadSeekCloser
+	if len(targets) == 1 && targets[0].Root == "" {
+		index, err = indexRoot(ctx, job, version.CommitSHA, targets[0])
+	} else {
+		index, err = mergeRoots(ctx, job, version.CommitSHA, targets)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	// A lost index only costs a re-index on rebuild, so it doesn't fail the stage
+	if err := keepIndex(ctx, job, index); err != nil {
+		capitan.Warn(ctx, events.ParseIndexStoreErrorSignal,
+			events.JobIDKey.Field(job.ID),
+			events.VersionIDKey.Field(job.VersionID),
+			events.ErrorKey.Field(err),
+		)
+	}
+
+	return index, nil
+}
+
+// indexRoot runs the indexer for one language root. Document paths in the
+// index are relative to the root.
+func indexRoot(ctx context.Context, job *models.Job, commitSHA string, config *models.IngestionConfig) (io.ReadSeekCloser, error) {
+	idx := sum.MustUse[contracts.Indexer](ctx)
+
 	req := indexer.Request{
 		JobID:        job.ID,
 		RepositoryID: job.RepositoryID,
@@ -177,99 +394,107 @@ func parseStage(ctx context.Context, job *models.Job) (*models.Job, error) {
 		Owner:        job.Owner,
 		RepoName:     job.RepoName,
 		Tag:          job.Tag,
-		CommitSHA:    version.CommitSHA,
+		CommitSHA:    commitSHA,
 		Language:     config.Language,
+		Root:         config.Root,
+	}
+	if err := withLanguageConfig(&req, config); err != nil {
+		return nil, fmt.Errorf("%s language config: %w", config.Language, err)
 	}
 
 	// Call indexer (may be local CLI or remote service)
 	result, err := idx.Index(ctx, req)
 	if err != nil {
-		return job, err
+		return nil, err
 	}
 
 	if result.Error != "" {
-		return job, fmt.Errorf("indexer error: %s", result.Error)
+		_ = result.Close()
+		return nil, fmt.Errorf("indexer error: %s", result.Error)
 	}
 
-	// Parse the SCIP index data
-	parser := vickyScip.New()
-	index, err := parser.Parse(ctx, result.IndexData)
+	return result.Index, nil
+}
+
+// withLanguageConfig attaches the config's language-specific settings to an
+// indexer request.
+func withLanguageConfig(req *indexer.Request, config *models.IngestionConfig) error {
+	var err error
+	switch config.Language {
+	case models.LanguageGo:
+		req.Go, err = config.GetGoConfig()
+	case models.LanguageTypeScript:
+		req.TypeScript, err = config.GetTypeScriptConfig()
+	case models.LanguagePython:
+		req.Python, err = config.GetPythonConfig()
+	}
+	return err
+}
+
+// mergeRoots indexes each language root in turn and merges the indexes into
+// one, spooled to a temporary file, with document paths rebased onto the
+// repository root.
+func mergeRoots(ctx context.Context, job *models.Job, commitSHA string, targets []*models.IngestionConfig) (io.ReadSeekCloser, error) {
+	merged, err := vickyScip.NewSpool("vicky-merged-*.scip")
 	if err != nil {
-		return job, fmt.Errorf("parse scip index: %w", err)
+		return nil, fmt.Errorf("spool merged scip index: %w", err)
 	}
 
-	// Create document rows sequentially — we need the docIDs map before concurrent processing
-	docIDs := make(map[string]int64, len(index.Documents))
-	for _, doc := range index.Documents {
-		contentType := contentTypeForPath(doc.RelativePath)
-		h := sha256.Sum256([]byte(doc.RelativePath + ":" + job.Tag))
-
-		d := &models.Document{
-			VersionID:   job.VersionID,
-			UserID:      job.UserID,
-			Owner:       job.Owner,
-			RepoName:    job.RepoName,
-			Tag:         job.Tag,
-			Path:        doc.RelativePath,
-			ContentType: contentType,
-			ContentHash: hex.EncodeToString(h[:]),
+	parser := vickyScip.New()
+	for _, config := range targets {
+		index, err := indexRoot(ctx, job, commitSHA, config)
+		if err != nil {
+			_ = merged.Close()
+			return nil, fmt.Errorf("index %s root %q: %w", config.Language, config.Root, err)
 		}
 
-		if err := documents.Set(ctx, "", d); err != nil {
-			return job, fmt.Errorf("create document %s: %w", doc.RelativePath, err)
+		_, err = parser.Rebase(merged, index, config.Root)
+		_ = index.Close()
+		if err != nil {
+			_ = merged.Close()
+			return nil, fmt.Errorf("merge %s root %q: %w", config.Language, config.Root, err)
 		}
-
-		docIDs[doc.RelativePath] = d.ID
 	}
 
-	if len(docIDs) == 0 {
-		return job, nil
+	if _, err := merged.Seek(0, io.SeekStart); err != nil {
+		_ = merged.Close()
+		return nil, fmt.Errorf("rewind merged scip index: %w", err)
 	}
+	return merged, nil
+}
 
-	// Process documents concurrently via long-lived pool
-	var (
-		wg       sync.WaitGroup
-		errOnce  sync.Once
-		firstErr error
-	)
-
-	for _, doc := range index.Documents {
-		docID, ok := docIDs[doc.RelativePath]
-		if !ok {
-			continue
-		}
-
-		wg.Add(1)
-		go func(d *scip.Document, id int64) {
-			defer wg.Done()
+// keepIndex copies the raw index to blob storage under the job's version
+// and rewinds it for parsing.
+func keepIndex(ctx context.Context, job *models.Job, index io.ReadSeeker) error {
+	scipIndexes := sum.MustUse[contracts.SCIPIndexes](ctx)
 
-			_, err := parsePool.Process(ctx, &parseWork{
-				UserID:     job.UserID,
-				Owner:      job.Owner,
-				RepoName:   job.RepoName,
-				Tag:        job.Tag,
-				JobID:      job.ID,
-				DocumentID: id,
-				Document:   d,
-			})
-			if err != nil {
-				errOnce.Do(func() { firstErr = err })
-			}
-		}(doc, docID)
+	size, err := index.Seek(0, io.SeekEnd)
+	if err != nil {
+		return fmt.Errorf("size scip index: %w", err)
+	}
+	if _, err := index.Seek(0, io.SeekStart); err != nil {
+		return fmt.Errorf("rewind scip index: %w", err)
 	}
 
-	wg.Wait()
+	putErr := scipIndexes.Put(ctx, job.UserID, job.Owner, job.RepoName, job.VersionID, index, size)
 
-	if firstErr != nil {
-		return job, firstErr
+	if _, err := index.Seek(0, io.SeekStart); err != nil {
+		return fmt.Errorf("rewind scip index: %w", err)
 	}
+	return putErr
+}
 
-	events.Ingest.Parse.Completed.Emit(ctx, events.ParseEvent{
-		RepositoryID:   job.RepositoryID,
-		VersionID:      job.VersionID,
-		FileCount:      len(docIDs),
-		ProcessedFiles: len(docIDs),
-	})
+// openStoredIndex opens the raw index uploaded for the version or kept from
+// its ingestion.
+func openStoredIndex(ctx context.Context, job *models.Job) (io.ReadSeekCloser, error) {
+	scipIndexes := sum.MustUse[contracts.SCIPIndexes](ctx)
 
-	return job, nil
+	index, err := scipIndexes.Open(ctx, job.UserID, job.Owner, job.RepoName, job.VersionID)
+	if errors.Is(err, grub.ErrNotFound) {
+		return nil, fmt.Errorf("version %d has no stored scip index", job.VersionID)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("open stored scip index: %w", err)
+	}
+	return index, nil
 }
@@ -3,8 +3,12 @@
 package ingest
 
 import (
+	"bytes"
 	"context"
+	"encoding/json"
+	"errors"
 	"fmt"
+	"io"
 	"strings"
 	"sync"
 	"testing"
@@ -64,7 +68,7 @@ func TestParseStage(t *testing.T) {
 			return &indexer.Result{
 				JobID:     req.JobID,
 				VersionID: req.VersionID,
-				IndexData: indexData,
+				Index:     vickytest.NewIndexReader(indexData),
 			}, nil
 		},
 	}
@@ -85,36 +89,35 @@ func TestParseStage(t *testing.T) {
 	}
 
 	var mu sync.Mutex
+	var batchCalls int
 	var symbolCalls int
 	var occurrenceCalls int
 
 	ms := &vickytest.MockSCIPSymbols{
-		OnSet: func(ctx context.Context, key string, symbol *models.SCIPSymbol) error {
+		OnInsertBatch: func(ctx context.Context, batch *models.SCIPBatch) error {
 			mu.Lock()
 			defer mu.Unlock()
-			symbolCalls++
-			symbol.ID = int64(symbolCalls)
-			return nil
-		},
-	}
-	mo := &vickytest.MockSCIPOccurrences{
-		OnSet: func(ctx context.Context, key string, occ *models.SCIPOccurrence) error {
-			mu.Lock()
-			defer mu.Unlock()
-			occurrenceCalls++
+			batchCalls++
+			symbolCalls += len(batch.Symbols)
+			occurrenceCalls += len(batch.Occurrences)
 			return nil
 		},
 	}
+	mo := &vickytest.MockSCIPOccurrences{}
 	mr := &vickytest.MockSCIPRelationships{}
 
 	ctx := vickytest.SetupRegistry(t,
+		vickytest.WithJobs(&vickytest.MockJobs{}),
+		vickytest.WithFileReports(&vickytest.MockFileReports{}),
 		vickytest.WithIndexer(mi),
+		vickytest.WithSCIPIndexes(&vickytest.MockSCIPIndexes{}),
 		vickytest.WithIngestionConfigs(mc),
 		vickytest.WithVersions(mv),
 		vickytest.WithDocuments(md),
 		vickytest.WithSCIPSymbols(ms),
 		vickytest.WithSCIPOccurrences(mo),
 		vickytest.WithSCIPRelationships(mr),
+		vickytest.WithSymbols(&vickytest.MockSymbols{}),
 	)
 
 	job := vickytest.NewJob(t)
@@ -132,11 +135,14 @@ func TestParseStage(t *testing.T) {
 
 	mu.Lock()
 	defer mu.Unlock()
+	if batchCalls != 1 {
+		t.Errorf("InsertBatch calls = %d, want 1 per document", batchCalls)
+	}
 	if symbolCalls != 1 {
-		t.Errorf("symbol Set calls = %d, want 1", symbolCalls)
+		t.Errorf("symbols written = %d, want 1", symbolCalls)
 	}
 	if occurrenceCalls != 1 {
-		t.Errorf("occurrence Set calls = %d, want 1", occurrenceCalls)
+		t.Errorf("occurrences written = %d, want 1", occurrenceCalls)
 	}
 }
 
@@ -149,13 +155,17 @@ func TestParseStage_UnsupportedLanguage(t *testing.T) {
 	mc := &vickytest.MockIngestionConfigs{}
 
 	ctx := vickytest.SetupRegistry(t,
+		vickytest.WithJobs(&vickytest.MockJobs{}),
+		vickytest.WithFileReports(&vickytest.MockFileReports{}),
 		vickytest.WithIndexer(mi),
+		vickytest.WithSCIPIndexes(&vickytest.MockSCIPIndexes{}),
 		vickytest.WithIngestionConfigs(mc),
 		vickytest.WithVersions(&vickytest.MockVersions{}),
 		vickytest.WithDocuments(&vickytest.MockDocuments{}),
 		vickytest.WithSCIPSymbols(&vickytest.MockSCIPSymbols{}),
 		vickytest.WithSCIPOccurrences(&vickytest.MockSCIPOccurrences{}),
 		vickytest.WithSCIPRelationships(&vickytest.MockSCIPRelationships{}),
+		vickytest.WithSymbols(&vickytest.MockSymbols{}),
 	)
 
 	job := vickytest.NewJob(t)
@@ -184,13 +194,17 @@ func TestParseStage_IndexerError(t *testing.T) {
 	}
 
 	ctx := vickytest.SetupRegistry(t,
+		vickytest.WithJobs(&vickytest.MockJobs{}),
+		vickytest.WithFileReports(&vickytest.MockFileReports{}),
 		vickytest.WithIndexer(mi),
+		vickytest.WithSCIPIndexes(&vickytest.MockSCIPIndexes{}),
 		vickytest.WithIngestionConfigs(&vickytest.MockIngestionConfigs{}),
 		vickytest.WithVersions(mv),
 		vickytest.WithDocuments(&vickytest.MockDocuments{}),
 		vickytest.WithSCIPSymbols(&vickytest.MockSCIPSymbols{}),
 		vickytest.WithSCIPOccurrences(&vickytest.MockSCIPOccurrences{}),
 		vickytest.WithSCIPRelationships(&vickytest.MockSCIPRelationships{}),
+		vickytest.WithSymbols(&vickytest.MockSymbols{}),
 	)
 
 	job := vickytest.NewJob(t)
@@ -222,13 +236,17 @@ func TestParseStage_IndexerErrorResult(t *testing.T) {
 	}
 
 	ctx := vickytest.SetupRegistry(t,
+		vickytest.WithJobs(&vickytest.MockJobs{}),
+		vickytest.WithFileReports(&vickytest.MockFileReports{}),
 		vickytest.WithIndexer(mi),
+		vickytest.WithSCIPIndexes(&vickytest.MockSCIPIndexes{}),
 		vickytest.WithIngestionConfigs(&vickytest.MockIngestionConfigs{}),
 		vickytest.WithVersions(mv),
 		vickytest.WithDocuments(&vickytest.MockDocuments{}),
 		vickytest.WithSCIPSymbols(&vickytest.MockSCIPSymbols{}),
 		vickytest.WithSCIPOccurrences(&vickytest.MockSCIPOccurrences{}),
 		vickytest.WithSCIPRelationships(&vickytest.MockSCIPRelationships{}),
+		vickytest.WithSymbols(&vickytest.MockSymbols{}),
 	)
 
 	job := vickytest.NewJob(t)
@@ -254,7 +272,7 @@ func TestParseStage_DocumentSetError(t *testing.T) {
 			return &indexer.Result{
 				JobID:     req.JobID,
 				VersionID: req.VersionID,
-				IndexData: indexData,
+				Index:     vickytest.NewIndexReader(indexData),
 			}, nil
 		},
 	}
@@ -270,13 +288,17 @@ func TestParseStage_DocumentSetError(t *testing.T) {
 	}
 
 	ctx := vickytest.SetupRegistry(t,
+		vickytest.WithJobs(&vickytest.MockJobs{}),
+		vickytest.WithFileReports(&vickytest.MockFileReports{}),
 		vickytest.WithIndexer(mi),
+		vickytest.WithSCIPIndexes(&vickytest.MockSCIPIndexes{}),
 		vickytest.WithIngestionConfigs(&vickytest.MockIngestionConfigs{}),
 		vickytest.WithVersions(mv),
 		vickytest.WithDocuments(md),
 		vickytest.WithSCIPSymbols(&vickytest.MockSCIPSymbols{}),
 		vickytest.WithSCIPOccurrences(&vickytest.MockSCIPOccurrences{}),
 		vickytest.WithSCIPRelationships(&vickytest.MockSCIPRelationships{}),
+		vickytest.WithSymbols(&vickytest.MockSymbols{}),
 	)
 
 	job := vickytest.NewJob(t)
@@ -288,3 +310,732 @@ func TestParseStage_DocumentSetError(t *testing.T) {
 		t.Errorf("error = %q, want it to contain %q", err.Error(), "create document")
 	}
 }
+
+func TestParseStage_ReusesFetchedDocuments(t *testing.T) {
+	version := vickytest.NewVersion(t)
+
+	indexData := buildSCIPIndex(t, []*scipproto.Document{
+		{
+			RelativePath: "main.go",
+			Occurrences: []*scipproto.Occurrence{
+				{Range: []int32{0, 5, 9}, Symbol: "local 0"},
+			},
+		},
+	})
+
+	mi := &vickytest.MockIndexer{
+		OnIndex: func(ctx context.Context, req indexer.Request) (*indexer.Result, error) {
+			return &indexer.Result{Index: vickytest.NewIndexReader(indexData)}, nil
+		},
+	}
+	mv := &vickytest.MockVersions{
+		OnGet: func(ctx context.Context, key string) (*models.Version, error) {
+			return version, nil
+		},
+	}
+
+	var docSetCalls int
+	md := &vickytest.MockDocuments{
+		OnListByUserRepoAndTag: func(ctx context.Context, userID int64, owner, repoName, tag string) ([]*models.Document, error) {
+			return []*models.Document{vickytest.NewDocument(t, 42, "main.go")}, nil
+		},
+		OnSet: func(ctx context.Context, key string, doc *models.Document) error {
+			docSetCalls++
+			return nil
+		},
+	}
+
+	var occDocID int64
+	ms := &vickytest.MockSCIPSymbols{
+		OnInsertBatch: func(ctx context.Context, batch *models.SCIPBatch) error {
+			for _, occ := range batch.Occurrences {
+				occDocID = occ.DocumentID
+			}
+			return nil
+		},
+	}
+
+	ctx := vickytest.SetupRegistry(t,
+		vickytest.WithJobs(&vickytest.MockJobs{}),
+		vickytest.WithFileReports(&vickytest.MockFileReports{}),
+		vickytest.WithIndexer(mi),
+		vickytest.WithSCIPIndexes(&vickytest.MockSCIPIndexes{}),
+		vickytest.WithIngestionConfigs(&vickytest.MockIngestionConfigs{}),
+		vickytest.WithVersions(mv),
+		vickytest.WithDocuments(md),
+		vickytest.WithSCIPSymbols(ms),
+		vickytest.WithSCIPOccurrences(&vickytest.MockSCIPOccurrences{}),
+		vickytest.WithSCIPRelationships(&vickytest.MockSCIPRelationships{}),
+		vickytest.WithSymbols(&vickytest.MockSymbols{}),
+	)
+
+	if _, err := parseStage(ctx, vickytest.NewJob(t)); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if docSetCalls != 0 {
+		t.Errorf("document Set calls = %d, want 0 for an already fetched file", docSetCalls)
+	}
+	if occDocID != 42 {
+		t.Errorf("occurrence DocumentID = %d, want 42", occDocID)
+	}
+}
+
+func TestParseStage_StreamsDocuments(t *testing.T) {
+	version := vickytest.NewVersion(t)
+
+	var scipDocs []*scipproto.Document
+	for i := 0; i < 20; i++ {
+		scipDocs = append(scipDocs, &scipproto.Document{RelativePath: fmt.Sprintf("pkg/file%d.go", i)})
+	}
+	indexData := buildSCIPIndex(t, scipDocs)
+
+	mi := &vickytest.MockIndexer{
+		OnIndex: func(ctx context.Context, req indexer.Request) (*indexer.Result, error) {
+			return &indexer.Result{Index: vickytest.NewIndexReader(indexData)}, nil
+		},
+	}
+	mv := &vickytest.MockVersions{
+		OnGet: func(ctx context.Context, key string) (*models.Version, error) {
+			return version, nil
+		},
+	}
+
+	var docSetCalls int
+	md := &vickytest.MockDocuments{
+		OnSet: func(ctx context.Context, key string, doc *models.Document) error {
+			docSetCalls++
+			doc.ID = int64(docSetCalls)
+			return nil
+		},
+	}
+
+	var mu sync.Mutex
+	var batchCalls int
+	ms := &vickytest.MockSCIPSymbols{
+		OnInsertBatch: func(ctx context.Context, batch *models.SCIPBatch) error {
+			mu.Lock()
+			defer mu.Unlock()
+			batchCalls++
+			return nil
+		},
+	}
+
+	var totals []int
+	mj := &vickytest.MockJobs{
+		OnUpdateProgress: func(ctx context.Context, id int64, stage models.JobStage, progress int, itemsTotal int, itemsProcessed int) error {
+			mu.Lock()
+			defer mu.Unlock()
+			totals = append(totals, itemsTotal)
+			return nil
+		},
+	}
+
+	ctx := vickytest.SetupRegistry(t,
+		vickytest.WithJobs(mj),
+		vickytest.WithFileReports(&vickytest.MockFileReports{}),
+		vickytest.WithIndexer(mi),
+		vickytest.WithSCIPIndexes(&vickytest.MockSCIPIndexes{}),
+		vickytest.WithIngestionConfigs(&vickytest.MockIngestionConfigs{}),
+		vickytest.WithVersions(mv),
+		vickytest.WithDocuments(md),
+		vickytest.WithSCIPSymbols(ms),
+		vickytest.WithSCIPOccurrences(&vickytest.MockSCIPOccurrences{}),
+		vickytest.WithSCIPRelationships(&vickytest.MockSCIPRelationships{}),
+		vickytest.WithSymbols(&vickytest.MockSymbols{}),
+	)
+
+	if _, err := parseStage(ctx, vickytest.NewJob(t)); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if docSetCalls != 20 {
+		t.Errorf("document Set calls = %d, want 20", docSetCalls)
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+	if batchCalls != 20 {
+		t.Errorf("InsertBatch calls = %d, want 20", batchCalls)
+	}
+	if len(totals) == 0 || totals[0] != 20 {
+		t.Errorf("progress totals = %v, want the document count known up front", totals)
+	}
+}
+
+func TestParseStage_CorruptIndex(t *testing.T) {
+	version := vickytest.NewVersion(t)
+	indexData := buildSCIPIndex(t, []*scipproto.Document{{RelativePath: "main.go"}})
+
+	mi := &vickytest.MockIndexer{
+		OnIndex: func(ctx context.Context, req indexer.Request) (*indexer.Result, error) {
+			return &indexer.Result{Index: vickytest.NewIndexReader(indexData[:len(indexData)-2])}, nil
+		},
+	}
+	mv := &vickytest.MockVersions{
+		OnGet: func(ctx context.Context, key string) (*models.Version, error) {
+			return version, nil
+		},
+	}
+
+	ctx := vickytest.SetupRegistry(t,
+		vickytest.WithJobs(&vickytest.MockJobs{}),
+		vickytest.WithFileReports(&vickytest.MockFileReports{}),
+		vickytest.WithIndexer(mi),
+		vickytest.WithSCIPIndexes(&vickytest.MockSCIPIndexes{}),
+		vickytest.WithIngestionConfigs(&vickytest.MockIngestionConfigs{}),
+		vickytest.WithVersions(mv),
+		vickytest.WithDocuments(&vickytest.MockDocuments{}),
+		vickytest.WithSCIPSymbols(&vickytest.MockSCIPSymbols{}),
+		vickytest.WithSCIPOccurrences(&vickytest.MockSCIPOccurrences{}),
+		vickytest.WithSCIPRelationships(&vickytest.MockSCIPRelationships{}),
+		vickytest.WithSymbols(&vickytest.MockSymbols{}),
+	)
+
+	_, err := parseStage(ctx, vickytest.NewJob(t))
+	if err == nil || !strings.Contains(err.Error(), "parse scip index") {
+		t.Errorf("err = %v, want a parse error", err)
+	}
+}
+
+func TestParseStage_StoresIndex(t *testing.T) {
+	version := vickytest.NewVersion(t)
+	indexData := buildSCIPIndex(t, []*scipproto.Document{{RelativePath: "main.go"}})
+
+	mi := &vickytest.MockIndexer{
+		OnIndex: func(ctx context.Context, req indexer.Request) (*indexer.Result, error) {
+			return &indexer.Result{Index: vickytest.NewIndexReader(indexData)}, nil
+		},
+	}
+	mv := &vickytest.MockVersions{
+		OnGet: func(ctx context.Context, key string) (*models.Version, error) {
+			return version, nil
+		},
+	}
+	var stored []byte
+	var storedVersion, storedSize int64
+	mx := &vickytest.MockSCIPIndexes{
+		OnPut: func(ctx context.Context, userID int64, owner, repo string, versionID int64, r io.Reader, size int64) error {
+			storedVersion, storedSize = versionID, size
+			var err error
+			stored, err = io.ReadAll(r)
+			return err
+		},
+	}
+	var batchCalls int
+	ms := &vickytest.MockSCIPSymbols{
+		OnInsertBatch: func(ctx context.Context, batch *models.SCIPBatch) error {
+			batchCalls++
+			return nil
+		},
+	}
+
+	ctx := vickytest.SetupRegistry(t,
+		vickytest.WithJobs(&vickytest.MockJobs{}),
+		vickytest.WithFileReports(&vickytest.MockFileReports{}),
+		vickytest.WithIndexer(mi),
+		vickytest.WithSCIPIndexes(mx),
+		vickytest.WithIngestionConfigs(&vickytest.MockIngestionConfigs{}),
+		vickytest.WithVersions(mv),
+		vickytest.WithDocuments(&vickytest.MockDocuments{}),
+		vickytest.WithSCIPSymbols(ms),
+		vickytest.WithSCIPOccurrences(&vickytest.MockSCIPOccurrences{}),
+		vickytest.WithSCIPRelationships(&vickytest.MockSCIPRelationships{}),
+		vickytest.WithSymbols(&vickytest.MockSymbols{}),
+	)
+
+	job := vickytest.NewJob(t)
+	if _, err := parseStage(ctx, job); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if !bytes.Equal(stored, indexData) || storedSize != int64(len(indexData)) {
+		t.Errorf("stored %d bytes (size %d), want the %d-byte index", len(stored), storedSize, len(indexData))
+	}
+	if storedVersion != job.VersionID {
+		t.Errorf("stored under version %d, want %d", storedVersion, job.VersionID)
+	}
+	if batchCalls != 1 {
+		t.Errorf("InsertBatch calls = %d, want the index parsed after storing", batchCalls)
+	}
+}
+
+func TestParseStage_StoreIndexErrorContinues(t *testing.T) {
+	version := vickytest.NewVersion(t)
+	indexData := buildSCIPIndex(t, []*scipproto.Document{{RelativePath: "main.go"}})
+
+	mi := &vickytest.MockIndexer{
+		OnIndex: func(ctx context.Context, req indexer.Request) (*indexer.Result, error) {
+			return &indexer.Result{Index: vickytest.NewIndexReader(indexData)}, nil
+		},
+	}
+	mv := &vickytest.MockVersions{
+		OnGet: func(ctx context.Context, key string) (*models.Version, error) {
+			return version, nil
+		},
+	}
+	mx := &vickytest.MockSCIPIndexes{
+		OnPut: func(ctx context.Context, userID int64, owner, repo string, versionID int64, r io.Reader, size int64) error {
+			_, _ = io.CopyN(io.Discard, r, 3)
+			return errors.New("bucket unavailable")
+		},
+	}
+	var batchCalls int
+	ms := &vickytest.MockSCIPSymbols{
+		OnInsertBatch: func(ctx context.Context, batch *models.SCIPBatch) error {
+			batchCalls++
+			return nil
+		},
+	}
+
+	ctx := vickytest.SetupRegistry(t,
+		vickytest.WithJobs(&vickytest.MockJobs{}),
+		vickytest.WithFileReports(&vickytest.MockFileReports{}),
+		vickytest.WithIndexer(mi),
+		vickytest.WithSCIPIndexes(mx),
+		vickytest.WithIngestionConfigs(&vickytest.MockIngestionConfigs{}),
+		vickytest.WithVersions(mv),
+		vickytest.WithDocuments(&vickytest.MockDocuments{}),
+		vickytest.WithSCIPSymbols(ms),
+		vickytest.WithSCIPOccurrences(&vickytest.MockSCIPOccurrences{}),
+		vickytest.WithSCIPRelationships(&vickytest.MockSCIPRelationships{}),
+		vickytest.WithSymbols(&vickytest.MockSymbols{}),
+	)
+
+	if _, err := parseStage(ctx, vickytest.NewJob(t)); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if batchCalls != 1 {
+		t.Errorf("InsertBatch calls = %d, want 1", batchCalls)
+	}
+}
+
+func TestParseStage_RebuildUsesStoredIndex(t *testing.T) {
+	indexData := buildSCIPIndex(t, []*scipproto.Document{{RelativePath: "main.go"}})
+
+	mi := &vickytest.MockIndexer{
+		OnIndex: func(ctx context.Context, req indexer.Request) (*indexer.Result, error) {
+			t.Error("indexer called for a rebuild")
+			return nil, errors.New("unexpected")
+		},
+	}
+	mx := &vickytest.MockSCIPIndexes{
+		OnOpen: func(ctx context.Context, userID int64, owner, repo string, versionID int64) (io.ReadSeekCloser, error) {
+			return vickytest.NewIndexReader(indexData), nil
+		},
+		OnPut: func(ctx context.Context, userID int64, owner, repo string, versionID int64, r io.Reader, size int64) error {
+			t.Error("stored index rewritten by a rebuild")
+			return nil
+		},
+	}
+	var batchCalls int
+	var clearedTag string
+	ms := &vickytest.MockSCIPSymbols{
+		OnInsertBatch: func(ctx context.Context, batch *models.SCIPBatch) error {
+			batchCalls++
+			return nil
+		},
+		OnDeleteByUserRepoAndTag: func(ctx context.Context, userID int64, owner, repoName, tag string) error {
+			clearedTag = tag
+			return nil
+		},
+	}
+	var listedTag string
+	md := &vickytest.MockDocuments{
+		OnListByUserRepoAndTag: func(ctx context.Context, userID int64, owner, repoName, tag string) ([]*models.Document, error) {
+			listedTag = tag
+			return []*models.Document{{ID: 1, Path: "main.go", Tag: tag}}, nil
+		},
+		OnSet: func(ctx context.Context, key string, doc *models.Document) error {
+			t.Errorf("document %s created by a rebuild", doc.Path)
+			return nil
+		},
+	}
+	version := vickytest.NewVersion(t)
+	version.Status = models.VersionStatusReady
+	mv := &vickytest.MockVersions{
+		OnGet: func(ctx context.Context, key string) (*models.Version, error) {
+			return version, nil
+		},
+	}
+
+	ctx := vickytest.SetupRegistry(t,
+		vickytest.WithJobs(&vickytest.MockJobs{}),
+		vickytest.WithFileReports(&vickytest.MockFileReports{}),
+		vickytest.WithIndexer(mi),
+		vickytest.WithSCIPIndexes(mx),
+		vickytest.WithDocuments(md),
+		vickytest.WithVersions(mv),
+		vickytest.WithSCIPSymbols(ms),
+		vickytest.WithSCIPOccurrences(&vickytest.MockSCIPOccurrences{}),
+		vickytest.WithSCIPRelationships(&vickytest.MockSCIPRelationships{}),
+		vickytest.WithSymbols(&vickytest.MockSymbols{}),
+	)
+
+	job := vickytest.NewJob(t)
+	job.Kind = models.JobKindRebuildSCIP
+	job.Tag = models.RebuildTag(version.Tag, version.ID)
+	if _, err := parseStage(ctx, job); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if batchCalls != 1 {
+		t.Errorf("InsertBatch calls = %d, want 1", batchCalls)
+	}
+	// The live rows stay until the store stage swaps the rebuilt ones in
+	if clearedTag != job.Tag {
+		t.Errorf("cleared SCIP rows under %q, want the rebuild tag %q", clearedTag, job.Tag)
+	}
+	if listedTag != version.Tag {
+		t.Errorf("documents listed under %q, want the live tag %q", listedTag, version.Tag)
+	}
+}
+
+func TestParseStage_RebuildWithoutStoredIndex(t *testing.T) {
+	var cleared bool
+	ms := &vickytest.MockSCIPSymbols{
+		OnDeleteByUserRepoAndTag: func(ctx context.Context, userID int64, owner, repoName, tag string) error {
+			cleared = true
+			return nil
+		},
+	}
+
+	ctx := vickytest.SetupRegistry(t,
+		vickytest.WithJobs(&vickytest.MockJobs{}),
+		vickytest.WithSCIPIndexes(&vickytest.MockSCIPIndexes{}),
+		vickytest.WithDocuments(&vickytest.MockDocuments{}),
+		vickytest.WithSCIPSymbols(ms),
+		vickytest.WithSCIPOccurrences(&vickytest.MockSCIPOccurrences{}),
+		vickytest.WithSymbols(&vickytest.MockSymbols{}),
+	)
+
+	job := vickytest.NewJob(t)
+	job.Kind = models.JobKindRebuildSCIP
+	_, err := parseStage(ctx, job)
+	if err == nil || !strings.Contains(err.Error(), "no stored scip index") {
+		t.Errorf("err = %v, want a missing index error", err)
+	}
+	if cleared {
+		t.Error("existing SCIP data cleared although there is nothing to rebuild from")
+	}
+}
+
+func TestParseStage_Python(t *testing.T) {
+	version := vickytest.NewVersion(t)
+	method := "scip-python python acme 2.1.0 `acme.billing`/Invoice#total()."
+	indexData := buildSCIPIndex(t, []*scipproto.Document{
+		{
+			RelativePath: "acme/billing.py",
+			Language:     "python",
+			Symbols: []*scipproto.SymbolInformation{
+				{Symbol: "scip-python python acme 2.1.0 `acme.billing`/Invoice#"},
+				{Symbol: method},
+			},
+			Occurrences: []*scipproto.Occurrence{
+				{Range: []int32{2, 6, 13}, Symbol: "scip-python python acme 2.1.0 `acme.billing`/Invoice#", SymbolRoles: int32(scipproto.SymbolRole_Definition)},
+				{Range: []int32{3, 8, 13}, Symbol: method, SymbolRoles: int32(scipproto.SymbolRole_Definition)},
+			},
+		},
+	})
+
+	var requested models.Language
+	mi := &vickytest.MockIndexer{
+		OnIndex: func(ctx context.Context, req indexer.Request) (*indexer.Result, error) {
+			requested = req.Language
+			return &indexer.Result{Index: vickytest.NewIndexReader(indexData)}, nil
+		},
+	}
+	mc := &vickytest.MockIngestionConfigs{
+		OnListByRepositoryID: func(ctx context.Context, repositoryID int64) ([]*models.IngestionConfig, error) {
+			return []*models.IngestionConfig{{Language: models.LanguagePython, MaxFileSize: models.DefaultMaxFileSize}}, nil
+		},
+	}
+	mv := &vickytest.MockVersions{
+		OnGet: func(ctx context.Context, key string) (*models.Version, error) {
+			return version, nil
+		},
+	}
+	var mu sync.Mutex
+	var names []string
+	msym := &vickytest.MockSymbols{
+		OnSet: func(ctx context.Context, key string, symbol *models.Symbol) error {
+			mu.Lock()
+			defer mu.Unlock()
+			if key != "" {
+				return nil // parent link
+			}
+			names = append(names, symbol.QualifiedName)
+			symbol.ID = int64(len(names))
+			return nil
+		},
+	}
+
+	ctx := vickytest.SetupRegistry(t,
+		vickytest.WithJobs(&vickytest.MockJobs{}),
+		vickytest.WithFileReports(&vickytest.MockFileReports{}),
+		vickytest.WithIndexer(mi),
+		vickytest.WithSCIPIndexes(&vickytest.MockSCIPIndexes{}),
+		vickytest.WithIngestionConfigs(mc),
+		vickytest.WithVersions(mv),
+		vickytest.WithDocuments(&vickytest.MockDocuments{}),
+		vickytest.WithSCIPSymbols(&vickytest.MockSCIPSymbols{}),
+		vickytest.WithSCIPOccurrences(&vickytest.MockSCIPOccurrences{}),
+		vickytest.WithSCIPRelationships(&vickytest.MockSCIPRelationships{}),
+		vickytest.WithSymbols(msym),
+	)
+
+	if _, err := parseStage(ctx, vickytest.NewJob(t)); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if requested != models.LanguagePython {
+		t.Errorf("indexer language = %q, want python", requested)
+	}
+	mu.Lock()
+	defer mu.Unlock()
+	if fmt.Sprint(names) != "[billing.Invoice billing.Invoice.total]" {
+		t.Errorf("symbols = %v, want the class and its method", names)
+	}
+}
+
+func TestParseStage_LanguageRoots(t *testing.T) {
+	version := vickytest.NewVersion(t)
+	indexes := map[models.Language][]byte{
+		models.LanguageGo: buildSCIPIndex(t, []*scipproto.Document{
+			{RelativePath: "cmd/server/main.go"},
+		}),
+		models.LanguageTypeScript: buildSCIPIndex(t, []*scipproto.Document{
+			{RelativePath: "src/app.ts"},
+			{RelativePath: "src/util.ts"},
+		}),
+	}
+
+	var mu sync.Mutex
+	requested := make(map[models.Language]string)
+	mi := &vickytest.MockIndexer{
+		OnIndex: func(ctx context.Context, req indexer.Request) (*indexer.Result, error) {
+			requested[req.Language] = req.Root
+			return &indexer.Result{Index: vickytest.NewIndexReader(indexes[req.Language])}, nil
+		},
+		OnSupports: func(language models.Language) bool {
+			return language != models.LanguagePython
+		},
+	}
+	mc := &vickytest.MockIngestionConfigs{
+		OnListByRepositoryID: func(ctx context.Context, repositoryID int64) ([]*models.IngestionConfig, error) {
+			return []*models.IngestionConfig{
+				{Language: models.LanguageGo},
+				{Language: models.LanguageTypeScript, Root: "web"},
+				{Language: models.LanguagePython, Root: "ml"},
+			}, nil
+		},
+	}
+	mv := &vickytest.MockVersions{
+		OnGet: func(ctx context.Context, key string) (*models.Version, error) {
+			return version, nil
+		},
+	}
+	var paths []string
+	md := &vickytest.MockDocuments{
+		OnSet: func(ctx context.Context, key string, doc *models.Document) error {
+			paths = append(paths, doc.Path)
+			doc.ID = int64(len(paths))
+			return nil
+		},
+	}
+	var kept []byte
+	mx := &vickytest.MockSCIPIndexes{
+		OnPut: func(ctx context.Context, userID int64, owner, repo string, versionID int64, r io.Reader, size int64) error {
+			data, err := io.ReadAll(r)
+			kept = data
+			return err
+		},
+	}
+	var batches int
+	ms := &vickytest.MockSCIPSymbols{
+		OnInsertBatch: func(ctx context.Context, batch *models.SCIPBatch) error {
+			mu.Lock()
+			defer mu.Unlock()
+			batches++
+			return nil
+		},
+	}
+
+	ctx := vickytest.SetupRegistry(t,
+		vickytest.WithJobs(&vickytest.MockJobs{}),
+		vickytest.WithFileReports(&vickytest.MockFileReports{}),
+		vickytest.WithIndexer(mi),
+		vickytest.WithSCIPIndexes(mx),
+		vickytest.WithIngestionConfigs(mc),
+		vickytest.WithVersions(mv),
+		vickytest.WithDocuments(md),
+		vickytest.WithSCIPSymbols(ms),
+		vickytest.WithSCIPOccurrences(&vickytest.MockSCIPOccurrences{}),
+		vickytest.WithSCIPRelationships(&vickytest.MockSCIPRelationships{}),
+		vickytest.WithSymbols(&vickytest.MockSymbols{}),
+	)
+
+	if _, err := parseStage(ctx, vickytest.NewJob(t)); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if len(requested) != 2 || requested[models.LanguageGo] != "" || requested[models.LanguageTypeScript] != "web" {
+		t.Errorf("indexer requests = %v, want go at the root and typescript at web", requested)
+	}
+	if fmt.Sprint(paths) != "[cmd/server/main.go web/src/app.ts web/src/util.ts]" {
+		t.Errorf("documents = %v, want every root's documents under repository paths", paths)
+	}
+	mu.Lock()
+	defer mu.Unlock()
+	if batches != 3 {
+		t.Errorf("InsertBatch calls = %d, want one per merged document", batches)
+	}
+
+	// The kept index is the merged one, so rebuilds see every root
+	var index scipproto.Index
+	if err := proto.Unmarshal(kept, &index); err != nil {
+		t.Fatalf("kept index does not decode: %v", err)
+	}
+	if len(index.Documents) != 3 || index.Documents[1].RelativePath != "web/src/app.ts" {
+		t.Errorf("kept index has %d documents, want the 3 merged ones", len(index.Documents))
+	}
+}
+
+func TestParseStage_RootPaths(t *testing.T) {
+	version := vickytest.NewVersion(t)
+
+	var roots []string
+	mi := &vickytest.MockIndexer{
+		OnIndex: func(ctx context.Context, req indexer.Request) (*indexer.Result, error) {
+			roots = append(roots, req.Root)
+			index := buildSCIPIndex(t, []*scipproto.Document{{RelativePath: "main.go"}})
+			return &indexer.Result{Index: vickytest.NewIndexReader(index)}, nil
+		},
+	}
+	mc := &vickytest.MockIngestionConfigs{
+		OnListByRepositoryID: func(ctx context.Context, repositoryID int64) ([]*models.IngestionConfig, error) {
+			return []*models.IngestionConfig{
+				{Language: models.LanguageGo, Root: "services", RootPaths: []string{"payments", "billing"}},
+			}, nil
+		},
+	}
+	mv := &vickytest.MockVersions{
+		OnGet: func(ctx context.Context, key string) (*models.Version, error) {
+			return version, nil
+		},
+	}
+	var paths []string
+	md := &vickytest.MockDocuments{
+		OnSet: func(ctx context.Context, key string, doc *models.Document) error {
+			paths = append(paths, doc.Path)
+			doc.ID = int64(len(paths))
+			return nil
+		},
+	}
+
+	ctx := vickytest.SetupRegistry(t,
+		vickytest.WithJobs(&vickytest.MockJobs{}),
+		vickytest.WithFileReports(&vickytest.MockFileReports{}),
+		vickytest.WithIndexer(mi),
+		vickytest.WithSCIPIndexes(&vickytest.MockSCIPIndexes{}),
+		vickytest.WithIngestionConfigs(mc),
+		vickytest.WithVersions(mv),
+		vickytest.WithDocuments(md),
+		vickytest.WithSCIPSymbols(&vickytest.MockSCIPSymbols{}),
+		vickytest.WithSCIPOccurrences(&vickytest.MockSCIPOccurrences{}),
+		vickytest.WithSCIPRelationships(&vickytest.MockSCIPRelationships{}),
+		vickytest.WithSymbols(&vickytest.MockSymbols{}),
+	)
+
+	if _, err := parseStage(ctx, vickytest.NewJob(t)); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if fmt.Sprint(roots) != "[services/payments services/billing]" {
+		t.Errorf("indexer roots = %v, want one request per root path", roots)
+	}
+	if fmt.Sprint(paths) != "[services/payments/main.go services/billing/main.go]" {
+		t.Errorf("documents = %v, want repository-relative paths", paths)
+	}
+}
+
+func TestWithLanguageConfig(t *testing.T) {
+	var req indexer.Request
+	config := &models.IngestionConfig{
+		Language:       models.LanguageGo,
+		LanguageConfig: json.RawMessage(`{"module_path": "example.com/app", "build_tags": ["integration"]}`),
+	}
+	if err := withLanguageConfig(&req, config); err != nil {
+		t.Fatalf("withLanguageConfig: %v", err)
+	}
+	if req.Go == nil || req.Go.ModulePath != "example.com/app" || len(req.Go.BuildTags) != 1 {
+		t.Errorf("Go = %+v, want the stored settings", req.Go)
+	}
+	if req.TypeScript != nil || req.Python != nil {
+		t.Error("settings attached for another language")
+	}
+
+	config = &models.IngestionConfig{Language: models.LanguageTypeScript, LanguageConfig: json.RawMessage(`{"tsconfig_path": 1}`)}
+	if err := withLanguageConfig(&indexer.Request{}, config); err == nil {
+		t.Error("expected error for a malformed config")
+	}
+}
+
+func TestProcessParseDoc_WritesOneBatch(t *testing.T) {
+	doc := &scipproto.Document{
+		RelativePath: "main.go",
+		Symbols: []*scipproto.SymbolInformation{
+			{
+				Symbol: "scip-go gomod example.com/test v1.0.0 Server#",
+				Relationships: []*scipproto.Relationship{
+					{Symbol: "scip-go gomod example.com/test v1.0.0 Handler#", IsImplementation: true},
+					{Symbol: "scip-go gomod example.com/test v1.0.0 Closer#", IsImplementation: true},
+				},
+			},
+			{Symbol: "scip-go gomod example.com/test v1.0.0 main()."},
+		},
+		Occurrences: []*scipproto.Occurrence{
+			{Range: []int32{1, 0, 4}, Symbol: "scip-go gomod example.com/test v1.0.0 main()."},
+		},
+	}
+
+	var batches []*models.SCIPBatch
+	ms := &vickytest.MockSCIPSymbols{
+		OnInsertBatch: func(ctx context.Context, batch *models.SCIPBatch) error {
+			batches = append(batches, batch)
+			return nil
+		},
+	}
+	ctx := vickytest.SetupRegistry(t, vickytest.WithSCIPSymbols(ms))
+
+	if _, err := processParseDoc(ctx, &parseWork{UserID: 1000, DocumentID: 7, Document: doc}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if len(batches) != 1 {
+		t.Fatalf("InsertBatch calls = %d, want 1", len(batches))
+	}
+	b := batches[0]
+	if len(b.Symbols) != 2 || len(b.Occurrences) != 1 {
+		t.Fatalf("batch = %d symbols, %d occurrences; want 2, 1", len(b.Symbols), len(b.Occurrences))
+	}
+	if len(b.Relationships) != 2 || len(b.Relationships[0]) != 2 || len(b.Relationships[1]) != 0 {
+		t.Errorf("relationships not grouped by symbol: %v", b.Relationships)
+	}
+	if b.Symbols[0].DocumentID != 7 || b.Occurrences[0].DocumentID != 7 {
+		t.Error("rows not attached to the document")
+	}
+}
+
+func TestProcessParseDoc_StoreError(t *testing.T) {
+	ms := &vickytest.MockSCIPSymbols{
+		OnInsertBatch: func(ctx context.Context, batch *models.SCIPBatch) error {
+			return fmt.Errorf("connection reset")
+		},
+	}
+	ctx := vickytest.SetupRegistry(t, vickytest.WithSCIPSymbols(ms))
+
+	_, err := processParseDoc(ctx, &parseWork{Document: &scipproto.Document{RelativePath: "main.go"}})
+	if err == nil || !strings.Contains(err.Error(), "main.go") {
+		t.Errorf("err = %v, want the document's store error", err)
+	}
+}
@@ -3,6 +3,7 @@ package ingest
 import (
 	"context"
 	"errors"
+	"fmt"
 	"time"
 
 	"github.com/zoobzio/pipz"
@@ -29,11 +30,25 @@ var (
 	EmbedTimeoutID = pipz.NewIdentity("embed-timeout", "Timeout for embed stage")
 	StoreTimeoutID = pipz.NewIdentity("store-timeout", "Timeout for store stage")
 
+	// Resume filter identities
+	FetchResumeID = pipz.NewIdentity("fetch-resume", "Skips fetch stage when already checkpointed")
+	ParseResumeID = pipz.NewIdentity("parse-resume", "Skips parse stage when already checkpointed")
+	ChunkResumeID = pipz.NewIdentity("chunk-resume", "Skips chunk stage when already checkpointed")
+	EmbedResumeID = pipz.NewIdentity("embed-resume", "Skips embed stage when already checkpointed")
+	StoreResumeID = pipz.NewIdentity("store-resume", "Skips store stage when already checkpointed")
+
+	// Checkpoint identities
+	FetchCheckpointID = pipz.NewIdentity("fetch-checkpoint", "Records fetch stage completion")
+	ParseCheckpointID = pipz.NewIdentity("parse-checkpoint", "Records parse stage completion")
+	ChunkCheckpointID = pipz.NewIdentity("chunk-checkpoint", "Records chunk stage completion")
+	EmbedCheckpointID = pipz.NewIdentity("embed-checkpoint", "Records embed stage completion")
+	StoreCheckpointID = pipz.NewIdentity("store-checkpoint", "Records store stage completion")
+
 	// Cancellation check identity
 	CancelCheckID = pipz.NewIdentity("cancel-check", "Checks if job cancellation was requested")
 )
 
-// Pipeline configuration.
+// Default pipeline configuration.
 const (
 	// Retry attempts for each stage
 	DefaultRetries = 3
@@ -46,6 +61,59 @@ const (
 	StoreTimeout = 10 * time.Minute
 )
 
+// reliableStage is a stage wrapped with a timeout inside a retry.
+type reliableStage struct {
+	timeout *pipz.Timeout[*models.Job]
+	retry   *pipz.Retry[*models.Job]
+}
+
+// Long-lived stage wrappers, shared by every pipeline so they can be
+// retuned at runtime.
+var reliableStages map[models.JobStage]*reliableStage
+
+func init() {
+	wrap := func(stage pipz.Chainable[*models.Job], timeoutID, retryID pipz.Identity, timeout time.Duration) *reliableStage {
+		t := pipz.NewTimeout(timeoutID, stage, timeout)
+		return &reliableStage{timeout: t, retry: pipz.NewRetry(retryID, t, DefaultRetries)}
+	}
+
+	reliableStages = map[models.JobStage]*reliableStage{
+		models.JobStageFetch: wrap(pipz.Apply(FetchStageID, fetchStage), FetchTimeoutID, FetchRetryID, FetchTimeout),
+		models.JobStageParse: wrap(pipz.Apply(ParseStageID, parseStage), ParseTimeoutID, ParseRetryID, ParseTimeout),
+		models.JobStageChunk: wrap(pipz.Apply(ChunkStageID, chunkStage), ChunkTimeoutID, ChunkRetryID, ChunkTimeout),
+		models.JobStageEmbed: wrap(pipz.Apply(EmbedStageID, embedStage), EmbedTimeoutID, EmbedRetryID, EmbedTimeout),
+		models.JobStageStore: wrap(pipz.Apply(StoreStageID, storeStage), StoreTimeoutID, StoreRetryID, StoreTimeout),
+	}
+}
+
+// SetStageTimeouts updates the whole-stage timeouts.
+// Called by capacitor when config changes. Zero values are ignored.
+func SetStageTimeouts(fetch, parse, chunk, embed, store time.Duration) {
+	timeouts := map[models.JobStage]time.Duration{
+		models.JobStageFetch: fetch,
+		models.JobStageParse: parse,
+		models.JobStageChunk: chunk,
+		models.JobStageEmbed: embed,
+		models.JobStageStore: store,
+	}
+	for stage, d := range timeouts {
+		if d > 0 {
+			reliableStages[stage].timeout.SetDuration(d)
+		}
+	}
+}
+
+// SetStageRetries updates how many attempts each stage gets.
+// Called by capacitor when config changes. Zero is ignored.
+func SetStageRetries(attempts int) {
+	if attempts <= 0 {
+		return
+	}
+	for _, s := range reliableStages {
+		s.retry.SetMaxAttempts(attempts)
+	}
+}
+
 // ErrJobCancelled is returned when a job is cancelled by user.
 var ErrJobCancelled = errors.New("job cancelled by user")
 
@@ -67,45 +135,70 @@ func checkCancellation() pipz.Chainable[*models.Job] {
 	})
 }
 
+// resumable skips a stage the job has already checkpointed, so a job retried
+// from a checkpoint picks up at the stage that failed. Stages outside the
+// job's kind are skipped too.
+func resumable(id pipz.Identity, stage models.JobStage, processor pipz.Chainable[*models.Job]) pipz.Chainable[*models.Job] {
+	return pipz.NewFilter(id, func(_ context.Context, job *models.Job) bool {
+		return job.Runs(stage) && !job.Completed(stage)
+	}, processor)
+}
+
+// checkpoint returns a pipz chainable that records the stage as completed.
+func checkpoint(id pipz.Identity, stage models.JobStage) pipz.Chainable[*models.Job] {
+	return pipz.Apply(id, func(ctx context.Context, job *models.Job) (*models.Job, error) {
+		if !job.Runs(stage) || job.Completed(stage) {
+			return job, nil
+		}
+
+		jobs := sum.MustUse[contracts.Jobs](ctx)
+		if err := jobs.Checkpoint(ctx, job.ID, stage); err != nil {
+			return job, fmt.Errorf("checkpoint %s: %w", stage, err)
+		}
+
+		job.Checkpoint = &stage
+		return job, nil
+	})
+}
+
 // NewPipeline creates the ingestion pipeline as a pipz.Sequence.
-// Each stage is wrapped with timeout and retry for reliability.
+// Each stage is wrapped with timeout and retry for reliability, skipped when
+// already checkpointed, and checkpointed on success.
 // Cancellation checks are inserted between stages.
 func NewPipeline() *pipz.Sequence[*models.Job] {
-	// Create base stages
-	fetch := pipz.Apply(FetchStageID, fetchStage)
-	parse := pipz.Apply(ParseStageID, parseStage)
-	chunk := pipz.Apply(ChunkStageID, chunkStage)
-	embed := pipz.Apply(EmbedStageID, embedStage)
-	store := pipz.Apply(StoreStageID, storeStage)
-
 	// Cancellation check
 	cancelCheck := checkCancellation()
 
-	// Wrap with timeout
-	fetchWithTimeout := pipz.NewTimeout(FetchTimeoutID, fetch, FetchTimeout)
-	parseWithTimeout := pipz.NewTimeout(ParseTimeoutID, parse, ParseTimeout)
-	chunkWithTimeout := pipz.NewTimeout(ChunkTimeoutID, chunk, ChunkTimeout)
-	embedWithTimeout := pipz.NewTimeout(EmbedTimeoutID, embed, EmbedTimeout)
-	storeWithTimeout := pipz.NewTimeout(StoreTimeoutID, store, StoreTimeout)
-
-	// Wrap with retry
-	fetchReliable := pipz.NewRetry(FetchRetryID, fetchWithTimeout, DefaultRetries)
-	parseReliable := pipz.NewRetry(ParseRetryID, parseWithTimeout, DefaultRetries)
-	chunkReliable := pipz.NewRetry(ChunkRetryID, chunkWithTimeout, DefaultRetries)
-	embedReliable := pipz.NewRetry(EmbedRetryID, embedWithTimeout, DefaultRetries)
-	storeReliable := pipz.NewRetry(StoreRetryID, storeWithTimeout, DefaultRetries)
-
-	// Build sequence with cancellation checks between stages:
-	// fetch → [check] → parse → [check] → chunk → [check] → embed → [check] → store
+	// Stages wrapped with timeout and retry
+	fetchReliable := reliableStages[models.JobStageFetch].retry
+	parseReliable := reliableStages[models.JobStageParse].retry
+	chunkReliable := reliableStages[models.JobStageChunk].retry
+	embedReliable := reliableStages[models.JobStageEmbed].retry
+	storeReliable := reliableStages[models.JobStageStore].retry
+
+	// Skip stages completed by an earlier attempt
+	fetchResumable := resumable(FetchResumeID, models.JobStageFetch, fetchReliable)
+	parseResumable := resumable(ParseResumeID, models.JobStageParse, parseReliable)
+	chunkResumable := resumable(ChunkResumeID, models.JobStageChunk, chunkReliable)
+	embedResumable := resumable(EmbedResumeID, models.JobStageEmbed, embedReliable)
+	storeResumable := resumable(StoreResumeID, models.JobStageStore, storeReliable)
+
+	// Build sequence with checkpoints after and cancellation checks between stages:
+	// fetch → [cp] → [check] → parse → [cp] → [check] → chunk → [cp] → [check] → embed → [cp] → [check] → store → [cp]
 	return pipz.NewSequence(PipelineID,
-		fetchReliable,
+		fetchResumable,
+		checkpoint(FetchCheckpointID, models.JobStageFetch),
 		cancelCheck,
-		parseReliable,
+		parseResumable,
+		checkpoint(ParseCheckpointID, models.JobStageParse),
 		cancelCheck,
-		chunkReliable,
+		chunkResumable,
+		checkpoint(ChunkCheckpointID, models.JobStageChunk),
 		cancelCheck,
-		embedReliable,
+		embedResumable,
+		checkpoint(EmbedCheckpointID, models.JobStageEmbed),
 		cancelCheck,
-		storeReliable,
+		storeResumable,
+		checkpoint(StoreCheckpointID, models.JobStageStore),
 	)
 }
@@ -0,0 +1,160 @@
+//go:build testing
+
+package ingest
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/zoobzio/pipz"
+	"github.com/zoobzio/vicky/models"
+	vickytest "github.com/zoobzio/vicky/testing"
+)
+
+func TestResumable_SkipsCheckpointedStages(t *testing.T) {
+	var ran []models.JobStage
+	var recorded []models.JobStage
+
+	mj := &vickytest.MockJobs{
+		OnCheckpoint: func(ctx context.Context, id int64, stage models.JobStage) error {
+			recorded = append(recorded, stage)
+			return nil
+		},
+	}
+	ctx := vickytest.SetupRegistry(t, vickytest.WithJobs(mj))
+
+	stage := func(s models.JobStage) pipz.Chainable[*models.Job] {
+		return pipz.Apply(pipz.NewIdentity(string(s)+"-test", "test stage"), func(_ context.Context, job *models.Job) (*models.Job, error) {
+			ran = append(ran, s)
+			return job, nil
+		})
+	}
+
+	seq := pipz.NewSequence(pipz.NewIdentity("resume-test", "test pipeline"),
+		resumable(FetchResumeID, models.JobStageFetch, stage(models.JobStageFetch)),
+		checkpoint(FetchCheckpointID, models.JobStageFetch),
+		resumable(ParseResumeID, models.JobStageParse, stage(models.JobStageParse)),
+		checkpoint(ParseCheckpointID, models.JobStageParse),
+		resumable(ChunkResumeID, models.JobStageChunk, stage(models.JobStageChunk)),
+		checkpoint(ChunkCheckpointID, models.JobStageChunk),
+	)
+
+	job := vickytest.NewJob(t)
+	parse := models.JobStageParse
+	job.Checkpoint = &parse
+
+	result, err := seq.Process(ctx, job)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if len(ran) != 1 || ran[0] != models.JobStageChunk {
+		t.Errorf("ran stages %v, want only chunk", ran)
+	}
+	if len(recorded) != 1 || recorded[0] != models.JobStageChunk {
+		t.Errorf("recorded checkpoints %v, want only chunk", recorded)
+	}
+	if result.Checkpoint == nil || *result.Checkpoint != models.JobStageChunk {
+		t.Errorf("Checkpoint = %v, want chunk", result.Checkpoint)
+	}
+}
+
+func TestResumable_SkipsStagesOutsideKind(t *testing.T) {
+	var ran []models.JobStage
+	var recorded []models.JobStage
+
+	mj := &vickytest.MockJobs{
+		OnCheckpoint: func(ctx context.Context, id int64, stage models.JobStage) error {
+			recorded = append(recorded, stage)
+			return nil
+		},
+	}
+	ctx := vickytest.SetupRegistry(t, vickytest.WithJobs(mj))
+
+	stage := func(s models.JobStage) pipz.Chainable[*models.Job] {
+		return pipz.Apply(pipz.NewIdentity(string(s)+"-test", "test stage"), func(_ context.Context, job *models.Job) (*models.Job, error) {
+			ran = append(ran, s)
+			return job, nil
+		})
+	}
+
+	seq := pipz.NewSequence(pipz.NewIdentity("kind-test", "test pipeline"),
+		resumable(FetchResumeID, models.JobStageFetch, stage(models.JobStageFetch)),
+		checkpoint(FetchCheckpointID, models.JobStageFetch),
+		resumable(ParseResumeID, models.JobStageParse, stage(models.JobStageParse)),
+		checkpoint(ParseCheckpointID, models.JobStageParse),
+		resumable(ChunkResumeID, models.JobStageChunk, stage(models.JobStageChunk)),
+		checkpoint(ChunkCheckpointID, models.JobStageChunk),
+		resumable(EmbedResumeID, models.JobStageEmbed, stage(models.JobStageEmbed)),
+		checkpoint(EmbedCheckpointID, models.JobStageEmbed),
+	)
+
+	job := vickytest.NewJob(t)
+	job.Kind = models.JobKindRebuildSCIP
+
+	if _, err := seq.Process(ctx, job); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	want := []models.JobStage{models.JobStageParse, models.JobStageEmbed}
+	if fmt.Sprint(ran) != fmt.Sprint(want) {
+		t.Errorf("ran stages %v, want %v", ran, want)
+	}
+	if fmt.Sprint(recorded) != fmt.Sprint(want) {
+		t.Errorf("recorded checkpoints %v, want %v", recorded, want)
+	}
+}
+
+func TestCheckpoint_NotRecordedOnFailure(t *testing.T) {
+	mj := &vickytest.MockJobs{
+		OnCheckpoint: func(ctx context.Context, id int64, stage models.JobStage) error {
+			t.Errorf("checkpoint %q recorded after a failed stage", stage)
+			return nil
+		},
+	}
+	ctx := vickytest.SetupRegistry(t, vickytest.WithJobs(mj))
+
+	failing := pipz.Apply(pipz.NewIdentity("failing-test", "test stage"), func(_ context.Context, job *models.Job) (*models.Job, error) {
+		return job, context.DeadlineExceeded
+	})
+
+	seq := pipz.NewSequence(pipz.NewIdentity("failure-test", "test pipeline"),
+		resumable(FetchResumeID, models.JobStageFetch, failing),
+		checkpoint(FetchCheckpointID, models.JobStageFetch),
+	)
+
+	if _, err := seq.Process(ctx, vickytest.NewJob(t)); err == nil {
+		t.Fatal("expected error, got nil")
+	}
+}
+
+func TestSetStageTimeouts(t *testing.T) {
+	t.Cleanup(func() {
+		SetStageTimeouts(FetchTimeout, ParseTimeout, ChunkTimeout, EmbedTimeout, StoreTimeout)
+	})
+
+	SetStageTimeouts(0, 0, 0, 2*time.Hour, 0)
+
+	if got := reliableStages[models.JobStageEmbed].timeout.GetDuration(); got != 2*time.Hour {
+		t.Errorf("embed timeout = %v, want 2h", got)
+	}
+	// Zero leaves the current value in place
+	if got := reliableStages[models.JobStageFetch].timeout.GetDuration(); got != FetchTimeout {
+		t.Errorf("fetch timeout = %v, want %v", got, FetchTimeout)
+	}
+}
+
+func TestSetStageRetries(t *testing.T) {
+	t.Cleanup(func() { SetStageRetries(DefaultRetries) })
+
+	SetStageRetries(5)
+	SetStageRetries(0) // ignored
+
+	for stage, s := range reliableStages {
+		if got := s.retry.GetMaxAttempts(); got != 5 {
+			t.Errorf("%s attempts = %d, want 5", stage, got)
+		}
+	}
+}
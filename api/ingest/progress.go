@@ -0,0 +1,121 @@
+package ingest
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/zoobzio/capitan"
+	"github.com/zoobzio/sum"
+	"github.com/zoobzio/vicky/api/contracts"
+	"github.com/zoobzio/vicky/api/events"
+	"github.com/zoobzio/vicky/models"
+)
+
+// ProgressInterval is the minimum time between persisted progress updates
+// while a stage is running. Stage start and finish are always persisted.
+const ProgressInterval = time.Second
+
+// progress tracks items processed within a stage and persists the job's
+// progress, throttled to at most one write per ProgressInterval.
+// Safe for concurrent use by a stage's pool goroutines.
+type progress struct {
+	job   *models.Job
+	total int
+
+	mu        sync.Mutex
+	processed int
+	last      time.Time
+}
+
+// trackProgress starts tracking a stage with the given number of items and
+// persists the stage's starting point.
+func trackProgress(ctx context.Context, job *models.Job, total int) *progress {
+	p := &progress{job: job, total: total}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.flush(ctx)
+	return p
+}
+
+// Add records n more processed items, persisting if the interval has elapsed.
+func (p *progress) Add(ctx context.Context, n int) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	p.processed += n
+	if time.Since(p.last) >= ProgressInterval {
+		p.flush(ctx)
+	}
+}
+
+// SetTotal replaces the stage's item count, for stages that only settle it
+// once under way, and persists it.
+func (p *progress) SetTotal(ctx context.Context, total int) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	p.total = total
+	p.flush(ctx)
+}
+
+// Finish records the stage's final processed count and persists it.
+func (p *progress) Finish(ctx context.Context, processed int) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	p.processed = processed
+	p.flush(ctx)
+}
+
+// flush writes the current counts to the job and the jobs table.
+// Failures are logged rather than returned: progress is informational and
+// must never fail the stage. Callers hold p.mu.
+func (p *progress) flush(ctx context.Context) {
+	jobs := sum.MustUse[contracts.Jobs](ctx)
+
+	p.last = time.Now()
+	p.job.ItemsTotal = p.total
+	p.job.ItemsProcessed = p.processed
+	p.job.Progress = overallProgress(p.job.Stage, p.processed, p.total)
+
+	if err := jobs.UpdateProgress(ctx, p.job.ID, p.job.Stage, p.job.Progress, p.total, p.processed); err != nil {
+		capitan.Warn(ctx, events.ProgressErrorSignal,
+			events.JobIDKey.Field(p.job.ID),
+			events.ErrorKey.Field(err),
+		)
+		return
+	}
+
+	events.Job.Progress.Emit(ctx, events.JobProgressEvent{
+		JobID:          p.job.ID,
+		Stage:          p.job.Stage,
+		Status:         models.JobStatusRunning,
+		Progress:       p.job.Progress,
+		ItemsTotal:     p.total,
+		ItemsProcessed: p.processed,
+	})
+}
+
+// overallProgress converts a stage's item counts into a job-wide percentage.
+// Every stage carries an equal share of the 0-100 range.
+func overallProgress(stage models.JobStage, processed, total int) int {
+	idx := stage.Index()
+	if idx < 0 {
+		return 0
+	}
+
+	share := 100 / len(models.JobStages)
+	done := share
+	if total > 0 && processed < total {
+		done = share * processed / total
+	}
+
+	pct := idx*share + done
+	if pct > 99 {
+		// 100 is reserved for a completed job
+		pct = 99
+	}
+	return pct
+}
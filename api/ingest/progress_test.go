@@ -0,0 +1,151 @@
+//go:build testing
+
+package ingest
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+
+	"github.com/zoobzio/vicky/models"
+	vickytest "github.com/zoobzio/vicky/testing"
+)
+
+func TestOverallProgress(t *testing.T) {
+	tests := []struct {
+		name      string
+		stage     models.JobStage
+		processed int
+		total     int
+		want      int
+	}{
+		{"fetch start", models.JobStageFetch, 0, 10, 0},
+		{"fetch half", models.JobStageFetch, 5, 10, 10},
+		{"fetch done", models.JobStageFetch, 10, 10, 20},
+		{"parse start", models.JobStageParse, 0, 4, 20},
+		{"embed half", models.JobStageEmbed, 50, 100, 70},
+		{"empty stage counts as done", models.JobStageChunk, 0, 0, 60},
+		{"store done capped below 100", models.JobStageStore, 1, 1, 99},
+		{"unknown stage", models.JobStage("bogus"), 1, 2, 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := overallProgress(tt.stage, tt.processed, tt.total); got != tt.want {
+				t.Errorf("overallProgress(%q, %d, %d) = %d, want %d", tt.stage, tt.processed, tt.total, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestProgress_Throttled(t *testing.T) {
+	type update struct {
+		progress, total, processed int
+	}
+
+	var mu sync.Mutex
+	var updates []update
+
+	mj := &vickytest.MockJobs{
+		OnUpdateProgress: func(ctx context.Context, id int64, stage models.JobStage, progress int, itemsTotal int, itemsProcessed int) error {
+			mu.Lock()
+			defer mu.Unlock()
+			updates = append(updates, update{progress, itemsTotal, itemsProcessed})
+			return nil
+		},
+	}
+	ctx := vickytest.SetupRegistry(t, vickytest.WithJobs(mj))
+
+	job := vickytest.NewJob(t)
+	job.Stage = models.JobStageChunk
+
+	tracker := trackProgress(ctx, job, 100)
+	for i := 0; i < 100; i++ {
+		tracker.Add(ctx, 1)
+	}
+	tracker.Finish(ctx, 100)
+
+	mu.Lock()
+	defer mu.Unlock()
+
+	// Start and finish are always written; the adds fall inside one interval
+	if len(updates) != 2 {
+		t.Fatalf("updates = %d, want 2", len(updates))
+	}
+	if updates[0] != (update{40, 100, 0}) {
+		t.Errorf("first update = %+v, want {40 100 0}", updates[0])
+	}
+	if updates[1] != (update{60, 100, 100}) {
+		t.Errorf("last update = %+v, want {60 100 100}", updates[1])
+	}
+	if job.ItemsTotal != 100 || job.ItemsProcessed != 100 || job.Progress != 60 {
+		t.Errorf("job = total %d, processed %d, progress %d; want 100, 100, 60", job.ItemsTotal, job.ItemsProcessed, job.Progress)
+	}
+}
+
+func TestProgress_PersistErrorIgnored(t *testing.T) {
+	mj := &vickytest.MockJobs{
+		OnUpdateProgress: func(ctx context.Context, id int64, stage models.JobStage, progress int, itemsTotal int, itemsProcessed int) error {
+			return errors.New("db down")
+		},
+	}
+	ctx := vickytest.SetupRegistry(t, vickytest.WithJobs(mj))
+
+	job := vickytest.NewJob(t)
+	tracker := trackProgress(ctx, job, 2)
+	tracker.Finish(ctx, 2)
+
+	// The job still carries the counts even when persisting fails
+	if job.ItemsProcessed != 2 {
+		t.Errorf("ItemsProcessed = %d, want 2", job.ItemsProcessed)
+	}
+}
+
+func TestChunkStage_ReportsProgress(t *testing.T) {
+	docs := []*models.Document{
+		vickytest.NewDocument(t, 1, "main.go"),
+		vickytest.NewDocument(t, 2, "utils.go"),
+	}
+
+	var mu sync.Mutex
+	var last struct{ total, processed int }
+
+	md := &vickytest.MockDocuments{
+		OnListByUserRepoAndTag: func(ctx context.Context, userID int64, owner, repoName, tag string) ([]*models.Document, error) {
+			return docs, nil
+		},
+	}
+	mj := &vickytest.MockJobs{
+		OnUpdateProgress: func(ctx context.Context, id int64, stage models.JobStage, progress int, itemsTotal int, itemsProcessed int) error {
+			mu.Lock()
+			defer mu.Unlock()
+			if stage != models.JobStageChunk {
+				t.Errorf("stage = %q, want %q", stage, models.JobStageChunk)
+			}
+			last.total, last.processed = itemsTotal, itemsProcessed
+			return nil
+		},
+	}
+
+	ctx := vickytest.SetupRegistry(t,
+		vickytest.WithJobs(mj),
+		vickytest.WithFileReports(&vickytest.MockFileReports{}),
+		vickytest.WithDocuments(md),
+		vickytest.WithBlobs(&vickytest.MockBlobs{}),
+		vickytest.WithChunker(&vickytest.MockChunker{}),
+		vickytest.WithChunks(&vickytest.MockChunks{}),
+		vickytest.WithIngestionConfigs(&vickytest.MockIngestionConfigs{}),
+	)
+
+	job := vickytest.NewJob(t)
+	if _, err := chunkStage(ctx, job); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+	if last.total != 2 || last.processed != 2 {
+		t.Errorf("last persisted = %d/%d, want 2/2", last.processed, last.total)
+	}
+}
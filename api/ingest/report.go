@@ -0,0 +1,167 @@
+package ingest
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/zoobzio/capitan"
+	"github.com/zoobzio/sum"
+	"github.com/zoobzio/vicky/api/contracts"
+	"github.com/zoobzio/vicky/api/events"
+	"github.com/zoobzio/vicky/models"
+)
+
+// defaultErrorBudget is the fraction of a stage's files allowed to fail
+// before the stage itself fails.
+const defaultErrorBudget = 0.05
+
+// Per-stage error budgets, set by the capacitors.
+var (
+	fetchErrorBudget = newErrorBudget(defaultErrorBudget)
+	parseErrorBudget = newErrorBudget(defaultErrorBudget)
+	chunkErrorBudget = newErrorBudget(defaultErrorBudget)
+)
+
+// errorBudget is the fraction of a stage's files that may fail without
+// failing the stage. Safe for concurrent use.
+type errorBudget struct {
+	bits atomic.Uint64
+}
+
+func newErrorBudget(fraction float64) *errorBudget {
+	b := &errorBudget{}
+	b.Set(fraction)
+	return b
+}
+
+// Set updates the budget. Values outside 0-1 are clamped.
+func (b *errorBudget) Set(fraction float64) {
+	b.bits.Store(math.Float64bits(math.Max(0, math.Min(1, fraction))))
+}
+
+// Load returns the current budget.
+func (b *errorBudget) Load() float64 {
+	return math.Float64frombits(b.bits.Load())
+}
+
+// Check returns an error when failed out of total files exceeds the budget.
+// The first failure is wrapped so the job error carries a concrete cause.
+func (b *errorBudget) Check(stage models.JobStage, failed, total int, first error) error {
+	budget := b.Load()
+	if failed == 0 || float64(failed) <= budget*float64(total) {
+		return nil
+	}
+	if first == nil {
+		return fmt.Errorf("%s: %d of %d files failed, over the %g error budget", stage, failed, total, budget)
+	}
+	return fmt.Errorf("%s: %d of %d files failed, over the %g error budget: %w", stage, failed, total, budget, first)
+}
+
+// SetFetchErrorBudget updates the fraction of files the fetch stage may fail.
+// Called by capacitor when config changes.
+func SetFetchErrorBudget(fraction float64) {
+	fetchErrorBudget.Set(fraction)
+}
+
+// SetParseErrorBudget updates the fraction of files the parse stage may fail.
+// Called by capacitor when config changes.
+func SetParseErrorBudget(fraction float64) {
+	parseErrorBudget.Set(fraction)
+}
+
+// SetChunkErrorBudget updates the fraction of files the chunk stage may fail.
+// Called by capacitor when config changes.
+func SetChunkErrorBudget(fraction float64) {
+	chunkErrorBudget.Set(fraction)
+}
+
+// fileReport collects a stage's per-file outcomes and persists them once
+// the stage finishes. Safe for concurrent use by a stage's pool goroutines.
+type fileReport struct {
+	job   *models.Job
+	stage models.JobStage
+
+	mu      sync.Mutex
+	entries []*models.FileReport
+	failed  int
+	first   error
+}
+
+// newFileReport starts a report for the job's current stage.
+func newFileReport(job *models.Job, stage models.JobStage) *fileReport {
+	return &fileReport{job: job, stage: stage}
+}
+
+// Add records a file's outcome. Empty rule and reason are left unset.
+func (r *fileReport) Add(path string, outcome models.FileOutcome, rule, reason string, size int64) {
+	entry := &models.FileReport{
+		JobID:   r.job.ID,
+		UserID:  r.job.UserID,
+		Stage:   r.stage,
+		Path:    path,
+		Outcome: outcome,
+		Size:    size,
+	}
+	if rule != "" {
+		entry.Rule = &rule
+	}
+	if reason != "" {
+		entry.Reason = &reason
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.entries = append(r.entries, entry)
+}
+
+// Fail records a failed file, keeping the first error for the budget check.
+func (r *fileReport) Fail(path string, err error) {
+	r.Add(path, models.FileOutcomeFailed, "", err.Error(), 0)
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.failed++
+	if r.first == nil {
+		r.first = err
+	}
+}
+
+// Failed returns the number of failed files and the first failure.
+func (r *fileReport) Failed() (int, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.failed, r.first
+}
+
+// reportSaveTimeout bounds how long saving a stage's report may take.
+const reportSaveTimeout = time.Minute
+
+// Save replaces the stage's persisted entries with the collected ones in one
+// transaction. It runs detached from ctx's deadline so a stage that timed out
+// or was cancelled still records which files it got through. Failures are
+// logged rather than returned: the report is informational and must never
+// fail the stage.
+func (r *fileReport) Save(ctx context.Context) {
+	reports := sum.MustUse[contracts.FileReports](ctx)
+
+	ctx, cancel := context.WithTimeout(context.WithoutCancel(ctx), reportSaveTimeout)
+	defer cancel()
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if err := reports.ReplaceByJobAndStage(ctx, r.job.ID, r.stage, r.entries); err != nil {
+		r.logError(ctx, err)
+	}
+}
+
+func (r *fileReport) logError(ctx context.Context, err error) {
+	capitan.Warn(ctx, events.ReportErrorSignal,
+		events.JobIDKey.Field(r.job.ID),
+		events.ErrorKey.Field(err),
+	)
+}
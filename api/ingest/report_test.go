@@ -0,0 +1,266 @@
+//go:build testing
+
+package ingest
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/zoobzio/grub"
+	"github.com/zoobzio/vicky/external/github"
+	"github.com/zoobzio/vicky/models"
+	vickytest "github.com/zoobzio/vicky/testing"
+)
+
+// recordReports returns a MockFileReports capturing saved entries by path.
+func recordReports() (*vickytest.MockFileReports, func() map[string]*models.FileReport) {
+	var mu sync.Mutex
+	saved := make(map[string]*models.FileReport)
+	mr := &vickytest.MockFileReports{
+		OnReplaceByJobAndStage: func(ctx context.Context, jobID int64, stage models.JobStage, reports []*models.FileReport) error {
+			mu.Lock()
+			defer mu.Unlock()
+			for _, report := range reports {
+				saved[report.Path] = report
+			}
+			return nil
+		},
+	}
+	return mr, func() map[string]*models.FileReport {
+		mu.Lock()
+		defer mu.Unlock()
+		return saved
+	}
+}
+
+func TestErrorBudget_Check(t *testing.T) {
+	cause := errors.New("boom")
+
+	tests := []struct {
+		name    string
+		budget  float64
+		failed  int
+		total   int
+		wantErr bool
+	}{
+		{"no failures", 0, 0, 10, false},
+		{"zero budget fails on first", 0, 1, 100, true},
+		{"within budget", 0.05, 5, 100, false},
+		{"over budget", 0.05, 6, 100, true},
+		{"single file failing", 0.05, 1, 1, true},
+		{"full budget", 1, 10, 10, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := newErrorBudget(tt.budget).Check(models.JobStageChunk, tt.failed, tt.total, cause)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Check() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if err != nil && !errors.Is(err, cause) {
+				t.Errorf("error %q does not wrap the first failure", err)
+			}
+		})
+	}
+}
+
+func TestErrorBudget_Clamped(t *testing.T) {
+	if got := newErrorBudget(2).Load(); got != 1 {
+		t.Errorf("Load() = %v, want 1", got)
+	}
+	if got := newErrorBudget(-1).Load(); got != 0 {
+		t.Errorf("Load() = %v, want 0", got)
+	}
+}
+
+func TestFileReport_SaveAfterCancel(t *testing.T) {
+	var got []*models.FileReport
+	mr := &vickytest.MockFileReports{
+		OnReplaceByJobAndStage: func(ctx context.Context, jobID int64, stage models.JobStage, reports []*models.FileReport) error {
+			if err := ctx.Err(); err != nil {
+				return err
+			}
+			if stage != models.JobStageChunk {
+				t.Errorf("stage = %s, want chunk", stage)
+			}
+			got = reports
+			return nil
+		},
+	}
+	ctx := vickytest.SetupRegistry(t, vickytest.WithFileReports(mr))
+	ctx, cancel := context.WithCancel(ctx)
+	cancel()
+
+	report := newFileReport(vickytest.NewJob(t), models.JobStageChunk)
+	report.Add("main.go", models.FileOutcomeIncluded, "", "", 12)
+	report.Fail("broken.go", errors.New("boom"))
+	report.Save(ctx)
+
+	if len(got) != 2 {
+		t.Fatalf("saved %d entries, want 2", len(got))
+	}
+}
+
+func TestFetchStage_ReportsFiles(t *testing.T) {
+	version := vickytest.NewVersion(t)
+
+	mg := &vickytest.MockGitHub{
+		OnGetTree: func(ctx context.Context, token, owner, repo, ref string) ([]github.TreeEntry, error) {
+			return []github.TreeEntry{
+				{Path: "main.go", Type: "blob", Size: 100},
+				{Path: "vendor/lib.go", Type: "blob", Size: 50},
+				{Path: "huge.go", Type: "blob", Size: 2 * 1024 * 1024},
+				{Path: "logo.png", Type: "blob", Size: 10},
+			}, nil
+		},
+		OnStreamArchive: streamFiles(map[string]string{"main.go": "package main"}),
+	}
+	mv := &vickytest.MockVersions{
+		OnGet: func(ctx context.Context, key string) (*models.Version, error) {
+			return version, nil
+		},
+	}
+	mr, saved := recordReports()
+
+	ctx := vickytest.SetupRegistry(t,
+		vickytest.WithJobs(&vickytest.MockJobs{}),
+		vickytest.WithFileReports(mr),
+		vickytest.WithUsers(&vickytest.MockUsers{}),
+		vickytest.WithGitHub(mg),
+		vickytest.WithIngestionConfigs(&vickytest.MockIngestionConfigs{}),
+		vickytest.WithVersions(mv),
+		vickytest.WithBlobs(&vickytest.MockBlobs{}),
+		vickytest.WithDocuments(&vickytest.MockDocuments{}),
+	)
+
+	if _, err := fetchStage(ctx, vickytest.NewJob(t)); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	tests := []struct {
+		path    string
+		outcome models.FileOutcome
+		rule    string
+	}{
+		{"main.go", models.FileOutcomeIncluded, ""},
+		{"vendor/lib.go", models.FileOutcomeExcluded, "vendor/**"},
+		{"huge.go", models.FileOutcomeExcluded, models.ExcludeRuleMaxFileSize},
+		{"logo.png", models.FileOutcomeExcluded, models.ExcludeRuleExtension},
+	}
+
+	entries := saved()
+	for _, tt := range tests {
+		entry, ok := entries[tt.path]
+		if !ok {
+			t.Errorf("%s: not reported", tt.path)
+			continue
+		}
+		if entry.Stage != models.JobStageFetch || entry.Outcome != tt.outcome {
+			t.Errorf("%s: %s/%s, want fetch/%s", tt.path, entry.Stage, entry.Outcome, tt.outcome)
+		}
+		var rule string
+		if entry.Rule != nil {
+			rule = *entry.Rule
+		}
+		if rule != tt.rule {
+			t.Errorf("%s: rule = %q, want %q", tt.path, rule, tt.rule)
+		}
+	}
+}
+
+func TestChunkStage_WithinErrorBudget(t *testing.T) {
+	SetChunkErrorBudget(0.5)
+	t.Cleanup(func() { SetChunkErrorBudget(defaultErrorBudget) })
+
+	docs := []*models.Document{
+		vickytest.NewDocument(t, 1, "main.go"),
+		vickytest.NewDocument(t, 2, "broken.go"),
+	}
+
+	md := &vickytest.MockDocuments{
+		OnListByUserRepoAndTag: func(ctx context.Context, userID int64, owner, repoName, tag string) ([]*models.Document, error) {
+			return docs, nil
+		},
+	}
+	mb := &vickytest.MockBlobs{
+		OnGetByPath: func(ctx context.Context, userID int64, owner, repo, tag, path string) (*grub.Object[models.Blob], error) {
+			if path == "broken.go" {
+				return nil, fmt.Errorf("blob missing")
+			}
+			return &grub.Object[models.Blob]{
+				Key:  path,
+				Data: models.Blob{Path: path, Content: "content", Owner: owner, Repo: repo, Tag: tag},
+			}, nil
+		},
+	}
+	mr, saved := recordReports()
+
+	ctx := vickytest.SetupRegistry(t,
+		vickytest.WithJobs(&vickytest.MockJobs{}),
+		vickytest.WithFileReports(mr),
+		vickytest.WithDocuments(md),
+		vickytest.WithBlobs(mb),
+		vickytest.WithChunker(&vickytest.MockChunker{}),
+		vickytest.WithChunks(&vickytest.MockChunks{}),
+		vickytest.WithIngestionConfigs(&vickytest.MockIngestionConfigs{}),
+	)
+
+	if _, err := chunkStage(ctx, vickytest.NewJob(t)); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	entry, ok := saved()["broken.go"]
+	if !ok {
+		t.Fatal("broken.go not reported")
+	}
+	if entry.Outcome != models.FileOutcomeFailed || entry.Stage != models.JobStageChunk {
+		t.Errorf("broken.go = %s/%s, want chunk/failed", entry.Stage, entry.Outcome)
+	}
+	if entry.Reason == nil || !strings.Contains(*entry.Reason, "blob missing") {
+		t.Errorf("reason = %v, want it to mention the blob error", entry.Reason)
+	}
+}
+
+func TestChunkStage_ReportsSkipped(t *testing.T) {
+	docs := []*models.Document{
+		vickytest.NewDocument(t, 1, "main.go"),
+	}
+
+	md := &vickytest.MockDocuments{
+		OnListByUserRepoAndTag: func(ctx context.Context, userID int64, owner, repoName, tag string) ([]*models.Document, error) {
+			return docs, nil
+		},
+	}
+	mch := &vickytest.MockChunker{
+		OnSupports: func(language string) bool {
+			return false
+		},
+	}
+	mr, saved := recordReports()
+
+	ctx := vickytest.SetupRegistry(t,
+		vickytest.WithJobs(&vickytest.MockJobs{}),
+		vickytest.WithFileReports(mr),
+		vickytest.WithDocuments(md),
+		vickytest.WithBlobs(&vickytest.MockBlobs{}),
+		vickytest.WithChunker(mch),
+		vickytest.WithChunks(&vickytest.MockChunks{}),
+		vickytest.WithIngestionConfigs(&vickytest.MockIngestionConfigs{}),
+	)
+
+	if _, err := chunkStage(ctx, vickytest.NewJob(t)); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	entry, ok := saved()["main.go"]
+	if !ok {
+		t.Fatal("main.go not reported")
+	}
+	if entry.Outcome != models.FileOutcomeSkipped {
+		t.Errorf("Outcome = %q, want %q", entry.Outcome, models.FileOutcomeSkipped)
+	}
+}
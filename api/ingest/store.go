@@ -4,6 +4,7 @@ import (
 	"context"
 	"fmt"
 
+	"github.com/zoobzio/capitan"
 	"github.com/zoobzio/pipz"
 	"github.com/zoobzio/sum"
 	"github.com/zoobzio/vicky/api/contracts"
@@ -15,6 +16,8 @@ import (
 var StoreStageID = pipz.NewIdentity("store", "Finalizes ingestion and marks version ready")
 
 // storeStage finalizes ingestion by marking the version as ready.
+// A staging version is swapped in for the live version it replaces, and a
+// rebuild's SCIP data for the version's current data.
 func storeStage(ctx context.Context, job *models.Job) (*models.Job, error) {
 	events.Ingest.Store.Started.Emit(ctx, events.StoreEvent{
 		RepositoryID: job.RepositoryID,
@@ -27,15 +30,106 @@ func storeStage(ctx context.Context, job *models.Job) (*models.Job, error) {
 	// Resolve dependencies
 	versions := sum.MustUse[contracts.Versions](ctx)
 
-	// Mark version as ready
-	if _, err := versions.UpdateStatus(ctx, job.VersionID, models.VersionStatusReady, nil); err != nil {
-		return job, fmt.Errorf("update version status: %w", err)
+	version, err := versions.Get(ctx, idToKey(job.VersionID))
+	if err != nil {
+		return job, fmt.Errorf("get version: %w", err)
+	}
+
+	var replacedID int64
+	switch {
+	case version.Staging():
+		// Swap the staging version in for the live one
+		replacedID = *version.ReplacesID
+		stagingTag := version.DataTag()
+
+		promoted, err := versions.Promote(ctx, version.ID)
+		if err != nil {
+			return job, fmt.Errorf("promote version: %w", err)
+		}
+		job.Tag = promoted.Tag
+
+		// The swap is committed; a failed blob move leaves the old
+		// generation's blobs live and the staged ones in place
+		if err := promoteBlobs(ctx, job, stagingTag); err != nil {
+			capitan.Error(ctx, events.StoreBlobCleanupErrorSignal,
+				events.JobIDKey.Field(job.ID),
+				events.VersionIDKey.Field(job.VersionID),
+				events.ErrorKey.Field(err),
+			)
+		}
+
+		// The replaced version's raw index is no longer reachable
+		scipIndexes := sum.MustUse[contracts.SCIPIndexes](ctx)
+		if err := scipIndexes.Delete(ctx, job.UserID, job.Owner, job.RepoName, replacedID); err != nil {
+			capitan.Error(ctx, events.StoreIndexCleanupErrorSignal,
+				events.JobIDKey.Field(job.ID),
+				events.VersionIDKey.Field(replacedID),
+				events.ErrorKey.Field(err),
+			)
+		}
+	case job.Kind == models.JobKindRebuildSCIP:
+		// Swap the rebuilt SCIP rows and symbols in for the version's current ones
+		if err := versions.ReplaceSCIP(ctx, version.ID, job.Tag); err != nil {
+			return job, fmt.Errorf("swap rebuilt scip data: %w", err)
+		}
+		job.Tag = version.Tag
+	default:
+		// Mark version as ready
+		if _, err := versions.UpdateStatus(ctx, job.VersionID, models.VersionStatusReady, nil); err != nil {
+			return job, fmt.Errorf("update version status: %w", err)
+		}
 	}
 
 	events.Ingest.Store.Completed.Emit(ctx, events.StoreEvent{
-		RepositoryID: job.RepositoryID,
-		VersionID:    job.VersionID,
+		RepositoryID:      job.RepositoryID,
+		VersionID:         job.VersionID,
+		ReplacedVersionID: replacedID,
 	})
 
 	return job, nil
 }
+
+// promoteBlobs moves a promoted version's blobs, manifests included, from
+// its staging tag to the real tag. Staged blobs overwrite the old
+// generation's first and paths the new generation dropped are removed after,
+// so the live tag never lacks a blob. The staging tag is only deleted once
+// everything is copied, so a failure leaves no blob unreachable.
+func promoteBlobs(ctx context.Context, job *models.Job, stagingTag string) error {
+	blobs := sum.MustUse[contracts.Blobs](ctx)
+
+	staged, err := blobs.ListPathsByVersion(ctx, job.UserID, job.Owner, job.RepoName, stagingTag)
+	if err != nil {
+		return fmt.Errorf("list staged blobs: %w", err)
+	}
+	live, err := blobs.ListPathsByVersion(ctx, job.UserID, job.Owner, job.RepoName, job.Tag)
+	if err != nil {
+		return fmt.Errorf("list live blobs: %w", err)
+	}
+
+	for _, path := range staged {
+		obj, err := blobs.GetByPath(ctx, job.UserID, job.Owner, job.RepoName, stagingTag, path)
+		if err != nil {
+			return fmt.Errorf("get staged blob %s: %w", path, err)
+		}
+		blob := obj.Data
+		blob.Tag = job.Tag
+		if err := blobs.PutBlob(ctx, job.UserID, &blob); err != nil {
+			return fmt.Errorf("store blob %s: %w", path, err)
+		}
+	}
+
+	keep := make(map[string]bool, len(staged))
+	for _, path := range staged {
+		keep[path] = true
+	}
+	for _, path := range live {
+		if keep[path] {
+			continue
+		}
+		if err := blobs.DeleteByPath(ctx, job.UserID, job.Owner, job.RepoName, job.Tag, path); err != nil {
+			return fmt.Errorf("delete old blob %s: %w", path, err)
+		}
+	}
+
+	return blobs.DeleteByVersion(ctx, job.UserID, job.Owner, job.RepoName, stagingTag)
+}
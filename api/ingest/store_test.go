@@ -4,6 +4,7 @@ package ingest
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"strings"
 	"testing"
@@ -70,3 +71,159 @@ func TestStoreStage_UpdateStatusError(t *testing.T) {
 		t.Error("expected job to be returned even on error")
 	}
 }
+
+func TestStoreStage_PromotesStaging(t *testing.T) {
+	live := int64(9)
+	staging := vickytest.NewVersion(t)
+	staging.ReplacesID = &live
+	stagingTag := staging.DataTag()
+
+	var promoted int64
+	mv := &vickytest.MockVersions{
+		OnGet: func(ctx context.Context, key string) (*models.Version, error) {
+			return staging, nil
+		},
+		OnPromote: func(ctx context.Context, id int64) (*models.Version, error) {
+			promoted = id
+			return &models.Version{ID: id, Tag: "v1.0.0", Status: models.VersionStatusReady}, nil
+		},
+		OnUpdateStatus: func(ctx context.Context, id int64, status models.VersionStatus, versionErr *string) (*models.Version, error) {
+			t.Error("staging version should be promoted, not marked ready in place")
+			return nil, nil
+		},
+	}
+	var events []string
+	mb := &vickytest.MockBlobs{
+		OnListPathsByVersion: func(ctx context.Context, userID int64, owner, repo, tag string) ([]string, error) {
+			if tag == stagingTag {
+				return []string{"main.go", "go.mod"}, nil
+			}
+			return []string{"main.go", "old.go"}, nil
+		},
+		OnPutBlob: func(ctx context.Context, userID int64, blob *models.Blob) error {
+			events = append(events, fmt.Sprintf("put %s/%s", blob.Tag, blob.Path))
+			return nil
+		},
+		OnDeleteByPath: func(ctx context.Context, userID int64, owner, repo, tag, path string) error {
+			events = append(events, fmt.Sprintf("delete %s/%s", tag, path))
+			return nil
+		},
+		OnDeleteByVersion: func(ctx context.Context, userID int64, owner, repo, tag string) error {
+			events = append(events, "delete "+tag)
+			return nil
+		},
+	}
+
+	var deletedIndex int64
+	mx := &vickytest.MockSCIPIndexes{
+		OnDelete: func(ctx context.Context, userID int64, owner, repo string, versionID int64) error {
+			deletedIndex = versionID
+			return nil
+		},
+	}
+
+	ctx := vickytest.SetupRegistry(t,
+		vickytest.WithVersions(mv),
+		vickytest.WithBlobs(mb),
+		vickytest.WithSCIPIndexes(mx),
+	)
+	job := vickytest.NewJob(t)
+	job.Tag = stagingTag
+
+	result, err := storeStage(ctx, job)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if promoted != staging.ID {
+		t.Errorf("promoted version %d, want %d", promoted, staging.ID)
+	}
+	if result.Tag != "v1.0.0" {
+		t.Errorf("Tag = %q, want v1.0.0 after promotion", result.Tag)
+	}
+	want := []string{"put v1.0.0/main.go", "put v1.0.0/go.mod", "delete v1.0.0/old.go", "delete " + stagingTag}
+	if fmt.Sprint(events) != fmt.Sprint(want) {
+		t.Errorf("blob operations = %v, want %v", events, want)
+	}
+	if deletedIndex != live {
+		t.Errorf("deleted scip index of version %d, want the replaced version %d", deletedIndex, live)
+	}
+}
+
+func TestStoreStage_SwapsRebuild(t *testing.T) {
+	version := vickytest.NewVersion(t)
+	version.Status = models.VersionStatusReady
+	rebuildTag := models.RebuildTag(version.Tag, version.ID)
+
+	var swappedTag string
+	mv := &vickytest.MockVersions{
+		OnGet: func(ctx context.Context, key string) (*models.Version, error) {
+			return version, nil
+		},
+		OnReplaceSCIP: func(ctx context.Context, id int64, dataTag string) error {
+			if id != version.ID {
+				t.Errorf("ReplaceSCIP id = %d, want %d", id, version.ID)
+			}
+			swappedTag = dataTag
+			return nil
+		},
+		OnUpdateStatus: func(ctx context.Context, id int64, status models.VersionStatus, versionErr *string) (*models.Version, error) {
+			t.Error("UpdateStatus called for a rebuild")
+			return version, nil
+		},
+	}
+
+	ctx := vickytest.SetupRegistry(t, vickytest.WithVersions(mv))
+	job := vickytest.NewJob(t)
+	job.Kind = models.JobKindRebuildSCIP
+	job.Tag = rebuildTag
+
+	result, err := storeStage(ctx, job)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if swappedTag != rebuildTag {
+		t.Errorf("swapped tag = %q, want %q", swappedTag, rebuildTag)
+	}
+	if result.Tag != version.Tag {
+		t.Errorf("job tag = %q, want the live tag %q", result.Tag, version.Tag)
+	}
+}
+
+func TestPromoteBlobs_CopyErrorKeepsBlobs(t *testing.T) {
+	var deleted []string
+	mb := &vickytest.MockBlobs{
+		OnListPathsByVersion: func(ctx context.Context, userID int64, owner, repo, tag string) ([]string, error) {
+			if tag == "v1.0.0" {
+				return []string{"main.go", "old.go"}, nil
+			}
+			return []string{"main.go", "go.mod"}, nil
+		},
+		OnPutBlob: func(ctx context.Context, userID int64, blob *models.Blob) error {
+			if blob.Path == "go.mod" {
+				return errors.New("bucket unavailable")
+			}
+			return nil
+		},
+		OnDeleteByPath: func(ctx context.Context, userID int64, owner, repo, tag, path string) error {
+			deleted = append(deleted, tag+"/"+path)
+			return nil
+		},
+		OnDeleteByVersion: func(ctx context.Context, userID int64, owner, repo, tag string) error {
+			deleted = append(deleted, tag)
+			return nil
+		},
+	}
+
+	ctx := vickytest.SetupRegistry(t, vickytest.WithBlobs(mb))
+	job := vickytest.NewJob(t)
+	job.Tag = "v1.0.0"
+
+	err := promoteBlobs(ctx, job, "v1.0.0~staging.2")
+	if err == nil || !strings.Contains(err.Error(), "store blob go.mod") {
+		t.Fatalf("err = %v, want the failed copy", err)
+	}
+	if len(deleted) != 0 {
+		t.Errorf("deleted %v, want no blobs deleted after a failed copy", deleted)
+	}
+}
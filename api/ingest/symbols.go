@@ -0,0 +1,134 @@
+package ingest
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/sourcegraph/scip/bindings/go/scip"
+	"github.com/zoobzio/capitan"
+	"github.com/zoobzio/sum"
+	"github.com/zoobzio/vicky/api/contracts"
+	"github.com/zoobzio/vicky/api/events"
+	vickyScip "github.com/zoobzio/vicky/internal/scip"
+	"github.com/zoobzio/vicky/models"
+)
+
+// symbolWriter derives searchable symbols from SCIP documents as they are
+// decoded. Symbols are written in two passes because a method's parent type
+// may be defined in another file: Add inserts each document's symbols, and
+// Link connects children to their parent's row ID once every document has
+// been added. Not safe for concurrent use.
+type symbolWriter struct {
+	job      *models.Job
+	ids      map[string]int64
+	children []vickyScip.CodeSymbol
+	count    int
+}
+
+func newSymbolWriter(job *models.Job) *symbolWriter {
+	return &symbolWriter{job: job, ids: make(map[string]int64)}
+}
+
+// Add stores the symbols defined in doc.
+func (w *symbolWriter) Add(ctx context.Context, doc *scip.Document) error {
+	symbols := sum.MustUse[contracts.Symbols](ctx)
+
+	meta := vickyScip.SymbolMeta{
+		DocumentMeta: vickyScip.DocumentMeta{
+			UserID:   w.job.UserID,
+			Owner:    w.job.Owner,
+			RepoName: w.job.RepoName,
+			Tag:      w.job.Tag,
+		},
+		VersionID: w.job.VersionID,
+		Path:      doc.RelativePath,
+	}
+
+	defs := definitions(doc)
+
+	for _, sym := range doc.Symbols {
+		def, ok := defs[sym.Symbol]
+		if !ok {
+			continue
+		}
+
+		cs, ok := vickyScip.ConvertCodeSymbol(sym, def, meta)
+		if !ok {
+			continue
+		}
+
+		if err := symbols.Set(ctx, "", &cs.Symbol); err != nil {
+			capitan.Error(ctx, events.ParseSymbolErrorSignal,
+				events.JobIDKey.Field(w.job.ID),
+				events.SymbolKey.Field(sym.Symbol),
+				events.ErrorKey.Field(err),
+			)
+			return fmt.Errorf("store symbol %s: %w", cs.Symbol.QualifiedName, err)
+		}
+
+		w.ids[cs.SCIPSymbol] = cs.Symbol.ID
+		w.count++
+
+		// Only children need revisiting once every parent is stored
+		if cs.Parent != "" {
+			w.children = append(w.children, cs)
+		}
+	}
+
+	return nil
+}
+
+// Link connects every stored child symbol to its parent and returns the
+// number of symbols stored.
+func (w *symbolWriter) Link(ctx context.Context) (int, error) {
+	symbols := sum.MustUse[contracts.Symbols](ctx)
+
+	for i := range w.children {
+		cs := &w.children[i]
+		parentID, ok := w.ids[cs.Parent]
+		if !ok {
+			continue
+		}
+
+		cs.Symbol.ParentID = &parentID
+		if err := symbols.Set(ctx, idToKey(cs.Symbol.ID), &cs.Symbol); err != nil {
+			return w.count, fmt.Errorf("link symbol %s: %w", cs.Symbol.QualifiedName, err)
+		}
+	}
+
+	return w.count, nil
+}
+
+// definitions maps each symbol defined in a SCIP document to its definition
+// occurrence.
+func definitions(doc *scip.Document) map[string]*scip.Occurrence {
+	defs := make(map[string]*scip.Occurrence, len(doc.Symbols))
+	for _, occ := range doc.Occurrences {
+		if occ.SymbolRoles&int32(scip.SymbolRole_Definition) == 0 {
+			continue
+		}
+		if _, ok := defs[occ.Symbol]; !ok {
+			defs[occ.Symbol] = occ
+		}
+	}
+	return defs
+}
+
+// symbolText builds the text embedded for a symbol: its kind and qualified
+// name, then the signature and documentation when present.
+func symbolText(s *models.Symbol) string {
+	var b strings.Builder
+	b.WriteString(string(s.Kind))
+	b.WriteString(" ")
+	b.WriteString(s.QualifiedName)
+	if s.Signature != nil {
+		b.WriteString("\n")
+		b.WriteString(*s.Signature)
+	}
+	if s.Doc != nil {
+		b.WriteString("\n\n")
+		b.WriteString(*s.Doc)
+	}
+	return b.String()
+}
@@ -0,0 +1,110 @@
+//go:build testing
+
+package ingest
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	scipproto "github.com/sourcegraph/scip/bindings/go/scip"
+	"github.com/zoobzio/vicky/models"
+	vickytest "github.com/zoobzio/vicky/testing"
+)
+
+func TestSymbolWriter(t *testing.T) {
+	const (
+		clientSym  = "scip-go gomod github.com/foo/bar v1.0.0 `github.com/foo/bar`/Client#"
+		connectSym = "scip-go gomod github.com/foo/bar v1.0.0 `github.com/foo/bar`/Client#Connect()."
+		localSym   = "local 0"
+	)
+
+	docs := []*scipproto.Document{
+		{
+			// The method is defined before its type to exercise cross-file linking
+			RelativePath: "connect.go",
+			Symbols: []*scipproto.SymbolInformation{
+				{Symbol: connectSym},
+				{Symbol: localSym},
+			},
+			Occurrences: []*scipproto.Occurrence{
+				{Range: []int32{4, 17, 24}, Symbol: connectSym, SymbolRoles: int32(scipproto.SymbolRole_Definition)},
+				{Range: []int32{5, 1, 4}, Symbol: localSym, SymbolRoles: int32(scipproto.SymbolRole_Definition)},
+			},
+		},
+		{
+			RelativePath: "client.go",
+			Symbols: []*scipproto.SymbolInformation{
+				{Symbol: clientSym, Kind: scipproto.SymbolInformation_Struct},
+			},
+			Occurrences: []*scipproto.Occurrence{
+				{Range: []int32{2, 5, 11}, Symbol: clientSym, SymbolRoles: int32(scipproto.SymbolRole_Definition)},
+			},
+		},
+	}
+
+	var inserted []*models.Symbol
+	linked := make(map[int64]int64)
+	ms := &vickytest.MockSymbols{
+		OnSet: func(ctx context.Context, key string, symbol *models.Symbol) error {
+			if key == "" {
+				symbol.ID = int64(len(inserted) + 1)
+				inserted = append(inserted, symbol)
+				return nil
+			}
+			if symbol.ParentID != nil {
+				linked[symbol.ID] = *symbol.ParentID
+			}
+			return nil
+		},
+	}
+
+	ctx := vickytest.SetupRegistry(t, vickytest.WithSymbols(ms))
+
+	w := newSymbolWriter(vickytest.NewJob(t))
+	for _, doc := range docs {
+		if err := w.Add(ctx, doc); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+	}
+	count, err := w.Link(ctx)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if count != 2 || len(inserted) != 2 {
+		t.Fatalf("stored %d symbols (%d inserts), want 2", count, len(inserted))
+	}
+
+	connect, client := inserted[0], inserted[1]
+	if connect.QualifiedName != "bar.Client.Connect" || connect.Kind != models.SymbolKindMethod {
+		t.Errorf("connect = %s %s, want method bar.Client.Connect", connect.Kind, connect.QualifiedName)
+	}
+	if client.Kind != models.SymbolKindStruct || client.FilePath != "client.go" {
+		t.Errorf("client = %s in %s, want struct in client.go", client.Kind, client.FilePath)
+	}
+	if connect.VersionID != 10 || connect.Tag != "v1.0.0" {
+		t.Errorf("connect version = (%d, %q), want (10, v1.0.0)", connect.VersionID, connect.Tag)
+	}
+	if linked[connect.ID] != client.ID {
+		t.Errorf("connect parent = %d, want %d", linked[connect.ID], client.ID)
+	}
+}
+
+func TestSymbolText(t *testing.T) {
+	sig := "func NewClient(opts ...Option) *Client"
+	doc := "NewClient creates a client."
+
+	got := symbolText(&models.Symbol{
+		Kind:          models.SymbolKindFunction,
+		QualifiedName: "vicky.NewClient",
+		Signature:     &sig,
+		Doc:           &doc,
+	})
+
+	for _, want := range []string{"function vicky.NewClient", sig, doc} {
+		if !strings.Contains(got, want) {
+			t.Errorf("symbolText() = %q, missing %q", got, want)
+		}
+	}
+}
@@ -3,6 +3,11 @@ package ingest
 import (
 	"context"
 	"errors"
+	"fmt"
+	"os"
+	"sync"
+	"sync/atomic"
+	"time"
 
 	"github.com/zoobzio/capitan"
 	"github.com/zoobzio/pipz"
@@ -16,16 +21,105 @@ import (
 const (
 	// DefaultWorkers is the number of concurrent ingestion jobs.
 	DefaultWorkers = 4
+
+	// MaxWorkers caps the configurable number of concurrent ingestion jobs.
+	MaxWorkers = 64
+
+	// DefaultUserConcurrency is how many jobs one user may have running at once.
+	DefaultUserConcurrency = 2
+
+	// DefaultRepositoryConcurrency is how many jobs one repository may have running at once.
+	DefaultRepositoryConcurrency = 1
+
+	// DefaultMaxAttempts is how many times a job may be claimed. A job whose
+	// lease lapses at the cap crashed or wedged every worker that ran it, so
+	// it is failed instead of reclaimed.
+	DefaultMaxAttempts = 3
+
+	// PollInterval is how often the worker checks the jobs table for claimable work.
+	PollInterval = 2 * time.Second
+
+	// LeaseDuration is how long a claimed job stays locked without a heartbeat.
+	// Once it lapses, any worker may reclaim the job.
+	LeaseDuration = 60 * time.Second
+
+	// HeartbeatInterval is how often a running job's lease is renewed.
+	HeartbeatInterval = LeaseDuration / 3
 )
 
 // Worker pool identity.
 var WorkerPoolID = pipz.NewIdentity("ingest-worker-pool", "Worker pool for ingestion jobs")
 
-// Worker manages the ingestion worker pool and event listener.
+// jobWorkers is how many jobs each worker runs at once, set by the capacitor.
+var jobWorkers atomic.Int32
+
+// userConcurrency and repositoryConcurrency cap running jobs across all
+// workers, set by the capacitor.
+var (
+	userConcurrency       atomic.Int32
+	repositoryConcurrency atomic.Int32
+)
+
+// maxAttempts caps how many times a job is claimed, set by the capacitor.
+var maxAttempts atomic.Int32
+
+func init() {
+	jobWorkers.Store(DefaultWorkers)
+	userConcurrency.Store(DefaultUserConcurrency)
+	repositoryConcurrency.Store(DefaultRepositoryConcurrency)
+	maxAttempts.Store(DefaultMaxAttempts)
+}
+
+// SetWorkerCount updates how many jobs each worker runs concurrently.
+// Called by capacitor when config changes. Values outside 1-MaxWorkers are ignored.
+func SetWorkerCount(workers int) {
+	if workers > 0 && workers <= MaxWorkers {
+		jobWorkers.Store(int32(workers))
+	}
+}
+
+// SetConcurrencyLimits updates how many jobs one user and one repository may
+// have running at once across every worker.
+// Called by capacitor when config changes. Values below 1 are ignored.
+func SetConcurrencyLimits(perUser, perRepository int) {
+	if perUser > 0 {
+		userConcurrency.Store(int32(perUser))
+	}
+	if perRepository > 0 {
+		repositoryConcurrency.Store(int32(perRepository))
+	}
+}
+
+// SetMaxAttempts updates how many times a job may be claimed before a
+// lapsed lease fails it.
+// Called by capacitor when config changes. Values below 1 are ignored.
+func SetMaxAttempts(attempts int) {
+	if attempts > 0 {
+		maxAttempts.Store(int32(attempts))
+	}
+}
+
+// concurrencyLimits returns the current per-user and per-repository limits
+// and the attempt cap.
+func concurrencyLimits() models.JobLimits {
+	return models.JobLimits{
+		PerUser:       int(userConcurrency.Load()),
+		PerRepository: int(repositoryConcurrency.Load()),
+		MaxAttempts:   int(maxAttempts.Load()),
+	}
+}
+
+// Worker claims ingestion jobs from the jobs table and runs them through the pipeline.
+// The table is the queue: jobs survive restarts and are shared between replicas.
 type Worker struct {
+	id       string
 	pool     *pipz.WorkerPool[*models.Job]
 	pipeline *pipz.Sequence[*models.Job]
 	listener *capitan.Listener
+	active   atomic.Int32
+	wake     chan struct{}
+	cancel   context.CancelFunc
+	wg       sync.WaitGroup
 }
 
 // NewWorker creates a new ingestion worker.
@@ -33,41 +127,163 @@ type Worker struct {
 func NewWorker() *Worker {
 	pipeline := NewPipeline()
 
+	// The pool is sized for the most jobs the worker may ever run; the
+	// configured count is enforced when claiming. Resizing a pool's
+	// semaphore with jobs in flight would strand their release.
 	return &Worker{
-		pool:     pipz.NewWorkerPool(WorkerPoolID, DefaultWorkers, pipeline),
+		id:       workerID(),
+		pool:     pipz.NewWorkerPool(WorkerPoolID, MaxWorkers, pipeline),
 		pipeline: pipeline,
+		wake:     make(chan struct{}, 1),
+	}
+}
+
+// workerID identifies this process in job leases.
+func workerID() string {
+	host, err := os.Hostname()
+	if err != nil {
+		host = "vicky"
 	}
+	return fmt.Sprintf("%s-%d", host, os.Getpid())
 }
 
-// Start begins listening for job creation events and processing jobs.
+// Start begins polling the jobs table and processing claimed jobs.
 func (w *Worker) Start(ctx context.Context) {
-	w.listener = events.Job.Created.Listen(func(ctx context.Context, e events.JobCreatedEvent) {
-		// Use the job from the event directly
-		w.processJob(ctx, e.Job)
+	ctx, w.cancel = context.WithCancel(ctx)
+
+	// Job creation events only wake the poller early; jobs created in
+	// another process are picked up on the next poll.
+	w.listener = events.Job.Created.Listen(func(_ context.Context, _ events.JobCreatedEvent) {
+		w.notify()
 	})
 
-	capitan.Emit(ctx, events.StartupWorkerReady, events.StartupWorkersKey.Field(DefaultWorkers))
+	w.wg.Add(1)
+	go w.poll(ctx)
+
+	capitan.Emit(ctx, events.StartupWorkerReady, events.StartupWorkersKey.Field(int(jobWorkers.Load())))
 }
 
 // Stop gracefully shuts down the worker pool and listener.
+// Jobs still in flight are released back to the queue.
 func (w *Worker) Stop() error {
 	if w.listener != nil {
 		w.listener.Close()
 	}
+	if w.cancel != nil {
+		w.cancel()
+	}
+	w.wg.Wait()
 	return w.pool.Close()
 }
 
-// processJob handles a single ingestion job.
+// notify wakes the poller without blocking.
+func (w *Worker) notify() {
+	select {
+	case w.wake <- struct{}{}:
+	default:
+	}
+}
+
+// poll claims jobs on every tick or wake-up until the context is cancelled.
+func (w *Worker) poll(ctx context.Context) {
+	defer w.wg.Done()
+
+	ticker := time.NewTicker(PollInterval)
+	defer ticker.Stop()
+
+	for {
+		w.claimAvailable(ctx)
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		case <-w.wake:
+		}
+	}
+}
+
+// claimAvailable claims jobs until every slot is busy or the queue is empty.
+// Only the poll goroutine claims, so checking then taking a slot is safe.
+func (w *Worker) claimAvailable(ctx context.Context) {
+	jobs := sum.MustUse[contracts.Jobs](ctx)
+
+	for {
+		if w.active.Load() >= jobWorkers.Load() {
+			return
+		}
+		w.active.Add(1)
+
+		job, err := jobs.Claim(ctx, w.id, LeaseDuration, concurrencyLimits())
+		if err != nil || job == nil {
+			w.active.Add(-1)
+			if err != nil && ctx.Err() == nil {
+				capitan.Error(ctx, events.WorkerClaimErrorSignal,
+					events.WorkerKey.Field(w.id),
+					events.ErrorKey.Field(err),
+				)
+			}
+			return
+		}
+
+		w.wg.Add(1)
+		go func() {
+			defer w.wg.Done()
+			w.processJob(ctx, job)
+			w.active.Add(-1)
+			// A slot just freed up; look for more work.
+			w.notify()
+		}()
+	}
+}
+
+// heartbeat renews the job lease until ctx is done.
+// If the lease is lost, lost is set and the job context is cancelled.
+func (w *Worker) heartbeat(ctx context.Context, cancel context.CancelFunc, jobID int64, lost *atomic.Bool) {
+	jobs := sum.MustUse[contracts.Jobs](ctx)
+
+	ticker := time.NewTicker(HeartbeatInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+
+		held, err := jobs.Heartbeat(ctx, jobID, w.id, LeaseDuration)
+		if err != nil {
+			if ctx.Err() == nil {
+				capitan.Error(ctx, events.WorkerHeartbeatErrorSignal,
+					events.JobIDKey.Field(jobID),
+					events.WorkerKey.Field(w.id),
+					events.ErrorKey.Field(err),
+				)
+			}
+			continue
+		}
+		if !held {
+			lost.Store(true)
+			cancel()
+			return
+		}
+	}
+}
+
+// processJob handles a single claimed ingestion job.
 func (w *Worker) processJob(ctx context.Context, job *models.Job) {
 	// Resolve jobs contract from registry
 	jobs := sum.MustUse[contracts.Jobs](ctx)
 
-	// Mark job as running
-	if err := jobs.Start(ctx, job.ID); err != nil {
-		events.Job.Failed.Emit(ctx, events.JobProgressEvent{
-			JobID:  job.ID,
-			Stage:  job.Stage,
-			Status: models.JobStatusFailed,
+	// Cancelled before any worker got to it
+	if job.Status == models.JobStatusCancelling {
+		_ = jobs.MarkCancelled(ctx, job.ID)
+		events.Job.Cancelled.Emit(ctx, events.JobProgressEvent{
+			JobID:    job.ID,
+			Stage:    job.Stage,
+			Status:   models.JobStatusCancelled,
+			Progress: job.Progress,
 		})
 		return
 	}
@@ -80,8 +296,39 @@ func (w *Worker) processJob(ctx context.Context, job *models.Job) {
 		Progress: 0,
 	})
 
+	// Keep the lease alive while the pipeline runs
+	jobCtx, cancel := context.WithCancel(ctx)
+	var lost atomic.Bool
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		w.heartbeat(jobCtx, cancel, job.ID, &lost)
+	}()
+
 	// Process through pipeline
-	result, err := w.pool.Process(ctx, job)
+	result, err := w.pool.Process(jobCtx, job)
+	cancel()
+	<-done
+
+	if lost.Load() {
+		// Another worker reclaimed the job; its state is no longer ours to write
+		capitan.Warn(ctx, events.WorkerLeaseLostSignal,
+			events.JobIDKey.Field(job.ID),
+			events.WorkerKey.Field(w.id),
+		)
+		return
+	}
+
+	if ctx.Err() != nil {
+		// Shutting down: hand the job back so another worker resumes it
+		_ = jobs.Release(context.WithoutCancel(ctx), job.ID, w.id)
+		capitan.Info(ctx, events.WorkerJobReleasedSignal,
+			events.JobIDKey.Field(job.ID),
+			events.WorkerKey.Field(w.id),
+		)
+		return
+	}
+
 	if err != nil {
 		// Check if cancellation
 		if errors.Is(err, ErrJobCancelled) {
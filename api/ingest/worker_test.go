@@ -0,0 +1,229 @@
+//go:build testing
+
+package ingest
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/zoobzio/vicky/models"
+	vickytest "github.com/zoobzio/vicky/testing"
+)
+
+func TestWorker_ClaimsQueuedJob(t *testing.T) {
+	job := vickytest.NewJob(t)
+	job.Status = models.JobStatusCancelling
+
+	var mu sync.Mutex
+	var claimedBy string
+	var claims int
+	cancelled := make(chan int64, 1)
+
+	mj := &vickytest.MockJobs{
+		OnClaim: func(ctx context.Context, workerID string, lease time.Duration, limits models.JobLimits) (*models.Job, error) {
+			mu.Lock()
+			defer mu.Unlock()
+			claims++
+			if claims > 1 {
+				return nil, nil
+			}
+			claimedBy = workerID
+			return job, nil
+		},
+		OnMarkCancelled: func(ctx context.Context, id int64) error {
+			cancelled <- id
+			return nil
+		},
+	}
+
+	ctx := vickytest.SetupRegistry(t, vickytest.WithJobs(mj))
+
+	w := NewWorker()
+	w.Start(ctx)
+	defer func() { _ = w.Stop() }()
+
+	select {
+	case id := <-cancelled:
+		if id != job.ID {
+			t.Errorf("MarkCancelled id = %d, want %d", id, job.ID)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("timed out waiting for queued job to be processed")
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+	if claimedBy != w.id {
+		t.Errorf("Claim workerID = %q, want %q", claimedBy, w.id)
+	}
+}
+
+func TestWorker_ClaimAvailable_EmptyQueue(t *testing.T) {
+	claims := 0
+	mj := &vickytest.MockJobs{
+		OnClaim: func(ctx context.Context, workerID string, lease time.Duration, limits models.JobLimits) (*models.Job, error) {
+			claims++
+			if lease != LeaseDuration {
+				t.Errorf("lease = %v, want %v", lease, LeaseDuration)
+			}
+			return nil, nil
+		},
+	}
+
+	ctx := vickytest.SetupRegistry(t, vickytest.WithJobs(mj))
+
+	w := NewWorker()
+	w.claimAvailable(ctx)
+
+	if claims != 1 {
+		t.Errorf("Claim called %d times, want 1", claims)
+	}
+	if n := w.active.Load(); n != 0 {
+		t.Errorf("slots in use = %d, want 0", n)
+	}
+}
+
+func TestWorker_ClaimAvailable_Error(t *testing.T) {
+	mj := &vickytest.MockJobs{
+		OnClaim: func(ctx context.Context, workerID string, lease time.Duration, limits models.JobLimits) (*models.Job, error) {
+			return nil, fmt.Errorf("db down")
+		},
+	}
+
+	ctx := vickytest.SetupRegistry(t, vickytest.WithJobs(mj))
+
+	w := NewWorker()
+	w.claimAvailable(ctx)
+
+	if n := w.active.Load(); n != 0 {
+		t.Errorf("slots in use = %d, want 0 after claim error", n)
+	}
+}
+
+func TestWorker_ClaimAvailable_SlotsFull(t *testing.T) {
+	claims := 0
+	mj := &vickytest.MockJobs{
+		OnClaim: func(ctx context.Context, workerID string, lease time.Duration, limits models.JobLimits) (*models.Job, error) {
+			claims++
+			return nil, nil
+		},
+	}
+
+	ctx := vickytest.SetupRegistry(t, vickytest.WithJobs(mj))
+
+	w := NewWorker()
+	w.active.Store(DefaultWorkers)
+	w.claimAvailable(ctx)
+
+	if claims != 0 {
+		t.Errorf("Claim called %d times with all slots busy, want 0", claims)
+	}
+}
+
+func TestWorker_ClaimAvailable_PassesConcurrencyLimits(t *testing.T) {
+	SetConcurrencyLimits(3, 2)
+	SetConcurrencyLimits(0, -1) // ignored
+	t.Cleanup(func() { SetConcurrencyLimits(DefaultUserConcurrency, DefaultRepositoryConcurrency) })
+
+	var got models.JobLimits
+	mj := &vickytest.MockJobs{
+		OnClaim: func(ctx context.Context, workerID string, lease time.Duration, limits models.JobLimits) (*models.Job, error) {
+			got = limits
+			return nil, nil
+		},
+	}
+
+	ctx := vickytest.SetupRegistry(t, vickytest.WithJobs(mj))
+
+	w := NewWorker()
+	w.claimAvailable(ctx)
+
+	if want := (models.JobLimits{PerUser: 3, PerRepository: 2, MaxAttempts: DefaultMaxAttempts}); got != want {
+		t.Errorf("Claim limits = %+v, want %+v", got, want)
+	}
+}
+
+func TestWorker_ClaimAvailable_PassesMaxAttempts(t *testing.T) {
+	SetMaxAttempts(5)
+	SetMaxAttempts(0) // ignored
+	t.Cleanup(func() { SetMaxAttempts(DefaultMaxAttempts) })
+
+	var got models.JobLimits
+	mj := &vickytest.MockJobs{
+		OnClaim: func(ctx context.Context, workerID string, lease time.Duration, limits models.JobLimits) (*models.Job, error) {
+			got = limits
+			return nil, nil
+		},
+	}
+
+	ctx := vickytest.SetupRegistry(t, vickytest.WithJobs(mj))
+
+	w := NewWorker()
+	w.claimAvailable(ctx)
+
+	if got.MaxAttempts != 5 {
+		t.Errorf("Claim MaxAttempts = %d, want 5", got.MaxAttempts)
+	}
+}
+
+func TestWorker_ClaimAvailable_HonorsWorkerCount(t *testing.T) {
+	SetWorkerCount(1)
+	t.Cleanup(func() { SetWorkerCount(DefaultWorkers) })
+
+	claims := 0
+	mj := &vickytest.MockJobs{
+		OnClaim: func(ctx context.Context, workerID string, lease time.Duration, limits models.JobLimits) (*models.Job, error) {
+			claims++
+			return nil, nil
+		},
+	}
+
+	ctx := vickytest.SetupRegistry(t, vickytest.WithJobs(mj))
+
+	w := NewWorker()
+	w.active.Store(1)
+	w.claimAvailable(ctx)
+
+	if claims != 0 {
+		t.Errorf("Claim called %d times with the configured worker busy, want 0", claims)
+	}
+}
+
+func TestWorker_ProcessJob_ReleasesOnShutdown(t *testing.T) {
+	var releasedID int64
+	var releasedBy string
+	var failed bool
+
+	mj := &vickytest.MockJobs{
+		OnRelease: func(ctx context.Context, id int64, workerID string) error {
+			releasedID = id
+			releasedBy = workerID
+			return nil
+		},
+		OnMarkFailed: func(ctx context.Context, id int64, errMsg string) error {
+			failed = true
+			return nil
+		},
+	}
+
+	ctx := vickytest.SetupRegistry(t, vickytest.WithJobs(mj))
+	ctx, cancel := context.WithCancel(ctx)
+	cancel()
+
+	w := NewWorker()
+	job := vickytest.NewJob(t)
+	w.processJob(ctx, job)
+
+	if releasedID != job.ID {
+		t.Errorf("Release id = %d, want %d", releasedID, job.ID)
+	}
+	if releasedBy != w.id {
+		t.Errorf("Release workerID = %q, want %q", releasedBy, w.id)
+	}
+	if failed {
+		t.Error("job should be released, not marked failed, on shutdown")
+	}
+}
@@ -1,28 +1,49 @@
 package transformers
 
 import (
+	"encoding/json"
+
 	"github.com/zoobzio/vicky/models"
 	"github.com/zoobzio/vicky/api/wire"
 )
 
 // IngestionConfigToResponse transforms an IngestionConfig model to an API response.
 func IngestionConfigToResponse(c *models.IngestionConfig) wire.IngestionConfigResponse {
+	// Formats are written by ApplyIngestionConfigRequest, so they parse
+	var formats models.ContentFormatMap
+	if c.ContentFormats != nil {
+		_ = json.Unmarshal(c.ContentFormats, &formats)
+	}
 	return wire.IngestionConfigResponse{
 		ID:              c.ID,
 		Language:        c.Language,
+		Root:            c.Root,
+		RootPaths:       c.RootPaths,
 		IncludeDocs:     c.IncludeDocs,
 		ExcludePatterns: c.ExcludePatterns,
+		IncludePatterns: c.IncludePatterns,
 		MaxFileSize:     c.MaxFileSize,
 		LanguageConfig:  c.LanguageConfig,
+		ContentFormats:  formats,
 	}
 }
 
 // ApplyIngestionConfigRequest applies an IngestionConfigRequest to an IngestionConfig model.
 func ApplyIngestionConfigRequest(req wire.IngestionConfigRequest, c *models.IngestionConfig) {
 	c.Language = req.Language
+	c.Root = models.CleanRoot(req.Root)
+	c.RootPaths = nil
+	for _, root := range req.RootPaths {
+		c.RootPaths = append(c.RootPaths, models.CleanRoot(root))
+	}
 	c.IncludeDocs = req.IncludeDocs
 	c.ExcludePatterns = req.ExcludePatterns
+	c.IncludePatterns = req.IncludePatterns
 	c.LanguageConfig = req.LanguageConfig
+	c.ContentFormats = nil
+	if len(req.ContentFormats) > 0 {
+		c.ContentFormats, _ = json.Marshal(req.ContentFormats)
+	}
 
 	// Apply max file size with default
 	if req.MaxFileSize != nil {
@@ -31,3 +52,48 @@ func ApplyIngestionConfigRequest(req wire.IngestionConfigRequest, c *models.Inge
 		c.MaxFileSize = models.DefaultMaxFileSize
 	}
 }
+
+// ClassificationsToPreview groups a tree's file classifications by decision.
+// Every decision gets a group, in models.FileDecisions order, so clients can
+// rely on the shape; files keep the order they were given in.
+func ClassificationsToPreview(ref string, files []models.FileClassification) wire.PreviewResponse {
+	resp := wire.PreviewResponse{
+		Ref:    ref,
+		Groups: make([]wire.PreviewGroup, len(models.FileDecisions)),
+	}
+
+	index := make(map[models.FileDecision]int, len(models.FileDecisions))
+	for i, d := range models.FileDecisions {
+		index[d] = i
+		resp.Groups[i] = wire.PreviewGroup{Decision: d, Files: []wire.PreviewFile{}}
+	}
+
+	for _, f := range files {
+		i, ok := index[f.Decision]
+		if !ok {
+			continue
+		}
+
+		file := wire.PreviewFile{Path: f.Path, Size: f.Size}
+		if f.Rule != "" {
+			rule := f.Rule
+			file.Rule = &rule
+		}
+		if f.Reason != "" {
+			reason := f.Reason
+			file.Reason = &reason
+		}
+
+		g := &resp.Groups[i]
+		g.Files = append(g.Files, file)
+		g.Count++
+		g.Bytes += f.Size
+
+		if f.Decision.Included() {
+			resp.IncludedCount++
+			resp.IncludedBytes += f.Size
+		}
+	}
+
+	return resp
+}
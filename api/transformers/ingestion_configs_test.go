@@ -69,3 +69,77 @@ func TestApplyIngestionConfigRequest_DefaultMaxFileSize(t *testing.T) {
 		t.Errorf("MaxFileSize = %d, want %d", c.MaxFileSize, models.DefaultMaxFileSize)
 	}
 }
+
+func TestApplyIngestionConfigRequest_Root(t *testing.T) {
+	for root, want := range map[string]string{"": "", ".": "", "web/": "web", "./services//api": "services/api"} {
+		c := &models.IngestionConfig{}
+		ApplyIngestionConfigRequest(wire.IngestionConfigRequest{Language: models.LanguageGo, Root: root}, c)
+		if c.Root != want {
+			t.Errorf("root %q applied as %q, want %q", root, c.Root, want)
+		}
+	}
+}
+
+func TestClassificationsToPreview(t *testing.T) {
+	files := []models.FileClassification{
+		{Path: "main.go", Size: 100, Decision: models.FileDecisionCode},
+		{Path: "vendor/a.go", Size: 30, Decision: models.FileDecisionExcluded, Rule: "vendor/**", Reason: "matches exclude pattern"},
+		{Path: "vendor/b.go", Size: 20, Decision: models.FileDecisionExcluded, Rule: "vendor/**", Reason: "matches exclude pattern"},
+	}
+
+	resp := ClassificationsToPreview("v1.0.0", files)
+
+	if resp.Ref != "v1.0.0" {
+		t.Errorf("Ref = %q, want v1.0.0", resp.Ref)
+	}
+	if len(resp.Groups) != len(models.FileDecisions) {
+		t.Fatalf("groups = %d, want one per decision (%d)", len(resp.Groups), len(models.FileDecisions))
+	}
+	if resp.IncludedCount != 1 || resp.IncludedBytes != 100 {
+		t.Errorf("included = %d/%d, want 1/100", resp.IncludedCount, resp.IncludedBytes)
+	}
+
+	for _, g := range resp.Groups {
+		switch g.Decision {
+		case models.FileDecisionExcluded:
+			if g.Count != 2 || g.Bytes != 50 {
+				t.Errorf("excluded = %d/%d, want 2/50", g.Count, g.Bytes)
+			}
+			if g.Files[0].Rule == nil || *g.Files[0].Rule != "vendor/**" {
+				t.Errorf("rule = %v, want vendor/**", g.Files[0].Rule)
+			}
+		case models.FileDecisionDocs:
+			if g.Count != 0 || g.Files == nil {
+				t.Errorf("empty docs group = %d files (nil=%v), want 0 and non-nil", g.Count, g.Files == nil)
+			}
+		}
+	}
+}
+
+func TestIngestionConfigContentFormats_RoundTrip(t *testing.T) {
+	req := wire.IngestionConfigRequest{
+		Language:       models.LanguageGo,
+		ContentFormats: models.ContentFormatMap{".yaml": models.ContentFormatOpenAPI, ".txt": ""},
+	}
+	c := &models.IngestionConfig{}
+
+	ApplyIngestionConfigRequest(req, c)
+
+	formats, err := c.GetContentFormats()
+	if err != nil {
+		t.Fatalf("GetContentFormats: %v", err)
+	}
+	if formats.For("api.yaml") != models.ContentFormatOpenAPI || formats.For("notes.txt") != "" {
+		t.Errorf("formats = %v, want the request's formats over the defaults", formats)
+	}
+
+	resp := IngestionConfigToResponse(c)
+	if len(resp.ContentFormats) != 2 || resp.ContentFormats[".yaml"] != models.ContentFormatOpenAPI {
+		t.Errorf("ContentFormats = %v, want the request's formats", resp.ContentFormats)
+	}
+
+	ApplyIngestionConfigRequest(wire.IngestionConfigRequest{Language: models.LanguageGo}, c)
+	if c.ContentFormats != nil {
+		t.Errorf("ContentFormats = %s, want nil once cleared", c.ContentFormats)
+	}
+}
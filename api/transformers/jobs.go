@@ -0,0 +1,92 @@
+package transformers
+
+import (
+	"github.com/zoobzio/vicky/api/wire"
+	"github.com/zoobzio/vicky/models"
+)
+
+// JobToResponse transforms a Job model to an API response.
+func JobToResponse(j *models.Job) wire.JobResponse {
+	return wire.JobResponse{
+		ID:             j.ID,
+		VersionID:      j.VersionID,
+		RepositoryID:   j.RepositoryID,
+		Owner:          j.Owner,
+		RepoName:       j.RepoName,
+		Tag:            j.Tag,
+		Kind:           j.Kind,
+		Stage:          j.Stage,
+		Status:         j.Status,
+		Progress:       j.Progress,
+		Error:          j.Error,
+		ItemsTotal:     j.ItemsTotal,
+		ItemsProcessed: j.ItemsProcessed,
+		CreatedAt:      j.CreatedAt,
+		StartedAt:      j.StartedAt,
+		CompletedAt:    j.CompletedAt,
+		UpdatedAt:      j.UpdatedAt,
+	}
+}
+
+// JobsToList transforms a slice of Job models to an API list response.
+func JobsToList(jobs []*models.Job) wire.JobListResponse {
+	resp := wire.JobListResponse{
+		Jobs: make([]wire.JobResponse, len(jobs)),
+	}
+	for i, j := range jobs {
+		resp.Jobs[i] = JobToResponse(j)
+	}
+	return resp
+}
+
+// JobToProgress transforms a Job model to a progress stream event.
+func JobToProgress(j *models.Job) wire.JobProgressResponse {
+	return wire.JobProgressResponse{
+		JobID:          j.ID,
+		Stage:          j.Stage,
+		Status:         j.Status,
+		Progress:       j.Progress,
+		ItemsTotal:     j.ItemsTotal,
+		ItemsProcessed: j.ItemsProcessed,
+		Error:          j.Error,
+		UpdatedAt:      j.UpdatedAt,
+	}
+}
+
+// FileReportToResponse transforms a FileReport model to an API response.
+func FileReportToResponse(r *models.FileReport) wire.FileReportResponse {
+	return wire.FileReportResponse{
+		Path:    r.Path,
+		Stage:   r.Stage,
+		Outcome: r.Outcome,
+		Rule:    r.Rule,
+		Reason:  r.Reason,
+		Size:    r.Size,
+	}
+}
+
+// FileReportsToJobReport transforms a job's report entries to an API response.
+// Counts cover every entry; an empty outcome lists every file, otherwise only
+// files with that outcome are listed.
+func FileReportsToJobReport(jobID int64, reports []*models.FileReport, outcome models.FileOutcome) wire.JobReportResponse {
+	resp := wire.JobReportResponse{
+		JobID: jobID,
+		Files: make([]wire.FileReportResponse, 0, len(reports)),
+	}
+	for _, r := range reports {
+		switch r.Outcome {
+		case models.FileOutcomeIncluded:
+			resp.Included++
+		case models.FileOutcomeExcluded:
+			resp.Excluded++
+		case models.FileOutcomeSkipped:
+			resp.Skipped++
+		case models.FileOutcomeFailed:
+			resp.Failed++
+		}
+		if outcome == "" || r.Outcome == outcome {
+			resp.Files = append(resp.Files, FileReportToResponse(r))
+		}
+	}
+	return resp
+}
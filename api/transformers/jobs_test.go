@@ -0,0 +1,122 @@
+package transformers
+
+import (
+	"testing"
+
+	"github.com/zoobzio/vicky/models"
+)
+
+func TestJobToResponse(t *testing.T) {
+	errMsg := "boom"
+	j := &models.Job{
+		ID:             1,
+		VersionID:      10,
+		RepositoryID:   100,
+		Owner:          "testorg",
+		RepoName:       "testrepo",
+		Tag:            "v1.0.0",
+		Stage:          models.JobStageEmbed,
+		Status:         models.JobStatusFailed,
+		Progress:       70,
+		Error:          &errMsg,
+		ItemsTotal:     200,
+		ItemsProcessed: 100,
+	}
+
+	resp := JobToResponse(j)
+
+	if resp.ID != 1 || resp.VersionID != 10 {
+		t.Errorf("ID/VersionID = %d/%d, want 1/10", resp.ID, resp.VersionID)
+	}
+	if resp.Stage != models.JobStageEmbed {
+		t.Errorf("Stage = %q, want %q", resp.Stage, models.JobStageEmbed)
+	}
+	if resp.Progress != 70 || resp.ItemsTotal != 200 || resp.ItemsProcessed != 100 {
+		t.Errorf("progress = %d (%d/%d), want 70 (100/200)", resp.Progress, resp.ItemsProcessed, resp.ItemsTotal)
+	}
+	if resp.Error == nil || *resp.Error != "boom" {
+		t.Errorf("Error = %v, want %q", resp.Error, "boom")
+	}
+}
+
+func TestJobsToList(t *testing.T) {
+	resp := JobsToList([]*models.Job{{ID: 1}, {ID: 2}})
+
+	if len(resp.Jobs) != 2 {
+		t.Fatalf("len = %d, want 2", len(resp.Jobs))
+	}
+	if resp.Jobs[1].ID != 2 {
+		t.Errorf("Jobs[1].ID = %d, want 2", resp.Jobs[1].ID)
+	}
+}
+
+func TestJobToProgress(t *testing.T) {
+	j := &models.Job{
+		ID:             1,
+		Stage:          models.JobStageChunk,
+		Status:         models.JobStatusRunning,
+		Progress:       50,
+		ItemsTotal:     10,
+		ItemsProcessed: 5,
+	}
+
+	resp := JobToProgress(j)
+
+	if resp.JobID != 1 {
+		t.Errorf("JobID = %d, want 1", resp.JobID)
+	}
+	if resp.Status != models.JobStatusRunning || resp.Stage != models.JobStageChunk {
+		t.Errorf("Status/Stage = %q/%q, want running/chunk", resp.Status, resp.Stage)
+	}
+	if resp.ItemsProcessed != 5 || resp.ItemsTotal != 10 {
+		t.Errorf("items = %d/%d, want 5/10", resp.ItemsProcessed, resp.ItemsTotal)
+	}
+}
+
+func TestFileReportsToJobReport(t *testing.T) {
+	rule := "vendor/**"
+	reports := []*models.FileReport{
+		{Path: "main.go", Stage: models.JobStageFetch, Outcome: models.FileOutcomeIncluded, Size: 120},
+		{Path: "vendor/x.go", Stage: models.JobStageFetch, Outcome: models.FileOutcomeExcluded, Rule: &rule},
+		{Path: "notes.txt", Stage: models.JobStageChunk, Outcome: models.FileOutcomeSkipped},
+		{Path: "broken.go", Stage: models.JobStageChunk, Outcome: models.FileOutcomeFailed},
+	}
+
+	resp := FileReportsToJobReport(7, reports, "")
+
+	if resp.JobID != 7 {
+		t.Errorf("JobID = %d, want 7", resp.JobID)
+	}
+	if resp.Included != 1 || resp.Excluded != 1 || resp.Skipped != 1 || resp.Failed != 1 {
+		t.Errorf("counts = %d/%d/%d/%d, want 1/1/1/1", resp.Included, resp.Excluded, resp.Skipped, resp.Failed)
+	}
+	if len(resp.Files) != 4 {
+		t.Fatalf("len(Files) = %d, want 4", len(resp.Files))
+	}
+	if resp.Files[1].Rule == nil || *resp.Files[1].Rule != "vendor/**" {
+		t.Errorf("Files[1].Rule = %v, want vendor/**", resp.Files[1].Rule)
+	}
+}
+
+func TestFileReportsToJobReport_FilterByOutcome(t *testing.T) {
+	reports := []*models.FileReport{
+		{Path: "main.go", Outcome: models.FileOutcomeIncluded},
+		{Path: "vendor/x.go", Outcome: models.FileOutcomeExcluded},
+		{Path: "vendor/y.go", Outcome: models.FileOutcomeExcluded},
+	}
+
+	resp := FileReportsToJobReport(7, reports, models.FileOutcomeExcluded)
+
+	// Counts still cover the whole report
+	if resp.Included != 1 || resp.Excluded != 2 {
+		t.Errorf("counts = %d/%d, want 1/2", resp.Included, resp.Excluded)
+	}
+	if len(resp.Files) != 2 {
+		t.Fatalf("len(Files) = %d, want 2", len(resp.Files))
+	}
+	for _, f := range resp.Files {
+		if f.Outcome != models.FileOutcomeExcluded {
+			t.Errorf("Outcome = %q, want excluded", f.Outcome)
+		}
+	}
+}
@@ -7,7 +7,7 @@ import (
 
 // VersionToResponse transforms a Version model to an API response.
 func VersionToResponse(v *models.Version) wire.VersionResponse {
-	return wire.VersionResponse{
+	resp := wire.VersionResponse{
 		ID:           v.ID,
 		RepositoryID: v.RepositoryID,
 		Owner:        v.Owner,
@@ -18,7 +18,17 @@ func VersionToResponse(v *models.Version) wire.VersionResponse {
 		Error:        v.Error,
 		CreatedAt:    v.CreatedAt,
 		UpdatedAt:    v.UpdatedAt,
+		ReplacesID:   v.ReplacesID,
 	}
+	if v.EmbeddingStrategy != nil {
+		if s, err := v.GetEmbeddingStrategy(); err == nil {
+			resp.EmbeddingStrategy = &wire.EmbeddingStrategyResponse{
+				Name:      s.Name,
+				Templates: s.Templates,
+			}
+		}
+	}
+	return resp
 }
 
 // VersionsToList transforms a slice of Version models to an API list response.
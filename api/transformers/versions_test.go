@@ -39,6 +39,28 @@ func TestVersionToResponse(t *testing.T) {
 	}
 }
 
+func TestVersionToResponse_EmbeddingStrategy(t *testing.T) {
+	v := &models.Version{ID: 10}
+	if resp := VersionToResponse(v); resp.EmbeddingStrategy != nil {
+		t.Errorf("EmbeddingStrategy = %+v, want nil when not recorded", resp.EmbeddingStrategy)
+	}
+
+	if err := v.SetEmbeddingStrategy(&models.EmbeddingStrategy{
+		Name:      "contextual",
+		Templates: map[models.ContentType]string{models.ContentTypeCode: "{{.Path}}\n{{.Content}}"},
+	}); err != nil {
+		t.Fatal(err)
+	}
+
+	resp := VersionToResponse(v)
+	if resp.EmbeddingStrategy == nil || resp.EmbeddingStrategy.Name != "contextual" {
+		t.Fatalf("EmbeddingStrategy = %+v, want contextual", resp.EmbeddingStrategy)
+	}
+	if resp.EmbeddingStrategy.Templates[models.ContentTypeCode] != "{{.Path}}\n{{.Content}}" {
+		t.Errorf("Templates = %v", resp.EmbeddingStrategy.Templates)
+	}
+}
+
 func TestVersionsToList(t *testing.T) {
 	versions := []*models.Version{
 		{ID: 1, Tag: "v1.0.0"},
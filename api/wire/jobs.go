@@ -0,0 +1,131 @@
+package wire
+
+import (
+	"time"
+
+	"github.com/zoobzio/vicky/models"
+)
+
+// JobResponse is the API response for an ingestion job.
+type JobResponse struct {
+	ID             int64            `json:"id" description:"Job ID" example:"12345"`
+	VersionID      int64            `json:"version_id" description:"Version being ingested"`
+	RepositoryID   int64            `json:"repository_id" description:"Parent repository ID"`
+	Owner          string           `json:"owner" description:"Repository owner" example:"octocat"`
+	RepoName       string           `json:"repo_name" description:"Repository name" example:"hello-world"`
+	Tag            string           `json:"tag" description:"Version tag" example:"v1.0.0"`
+	Kind           models.JobKind   `json:"kind" description:"Which pipeline stages the job runs" example:"ingest"`
+	Stage          models.JobStage  `json:"stage" description:"Current processing stage" example:"embed"`
+	Status         models.JobStatus `json:"status" description:"Job status" example:"running"`
+	Progress       int              `json:"progress" description:"Percentage completion 0-100" example:"45"`
+	Error          *string          `json:"error,omitempty" description:"Error message if failed"`
+	ItemsTotal     int              `json:"items_total" description:"Items to process in the current stage"`
+	ItemsProcessed int              `json:"items_processed" description:"Items processed so far in the current stage"`
+	CreatedAt      time.Time        `json:"created_at" description:"Job creation time"`
+	StartedAt      *time.Time       `json:"started_at,omitempty" description:"Processing start time"`
+	CompletedAt    *time.Time       `json:"completed_at,omitempty" description:"Processing completion time"`
+	UpdatedAt      time.Time        `json:"updated_at" description:"Last update time"`
+}
+
+// JobListResponse is the API response for listing jobs.
+type JobListResponse struct {
+	Jobs []JobResponse `json:"jobs" description:"List of jobs, newest first"`
+}
+
+// JobProgressResponse is a single progress event on a version's progress stream.
+type JobProgressResponse struct {
+	JobID          int64            `json:"job_id" description:"Job ID" example:"12345"`
+	Stage          models.JobStage  `json:"stage" description:"Current processing stage" example:"embed"`
+	Status         models.JobStatus `json:"status" description:"Job status" example:"running"`
+	Progress       int              `json:"progress" description:"Percentage completion 0-100" example:"45"`
+	ItemsTotal     int              `json:"items_total" description:"Items to process in the current stage"`
+	ItemsProcessed int              `json:"items_processed" description:"Items processed so far in the current stage"`
+	Error          *string          `json:"error,omitempty" description:"Error message if failed"`
+	UpdatedAt      time.Time        `json:"updated_at" description:"Last update time"`
+}
+
+// Clone returns a deep copy of the JobResponse.
+func (r JobResponse) Clone() JobResponse {
+	c := r
+	if r.Error != nil {
+		e := *r.Error
+		c.Error = &e
+	}
+	if r.StartedAt != nil {
+		s := *r.StartedAt
+		c.StartedAt = &s
+	}
+	if r.CompletedAt != nil {
+		comp := *r.CompletedAt
+		c.CompletedAt = &comp
+	}
+	return c
+}
+
+// Clone returns a deep copy of the JobListResponse.
+func (r JobListResponse) Clone() JobListResponse {
+	c := r
+	if r.Jobs != nil {
+		c.Jobs = make([]JobResponse, len(r.Jobs))
+		for i, j := range r.Jobs {
+			c.Jobs[i] = j.Clone()
+		}
+	}
+	return c
+}
+
+// Clone returns a deep copy of the JobProgressResponse.
+func (r JobProgressResponse) Clone() JobProgressResponse {
+	c := r
+	if r.Error != nil {
+		e := *r.Error
+		c.Error = &e
+	}
+	return c
+}
+
+// FileReportResponse is one file's outcome in a job's ingestion report.
+type FileReportResponse struct {
+	Path    string             `json:"path" description:"File path within repository" example:"vendor/github.com/pkg/errors/errors.go"`
+	Stage   models.JobStage    `json:"stage" description:"Stage that decided the outcome" example:"fetch"`
+	Outcome models.FileOutcome `json:"outcome" description:"included, excluded, skipped or failed" example:"excluded"`
+	Rule    *string            `json:"rule,omitempty" description:"Exclude pattern or rule that excluded the file" example:"vendor/**"`
+	Reason  *string            `json:"reason,omitempty" description:"Why the file was excluded, skipped or failed"`
+	Size    int64              `json:"size" description:"File size in bytes, when known"`
+}
+
+// JobReportResponse is the API response for a job's per-file ingestion report.
+type JobReportResponse struct {
+	JobID    int64                `json:"job_id" description:"Job ID" example:"12345"`
+	Included int                  `json:"included" description:"Files ingested"`
+	Excluded int                  `json:"excluded" description:"Files filtered out by pattern, size or extension"`
+	Skipped  int                  `json:"skipped" description:"Files no chunker handles"`
+	Failed   int                  `json:"failed" description:"Files that failed within the error budget"`
+	Files    []FileReportResponse `json:"files" description:"Report entries ordered by path"`
+}
+
+// Clone returns a deep copy of the FileReportResponse.
+func (r FileReportResponse) Clone() FileReportResponse {
+	c := r
+	if r.Rule != nil {
+		rule := *r.Rule
+		c.Rule = &rule
+	}
+	if r.Reason != nil {
+		reason := *r.Reason
+		c.Reason = &reason
+	}
+	return c
+}
+
+// Clone returns a deep copy of the JobReportResponse.
+func (r JobReportResponse) Clone() JobReportResponse {
+	c := r
+	if r.Files != nil {
+		c.Files = make([]FileReportResponse, len(r.Files))
+		for i, f := range r.Files {
+			c.Files[i] = f.Clone()
+		}
+	}
+	return c
+}
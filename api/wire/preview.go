@@ -0,0 +1,91 @@
+package wire
+
+import (
+	"github.com/zoobzio/check"
+	"github.com/zoobzio/vicky/models"
+)
+
+// PreviewRequest is the request body for a dry-run of an ingestion config.
+type PreviewRequest struct {
+	Ref     string                   `json:"ref" description:"Tag, branch or commit SHA whose tree is listed" example:"v1.0.0" validate:"required,max=255"`
+	Configs []IngestionConfigRequest `json:"configs" description:"Candidate ingestion configuration, one per language root" validate:"required,min=1"`
+}
+
+// PreviewFile is one file in an ingestion preview.
+type PreviewFile struct {
+	Path   string  `json:"path" description:"File path within repository" example:"vendor/github.com/pkg/errors/errors.go"`
+	Size   int64   `json:"size" description:"File size in bytes"`
+	Rule   *string `json:"rule,omitempty" description:"Exclude pattern or rule that excluded the file" example:"vendor/**"`
+	Reason *string `json:"reason,omitempty" description:"Why the file would be excluded"`
+}
+
+// PreviewGroup collects the files that share a decision.
+type PreviewGroup struct {
+	Decision models.FileDecision `json:"decision" description:"code, docs, excluded, too_large or unsupported" example:"excluded"`
+	Count    int                 `json:"count" description:"Number of files"`
+	Bytes    int64               `json:"bytes" description:"Total size of the files in bytes"`
+	Files    []PreviewFile       `json:"files" description:"Files ordered by path"`
+}
+
+// PreviewResponse is the API response for a dry-run of an ingestion config.
+type PreviewResponse struct {
+	Ref           string         `json:"ref" description:"Ref whose tree was listed" example:"v1.0.0"`
+	IncludedCount int            `json:"included_count" description:"Files the config would ingest"`
+	IncludedBytes int64          `json:"included_bytes" description:"Total size of the files the config would ingest"`
+	Groups        []PreviewGroup `json:"groups" description:"Files grouped by decision, one group per decision"`
+}
+
+// Clone returns a deep copy of the PreviewRequest.
+func (r PreviewRequest) Clone() PreviewRequest {
+	c := r
+	c.Configs = cloneConfigs(r.Configs)
+	return c
+}
+
+// Validate validates the PreviewRequest.
+func (r *PreviewRequest) Validate() error {
+	if err := check.All(
+		check.Str(r.Ref, "ref").Required().MaxLen(255).V(),
+	).Err(); err != nil {
+		return err
+	}
+	return validateConfigs(r.Configs)
+}
+
+// Clone returns a deep copy of the PreviewFile.
+func (f PreviewFile) Clone() PreviewFile {
+	c := f
+	if f.Rule != nil {
+		rule := *f.Rule
+		c.Rule = &rule
+	}
+	if f.Reason != nil {
+		reason := *f.Reason
+		c.Reason = &reason
+	}
+	return c
+}
+
+// Clone returns a deep copy of the PreviewGroup.
+func (g PreviewGroup) Clone() PreviewGroup {
+	c := g
+	if g.Files != nil {
+		c.Files = make([]PreviewFile, len(g.Files))
+		for i, f := range g.Files {
+			c.Files[i] = f.Clone()
+		}
+	}
+	return c
+}
+
+// Clone returns a deep copy of the PreviewResponse.
+func (r PreviewResponse) Clone() PreviewResponse {
+	c := r
+	if r.Groups != nil {
+		c.Groups = make([]PreviewGroup, len(r.Groups))
+		for i, g := range r.Groups {
+			c.Groups[i] = g.Clone()
+		}
+	}
+	return c
+}
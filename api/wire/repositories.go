@@ -2,6 +2,9 @@ package wire
 
 import (
 	"encoding/json"
+	"maps"
+	"path"
+	"regexp"
 
 	"github.com/zoobzio/check"
 	"github.com/zoobzio/vicky/models"
@@ -27,34 +30,43 @@ type RepositoryListResponse struct {
 
 // RegisterRepositoryRequest is the request body for registering a repository.
 type RegisterRepositoryRequest struct {
-	GitHubID      int64                   `json:"github_id" description:"GitHub repository ID" example:"123456789" validate:"required"`
-	Owner         string                  `json:"owner" description:"Repository owner" example:"octocat" validate:"required,max=255"`
-	Name          string                  `json:"name" description:"Repository name" example:"hello-world" validate:"required,max=255"`
-	FullName      string                  `json:"full_name" description:"Full repository name" example:"octocat/hello-world" validate:"required,max=512"`
-	Description   *string                 `json:"description,omitempty" description:"Repository description" validate:"omitempty,max=1000"`
-	DefaultBranch string                  `json:"default_branch" description:"Default branch" example:"main" validate:"required,max=255"`
-	Private       bool                    `json:"private" description:"Whether repository is private" example:"false"`
-	HTMLURL       string                  `json:"html_url" description:"GitHub URL" example:"https://github.com/octocat/hello-world" validate:"required,url"`
-	Config        IngestionConfigRequest  `json:"config" description:"Ingestion configuration"`
+	GitHubID      int64                    `json:"github_id" description:"GitHub repository ID" example:"123456789" validate:"required"`
+	Owner         string                   `json:"owner" description:"Repository owner" example:"octocat" validate:"required,max=255"`
+	Name          string                   `json:"name" description:"Repository name" example:"hello-world" validate:"required,max=255"`
+	FullName      string                   `json:"full_name" description:"Full repository name" example:"octocat/hello-world" validate:"required,max=512"`
+	Description   *string                  `json:"description,omitempty" description:"Repository description" validate:"omitempty,max=1000"`
+	DefaultBranch string                   `json:"default_branch" description:"Default branch" example:"main" validate:"required,max=255"`
+	Private       bool                     `json:"private" description:"Whether repository is private" example:"false"`
+	HTMLURL       string                   `json:"html_url" description:"GitHub URL" example:"https://github.com/octocat/hello-world" validate:"required,url"`
+	Configs       []IngestionConfigRequest `json:"configs" description:"Ingestion configuration, one per language root" validate:"required,min=1"`
 }
 
-// IngestionConfigRequest is the request body for ingestion configuration.
+// IngestionConfigRequest is the request body for the ingestion configuration
+// of one language root.
 type IngestionConfigRequest struct {
-	Language        models.Language `json:"language" description:"Primary language for SCIP indexing" example:"go" validate:"required,oneof=go typescript"`
-	IncludeDocs     bool            `json:"include_docs" description:"Include markdown documentation" example:"true"`
-	ExcludePatterns []string        `json:"exclude_patterns,omitempty" description:"Additional glob patterns to exclude"`
-	MaxFileSize     *int64          `json:"max_file_size,omitempty" description:"Maximum file size in bytes (default 1MB)"`
-	LanguageConfig  json.RawMessage `json:"language_config,omitempty" description:"Language-specific configuration"`
+	Language        models.Language         `json:"language" description:"Language for SCIP indexing" example:"go" validate:"required,oneof=go typescript python"`
+	Root            string                  `json:"root,omitempty" description:"Project root within the repository, empty for the repository root" example:"web" validate:"max=512"`
+	RootPaths       []string                `json:"root_paths,omitempty" description:"Project roots beneath root, each indexed on its own; empty to index root itself"`
+	IncludeDocs     bool                    `json:"include_docs" description:"Include documentation and schema files" example:"true"`
+	ExcludePatterns []string                `json:"exclude_patterns,omitempty" description:"Additional gitignore-style patterns to exclude"`
+	IncludePatterns []string                `json:"include_patterns,omitempty" description:"Gitignore-style patterns a file must match to be ingested"`
+	MaxFileSize     *int64                  `json:"max_file_size,omitempty" description:"Maximum file size in bytes (default 1MB)"`
+	LanguageConfig  json.RawMessage         `json:"language_config,omitempty" description:"Language-specific configuration"`
+	ContentFormats  models.ContentFormatMap `json:"content_formats,omitempty" description:"Content format per file name suffix, over the defaults; empty to not ingest the suffix" example:"{\".yaml\": \"openapi\"}"`
 }
 
 // IngestionConfigResponse is the API response for ingestion configuration.
 type IngestionConfigResponse struct {
-	ID              int64           `json:"id" description:"Config ID"`
-	Language        models.Language `json:"language" description:"Primary language for SCIP indexing" example:"go"`
-	IncludeDocs     bool            `json:"include_docs" description:"Include markdown documentation"`
-	ExcludePatterns []string        `json:"exclude_patterns" description:"Additional glob patterns to exclude"`
-	MaxFileSize     int64           `json:"max_file_size" description:"Maximum file size in bytes"`
-	LanguageConfig  json.RawMessage `json:"language_config,omitempty" description:"Language-specific configuration"`
+	ID              int64                   `json:"id" description:"Config ID"`
+	Language        models.Language         `json:"language" description:"Language for SCIP indexing" example:"go"`
+	Root            string                  `json:"root" description:"Project root within the repository, empty for the repository root" example:"web"`
+	RootPaths       []string                `json:"root_paths" description:"Project roots beneath root, each indexed on its own"`
+	IncludeDocs     bool                    `json:"include_docs" description:"Include documentation and schema files"`
+	ExcludePatterns []string                `json:"exclude_patterns" description:"Additional gitignore-style patterns to exclude"`
+	IncludePatterns []string                `json:"include_patterns" description:"Gitignore-style patterns a file must match to be ingested"`
+	MaxFileSize     int64                   `json:"max_file_size" description:"Maximum file size in bytes"`
+	LanguageConfig  json.RawMessage         `json:"language_config,omitempty" description:"Language-specific configuration"`
+	ContentFormats  models.ContentFormatMap `json:"content_formats,omitempty" description:"Content format per file name suffix, over the defaults"`
 }
 
 // Clone returns a deep copy of the RepositoryResponse.
@@ -86,7 +98,19 @@ func (r RegisterRepositoryRequest) Clone() RegisterRepositoryRequest {
 		d := *r.Description
 		c.Description = &d
 	}
-	c.Config = r.Config.Clone()
+	c.Configs = cloneConfigs(r.Configs)
+	return c
+}
+
+// cloneConfigs deep-copies a list of ingestion config requests.
+func cloneConfigs(configs []IngestionConfigRequest) []IngestionConfigRequest {
+	if configs == nil {
+		return nil
+	}
+	c := make([]IngestionConfigRequest, len(configs))
+	for idx, config := range configs {
+		c[idx] = config.Clone()
+	}
 	return c
 }
 
@@ -97,6 +121,14 @@ func (r IngestionConfigRequest) Clone() IngestionConfigRequest {
 		c.ExcludePatterns = make([]string, len(r.ExcludePatterns))
 		copy(c.ExcludePatterns, r.ExcludePatterns)
 	}
+	if r.IncludePatterns != nil {
+		c.IncludePatterns = make([]string, len(r.IncludePatterns))
+		copy(c.IncludePatterns, r.IncludePatterns)
+	}
+	if r.RootPaths != nil {
+		c.RootPaths = make([]string, len(r.RootPaths))
+		copy(c.RootPaths, r.RootPaths)
+	}
 	if r.MaxFileSize != nil {
 		m := *r.MaxFileSize
 		c.MaxFileSize = &m
@@ -105,6 +137,7 @@ func (r IngestionConfigRequest) Clone() IngestionConfigRequest {
 		c.LanguageConfig = make(json.RawMessage, len(r.LanguageConfig))
 		copy(c.LanguageConfig, r.LanguageConfig)
 	}
+	c.ContentFormats = maps.Clone(r.ContentFormats)
 	return c
 }
 
@@ -115,10 +148,19 @@ func (r IngestionConfigResponse) Clone() IngestionConfigResponse {
 		c.ExcludePatterns = make([]string, len(r.ExcludePatterns))
 		copy(c.ExcludePatterns, r.ExcludePatterns)
 	}
+	if r.IncludePatterns != nil {
+		c.IncludePatterns = make([]string, len(r.IncludePatterns))
+		copy(c.IncludePatterns, r.IncludePatterns)
+	}
+	if r.RootPaths != nil {
+		c.RootPaths = make([]string, len(r.RootPaths))
+		copy(c.RootPaths, r.RootPaths)
+	}
 	if r.LanguageConfig != nil {
 		c.LanguageConfig = make(json.RawMessage, len(r.LanguageConfig))
 		copy(c.LanguageConfig, r.LanguageConfig)
 	}
+	c.ContentFormats = maps.Clone(r.ContentFormats)
 	return c
 }
 
@@ -135,12 +177,62 @@ func (r *RegisterRepositoryRequest) Validate() error {
 	).Err(); err != nil {
 		return err
 	}
-	return r.Config.Validate()
+	return validateConfigs(r.Configs)
 }
 
 // Validate validates the IngestionConfigRequest.
 func (r *IngestionConfigRequest) Validate() error {
+	return check.Merge(
+		check.All(
+			check.Str(string(r.Language), "language").Required().OneOf([]string{"go", "typescript", "python"}).V(),
+			check.Str(r.Root, "root").MaxLen(512).NotMatch(outsideRoot).V(),
+			check.MaxKeys(r.ContentFormats, 100, "content_formats"),
+		),
+		check.EachValue(r.RootPaths, func(root string) *check.Validation {
+			return check.Str(root, "root_paths").MaxLen(512).NotMatch(outsideRoot).V()
+		}),
+		check.EachKey(r.ContentFormats, func(suffix string) *check.Validation {
+			return check.Str(suffix, "content_formats").Required().MaxLen(128).NotContains("/").V()
+		}),
+		check.EachMapValue(r.ContentFormats, func(format models.ContentFormat) *check.Validation {
+			return check.OneOfValues(format, contentFormats, "content_formats")
+		}),
+	).Err()
+}
+
+// projectRoots returns the project roots a config indexes.
+func (r *IngestionConfigRequest) projectRoots() []string {
+	if len(r.RootPaths) == 0 {
+		return []string{models.CleanRoot(r.Root)}
+	}
+	roots := make([]string, len(r.RootPaths))
+	for i, root := range r.RootPaths {
+		roots[i] = models.CleanRoot(path.Join(r.Root, root))
+	}
+	return roots
+}
+
+// contentFormats are the formats a suffix may map to; empty drops it.
+var contentFormats = append([]models.ContentFormat{""}, models.ContentFormats...)
+
+// outsideRoot matches project roots that are absolute or climb out of the
+// repository.
+var outsideRoot = regexp.MustCompile(`^/|(^|/)\.\.(/|$)`)
+
+// validateConfigs validates a repository's language roots: at least one,
+// each valid, and no two project roots for the same language and path.
+func validateConfigs(configs []IngestionConfigRequest) error {
+	var targets []string
+	for i := range configs {
+		if err := configs[i].Validate(); err != nil {
+			return err
+		}
+		for _, root := range configs[i].projectRoots() {
+			targets = append(targets, string(configs[i].Language)+":"+root)
+		}
+	}
 	return check.All(
-		check.Str(string(r.Language), "language").Required().OneOf([]string{"go", "typescript"}).V(),
+		check.Slice(configs, "configs").NotEmpty().V(),
+		check.Unique(targets, "configs"),
 	).Err()
 }
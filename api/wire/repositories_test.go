@@ -3,6 +3,8 @@ package wire
 import (
 	"strings"
 	"testing"
+
+	"github.com/zoobzio/vicky/models"
 )
 
 func TestRegisterRepositoryRequestValidate_Valid(t *testing.T) {
@@ -13,7 +15,7 @@ func TestRegisterRepositoryRequestValidate_Valid(t *testing.T) {
 		FullName:      "octocat/hello-world",
 		DefaultBranch: "main",
 		HTMLURL:       "https://github.com/octocat/hello-world",
-		Config:        IngestionConfigRequest{Language: "go"},
+		Configs:       []IngestionConfigRequest{{Language: "go"}},
 	}
 	if err := req.Validate(); err != nil {
 		t.Errorf("unexpected error: %v", err)
@@ -28,7 +30,7 @@ func TestRegisterRepositoryRequestValidate_MissingOwner(t *testing.T) {
 		FullName:      "octocat/hello-world",
 		DefaultBranch: "main",
 		HTMLURL:       "https://github.com/octocat/hello-world",
-		Config:        IngestionConfigRequest{Language: "go"},
+		Configs:       []IngestionConfigRequest{{Language: "go"}},
 	}
 	err := req.Validate()
 	if err == nil {
@@ -47,7 +49,7 @@ func TestRegisterRepositoryRequestValidate_MissingName(t *testing.T) {
 		FullName:      "octocat/hello-world",
 		DefaultBranch: "main",
 		HTMLURL:       "https://github.com/octocat/hello-world",
-		Config:        IngestionConfigRequest{Language: "go"},
+		Configs:       []IngestionConfigRequest{{Language: "go"}},
 	}
 	err := req.Validate()
 	if err == nil {
@@ -59,12 +61,9 @@ func TestRegisterRepositoryRequestValidate_MissingName(t *testing.T) {
 }
 
 func TestIngestionConfigRequestValidate_ValidLanguage(t *testing.T) {
-	for _, lang := range []string{"go", "typescript"} {
-		t.Run(lang, func(t *testing.T) {
-			req := &IngestionConfigRequest{Language: "go"}
-			if lang == "typescript" {
-				req.Language = "typescript"
-			}
+	for _, lang := range []models.Language{models.LanguageGo, models.LanguageTypeScript, models.LanguagePython} {
+		t.Run(string(lang), func(t *testing.T) {
+			req := &IngestionConfigRequest{Language: lang}
 			if err := req.Validate(); err != nil {
 				t.Errorf("unexpected error for language %q: %v", lang, err)
 			}
@@ -73,7 +72,7 @@ func TestIngestionConfigRequestValidate_ValidLanguage(t *testing.T) {
 }
 
 func TestIngestionConfigRequestValidate_InvalidLanguage(t *testing.T) {
-	req := &IngestionConfigRequest{Language: "python"}
+	req := &IngestionConfigRequest{Language: "cobol"}
 	err := req.Validate()
 	if err == nil {
 		t.Fatal("expected error for invalid language, got nil")
@@ -82,3 +81,76 @@ func TestIngestionConfigRequestValidate_InvalidLanguage(t *testing.T) {
 		t.Errorf("error = %q, want it to contain %q", err.Error(), "language")
 	}
 }
+
+func TestRegisterRepositoryRequestValidate_Configs(t *testing.T) {
+	tests := []struct {
+		name    string
+		configs []IngestionConfigRequest
+		wantErr bool
+	}{
+		{"none", nil, true},
+		{"monorepo", []IngestionConfigRequest{{Language: "go"}, {Language: "typescript", Root: "web"}}, false},
+		{"same root, two languages", []IngestionConfigRequest{{Language: "go"}, {Language: "python"}}, false},
+		{"duplicate", []IngestionConfigRequest{{Language: "go", Root: "api"}, {Language: "go", Root: "api/"}}, true},
+		{"invalid member", []IngestionConfigRequest{{Language: "go"}, {Language: "cobol"}}, true},
+		{"root paths", []IngestionConfigRequest{{Language: "go", RootPaths: []string{"services/payments", "services/billing"}}}, false},
+		{"duplicate root path", []IngestionConfigRequest{{Language: "go", Root: "services", RootPaths: []string{"payments"}}, {Language: "go", Root: "services/payments"}}, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := &RegisterRepositoryRequest{
+				GitHubID:      123,
+				Owner:         "octocat",
+				Name:          "hello-world",
+				FullName:      "octocat/hello-world",
+				DefaultBranch: "main",
+				HTMLURL:       "https://github.com/octocat/hello-world",
+				Configs:       tt.configs,
+			}
+			if err := req.Validate(); (err != nil) != tt.wantErr {
+				t.Errorf("Validate() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestIngestionConfigRequestValidate_Root(t *testing.T) {
+	for _, root := range []string{"", "web", "services/api", "./web", "web/"} {
+		req := &IngestionConfigRequest{Language: "go", Root: root}
+		if err := req.Validate(); err != nil {
+			t.Errorf("root %q: unexpected error: %v", root, err)
+		}
+	}
+	for _, root := range []string{"/etc", "..", "../other", "web/../../other"} {
+		req := &IngestionConfigRequest{Language: "go", Root: root}
+		if err := req.Validate(); err == nil || !strings.Contains(err.Error(), "root") {
+			t.Errorf("root %q: error = %v, want a root error", root, err)
+		}
+		req = &IngestionConfigRequest{Language: "go", RootPaths: []string{"web", root}}
+		if err := req.Validate(); err == nil || !strings.Contains(err.Error(), "root_paths") {
+			t.Errorf("root path %q: error = %v, want a root_paths error", root, err)
+		}
+	}
+}
+
+func TestIngestionConfigRequestValidate_ContentFormats(t *testing.T) {
+	req := &IngestionConfigRequest{Language: "go", ContentFormats: models.ContentFormatMap{
+		".yaml":   models.ContentFormatOpenAPI,
+		"api.sql": models.ContentFormatText,
+		".txt":    "",
+	}}
+	if err := req.Validate(); err != nil {
+		t.Errorf("unexpected error: %v", err)
+	}
+
+	for _, formats := range []models.ContentFormatMap{
+		{".yaml": "yaml"},
+		{"": models.ContentFormatText},
+		{"docs/.txt": models.ContentFormatText},
+	} {
+		req := &IngestionConfigRequest{Language: "go", ContentFormats: formats}
+		if err := req.Validate(); err == nil || !strings.Contains(err.Error(), "content_formats") {
+			t.Errorf("formats %v: error = %v, want a content_formats error", formats, err)
+		}
+	}
+}
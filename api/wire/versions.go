@@ -9,16 +9,25 @@ import (
 
 // VersionResponse is the API response for version data.
 type VersionResponse struct {
-	ID           int64                `json:"id" description:"Version ID"`
-	RepositoryID int64                `json:"repository_id" description:"Parent repository ID"`
-	Owner        string               `json:"owner" description:"Repository owner" example:"octocat"`
-	RepoName     string               `json:"repo_name" description:"Repository name" example:"hello-world"`
-	Tag          string               `json:"tag" description:"Version tag" example:"v1.0.0"`
-	CommitSHA    string               `json:"commit_sha" description:"Git commit SHA" example:"abc123def456"`
-	Status       models.VersionStatus `json:"status" description:"Ingestion status" example:"ready"`
-	Error        *string              `json:"error,omitempty" description:"Error message if failed"`
-	CreatedAt    time.Time            `json:"created_at" description:"Creation timestamp"`
-	UpdatedAt    time.Time            `json:"updated_at" description:"Last update timestamp"`
+	ID                int64                      `json:"id" description:"Version ID"`
+	RepositoryID      int64                      `json:"repository_id" description:"Parent repository ID"`
+	Owner             string                     `json:"owner" description:"Repository owner" example:"octocat"`
+	RepoName          string                     `json:"repo_name" description:"Repository name" example:"hello-world"`
+	Tag               string                     `json:"tag" description:"Version tag" example:"v1.0.0"`
+	CommitSHA         string                     `json:"commit_sha" description:"Git commit SHA" example:"abc123def456"`
+	Status            models.VersionStatus       `json:"status" description:"Ingestion status" example:"ready"`
+	Error             *string                    `json:"error,omitempty" description:"Error message if failed"`
+	CreatedAt         time.Time                  `json:"created_at" description:"Creation timestamp"`
+	UpdatedAt         time.Time                  `json:"updated_at" description:"Last update timestamp"`
+	ReplacesID        *int64                     `json:"replaces_id,omitempty" description:"Live version this re-ingestion replaces once complete"`
+	EmbeddingStrategy *EmbeddingStrategyResponse `json:"embedding_strategy,omitempty" description:"How chunk text was enriched before embedding"`
+}
+
+// EmbeddingStrategyResponse describes how a version's chunk text was
+// enriched before embedding.
+type EmbeddingStrategyResponse struct {
+	Name      string                        `json:"name" description:"Strategy name" example:"raw"`
+	Templates map[models.ContentType]string `json:"templates,omitempty" description:"Embedding-text template per content type; others embed content verbatim"`
 }
 
 // VersionListResponse is the API response for listing versions.
@@ -38,6 +47,26 @@ func (v VersionResponse) Clone() VersionResponse {
 		e := *v.Error
 		c.Error = &e
 	}
+	if v.ReplacesID != nil {
+		r := *v.ReplacesID
+		c.ReplacesID = &r
+	}
+	if v.EmbeddingStrategy != nil {
+		s := v.EmbeddingStrategy.Clone()
+		c.EmbeddingStrategy = &s
+	}
+	return c
+}
+
+// Clone returns a deep copy of the EmbeddingStrategyResponse.
+func (r EmbeddingStrategyResponse) Clone() EmbeddingStrategyResponse {
+	c := r
+	if r.Templates != nil {
+		c.Templates = make(map[models.ContentType]string, len(r.Templates))
+		for k, v := range r.Templates {
+			c.Templates[k] = v
+		}
+	}
 	return c
 }
 
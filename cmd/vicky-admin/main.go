@@ -9,6 +9,8 @@ import (
 
 	"github.com/jmoiron/sqlx"
 	_ "github.com/lib/pq"
+	"github.com/minio/minio-go/v7"
+	"github.com/minio/minio-go/v7/pkg/credentials"
 	"github.com/zoobzio/astql/postgres"
 	"github.com/zoobzio/cereal"
 	"github.com/zoobzio/rocco/session"
@@ -46,6 +48,9 @@ func run() error {
 	if err := sum.Config[config.Encryption](ctx, k, nil); err != nil {
 		return fmt.Errorf("failed to load encryption config: %w", err)
 	}
+	if err := sum.Config[config.Storage](ctx, k, nil); err != nil {
+		return fmt.Errorf("failed to load storage config: %w", err)
+	}
 	if err := sum.Config[config.Observability](ctx, k, nil); err != nil {
 		return fmt.Errorf("failed to load observability config: %w", err)
 	}
@@ -97,10 +102,28 @@ func run() error {
 		return fmt.Errorf("failed to create jobs store: %w", err)
 	}
 
+	// Create versions store
+	versionsStore, err := stores.NewVersions(db, postgres.New())
+	if err != nil {
+		return fmt.Errorf("failed to create versions store: %w", err)
+	}
+
+	// Connect to object storage; rebuilds check for a stored SCIP index
+	storageCfg := sum.MustUse[config.Storage](ctx)
+	minioClient, err := minio.New(storageCfg.Endpoint, &minio.Options{
+		Creds:  credentials.NewStaticV4(storageCfg.AccessKey, storageCfg.SecretKey, ""),
+		Secure: storageCfg.UseSSL,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to create minio client: %w", err)
+	}
+
 	// Register stores against admin contracts
 	sum.Register[admincontracts.Users](k, usersStore)
 	sum.Register[admincontracts.Repositories](k, reposStore)
 	sum.Register[admincontracts.Jobs](k, jobsStore)
+	sum.Register[admincontracts.Versions](k, versionsStore)
+	sum.Register[admincontracts.SCIPIndexes](k, stores.NewSCIPIndexes(minioClient, storageCfg.Bucket))
 
 	// Register model boundaries (User needs encryption/decryption)
 	if _, err := sum.NewBoundary[models.User](k); err != nil {
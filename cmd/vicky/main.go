@@ -145,6 +145,11 @@ func run() error {
 	sum.Register[contracts.Sessions](k, allStores.Sessions)
 	sum.Register[contracts.Blobs](k, allStores.Blobs)
 	sum.Register[contracts.Keys](k, allStores.Keys)
+	sum.Register[contracts.FileReports](k, allStores.FileReports)
+	sum.Register[contracts.EmbeddingCache](k, allStores.EmbeddingCache)
+
+	// Raw SCIP indexes are streamed, so they bypass the grub bucket
+	sum.Register[contracts.SCIPIndexes](k, stores.NewSCIPIndexes(minioClient, storageCfg.Bucket))
 
 	// Register external services
 	sum.Register[contracts.GitHub](k, github.NewClient())
@@ -6,6 +6,7 @@ import "github.com/zoobzio/vicky/models"
 type Indexer struct {
 	GoAddr string `env:"VICKY_INDEXER_GO_ADDR"`
 	TsAddr string `env:"VICKY_INDEXER_TS_ADDR"`
+	PyAddr string `env:"VICKY_INDEXER_PY_ADDR"`
 }
 
 // Validate checks Indexer configuration for required values.
@@ -22,5 +23,8 @@ func (c Indexer) Addresses() map[models.Language]string {
 	if c.TsAddr != "" {
 		addrs[models.LanguageTypeScript] = c.TsAddr
 	}
+	if c.PyAddr != "" {
+		addrs[models.LanguagePython] = c.PyAddr
+	}
 	return addrs
 }
@@ -21,6 +21,18 @@ func TestAddresses_Both(t *testing.T) {
 	}
 }
 
+func TestAddresses_Python(t *testing.T) {
+	c := Indexer{PyAddr: "localhost:9092"}
+	addrs := c.Addresses()
+
+	if got, ok := addrs[models.LanguagePython]; !ok || got != "localhost:9092" {
+		t.Errorf("Python address = %q (present=%v), want %q", got, ok, "localhost:9092")
+	}
+	if len(addrs) != 1 {
+		t.Errorf("len(addrs) = %d, want 1", len(addrs))
+	}
+}
+
 func TestAddresses_GoOnly(t *testing.T) {
 	c := Indexer{GoAddr: "localhost:9090"}
 	addrs := c.Addresses()
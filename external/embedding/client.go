@@ -15,15 +15,17 @@ import (
 
 // Client implements contracts.Embedder using vex embedding providers.
 type Client struct {
-	svc  *vex.Service
-	dims int
+	svc      *vex.Service
+	dims     int
+	provider string
+	model    string
 }
 
 // NewClient creates a new embedding client for the given provider.
 // Supported providers: stub, openai, voyage, gemini, cohere.
 func NewClient(provider, model, apiKey string, dimensions int) (*Client, error) {
 	if provider == "" || provider == "stub" {
-		return &Client{dims: dimensions}, nil
+		return &Client{dims: dimensions, provider: "stub"}, nil
 	}
 
 	p, err := newProvider(provider, model, apiKey, dimensions)
@@ -37,7 +39,7 @@ func NewClient(provider, model, apiKey string, dimensions int) (*Client, error)
 		vex.WithCircuitBreaker(5, 30*time.Second),
 	)
 
-	return &Client{svc: svc, dims: dimensions}, nil
+	return &Client{svc: svc, dims: dimensions, provider: provider, model: model}, nil
 }
 
 // Embed generates embeddings for the given texts (document mode).
@@ -83,6 +85,16 @@ func (c *Client) Dimensions() int {
 	return c.dims
 }
 
+// Provider returns the embedding provider name.
+func (c *Client) Provider() string {
+	return c.provider
+}
+
+// Model returns the embedding model name.
+func (c *Client) Model() string {
+	return c.model
+}
+
 // newProvider creates a vex.Provider from configuration.
 func newProvider(provider, model, apiKey string, dimensions int) (vex.Provider, error) {
 	if apiKey == "" {
@@ -0,0 +1,157 @@
+package github
+
+import (
+	"archive/tar"
+	"compress/gzip"
+	"context"
+	"crypto/sha1" //nolint:gosec // git blob IDs are SHA-1
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+
+	"github.com/google/go-github/v60/github"
+	"github.com/zoobzio/pipz"
+)
+
+// Archive pipeline identities.
+var (
+	archiveProcessorID   = pipz.NewIdentity("github.archive.call", "GitHub API call for archive link")
+	archiveTimeoutID     = pipz.NewIdentity("github.archive.timeout", "Timeout for archive link calls")
+	archiveBackoffID     = pipz.NewIdentity("github.archive.backoff", "Backoff retry for archive link calls")
+	archiveBreakerID     = pipz.NewIdentity("github.archive.breaker", "Circuit breaker for archive link calls")
+	archiveRateLimiterID = pipz.NewIdentity("github.archive.ratelimit", "Rate limiter for archive link calls")
+)
+
+// archiveCall carries request and response through the pipeline.
+type archiveCall struct {
+	token string
+	owner string
+	repo  string
+	ref   string
+	url   string
+}
+
+func (c *archiveCall) Clone() *archiveCall {
+	clone := *c
+	return &clone
+}
+
+// buildArchivePipeline constructs the resilient pipeline that resolves the
+// tarball download URL. The download itself is streamed outside the pipeline
+// so it is bounded by the caller's context rather than the API call timeout.
+func (c *Client) buildArchivePipeline() pipz.Chainable[*archiveCall] {
+	processor := pipz.Apply(archiveProcessorID, func(ctx context.Context, call *archiveCall) (*archiveCall, error) {
+		gh := newGitHubClient(ctx, call.token)
+
+		opts := &github.RepositoryContentGetOptions{Ref: call.ref}
+		link, _, err := gh.Repositories.GetArchiveLink(ctx, call.owner, call.repo, github.Tarball, opts, 0)
+		if err != nil {
+			return call, err
+		}
+
+		call.url = link.String()
+		return call, nil
+	})
+
+	return pipz.NewRateLimiter(archiveRateLimiterID, ghRatePerSecond, ghRateBurst,
+		pipz.NewCircuitBreaker(archiveBreakerID,
+			pipz.NewBackoff(archiveBackoffID,
+				pipz.NewTimeout(archiveTimeoutID, processor, ghTimeout),
+				ghMaxAttempts, ghBackoffDelay,
+			),
+			ghFailureThreshold, ghResetTimeout,
+		),
+	)
+}
+
+// StreamArchive downloads the tarball for ref in a single request and walks it
+// without buffering the whole archive. include is called with the path and size
+// of every regular file; files it accepts are read and passed to fn in archive
+// order. An error from fn stops the walk and is returned.
+func (c *Client) StreamArchive(ctx context.Context, token, owner, repo, ref string, include func(path string, size int64) bool, fn func(*FileContent) error) error {
+	result, err := c.archivePipeline.Process(ctx, &archiveCall{
+		token: token,
+		owner: owner,
+		repo:  repo,
+		ref:   ref,
+	})
+	if err != nil {
+		return fmt.Errorf("resolve archive link: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, result.url, nil)
+	if err != nil {
+		return err
+	}
+
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("download archive: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("download archive: unexpected status %s", resp.Status)
+	}
+
+	return walkTarball(resp.Body, include, fn)
+}
+
+// walkTarball reads a gzipped repository tarball. GitHub nests every entry
+// under a single "<owner>-<repo>-<sha>/" directory, which is stripped so paths
+// match the repository tree.
+func walkTarball(r io.Reader, include func(path string, size int64) bool, fn func(*FileContent) error) error {
+	gz, err := gzip.NewReader(r)
+	if err != nil {
+		return fmt.Errorf("open archive: %w", err)
+	}
+	defer gz.Close()
+
+	tr := tar.NewReader(gz)
+	for {
+		hdr, err := tr.Next()
+		if errors.Is(err, io.EOF) {
+			return nil
+		}
+		if err != nil {
+			return fmt.Errorf("read archive: %w", err)
+		}
+
+		if hdr.Typeflag != tar.TypeReg {
+			continue
+		}
+
+		_, path, ok := strings.Cut(hdr.Name, "/")
+		if !ok || path == "" {
+			continue
+		}
+		if !include(path, hdr.Size) {
+			continue
+		}
+
+		content, err := io.ReadAll(tr)
+		if err != nil {
+			return fmt.Errorf("read %s from archive: %w", path, err)
+		}
+
+		if err := fn(&FileContent{
+			Path:    path,
+			SHA:     blobSHA(content),
+			Content: content,
+			Size:    hdr.Size,
+		}); err != nil {
+			return err
+		}
+	}
+}
+
+// blobSHA computes the git blob ID for content, matching TreeEntry.SHA.
+func blobSHA(content []byte) string {
+	h := sha1.New() //nolint:gosec // git blob IDs are SHA-1
+	fmt.Fprintf(h, "blob %d\x00", len(content))
+	h.Write(content)
+	return hex.EncodeToString(h.Sum(nil))
+}
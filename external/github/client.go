@@ -2,6 +2,8 @@ package github
 
 import (
 	"context"
+	"errors"
+	"fmt"
 	"sync"
 	"time"
 
@@ -58,6 +60,20 @@ type FileContent struct {
 	Size    int64
 }
 
+// FileError reports a file that could not be retrieved.
+type FileError struct {
+	Path string
+	Err  error
+}
+
+func (e *FileError) Error() string {
+	return fmt.Sprintf("%s: %v", e.Path, e.Err)
+}
+
+func (e *FileError) Unwrap() error {
+	return e.Err
+}
+
 // treeCall carries request and response through the pipeline.
 type treeCall struct {
 	token   string
@@ -101,9 +117,10 @@ func (c *fileCall) Clone() *fileCall {
 
 // Client implements contracts.GitHub using google/go-github.
 type Client struct {
-	workers      int
-	treePipeline pipz.Chainable[*treeCall]
-	filePipeline pipz.Chainable[*fileCall]
+	workers         int
+	treePipeline    pipz.Chainable[*treeCall]
+	filePipeline    pipz.Chainable[*fileCall]
+	archivePipeline pipz.Chainable[*archiveCall]
 }
 
 // NewClient creates a new GitHub API client.
@@ -113,6 +130,7 @@ func NewClient() *Client {
 	}
 	c.treePipeline = c.buildTreePipeline()
 	c.filePipeline = c.buildFilePipeline()
+	c.archivePipeline = c.buildArchivePipeline()
 	return c
 }
 
@@ -230,12 +248,15 @@ func (c *Client) GetFileContent(ctx context.Context, token, owner, repo, path, r
 
 // GetFileContentBatch retrieves multiple files concurrently.
 // Uses the resilient GetFileContent pipeline internally.
+// Files that fail are reported as joined *FileError values alongside the
+// successful results.
 func (c *Client) GetFileContentBatch(ctx context.Context, token, owner, repo, ref string, paths []string) ([]*FileContent, error) {
 	if len(paths) == 0 {
 		return nil, nil
 	}
 
 	type result struct {
+		path    string
 		content *FileContent
 		err     error
 	}
@@ -260,7 +281,7 @@ func (c *Client) GetFileContentBatch(ctx context.Context, token, owner, repo, re
 			defer wg.Done()
 			for path := range pathChan {
 				content, err := c.GetFileContent(ctx, token, owner, repo, path, ref)
-				results <- result{content: content, err: err}
+				results <- result{path: path, content: content, err: err}
 			}
 		}()
 	}
@@ -270,14 +291,21 @@ func (c *Client) GetFileContentBatch(ctx context.Context, token, owner, repo, re
 		close(results)
 	}()
 
-	var contents []*FileContent
+	var (
+		contents []*FileContent
+		errs     []error
+	)
 	for r := range results {
-		if r.err == nil && r.content != nil {
+		if r.err != nil {
+			errs = append(errs, &FileError{Path: r.path, Err: r.err})
+			continue
+		}
+		if r.content != nil {
 			contents = append(contents, r.content)
 		}
 	}
 
-	return contents, nil
+	return contents, errors.Join(errs...)
 }
 
 // Close shuts down the pipelines.
@@ -294,6 +322,11 @@ func (c *Client) Close() error {
 			errs = append(errs, err)
 		}
 	}
+	if c.archivePipeline != nil {
+		if err := c.archivePipeline.Close(); err != nil {
+			errs = append(errs, err)
+		}
+	}
 
 	if len(errs) > 0 {
 		return errs[0]
@@ -3,11 +3,14 @@ package indexer
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"io"
 	"sync"
 	"time"
 
 	"github.com/zoobzio/pipz"
+	vickyScip "github.com/zoobzio/vicky/internal/scip"
 	pb "github.com/zoobzio/vicky/proto/indexer"
 	"google.golang.org/grpc"
 	"google.golang.org/grpc/credentials/insecure"
@@ -35,35 +38,122 @@ type Request struct {
 	Tag          string
 	CommitSHA    string
 	Language     models.Language
+	Root         string // project root to index from; empty for the repository root
+
+	// Language-specific settings; only the one matching Language is sent.
+	Go         *models.GoConfig
+	TypeScript *models.TypeScriptConfig
+	Python     *models.PythonConfig
+}
+
+// toProto converts the request for the wire.
+func (r Request) toProto() *pb.IndexRequest {
+	req := &pb.IndexRequest{
+		JobId:        r.JobID,
+		RepositoryId: r.RepositoryID,
+		VersionId:    r.VersionID,
+		UserId:       r.UserID,
+		Owner:        r.Owner,
+		RepoName:     r.RepoName,
+		Tag:          r.Tag,
+		CommitSha:    r.CommitSHA,
+		Language:     string(r.Language),
+		Root:         r.Root,
+	}
+	switch {
+	case r.Language == models.LanguageGo && r.Go != nil:
+		req.LanguageConfig = &pb.IndexRequest_Go{Go: &pb.GoConfig{
+			ModulePath: r.Go.ModulePath,
+			BuildTags:  r.Go.BuildTags,
+		}}
+	case r.Language == models.LanguageTypeScript && r.TypeScript != nil:
+		req.LanguageConfig = &pb.IndexRequest_Typescript{Typescript: &pb.TypeScriptConfig{
+			TsconfigPath: r.TypeScript.TsConfigPath,
+			IncludeTests: r.TypeScript.IncludeTests,
+		}}
+	case r.Language == models.LanguagePython && r.Python != nil:
+		req.LanguageConfig = &pb.IndexRequest_Python{Python: &pb.PythonConfig{
+			ProjectName:    r.Python.ProjectName,
+			ProjectVersion: r.Python.ProjectVersion,
+			IncludeTests:   r.Python.IncludeTests,
+		}}
+	}
+	return req
 }
 
 // Result contains the output from a SCIP indexer.
 type Result struct {
 	JobID     int64
 	VersionID int64
-	IndexData []byte // raw SCIP protobuf
+	Index     io.ReadSeekCloser `json:"-"` // raw SCIP protobuf; nil when Error is set, closed by the caller
 	Error     string
 }
 
+// Close releases the index, if any.
+func (r *Result) Close() error {
+	if r.Index == nil {
+		return nil
+	}
+	return r.Index.Close()
+}
+
 // indexCall carries request and response through the pipeline.
 type indexCall struct {
 	request Request
 	result  *Result
 }
 
+// Clone shares the spooled index: it is a single file on disk, owned by
+// whoever receives the final result.
 func (c *indexCall) Clone() *indexCall {
 	clone := *c
 	if c.result != nil {
 		r := *c.result
-		if c.result.IndexData != nil {
-			r.IndexData = make([]byte, len(c.result.IndexData))
-			copy(r.IndexData, c.result.IndexData)
-		}
 		clone.result = &r
 	}
 	return &clone
 }
 
+// receive drains an index stream into a spool. An in-band error ends the
+// stream and is returned on the result without an index.
+func receive(stream pb.IndexerService_IndexClient) (*Result, error) {
+	sp, err := vickyScip.NewSpool("vicky-index-*.scip")
+	if err != nil {
+		return nil, fmt.Errorf("create index spool: %w", err)
+	}
+
+	result := &Result{}
+	for {
+		chunk, err := stream.Recv()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			_ = sp.Close()
+			return nil, fmt.Errorf("receive index: %w", err)
+		}
+
+		result.JobID = chunk.JobId
+		result.VersionID = chunk.VersionId
+		if chunk.Error != "" {
+			_ = sp.Close()
+			result.Error = chunk.Error
+			return result, nil
+		}
+		if _, err := sp.Write(chunk.Data); err != nil {
+			_ = sp.Close()
+			return nil, fmt.Errorf("spool index: %w", err)
+		}
+	}
+
+	if _, err := sp.Seek(0, io.SeekStart); err != nil {
+		_ = sp.Close()
+		return nil, fmt.Errorf("rewind index spool: %w", err)
+	}
+	result.Index = sp
+	return result, nil
+}
+
 // langPipeline holds per-language connection and circuit breaker.
 type langPipeline struct {
 	addr     string
@@ -124,27 +214,19 @@ func (c *Client) buildPipeline(lang models.Language, conn *grpc.ClientConn) pipz
 	breakerID := pipz.NewIdentity(fmt.Sprintf("indexer.%s.breaker", lang), "Circuit breaker for indexer sidecar")
 
 	processor := pipz.Apply(processorID, func(ctx context.Context, call *indexCall) (*indexCall, error) {
-		res, err := client.Index(ctx, &pb.IndexRequest{
-			JobId:        call.request.JobID,
-			RepositoryId: call.request.RepositoryID,
-			VersionId:    call.request.VersionID,
-			UserId:       call.request.UserID,
-			Owner:        call.request.Owner,
-			RepoName:     call.request.RepoName,
-			Tag:          call.request.Tag,
-			CommitSha:    call.request.CommitSHA,
-			Language:     string(call.request.Language),
-		})
+		stream, err := client.Index(ctx, call.request.toProto())
 		if err != nil {
 			return call, fmt.Errorf("indexer %s: %w", lang, err)
 		}
 
-		call.result = &Result{
-			JobID:     res.JobId,
-			VersionID: res.VersionId,
-			IndexData: res.IndexData,
-			Error:     res.Error,
+		// The whole transfer runs under the call's timeout, and a broken
+		// stream is retried from the start
+		result, err := receive(stream)
+		if err != nil {
+			return call, fmt.Errorf("indexer %s: %w", lang, err)
 		}
+
+		call.result = result
 		return call, nil
 	})
 
@@ -157,7 +239,9 @@ func (c *Client) buildPipeline(lang models.Language, conn *grpc.ClientConn) pipz
 	)
 }
 
-// Index calls the appropriate language-specific indexer sidecar.
+// Index calls the appropriate language-specific indexer sidecar. The index
+// is streamed from the sidecar in chunks, so its size is not bound by gRPC's
+// message limit. The caller must Close the result.
 func (c *Client) Index(ctx context.Context, req Request) (*Result, error) {
 	lp, err := c.getPipeline(req.Language)
 	if err != nil {
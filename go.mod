@@ -19,6 +19,7 @@ require (
 	github.com/zoobzio/grub v0.1.8
 	github.com/zoobzio/grub/minio v0.0.0-20260201215402-4f15c321a465
 	github.com/zoobzio/pipz v1.0.4
+	github.com/zoobzio/rocco v0.1.13
 	github.com/zoobzio/sum v0.0.7
 	github.com/zoobzio/vex v0.0.1
 	github.com/zoobzio/vicky/proto v0.0.0-00010101000000-000000000000
@@ -123,7 +124,6 @@ require (
 	github.com/zoobzio/edamame v1.0.1 // indirect
 	github.com/zoobzio/fig v0.0.1 // indirect
 	github.com/zoobzio/openapi v1.0.1 // indirect
-	github.com/zoobzio/rocco v0.1.13 // indirect
 	github.com/zoobzio/scio v0.0.3 // indirect
 	github.com/zoobzio/sentinel v1.0.2 // indirect
 	github.com/zoobzio/slush v0.0.2 // indirect
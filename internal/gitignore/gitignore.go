@@ -0,0 +1,251 @@
+// Package gitignore matches repository paths against gitignore-style rules.
+//
+// Patterns follow gitignore(5): blank lines and # comments are skipped, a
+// leading ! negates, a trailing / matches directories only, a pattern with a
+// slash before its end is anchored to its base directory, and ** matches
+// across directories. The last matching rule decides, and a file whose
+// parent directory is excluded cannot be re-included.
+package gitignore
+
+import (
+	"path"
+	"regexp"
+	"strings"
+)
+
+// Rule is one compiled pattern.
+type Rule struct {
+	Pattern string // as written, without a leading ! or surrounding spaces
+	Source  string // where the rule came from, e.g. ".gitignore"
+	Line    int    // line within Source, or 1-based index in a pattern list
+	Negate  bool   // the rule re-includes what it matches
+
+	base    string // directory the pattern is relative to; empty for the root
+	dirOnly bool
+	re      *regexp.Regexp
+}
+
+// Matcher holds rules in precedence order; later rules win.
+type Matcher struct {
+	rules []*Rule
+}
+
+// New compiles patterns relative to the repository root. Source names them
+// in reports, and each rule's Line is its position in patterns.
+func New(source string, patterns []string) *Matcher {
+	m := &Matcher{}
+	m.Add(source, "", patterns)
+	return m
+}
+
+// Add compiles patterns relative to the base directory, with precedence over
+// the rules already added. Invalid patterns are skipped, as git does.
+func (m *Matcher) Add(source, base string, patterns []string) {
+	for i, line := range patterns {
+		if r := compile(line); r != nil {
+			r.Source, r.Line, r.base = source, i+1, base
+			m.rules = append(m.rules, r)
+		}
+	}
+}
+
+// AddFile compiles the content of an ignore file at file, relative to the
+// directory that holds it.
+func (m *Matcher) AddFile(file, content string) {
+	base := path.Dir(file)
+	if base == "." {
+		base = ""
+	}
+	m.Add(file, base, strings.Split(strings.ReplaceAll(content, "\r\n", "\n"), "\n"))
+}
+
+// Len returns the number of rules.
+func (m *Matcher) Len() int {
+	if m == nil {
+		return 0
+	}
+	return len(m.rules)
+}
+
+// Match reports whether the rules exclude the file at p, and the rule that
+// decided. The rule is a negation when the file was re-included, and nil
+// when no rule matched.
+func (m *Matcher) Match(p string) (*Rule, bool) {
+	if m.Len() == 0 {
+		return nil, false
+	}
+	// An excluded directory excludes everything beneath it
+	for i := strings.IndexByte(p, '/'); i >= 0; i = nextSlash(p, i) {
+		if r := m.last(p[:i], true); r != nil && !r.Negate {
+			return r, true
+		}
+	}
+	r := m.last(p, false)
+	return r, r != nil && !r.Negate
+}
+
+// nextSlash returns the index of the next slash after i, or -1.
+func nextSlash(p string, i int) int {
+	j := strings.IndexByte(p[i+1:], '/')
+	if j < 0 {
+		return -1
+	}
+	return i + 1 + j
+}
+
+// last returns the last rule matching p, a directory when dir is set.
+func (m *Matcher) last(p string, dir bool) *Rule {
+	for i := len(m.rules) - 1; i >= 0; i-- {
+		r := m.rules[i]
+		if r.dirOnly && !dir {
+			continue
+		}
+		if r.matches(p) {
+			return r
+		}
+	}
+	return nil
+}
+
+// matches reports whether the rule matches p, a path from the repository root.
+func (r *Rule) matches(p string) bool {
+	if r.base != "" {
+		if !strings.HasPrefix(p, r.base+"/") {
+			return false
+		}
+		p = p[len(r.base)+1:]
+	}
+	return r.re.MatchString(p)
+}
+
+// MatchesName reports whether the rule matches a file of this name on its
+// own, at the top of its base directory. Rules that do not match only
+// exclude what lies beneath a directory.
+func (r *Rule) MatchesName(name string) bool {
+	return !r.dirOnly && r.re.MatchString(name)
+}
+
+// String renders the rule as written, with its negation.
+func (r *Rule) String() string {
+	if r.Negate {
+		return "!" + r.Pattern
+	}
+	return r.Pattern
+}
+
+// compile parses one pattern line, returning nil for blank lines, comments,
+// and patterns that do not compile.
+func compile(line string) *Rule {
+	line = trimTrailingSpace(line)
+	if line == "" || line[0] == '#' {
+		return nil
+	}
+
+	r := &Rule{}
+	if line[0] == '!' {
+		r.Negate = true
+		line = line[1:]
+	} else if strings.HasPrefix(line, `\!`) || strings.HasPrefix(line, `\#`) {
+		line = line[1:]
+	}
+	r.Pattern = line
+
+	if strings.HasSuffix(line, "/") {
+		r.dirOnly = true
+		line = strings.TrimRight(line, "/")
+	}
+	if line == "" {
+		return nil
+	}
+
+	// A slash anywhere but the end anchors the pattern to its base
+	anchored := strings.Contains(line, "/")
+	line = strings.TrimPrefix(line, "/")
+
+	var b strings.Builder
+	b.WriteString("^")
+	if !anchored {
+		b.WriteString("(?:.*/)?")
+	}
+	if !translate(&b, line) {
+		return nil
+	}
+	b.WriteString("$")
+
+	re, err := regexp.Compile(b.String())
+	if err != nil {
+		return nil
+	}
+	r.re = re
+	return r
+}
+
+// translate writes the regular expression for a glob. It reports false for
+// a glob with an unterminated character class.
+func translate(b *strings.Builder, glob string) bool {
+	for i := 0; i < len(glob); i++ {
+		c := glob[i]
+		switch {
+		case strings.HasPrefix(glob[i:], "**/") && (i == 0 || glob[i-1] == '/'):
+			// Zero or more leading directories
+			b.WriteString("(?:.*/)?")
+			i += 2
+		case glob[i:] == "**" && i > 0 && glob[i-1] == '/':
+			// Everything inside
+			b.WriteString(".*")
+			i++
+		case c == '*':
+			b.WriteString("[^/]*")
+			for i+1 < len(glob) && glob[i+1] == '*' {
+				i++
+			}
+		case c == '?':
+			b.WriteString("[^/]")
+		case c == '[':
+			end := classEnd(glob, i)
+			if end < 0 {
+				return false
+			}
+			class := glob[i+1 : end]
+			if strings.HasPrefix(class, "!") {
+				class = "^" + class[1:]
+			}
+			b.WriteString("[" + strings.ReplaceAll(class, `\`, `\\`) + "]")
+			i = end
+		case c == '\\' && i+1 < len(glob):
+			i++
+			b.WriteString(regexp.QuoteMeta(glob[i : i+1]))
+		default:
+			b.WriteString(regexp.QuoteMeta(glob[i : i+1]))
+		}
+	}
+	return true
+}
+
+// classEnd returns the index of the ] closing the class opened at i, or -1.
+func classEnd(glob string, i int) int {
+	j := i + 1
+	if j < len(glob) && (glob[j] == '!' || glob[j] == '^') {
+		j++
+	}
+	if j < len(glob) && glob[j] == ']' {
+		j++ // a leading ] is literal
+	}
+	for ; j < len(glob); j++ {
+		if glob[j] == ']' {
+			return j
+		}
+	}
+	return -1
+}
+
+// trimTrailingSpace drops trailing spaces unless escaped with a backslash.
+func trimTrailingSpace(line string) string {
+	for strings.HasSuffix(line, " ") && !strings.HasSuffix(line, `\ `) {
+		line = line[:len(line)-1]
+	}
+	if strings.HasSuffix(line, `\ `) {
+		line = line[:len(line)-2] + " "
+	}
+	return line
+}
@@ -0,0 +1,122 @@
+package gitignore
+
+import "testing"
+
+func TestMatch(t *testing.T) {
+	tests := []struct {
+		name     string
+		path     string
+		patterns []string
+		want     bool
+	}{
+		{"vendor dir", "vendor/pkg/foo.go", []string{"vendor/**"}, true},
+		{"no match vendor", "src/main.go", []string{"vendor/**"}, false},
+		{"anchored dir", "src/vendor/foo.go", []string{"vendor/**"}, false},
+		{"minified js", "src/foo.min.js", []string{"*.min.js"}, true},
+		{"non-minified js", "src/app.js", []string{"*.min.js"}, false},
+		{"glob md root", "README.md", []string{"**/*.md"}, true},
+		{"glob md nested", "docs/guide.md", []string{"**/*.md"}, true},
+		{"empty patterns", "main.go", []string{}, false},
+		{"github dir", ".github/workflows/ci.yml", []string{".github/**"}, true},
+		{"multiple patterns first", "vendor/x.go", []string{"vendor/**", "*.test.js"}, true},
+		{"multiple patterns second", "foo.test.js", []string{"vendor/**", "*.test.js"}, true},
+		{"multiple patterns none", "src/app.go", []string{"vendor/**", "*.test.js"}, false},
+		{"double star in the middle", "src/a/b/gen/x.go", []string{"src/**/gen/*.go"}, true},
+		{"double star matches no directory", "src/gen/x.go", []string{"src/**/gen/*.go"}, true},
+		{"double star stays in its prefix", "lib/gen/x.go", []string{"src/**/gen/*.go"}, false},
+		{"directory at any depth", "web/node_modules/x/index.js", []string{"node_modules/"}, true},
+		{"directory pattern skips files", "build", []string{"build/"}, false},
+		{"leading slash anchors", "sub/todo.txt", []string{"/todo.txt"}, false},
+		{"name at any depth", "a/b/debug.log", []string{"*.log"}, true},
+		{"negation", "keep.go", []string{"*.go", "!keep.go"}, false},
+		{"last rule wins", "keep.go", []string{"!keep.go", "*.go"}, true},
+		{"no re-include under an excluded directory", "gen/keep.go", []string{"gen/", "!gen/keep.go"}, true},
+		{"re-include beside a glob", "gen/keep.go", []string{"gen/*", "!gen/keep.go"}, false},
+		{"character class", "file1.txt", []string{"file[0-9].txt"}, true},
+		{"negated class", "fileA.txt", []string{"file[!0-9].txt"}, true},
+		{"question mark", "a.c", []string{"?.c"}, true},
+		{"comment", "#notes", []string{"#notes"}, false},
+		{"escaped hash", "#notes", []string{`\#notes`}, true},
+		{"escaped bang", "!important", []string{`\!important`}, true},
+		{"trailing space", "a.txt", []string{"a.txt   "}, true},
+		{"unterminated class", "a[b", []string{"a[b"}, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, got := New("test", tt.patterns).Match(tt.path); got != tt.want {
+				t.Errorf("Match(%q) with %q = %v, want %v", tt.path, tt.patterns, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestMatch_Rule(t *testing.T) {
+	m := New("exclude_patterns", []string{"*.gen.go", "!keep.gen.go"})
+
+	r, ok := m.Match("api/types.gen.go")
+	if !ok || r.Pattern != "*.gen.go" || r.Source != "exclude_patterns" || r.Line != 1 {
+		t.Errorf("Match = %+v, %v; want rule 1 of exclude_patterns", r, ok)
+	}
+
+	r, ok = m.Match("keep.gen.go")
+	if ok || r == nil || !r.Negate || r.String() != "!keep.gen.go" {
+		t.Errorf("Match = %+v, %v; want the re-including rule", r, ok)
+	}
+
+	if r, ok := m.Match("main.go"); ok || r != nil {
+		t.Errorf("Match = %+v, %v; want no rule", r, ok)
+	}
+}
+
+func TestAddFile(t *testing.T) {
+	m := New("default", []string{"*.log"})
+	m.AddFile(".gitignore", "# build output\n/bin/\ntmp/\r\n")
+	m.AddFile("web/.gitignore", "dist/\n!important.log\n")
+
+	tests := []struct {
+		path   string
+		want   bool
+		source string
+		line   int
+	}{
+		{"bin/server", true, ".gitignore", 2},
+		{"cmd/bin/server", false, "", 0},
+		{"web/tmp/cache", true, ".gitignore", 3},
+		{"web/dist/app.js", true, "web/.gitignore", 1},
+		{"dist/app.js", false, "", 0},
+		{"web/important.log", false, "web/.gitignore", 2},
+		{"important.log", true, "default", 1},
+	}
+	for _, tt := range tests {
+		r, ok := m.Match(tt.path)
+		if ok != tt.want {
+			t.Errorf("Match(%q) = %v, want %v", tt.path, ok, tt.want)
+			continue
+		}
+		if tt.source == "" {
+			continue
+		}
+		if r == nil || r.Source != tt.source || r.Line != tt.line {
+			t.Errorf("Match(%q) rule = %+v, want %s line %d", tt.path, r, tt.source, tt.line)
+		}
+	}
+}
+
+func TestMatchesName(t *testing.T) {
+	tests := []struct {
+		pattern, name string
+		want          bool
+	}{
+		{"vendor/**", "go.mod", false},
+		{"go.sum", "go.sum", true},
+		{"*.lock", "yarn.lock", true},
+		{"node_modules/", "node_modules", false},
+	}
+	for _, tt := range tests {
+		r := compile(tt.pattern)
+		if got := r.MatchesName(tt.name); got != tt.want {
+			t.Errorf("%s.MatchesName(%q) = %v, want %v", tt.pattern, tt.name, got, tt.want)
+		}
+	}
+}
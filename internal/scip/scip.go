@@ -2,11 +2,17 @@
 package scip
 
 import (
+	"bufio"
 	"context"
+	"encoding/binary"
+	"errors"
 	"fmt"
+	"io"
+	"path"
 
 	"github.com/sourcegraph/scip/bindings/go/scip"
 	"github.com/zoobzio/vicky/models"
+	"google.golang.org/protobuf/encoding/protowire"
 	"google.golang.org/protobuf/proto"
 )
 
@@ -49,6 +55,123 @@ func (p *Parser) Parse(_ context.Context, data []byte) (*scip.Index, error) {
 	return &index, nil
 }
 
+// ParseStream decodes a SCIP index from r one document at a time, calling
+// visit for each. Only the document being visited is held in memory, so
+// indexes of any size can be processed. Metadata and external symbols are
+// skipped. An error from visit stops decoding and is returned as is.
+func (p *Parser) ParseStream(ctx context.Context, r io.Reader, visit func(ctx context.Context, doc *scip.Document) error) error {
+	var visitErr error
+	visitor := scip.IndexVisitor{
+		VisitDocument: func(ctx context.Context, doc *scip.Document) error {
+			visitErr = visit(ctx, doc)
+			return visitErr
+		},
+	}
+
+	// The visitor reads tags and lengths a byte at a time
+	if err := visitor.ParseStreaming(ctx, bufio.NewReader(r)); err != nil {
+		if visitErr != nil {
+			return visitErr
+		}
+		return fmt.Errorf("decode scip index: %w", err)
+	}
+	return nil
+}
+
+// CountDocuments returns the number of documents in the SCIP index read
+// from r. Documents are skipped rather than decoded.
+func (p *Parser) CountDocuments(r io.Reader) (int, error) {
+	br := bufio.NewReader(r)
+	documentsField := protowire.Number(2) // scip.Index.documents
+
+	count := 0
+	for {
+		tag, err := binary.ReadUvarint(br)
+		if errors.Is(err, io.EOF) {
+			return count, nil
+		}
+		if err != nil {
+			return count, fmt.Errorf("read scip field tag: %w", err)
+		}
+
+		num, typ := protowire.DecodeTag(tag)
+		if typ != protowire.BytesType {
+			return count, fmt.Errorf("unexpected wire type %d for scip index field %d", typ, num)
+		}
+		size, err := binary.ReadUvarint(br)
+		if err != nil {
+			return count, fmt.Errorf("read scip field length: %w", err)
+		}
+		if _, err := br.Discard(int(size)); err != nil {
+			return count, fmt.Errorf("skip scip field %d: %w", num, err)
+		}
+
+		if num == documentsField {
+			count++
+		}
+	}
+}
+
+// Rebase copies the SCIP index read from r to w with every document's
+// RelativePath prefixed by root, for indexes produced from a project root
+// below the repository root. Metadata and external symbols are copied as
+// is. Indexes rebased one after another onto the same w form a single index
+// holding all their documents. Returns the number of documents copied.
+func (p *Parser) Rebase(w io.Writer, r io.Reader, root string) (int, error) {
+	br := bufio.NewReader(r)
+	bw := bufio.NewWriter(w)
+	documentsField := protowire.Number(2) // scip.Index.documents
+
+	count := 0
+	for {
+		tag, err := binary.ReadUvarint(br)
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return count, fmt.Errorf("read scip field tag: %w", err)
+		}
+
+		num, typ := protowire.DecodeTag(tag)
+		if typ != protowire.BytesType {
+			return count, fmt.Errorf("unexpected wire type %d for scip index field %d", typ, num)
+		}
+		size, err := binary.ReadUvarint(br)
+		if err != nil {
+			return count, fmt.Errorf("read scip field length: %w", err)
+		}
+		field := make([]byte, size)
+		if _, err := io.ReadFull(br, field); err != nil {
+			return count, fmt.Errorf("read scip field %d: %w", num, err)
+		}
+
+		if num == documentsField {
+			count++
+			if root != "" {
+				var doc scip.Document
+				if err := proto.Unmarshal(field, &doc); err != nil {
+					return count, fmt.Errorf("decode scip document: %w", err)
+				}
+				doc.RelativePath = path.Join(root, doc.RelativePath)
+				if field, err = proto.Marshal(&doc); err != nil {
+					return count, fmt.Errorf("encode scip document %s: %w", doc.RelativePath, err)
+				}
+			}
+		}
+
+		out := protowire.AppendTag(nil, num, protowire.BytesType)
+		out = protowire.AppendBytes(out, field)
+		if _, err := bw.Write(out); err != nil {
+			return count, fmt.Errorf("write scip field %d: %w", num, err)
+		}
+	}
+
+	if err := bw.Flush(); err != nil {
+		return count, fmt.Errorf("write scip index: %w", err)
+	}
+	return count, nil
+}
+
 // ParseDocument extracts symbols and occurrences from a single SCIP document.
 func (p *Parser) ParseDocument(_ context.Context, doc *scip.Document, meta FileMeta) *Result {
 	result := &Result{}
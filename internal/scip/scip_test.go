@@ -0,0 +1,131 @@
+package scip
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"testing"
+
+	scipproto "github.com/sourcegraph/scip/bindings/go/scip"
+	"google.golang.org/protobuf/proto"
+)
+
+func marshalIndex(t *testing.T, paths ...string) []byte {
+	t.Helper()
+	index := &scipproto.Index{
+		Metadata:        &scipproto.Metadata{ToolInfo: &scipproto.ToolInfo{Name: "test"}},
+		ExternalSymbols: []*scipproto.SymbolInformation{{Symbol: "scip-go gomod fmt v1 Println()."}},
+	}
+	for _, p := range paths {
+		index.Documents = append(index.Documents, &scipproto.Document{
+			RelativePath: p,
+			Symbols:      []*scipproto.SymbolInformation{{Symbol: "local 0"}},
+		})
+	}
+	data, err := proto.Marshal(index)
+	if err != nil {
+		t.Fatalf("marshal index: %v", err)
+	}
+	return data
+}
+
+func TestParseStream(t *testing.T) {
+	data := marshalIndex(t, "a.go", "b.go", "c.go")
+
+	var paths []string
+	err := New().ParseStream(context.Background(), bytes.NewReader(data), func(ctx context.Context, doc *scipproto.Document) error {
+		paths = append(paths, doc.RelativePath)
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(paths) != 3 || paths[0] != "a.go" || paths[2] != "c.go" {
+		t.Errorf("visited %v, want a.go b.go c.go in order", paths)
+	}
+}
+
+func TestParseStream_VisitError(t *testing.T) {
+	data := marshalIndex(t, "a.go", "b.go")
+	stop := errors.New("stop")
+
+	visits := 0
+	err := New().ParseStream(context.Background(), bytes.NewReader(data), func(ctx context.Context, doc *scipproto.Document) error {
+		visits++
+		return stop
+	})
+	if err != stop {
+		t.Errorf("err = %v, want the visit error unwrapped", err)
+	}
+	if visits != 1 {
+		t.Errorf("visits = %d, want decoding to stop after the error", visits)
+	}
+}
+
+func TestParseStream_Corrupt(t *testing.T) {
+	data := marshalIndex(t, "a.go")
+
+	err := New().ParseStream(context.Background(), bytes.NewReader(data[:len(data)-3]), func(ctx context.Context, doc *scipproto.Document) error {
+		return nil
+	})
+	if err == nil {
+		t.Error("expected an error for a truncated index")
+	}
+}
+
+func TestCountDocuments(t *testing.T) {
+	p := New()
+
+	for _, paths := range [][]string{nil, {"a.go"}, {"a.go", "b.go", "c.go"}} {
+		n, err := p.CountDocuments(bytes.NewReader(marshalIndex(t, paths...)))
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if n != len(paths) {
+			t.Errorf("CountDocuments = %d, want %d", n, len(paths))
+		}
+	}
+
+	data := marshalIndex(t, "a.go")
+	if _, err := p.CountDocuments(bytes.NewReader(data[:len(data)-3])); err == nil {
+		t.Error("expected an error for a truncated index")
+	}
+}
+
+func TestRebase(t *testing.T) {
+	p := New()
+
+	var merged bytes.Buffer
+	n, err := p.Rebase(&merged, bytes.NewReader(marshalIndex(t, "main.go")), "")
+	if err != nil || n != 1 {
+		t.Fatalf("Rebase = %d, %v; want 1 document", n, err)
+	}
+	n, err = p.Rebase(&merged, bytes.NewReader(marshalIndex(t, "src/app.ts", "src/util.ts")), "web")
+	if err != nil || n != 2 {
+		t.Fatalf("Rebase = %d, %v; want 2 documents", n, err)
+	}
+
+	var index scipproto.Index
+	if err := proto.Unmarshal(merged.Bytes(), &index); err != nil {
+		t.Fatalf("merged index does not decode: %v", err)
+	}
+	var paths []string
+	for _, doc := range index.Documents {
+		paths = append(paths, doc.RelativePath)
+		if len(doc.Symbols) != 1 {
+			t.Errorf("%s lost its symbols", doc.RelativePath)
+		}
+	}
+	if len(paths) != 3 || paths[0] != "main.go" || paths[1] != "web/src/app.ts" || paths[2] != "web/src/util.ts" {
+		t.Errorf("paths = %v, want main.go then the web documents under web/", paths)
+	}
+	if len(index.ExternalSymbols) != 2 {
+		t.Errorf("external symbols = %d, want both indexes' symbols", len(index.ExternalSymbols))
+	}
+
+	data := marshalIndex(t, "a.go")
+	if _, err := p.Rebase(io.Discard, bytes.NewReader(data[:len(data)-3]), "api"); err == nil {
+		t.Error("expected an error for a truncated index")
+	}
+}
@@ -0,0 +1,28 @@
+package scip
+
+import "os"
+
+// Spool is a SCIP index buffered in a temporary file, so it is never held
+// in memory whole. Close removes the file.
+type Spool struct {
+	*os.File
+}
+
+// NewSpool creates an empty spool. The pattern names the temporary file as
+// in os.CreateTemp.
+func NewSpool(pattern string) (*Spool, error) {
+	f, err := os.CreateTemp("", pattern)
+	if err != nil {
+		return nil, err
+	}
+	return &Spool{File: f}, nil
+}
+
+// Close closes and removes the file.
+func (s *Spool) Close() error {
+	err := s.File.Close()
+	if rmErr := os.Remove(s.Name()); err == nil {
+		err = rmErr
+	}
+	return err
+}
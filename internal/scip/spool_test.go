@@ -0,0 +1,33 @@
+package scip
+
+import (
+	"errors"
+	"io"
+	"io/fs"
+	"os"
+	"testing"
+)
+
+func TestSpool(t *testing.T) {
+	sp, err := NewSpool("vicky-test-*.scip")
+	if err != nil {
+		t.Fatalf("NewSpool: %v", err)
+	}
+	if _, err := sp.Write([]byte("index")); err != nil {
+		t.Fatalf("Write: %v", err)
+	}
+	if _, err := sp.Seek(0, io.SeekStart); err != nil {
+		t.Fatalf("Seek: %v", err)
+	}
+	data, err := io.ReadAll(sp)
+	if err != nil || string(data) != "index" {
+		t.Fatalf("ReadAll = %q, %v; want index", data, err)
+	}
+
+	if err := sp.Close(); err != nil {
+		t.Fatalf("Close: %v", err)
+	}
+	if _, err := os.Stat(sp.Name()); !errors.Is(err, fs.ErrNotExist) {
+		t.Errorf("Stat after Close = %v, want the file removed", err)
+	}
+}
@@ -0,0 +1,233 @@
+package scip
+
+import (
+	"path"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/sourcegraph/scip/bindings/go/scip"
+	"github.com/zoobzio/vicky/models"
+)
+
+// SymbolMeta provides context for deriving code symbols from SCIP data.
+type SymbolMeta struct {
+	DocumentMeta
+	VersionID int64
+	Path      string
+}
+
+// CodeSymbol is a models.Symbol derived from SCIP, along with the SCIP
+// identifiers needed to link it to its parent once both are stored.
+type CodeSymbol struct {
+	Symbol     models.Symbol
+	SCIPSymbol string
+	Parent     string
+}
+
+// ConvertCodeSymbol derives a models.Symbol from a SCIP symbol and its
+// definition occurrence. Returns false for local symbols and for descriptors
+// that are not searchable code entities (packages, parameters, type parameters).
+func ConvertCodeSymbol(sym *scip.SymbolInformation, def *scip.Occurrence, meta SymbolMeta) (CodeSymbol, bool) {
+	if sym.Symbol == "" || scip.IsLocalSymbol(sym.Symbol) {
+		return CodeSymbol{}, false
+	}
+
+	parsed, err := scip.ParseSymbol(sym.Symbol)
+	if err != nil || len(parsed.Descriptors) == 0 {
+		return CodeSymbol{}, false
+	}
+
+	descriptors := parsed.Descriptors
+	last := descriptors[len(descriptors)-1]
+
+	var parent *scip.Descriptor
+	if len(descriptors) > 1 {
+		parent = descriptors[len(descriptors)-2]
+	}
+
+	kind, ok := symbolKind(sym.Kind, last, parent)
+	if !ok {
+		return CodeSymbol{}, false
+	}
+
+	name := sym.DisplayName
+	if name == "" {
+		name = last.Name
+	}
+
+	signature, doc := symbolDocs(sym)
+
+	startLine, endLine := definitionLines(def)
+
+	result := CodeSymbol{
+		Symbol: models.Symbol{
+			VersionID:     meta.VersionID,
+			UserID:        meta.UserID,
+			Owner:         meta.Owner,
+			RepoName:      meta.RepoName,
+			Tag:           meta.Tag,
+			Name:          name,
+			QualifiedName: qualifiedName(parsed.Scheme, descriptors),
+			Kind:          kind,
+			Signature:     signature,
+			Doc:           doc,
+			FilePath:      meta.Path,
+			StartLine:     startLine,
+			EndLine:       endLine,
+			Exported:      isExported(parsed.Scheme, name),
+		},
+		SCIPSymbol: sym.Symbol,
+	}
+
+	// Methods and fields hang off their enclosing type
+	switch {
+	case sym.EnclosingSymbol != "":
+		result.Parent = sym.EnclosingSymbol
+	case parent != nil && parent.Suffix == scip.Descriptor_Type:
+		parsed.Descriptors = descriptors[:len(descriptors)-1]
+		result.Parent = scip.LenientVerboseSymbolFormatter.FormatSymbol(parsed)
+	}
+
+	return result, true
+}
+
+// symbolKind maps a SCIP kind to a SymbolKind. Indexers that leave the kind
+// unspecified (scip-go among them) fall back to the descriptor suffix.
+func symbolKind(kind scip.SymbolInformation_Kind, last, parent *scip.Descriptor) (models.SymbolKind, bool) {
+	switch kind {
+	case scip.SymbolInformation_Function:
+		return models.SymbolKindFunction, true
+	case scip.SymbolInformation_Method, scip.SymbolInformation_AbstractMethod,
+		scip.SymbolInformation_StaticMethod, scip.SymbolInformation_Constructor,
+		scip.SymbolInformation_Getter, scip.SymbolInformation_Setter:
+		return models.SymbolKindMethod, true
+	case scip.SymbolInformation_Interface, scip.SymbolInformation_Trait:
+		return models.SymbolKindInterface, true
+	case scip.SymbolInformation_Struct, scip.SymbolInformation_Class, scip.SymbolInformation_Object:
+		return models.SymbolKindStruct, true
+	case scip.SymbolInformation_Type, scip.SymbolInformation_TypeAlias, scip.SymbolInformation_Enum:
+		return models.SymbolKindType, true
+	case scip.SymbolInformation_Constant, scip.SymbolInformation_EnumMember:
+		return models.SymbolKindConst, true
+	case scip.SymbolInformation_Variable, scip.SymbolInformation_StaticVariable:
+		return models.SymbolKindVar, true
+	case scip.SymbolInformation_Field, scip.SymbolInformation_StaticField, scip.SymbolInformation_Property:
+		return models.SymbolKindField, true
+	}
+
+	onType := parent != nil && parent.Suffix == scip.Descriptor_Type
+
+	switch last.Suffix {
+	case scip.Descriptor_Method:
+		if onType {
+			return models.SymbolKindMethod, true
+		}
+		return models.SymbolKindFunction, true
+	case scip.Descriptor_Type:
+		return models.SymbolKindType, true
+	case scip.Descriptor_Term:
+		if onType {
+			return models.SymbolKindField, true
+		}
+		return models.SymbolKindVar, true
+	}
+
+	return "", false
+}
+
+// qualifiedName joins the descriptors after the last namespace onto the
+// namespace's base name, e.g. "github.com/foo/bar"/Client#Connect() becomes
+// "bar.Client.Connect".
+func qualifiedName(scheme string, descriptors []*scip.Descriptor) string {
+	var pkg string
+	var parts []string
+	for _, d := range descriptors {
+		if d.Suffix == scip.Descriptor_Namespace {
+			pkg = namespaceBase(scheme, d.Name)
+			parts = parts[:0]
+			continue
+		}
+		parts = append(parts, d.Name)
+	}
+
+	if pkg != "" {
+		parts = append([]string{pkg}, parts...)
+	}
+	return strings.Join(parts, ".")
+}
+
+// namespaceBase returns the last element of a namespace: the package of a Go
+// import path, the file of a TypeScript module, or the module of a dotted
+// Python module path.
+func namespaceBase(scheme, name string) string {
+	if scheme == "scip-python" {
+		return name[strings.LastIndex(name, ".")+1:]
+	}
+	base := path.Base(name)
+	return strings.TrimSuffix(base, path.Ext(base))
+}
+
+// symbolDocs returns the signature and documentation for a symbol.
+// When SignatureDocumentation is absent, indexers put the signature in the
+// first documentation entry as a fenced code block; it is lifted out so the
+// doc holds prose only.
+func symbolDocs(sym *scip.SymbolInformation) (signature, doc *string) {
+	docs := sym.Documentation
+
+	if sym.SignatureDocumentation != nil && sym.SignatureDocumentation.Text != "" {
+		text := sym.SignatureDocumentation.Text
+		signature = &text
+	} else if len(docs) > 0 && strings.HasPrefix(docs[0], "```") {
+		if code := fencedCode(docs[0]); code != "" {
+			signature = &code
+		}
+		docs = docs[1:]
+	}
+
+	if joined := strings.TrimSpace(strings.Join(docs, "\n\n")); joined != "" {
+		doc = &joined
+	}
+	return signature, doc
+}
+
+// fencedCode returns the body of a markdown fenced code block.
+func fencedCode(s string) string {
+	s = strings.TrimSpace(s)
+	if _, rest, ok := strings.Cut(s, "\n"); ok {
+		s = rest
+	} else {
+		s = strings.TrimPrefix(s, "```")
+	}
+	return strings.TrimSpace(strings.TrimSuffix(s, "```"))
+}
+
+// definitionLines returns the 1-indexed line range of a definition, using
+// the enclosing range (the full declaration) when the indexer provides it.
+func definitionLines(def *scip.Occurrence) (startLine, endLine int) {
+	if def == nil {
+		return 0, 0
+	}
+
+	r := def.Range
+	if len(def.EnclosingRange) > 0 {
+		r = def.EnclosingRange
+	}
+
+	startLine, _, endLine, _ = parseRange(r)
+	return startLine + 1, endLine + 1
+}
+
+// isExported reports whether a symbol is part of its package's public API.
+// Go exports by capitalization; elsewhere a leading underscore or # marks
+// a private member by convention.
+func isExported(scheme, name string) bool {
+	if name == "" {
+		return false
+	}
+	if scheme == "scip-go" {
+		r, _ := utf8.DecodeRuneInString(name)
+		return unicode.IsUpper(r)
+	}
+	return !strings.HasPrefix(name, "_") && !strings.HasPrefix(name, "#")
+}
@@ -0,0 +1,201 @@
+package scip
+
+import (
+	"testing"
+
+	scipproto "github.com/sourcegraph/scip/bindings/go/scip"
+	"github.com/zoobzio/vicky/models"
+)
+
+func TestConvertCodeSymbol(t *testing.T) {
+	meta := SymbolMeta{
+		DocumentMeta: DocumentMeta{
+			UserID:   1000,
+			Owner:    "testorg",
+			RepoName: "testrepo",
+			Tag:      "v1.0.0",
+		},
+		VersionID: 10,
+		Path:      "client.go",
+	}
+
+	sym := &scipproto.SymbolInformation{
+		Symbol:        "scip-go gomod github.com/foo/bar v1.0.0 `github.com/foo/bar`/Client#Connect().",
+		DisplayName:   "Connect",
+		Documentation: []string{"```go\nfunc (c *Client) Connect() error\n```", "Connect opens the connection."},
+	}
+	def := &scipproto.Occurrence{
+		Range:          []int32{11, 17, 24},
+		Symbol:         sym.Symbol,
+		SymbolRoles:    int32(scipproto.SymbolRole_Definition),
+		EnclosingRange: []int32{10, 0, 14, 1},
+	}
+
+	result, ok := ConvertCodeSymbol(sym, def, meta)
+	if !ok {
+		t.Fatal("expected symbol to convert")
+	}
+
+	s := result.Symbol
+	if s.VersionID != 10 || s.UserID != 1000 || s.FilePath != "client.go" {
+		t.Errorf("meta = (%d, %d, %q), want (10, 1000, client.go)", s.VersionID, s.UserID, s.FilePath)
+	}
+	if s.Name != "Connect" {
+		t.Errorf("Name = %q, want Connect", s.Name)
+	}
+	if s.QualifiedName != "bar.Client.Connect" {
+		t.Errorf("QualifiedName = %q, want bar.Client.Connect", s.QualifiedName)
+	}
+	if s.Kind != models.SymbolKindMethod {
+		t.Errorf("Kind = %q, want %q", s.Kind, models.SymbolKindMethod)
+	}
+	if s.Signature == nil || *s.Signature != "func (c *Client) Connect() error" {
+		t.Errorf("Signature = %v, want lifted code block", s.Signature)
+	}
+	if s.Doc == nil || *s.Doc != "Connect opens the connection." {
+		t.Errorf("Doc = %v, want prose only", s.Doc)
+	}
+	if s.StartLine != 11 || s.EndLine != 15 {
+		t.Errorf("lines = %d-%d, want 11-15", s.StartLine, s.EndLine)
+	}
+	if !s.Exported {
+		t.Error("expected Connect to be exported")
+	}
+	if result.Parent != "scip-go gomod github.com/foo/bar v1.0.0 `github.com/foo/bar`/Client#" {
+		t.Errorf("Parent = %q, want Client type symbol", result.Parent)
+	}
+}
+
+func TestConvertCodeSymbol_SignatureDocumentation(t *testing.T) {
+	sym := &scipproto.SymbolInformation{
+		Symbol:                 "scip-typescript npm pkg 1.0.0 src/`client.ts`/connect().",
+		Kind:                   scipproto.SymbolInformation_Function,
+		Documentation:          []string{"Opens a connection."},
+		SignatureDocumentation: &scipproto.Document{Text: "function connect(): void"},
+	}
+
+	result, ok := ConvertCodeSymbol(sym, &scipproto.Occurrence{Range: []int32{2, 16, 23}}, SymbolMeta{})
+	if !ok {
+		t.Fatal("expected symbol to convert")
+	}
+
+	s := result.Symbol
+	if s.Kind != models.SymbolKindFunction {
+		t.Errorf("Kind = %q, want %q", s.Kind, models.SymbolKindFunction)
+	}
+	if s.QualifiedName != "client.connect" {
+		t.Errorf("QualifiedName = %q, want client.connect", s.QualifiedName)
+	}
+	if s.Signature == nil || *s.Signature != "function connect(): void" {
+		t.Errorf("Signature = %v, want signature documentation text", s.Signature)
+	}
+	if s.Doc == nil || *s.Doc != "Opens a connection." {
+		t.Errorf("Doc = %v, want documentation", s.Doc)
+	}
+	if s.StartLine != 3 || s.EndLine != 3 {
+		t.Errorf("lines = %d-%d, want 3-3", s.StartLine, s.EndLine)
+	}
+	if !s.Exported {
+		t.Error("expected connect to be exported outside Go")
+	}
+	if result.Parent != "" {
+		t.Errorf("Parent = %q, want none", result.Parent)
+	}
+}
+
+func TestConvertCodeSymbol_Python(t *testing.T) {
+	sym := &scipproto.SymbolInformation{
+		Symbol:        "scip-python python acme 2.1.0 `acme.billing`/Invoice#total().",
+		Documentation: []string{"```python\ndef total(self) -> Decimal\n```"},
+	}
+
+	result, ok := ConvertCodeSymbol(sym, nil, SymbolMeta{Path: "acme/billing.py"})
+	if !ok {
+		t.Fatal("expected symbol to convert")
+	}
+
+	s := result.Symbol
+	if s.Name != "total" || s.QualifiedName != "billing.Invoice.total" {
+		t.Errorf("Name/QualifiedName = %q/%q, want total/billing.Invoice.total", s.Name, s.QualifiedName)
+	}
+	if s.Kind != models.SymbolKindMethod {
+		t.Errorf("Kind = %q, want %q", s.Kind, models.SymbolKindMethod)
+	}
+	if s.Signature == nil || *s.Signature != "def total(self) -> Decimal" {
+		t.Errorf("Signature = %v, want lifted code block", s.Signature)
+	}
+	if result.Parent != "scip-python python acme 2.1.0 `acme.billing`/Invoice#" {
+		t.Errorf("Parent = %q, want the enclosing class", result.Parent)
+	}
+}
+
+func TestConvertCodeSymbol_Skipped(t *testing.T) {
+	tests := []struct {
+		name   string
+		symbol string
+	}{
+		{"local", "local 3"},
+		{"package", "scip-go gomod github.com/foo/bar v1.0.0 `github.com/foo/bar`/"},
+		{"parameter", "scip-go gomod github.com/foo/bar v1.0.0 `github.com/foo/bar`/New().(opts)"},
+		{"empty", ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			sym := &scipproto.SymbolInformation{Symbol: tt.symbol}
+			if _, ok := ConvertCodeSymbol(sym, nil, SymbolMeta{}); ok {
+				t.Errorf("ConvertCodeSymbol(%q) converted, want skipped", tt.symbol)
+			}
+		})
+	}
+}
+
+func TestSymbolKind_FromDescriptor(t *testing.T) {
+	typ := &scipproto.Descriptor{Name: "Client", Suffix: scipproto.Descriptor_Type}
+	ns := &scipproto.Descriptor{Name: "bar", Suffix: scipproto.Descriptor_Namespace}
+
+	tests := []struct {
+		name   string
+		last   *scipproto.Descriptor
+		parent *scipproto.Descriptor
+		want   models.SymbolKind
+	}{
+		{"method on type", &scipproto.Descriptor{Name: "Do", Suffix: scipproto.Descriptor_Method}, typ, models.SymbolKindMethod},
+		{"function", &scipproto.Descriptor{Name: "New", Suffix: scipproto.Descriptor_Method}, ns, models.SymbolKindFunction},
+		{"type", typ, ns, models.SymbolKindType},
+		{"field", &scipproto.Descriptor{Name: "Name", Suffix: scipproto.Descriptor_Term}, typ, models.SymbolKindField},
+		{"var", &scipproto.Descriptor{Name: "Default", Suffix: scipproto.Descriptor_Term}, ns, models.SymbolKindVar},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, ok := symbolKind(scipproto.SymbolInformation_UnspecifiedKind, tt.last, tt.parent)
+			if !ok || got != tt.want {
+				t.Errorf("symbolKind() = (%q, %v), want %q", got, ok, tt.want)
+			}
+		})
+	}
+}
+
+func TestIsExported(t *testing.T) {
+	tests := []struct {
+		scheme string
+		name   string
+		want   bool
+	}{
+		{"scip-go", "Client", true},
+		{"scip-go", "client", false},
+		{"scip-typescript", "client", true},
+		{"scip-typescript", "_internal", false},
+		{"scip-typescript", "#private", false},
+		{"scip-python", "total", true},
+		{"scip-python", "_helper", false},
+		{"scip-go", "", false},
+	}
+
+	for _, tt := range tests {
+		if got := isExported(tt.scheme, tt.name); got != tt.want {
+			t.Errorf("isExported(%q, %q) = %v, want %v", tt.scheme, tt.name, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,15 @@
+-- +goose Up
+-- Lease columns let workers claim jobs from the table with SKIP LOCKED.
+-- A running job whose lease has expired is reclaimable by any worker.
+ALTER TABLE jobs ADD COLUMN locked_by TEXT;
+ALTER TABLE jobs ADD COLUMN lease_expires_at TIMESTAMPTZ;
+ALTER TABLE jobs ADD COLUMN attempts INT NOT NULL DEFAULT 0;
+
+CREATE INDEX idx_jobs_claimable ON jobs(created_at, id)
+    WHERE status IN ('pending', 'running', 'cancelling');
+
+-- +goose Down
+DROP INDEX IF EXISTS idx_jobs_claimable;
+ALTER TABLE jobs DROP COLUMN attempts;
+ALTER TABLE jobs DROP COLUMN lease_expires_at;
+ALTER TABLE jobs DROP COLUMN locked_by;
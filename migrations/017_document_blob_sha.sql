@@ -0,0 +1,7 @@
+-- +goose Up
+-- Git blob SHA per document lets re-ingestion reuse unchanged files
+-- from the previous ready version.
+ALTER TABLE documents ADD COLUMN blob_sha TEXT;
+
+-- +goose Down
+ALTER TABLE documents DROP COLUMN blob_sha;
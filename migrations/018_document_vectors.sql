@@ -0,0 +1,12 @@
+-- +goose Up
+-- Enable document-level embeddings pooled from chunk vectors.
+UPDATE configs SET
+    data = data || '{"document_vectors": true}',
+    updated_at = now()
+WHERE domain = 'embedding';
+
+-- +goose Down
+UPDATE configs SET
+    data = data - 'document_vectors',
+    updated_at = now()
+WHERE domain = 'embedding';
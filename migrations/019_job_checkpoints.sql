@@ -0,0 +1,6 @@
+-- +goose Up
+-- Last pipeline stage a job completed, so a retry can resume after it.
+ALTER TABLE jobs ADD COLUMN checkpoint TEXT;
+
+-- +goose Down
+ALTER TABLE jobs DROP COLUMN checkpoint;
@@ -0,0 +1,24 @@
+-- +goose Up
+-- Re-ingesting a tag builds a staging version that replaces the live one
+-- once the pipeline finishes; deleting the live version promotes it.
+ALTER TABLE versions ADD COLUMN replaces_id BIGINT REFERENCES versions(id) ON DELETE SET NULL;
+
+-- A tag has one live version and at most one staging version replacing it
+ALTER TABLE versions DROP CONSTRAINT versions_repository_id_tag_key;
+ALTER TABLE versions DROP CONSTRAINT versions_user_id_owner_repo_name_tag_key;
+
+CREATE UNIQUE INDEX idx_versions_live_repository_tag ON versions(repository_id, tag) WHERE replaces_id IS NULL;
+CREATE UNIQUE INDEX idx_versions_live_lookup ON versions(user_id, owner, repo_name, tag) WHERE replaces_id IS NULL;
+CREATE UNIQUE INDEX idx_versions_replaces_id ON versions(replaces_id);
+
+-- +goose Down
+DELETE FROM versions WHERE replaces_id IS NOT NULL;
+
+DROP INDEX idx_versions_replaces_id;
+DROP INDEX idx_versions_live_lookup;
+DROP INDEX idx_versions_live_repository_tag;
+
+ALTER TABLE versions ADD CONSTRAINT versions_repository_id_tag_key UNIQUE (repository_id, tag);
+ALTER TABLE versions ADD CONSTRAINT versions_user_id_owner_repo_name_tag_key UNIQUE (user_id, owner, repo_name, tag);
+
+ALTER TABLE versions DROP COLUMN replaces_id;
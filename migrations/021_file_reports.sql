@@ -0,0 +1,30 @@
+-- +goose Up
+-- Per-file ingestion report: what each stage included, excluded, skipped or failed.
+CREATE TABLE file_reports (
+    id         BIGSERIAL PRIMARY KEY,
+    job_id     BIGINT NOT NULL REFERENCES jobs(id) ON DELETE CASCADE,
+    user_id    BIGINT NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    stage      TEXT NOT NULL,
+    path       TEXT NOT NULL,
+    outcome    TEXT NOT NULL,
+    rule       TEXT,
+    reason     TEXT,
+    size       BIGINT NOT NULL DEFAULT 0,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+
+CREATE INDEX idx_file_reports_job_id ON file_reports(job_id, stage);
+
+-- Let a stage tolerate a fraction of failed files instead of failing on the first.
+UPDATE configs SET
+    data = data || '{"error_budget": 0.05}',
+    updated_at = now()
+WHERE domain IN ('fetch', 'parse', 'chunk');
+
+-- +goose Down
+UPDATE configs SET
+    data = data - 'error_budget',
+    updated_at = now()
+WHERE domain IN ('fetch', 'parse', 'chunk');
+
+DROP TABLE file_reports;
@@ -0,0 +1,9 @@
+-- +goose Up
+-- Outer pipeline settings: concurrent jobs, stage retries and whole-stage timeouts.
+-- Timeouts stored as nanoseconds (Go time.Duration is int64 nanoseconds).
+INSERT INTO configs (domain, data) VALUES
+    ('pipeline', '{"workers": 4, "retries": 3, "fetch_timeout": 300000000000, "parse_timeout": 600000000000, "chunk_timeout": 300000000000, "embed_timeout": 1800000000000, "store_timeout": 600000000000}')
+ON CONFLICT (domain) DO NOTHING;
+
+-- +goose Down
+DELETE FROM configs WHERE domain = 'pipeline';
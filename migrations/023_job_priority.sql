@@ -0,0 +1,16 @@
+-- +goose Up
+-- Scheduling priority: higher runs first; equal priorities are shared fairly between users.
+ALTER TABLE jobs ADD COLUMN priority INT NOT NULL DEFAULT 0;
+
+CREATE INDEX idx_jobs_pending ON jobs(priority DESC, created_at, id)
+    WHERE status = 'pending';
+
+-- Per-user and per-repository limits on concurrently running jobs.
+UPDATE configs SET data = data || '{"user_concurrency": 2, "repository_concurrency": 1}'
+    WHERE domain = 'pipeline';
+
+-- +goose Down
+UPDATE configs SET data = data - 'user_concurrency' - 'repository_concurrency'
+    WHERE domain = 'pipeline';
+DROP INDEX IF EXISTS idx_jobs_pending;
+ALTER TABLE jobs DROP COLUMN priority;
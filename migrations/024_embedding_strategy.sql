@@ -0,0 +1,13 @@
+-- +goose Up
+-- How a version's chunk text was enriched before embedding, so retrieval
+-- quality can be compared across strategies. NULL means raw content.
+ALTER TABLE versions ADD COLUMN embedding_strategy JSONB;
+
+-- Embedding-text templates per content type; none means embed content verbatim.
+UPDATE configs SET data = data || '{"strategy": "raw", "templates": {}}'
+    WHERE domain = 'embedding';
+
+-- +goose Down
+UPDATE configs SET data = data - 'strategy' - 'templates'
+    WHERE domain = 'embedding';
+ALTER TABLE versions DROP COLUMN embedding_strategy;
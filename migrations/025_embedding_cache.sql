@@ -0,0 +1,22 @@
+-- +goose Up
+-- Content-addressed embedding cache: identical inputs embedded by the same
+-- model are never sent to the provider twice. The vector column is
+-- unconstrained so entries from models of any dimensionality can coexist.
+CREATE TABLE embedding_cache (
+    key        TEXT PRIMARY KEY,
+    provider   TEXT NOT NULL,
+    model      TEXT NOT NULL,
+    dimensions INTEGER NOT NULL,
+    vector     vector NOT NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+
+CREATE INDEX idx_embedding_cache_model ON embedding_cache(provider, model, dimensions);
+
+UPDATE configs SET data = data || '{"cache": true}'
+    WHERE domain = 'embedding';
+
+-- +goose Down
+UPDATE configs SET data = data - 'cache'
+    WHERE domain = 'embedding';
+DROP TABLE embedding_cache;
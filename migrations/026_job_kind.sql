@@ -0,0 +1,7 @@
+-- +goose Up
+-- Jobs either run the whole ingestion pipeline or rebuild a version's SCIP
+-- data from its stored index.
+ALTER TABLE jobs ADD COLUMN kind TEXT NOT NULL DEFAULT 'ingest';
+
+-- +goose Down
+ALTER TABLE jobs DROP COLUMN kind;
@@ -0,0 +1,11 @@
+-- +goose Up
+-- Recreate check constraint to include Python
+ALTER TABLE ingestion_configs DROP CONSTRAINT IF EXISTS ingestion_configs_language_check;
+ALTER TABLE ingestion_configs ADD CONSTRAINT ingestion_configs_language_check
+    CHECK (language IN ('go', 'typescript', 'python'));
+
+-- +goose Down
+-- Restore original check constraint
+ALTER TABLE ingestion_configs DROP CONSTRAINT IF EXISTS ingestion_configs_language_check;
+ALTER TABLE ingestion_configs ADD CONSTRAINT ingestion_configs_language_check
+    CHECK (language IN ('go', 'typescript'));
@@ -0,0 +1,12 @@
+-- +goose Up
+-- A repository carries one config per language root
+ALTER TABLE ingestion_configs DROP CONSTRAINT IF EXISTS ingestion_configs_repository_id_key;
+ALTER TABLE ingestion_configs ADD COLUMN root TEXT NOT NULL DEFAULT '';
+ALTER TABLE ingestion_configs ADD CONSTRAINT ingestion_configs_repository_language_root_key
+    UNIQUE (repository_id, language, root);
+
+-- +goose Down
+-- Restore one config per repository
+ALTER TABLE ingestion_configs DROP CONSTRAINT IF EXISTS ingestion_configs_repository_language_root_key;
+ALTER TABLE ingestion_configs DROP COLUMN root;
+ALTER TABLE ingestion_configs ADD CONSTRAINT ingestion_configs_repository_id_key UNIQUE (repository_id);
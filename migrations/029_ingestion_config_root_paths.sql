@@ -0,0 +1,7 @@
+-- +goose Up
+-- A config can cover several project roots beneath its root
+ALTER TABLE ingestion_configs ADD COLUMN root_paths TEXT[] DEFAULT '{}';
+
+-- +goose Down
+-- Configs index their root only
+ALTER TABLE ingestion_configs DROP COLUMN root_paths;
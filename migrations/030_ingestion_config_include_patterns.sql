@@ -0,0 +1,7 @@
+-- +goose Up
+-- An allowlist of patterns a file must match to be ingested
+ALTER TABLE ingestion_configs ADD COLUMN include_patterns TEXT[] DEFAULT '{}';
+
+-- +goose Down
+-- Every file not excluded is ingested
+ALTER TABLE ingestion_configs DROP COLUMN include_patterns;
@@ -0,0 +1,7 @@
+-- +goose Up
+-- Content formats per file name suffix, over the built-in defaults
+ALTER TABLE ingestion_configs ADD COLUMN content_formats JSONB;
+
+-- +goose Down
+-- Only the built-in content formats apply
+ALTER TABLE ingestion_configs DROP COLUMN content_formats;
@@ -0,0 +1,8 @@
+-- +goose Up
+-- Claims per job before a lapsed lease fails it instead of reclaiming it.
+UPDATE configs SET data = data || '{"max_attempts": 3}'
+    WHERE domain = 'pipeline';
+
+-- +goose Down
+UPDATE configs SET data = data - 'max_attempts'
+    WHERE domain = 'pipeline';
@@ -8,4 +8,5 @@ type Blob struct {
 	Owner    string `json:"owner"`
 	Repo     string `json:"repo"`
 	Tag      string `json:"tag"`
+	SHA      string `json:"sha,omitempty"`
 }
@@ -26,6 +26,27 @@ const (
 	ChunkKindCode      ChunkKind = "code" // Code block within docs
 )
 
+// ChunkKind values for schema chunks.
+const (
+	ChunkKindMessage   ChunkKind = "message"   // protobuf message
+	ChunkKindService   ChunkKind = "service"   // protobuf service
+	ChunkKindEndpoint  ChunkKind = "endpoint"  // OpenAPI path and its operations
+	ChunkKindSchema    ChunkKind = "schema"    // OpenAPI schema definition
+	ChunkKindTable     ChunkKind = "table"     // SQL CREATE TABLE
+	ChunkKindView      ChunkKind = "view"      // SQL CREATE VIEW
+	ChunkKindIndex     ChunkKind = "index"     // SQL CREATE INDEX
+	ChunkKindStatement ChunkKind = "statement" // any other SQL statement
+)
+
+// ChunkKinds lists every chunk kind, so searches can filter by them.
+var ChunkKinds = []ChunkKind{
+	ChunkKindFunction, ChunkKindMethod, ChunkKindClass, ChunkKindInterface, ChunkKindType,
+	ChunkKindEnum, ChunkKindConstant, ChunkKindVariable, ChunkKindModule,
+	ChunkKindSection, ChunkKindParagraph, ChunkKindCode,
+	ChunkKindMessage, ChunkKindService, ChunkKindEndpoint, ChunkKindSchema,
+	ChunkKindTable, ChunkKindView, ChunkKindIndex, ChunkKindStatement,
+}
+
 // Chunk represents an embedded segment of a document.
 type Chunk struct {
 	ID         int64     `json:"id" db:"id" constraints:"primarykey" description:"Internal chunk ID"`
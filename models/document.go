@@ -7,10 +7,14 @@ type ContentType string
 
 // ContentType values.
 const (
-	ContentTypeCode ContentType = "code"
-	ContentTypeDocs ContentType = "docs"
+	ContentTypeCode   ContentType = "code"
+	ContentTypeDocs   ContentType = "docs"
+	ContentTypeSchema ContentType = "schema" // API and data definitions
 )
 
+// ContentTypes lists every content type.
+var ContentTypes = []ContentType{ContentTypeCode, ContentTypeDocs, ContentTypeSchema}
+
 // Document represents a file within an ingested version.
 type Document struct {
 	ID          int64       `json:"id" db:"id" constraints:"primarykey" description:"Internal document ID"`
@@ -22,6 +26,7 @@ type Document struct {
 	Path        string      `json:"path" db:"path" constraints:"notnull" description:"File path within repository" example:"docs/guide.md"`
 	ContentType ContentType `json:"content_type" db:"content_type" constraints:"notnull" description:"Type of content"`
 	ContentHash string      `json:"content_hash" db:"content_hash" constraints:"notnull" description:"SHA256 of file content"`
+	BlobSHA     *string     `json:"blob_sha,omitempty" db:"blob_sha" description:"Git blob SHA of the source file"`
 	Vector      []float32   `json:"-" db:"vector" description:"Document-level embedding for similarity"`
 	CreatedAt   time.Time   `json:"created_at" db:"created_at" default:"now()" description:"Ingestion time"`
 }
@@ -29,6 +34,10 @@ type Document struct {
 // Clone returns a deep copy of the Document.
 func (d Document) Clone() Document {
 	c := d
+	if d.BlobSHA != nil {
+		sha := *d.BlobSHA
+		c.BlobSHA = &sha
+	}
 	if d.Vector != nil {
 		c.Vector = make([]float32, len(d.Vector))
 		copy(c.Vector, d.Vector)
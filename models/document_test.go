@@ -3,17 +3,23 @@ package models
 import "testing"
 
 func TestDocumentClone(t *testing.T) {
+	sha := "abc123"
 	orig := Document{
-		ID:     1,
-		Vector: []float32{0.1, 0.2, 0.3},
+		ID:      1,
+		BlobSHA: &sha,
+		Vector:  []float32{0.1, 0.2, 0.3},
 	}
 	clone := orig.Clone()
 
 	clone.Vector[0] = 9.9
+	*clone.BlobSHA = "CHANGED"
 
 	if orig.Vector[0] != 0.1 {
 		t.Error("Clone did not isolate Vector")
 	}
+	if *orig.BlobSHA != "abc123" {
+		t.Error("Clone did not isolate BlobSHA pointer")
+	}
 }
 
 func TestDocumentClone_NilVector(t *testing.T) {
@@ -0,0 +1,41 @@
+package models
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"strconv"
+	"time"
+)
+
+// EmbeddingCacheEntry is a previously computed embedding, addressed by the
+// exact input text and the model that produced it. Identical chunks across
+// tags, repositories, and users share an entry.
+type EmbeddingCacheEntry struct {
+	Key        string    `json:"key" db:"key" constraints:"primarykey" description:"Hash of the input text, provider, model, and dimensions"`
+	Provider   string    `json:"provider" db:"provider" constraints:"notnull" description:"Embedding provider" example:"openai"`
+	Model      string    `json:"model" db:"model" constraints:"notnull" description:"Embedding model" example:"text-embedding-3-small"`
+	Dimensions int       `json:"dimensions" db:"dimensions" constraints:"notnull" description:"Vector dimensionality" example:"1536"`
+	Vector     []float32 `json:"-" db:"vector" constraints:"notnull" description:"Cached embedding"`
+	CreatedAt  time.Time `json:"created_at" db:"created_at" default:"now()" description:"When the embedding was cached"`
+}
+
+// EmbeddingCacheKey addresses the embedding of text by a specific model.
+// Any change to the input, provider, model, or dimensions yields a new key.
+func EmbeddingCacheKey(text, provider, model string, dimensions int) string {
+	h := sha256.New()
+	for _, part := range []string{provider, model, strconv.Itoa(dimensions), text} {
+		h.Write([]byte(part))
+		h.Write([]byte{0})
+	}
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+// Clone returns a deep copy of the EmbeddingCacheEntry.
+func (e EmbeddingCacheEntry) Clone() EmbeddingCacheEntry {
+	c := e
+	if e.Vector != nil {
+		c.Vector = make([]float32, len(e.Vector))
+		copy(c.Vector, e.Vector)
+	}
+	return c
+}
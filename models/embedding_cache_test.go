@@ -0,0 +1,35 @@
+package models
+
+import "testing"
+
+func TestEmbeddingCacheKey(t *testing.T) {
+	base := EmbeddingCacheKey("func main() {}", "openai", "text-embedding-3-small", 1536)
+
+	if got := EmbeddingCacheKey("func main() {}", "openai", "text-embedding-3-small", 1536); got != base {
+		t.Errorf("key not stable: %q != %q", got, base)
+	}
+
+	variants := map[string]string{
+		"text":       EmbeddingCacheKey("func main() { }", "openai", "text-embedding-3-small", 1536),
+		"provider":   EmbeddingCacheKey("func main() {}", "voyage", "text-embedding-3-small", 1536),
+		"model":      EmbeddingCacheKey("func main() {}", "openai", "text-embedding-3-large", 1536),
+		"dimensions": EmbeddingCacheKey("func main() {}", "openai", "text-embedding-3-small", 512),
+		"boundary":   EmbeddingCacheKey("small"+"func main() {}", "openai", "text-embedding-3-", 1536),
+	}
+	for name, key := range variants {
+		if key == base {
+			t.Errorf("changing %s did not change the key", name)
+		}
+	}
+}
+
+func TestEmbeddingCacheEntryClone(t *testing.T) {
+	orig := EmbeddingCacheEntry{Key: "k", Vector: []float32{0.1, 0.2}}
+	clone := orig.Clone()
+
+	clone.Vector[0] = 9.9
+
+	if orig.Vector[0] != 0.1 {
+		t.Error("Clone did not isolate Vector")
+	}
+}
@@ -0,0 +1,41 @@
+package models
+
+import "maps"
+
+// RawEmbeddingStrategy embeds chunk content verbatim. Versions ingested
+// before strategies were recorded used it.
+const RawEmbeddingStrategy = "raw"
+
+// EmbeddingStrategy records how chunk text is enriched before embedding.
+// Templates are Go text/templates keyed by content type; a content type
+// without a template embeds the chunk content verbatim. Enrichment only
+// shapes the embedding input, never the stored chunk content.
+type EmbeddingStrategy struct {
+	Name      string                 `json:"name"`
+	Templates map[ContentType]string `json:"templates,omitempty"`
+}
+
+// DefaultEmbeddingStrategy returns the raw strategy.
+func DefaultEmbeddingStrategy() *EmbeddingStrategy {
+	return &EmbeddingStrategy{Name: RawEmbeddingStrategy}
+}
+
+// Equal reports whether both strategies produce the same embedding input.
+func (s *EmbeddingStrategy) Equal(o *EmbeddingStrategy) bool {
+	if s == nil || o == nil {
+		return s == o
+	}
+	return s.Name == o.Name && maps.Equal(s.Templates, o.Templates)
+}
+
+// Clone returns a deep copy of the EmbeddingStrategy.
+func (s *EmbeddingStrategy) Clone() *EmbeddingStrategy {
+	if s == nil {
+		return nil
+	}
+	c := *s
+	if s.Templates != nil {
+		c.Templates = maps.Clone(s.Templates)
+	}
+	return &c
+}
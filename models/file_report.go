@@ -0,0 +1,98 @@
+package models
+
+import "time"
+
+// FileOutcome records what an ingestion job did with a file.
+type FileOutcome string
+
+// FileOutcome values.
+const (
+	FileOutcomeIncluded FileOutcome = "included"
+	FileOutcomeExcluded FileOutcome = "excluded"
+	FileOutcomeSkipped  FileOutcome = "skipped"
+	FileOutcomeFailed   FileOutcome = "failed"
+)
+
+// Exclusion rules recorded when a file is filtered out by something other
+// than an exclude pattern.
+const (
+	ExcludeRuleMaxFileSize = "max_file_size"
+	ExcludeRuleExtension   = "extension"
+	ExcludeRuleRoot        = "root"
+	ExcludeRuleInclude     = "include_patterns"
+)
+
+// FileDecision is how an ingestion config treats a file in the repository tree.
+type FileDecision string
+
+// FileDecision values.
+const (
+	FileDecisionCode        FileDecision = "code"
+	FileDecisionDocs        FileDecision = "docs"
+	FileDecisionSchema      FileDecision = "schema"
+	FileDecisionExcluded    FileDecision = "excluded"
+	FileDecisionTooLarge    FileDecision = "too_large"
+	FileDecisionUnsupported FileDecision = "unsupported"
+)
+
+// FileDecisions lists every decision in preview order.
+var FileDecisions = []FileDecision{
+	FileDecisionCode,
+	FileDecisionDocs,
+	FileDecisionSchema,
+	FileDecisionExcluded,
+	FileDecisionTooLarge,
+	FileDecisionUnsupported,
+}
+
+// Included reports whether files with this decision are ingested.
+func (d FileDecision) Included() bool {
+	return d == FileDecisionCode || d == FileDecisionDocs || d == FileDecisionSchema
+}
+
+// FileClassification is an ingestion config's decision for one file.
+type FileClassification struct {
+	Path     string
+	Size     int64
+	Decision FileDecision
+	Rule     string        // exclude pattern or rule, when not included
+	Reason   string        // human-readable explanation, when not included
+	Language Language      // language of the config that owns a code file
+	Format   ContentFormat // format of a docs or schema file
+}
+
+// ContentType returns the content type of an included file.
+func (c FileClassification) ContentType() ContentType {
+	if c.Format != "" {
+		return c.Format.ContentType()
+	}
+	return ContentTypeCode
+}
+
+// FileReport records one file's outcome in a stage of an ingestion job.
+type FileReport struct {
+	ID        int64       `json:"id" db:"id" constraints:"primarykey" description:"Internal report entry ID"`
+	JobID     int64       `json:"job_id" db:"job_id" constraints:"notnull" references:"jobs(id)" description:"Parent job"`
+	UserID    int64       `json:"user_id" db:"user_id" constraints:"notnull" references:"users(id)" description:"Owning user"`
+	Stage     JobStage    `json:"stage" db:"stage" constraints:"notnull" description:"Stage that decided the outcome"`
+	Path      string      `json:"path" db:"path" constraints:"notnull" description:"File path within repository" example:"src/main.go"`
+	Outcome   FileOutcome `json:"outcome" db:"outcome" constraints:"notnull" description:"What the stage did with the file"`
+	Rule      *string     `json:"rule,omitempty" db:"rule" description:"Exclude pattern or rule that excluded the file" example:"vendor/**"`
+	Reason    *string     `json:"reason,omitempty" db:"reason" description:"Why the file was excluded, skipped or failed"`
+	Size      int64       `json:"size" db:"size" constraints:"notnull" default:"0" description:"File size in bytes, when known"`
+	CreatedAt time.Time   `json:"created_at" db:"created_at" default:"now()" description:"Record time"`
+}
+
+// Clone returns a deep copy of the FileReport.
+func (r FileReport) Clone() FileReport {
+	c := r
+	if r.Rule != nil {
+		rule := *r.Rule
+		c.Rule = &rule
+	}
+	if r.Reason != nil {
+		reason := *r.Reason
+		c.Reason = &reason
+	}
+	return c
+}
@@ -0,0 +1,26 @@
+package models
+
+import "testing"
+
+func TestFileReportClone(t *testing.T) {
+	rule := "vendor/**"
+	reason := "matches exclude pattern"
+
+	orig := FileReport{
+		ID:     1,
+		Rule:   &rule,
+		Reason: &reason,
+	}
+	clone := orig.Clone()
+
+	// Modify clone pointers
+	*clone.Rule = "CHANGED"
+	*clone.Reason = "CHANGED"
+
+	if *orig.Rule != "vendor/**" {
+		t.Error("Clone did not isolate Rule")
+	}
+	if *orig.Reason != "matches exclude pattern" {
+		t.Error("Clone did not isolate Reason")
+	}
+}
@@ -2,6 +2,9 @@ package models
 
 import (
 	"encoding/json"
+	"maps"
+	"path"
+	"strings"
 	"time"
 )
 
@@ -12,9 +15,86 @@ type Language string
 const (
 	LanguageGo         Language = "go"
 	LanguageTypeScript Language = "typescript"
+	LanguagePython     Language = "python"
 )
 
-// DefaultExcludePatterns are always applied during ingestion.
+// ContentFormat names how a non-code file is chunked. Each format is a
+// language of the chunker sidecar.
+type ContentFormat string
+
+// Supported content formats.
+const (
+	ContentFormatMarkdown ContentFormat = "markdown"
+	ContentFormatRST      ContentFormat = "rst"
+	ContentFormatAsciiDoc ContentFormat = "asciidoc"
+	ContentFormatText     ContentFormat = "text"
+	ContentFormatOpenAPI  ContentFormat = "openapi"
+	ContentFormatProto    ContentFormat = "proto"
+	ContentFormatSQL      ContentFormat = "sql"
+)
+
+// ContentFormats lists every content format.
+var ContentFormats = []ContentFormat{
+	ContentFormatMarkdown,
+	ContentFormatRST,
+	ContentFormatAsciiDoc,
+	ContentFormatText,
+	ContentFormatOpenAPI,
+	ContentFormatProto,
+	ContentFormatSQL,
+}
+
+// ContentType returns the content type of files in the format.
+func (f ContentFormat) ContentType() ContentType {
+	switch f {
+	case ContentFormatOpenAPI, ContentFormatProto, ContentFormatSQL:
+		return ContentTypeSchema
+	default:
+		return ContentTypeDocs
+	}
+}
+
+// ContentFormatMap maps file name suffixes, such as ".rst" or "openapi.yaml",
+// to the format files ending in them are ingested as.
+type ContentFormatMap map[string]ContentFormat
+
+// DefaultContentFormats are applied to every config, beneath its own
+// content formats. YAML and JSON are only ingested as OpenAPI under the
+// conventional file names, as most such files are not API specs.
+var DefaultContentFormats = ContentFormatMap{
+	".md":          ContentFormatMarkdown,
+	".mdx":         ContentFormatMarkdown,
+	".markdown":    ContentFormatMarkdown,
+	".rst":         ContentFormatRST,
+	".adoc":        ContentFormatAsciiDoc,
+	".asciidoc":    ContentFormatAsciiDoc,
+	".txt":         ContentFormatText,
+	"openapi.yaml": ContentFormatOpenAPI,
+	"openapi.yml":  ContentFormatOpenAPI,
+	"openapi.json": ContentFormatOpenAPI,
+	"swagger.yaml": ContentFormatOpenAPI,
+	"swagger.yml":  ContentFormatOpenAPI,
+	"swagger.json": ContentFormatOpenAPI,
+	".proto":       ContentFormatProto,
+	".sql":         ContentFormatSQL,
+}
+
+// For returns the format of the file at p: that of the longest suffix its
+// name ends in, compared case-insensitively, or empty when none matches.
+func (f ContentFormatMap) For(p string) ContentFormat {
+	name := strings.ToLower(path.Base(p))
+	var format ContentFormat
+	longest := 0
+	for suffix, fm := range f {
+		if len(suffix) > longest && strings.HasSuffix(name, strings.ToLower(suffix)) {
+			format, longest = fm, len(suffix)
+		}
+	}
+	return format
+}
+
+// DefaultExcludePatterns are always applied during ingestion, before a
+// config's own patterns and the repository's ignore files.
 var DefaultExcludePatterns = []string{
 	".git/**",
 	".github/**",
@@ -22,11 +102,14 @@ var DefaultExcludePatterns = []string{
 	".idea/**",
 	"vendor/**",
 	"node_modules/**",
+	"__pycache__/**",
+	".venv/**",
 	"dist/**",
 	"build/**",
 	"*.min.js",
 	"*.min.css",
 	"*.map",
+	"*.pyc",
 	"*.lock",
 	"package-lock.json",
 	"yarn.lock",
@@ -36,16 +119,22 @@ var DefaultExcludePatterns = []string{
 // DefaultMaxFileSize is the default maximum file size to ingest (1MB).
 const DefaultMaxFileSize = 1024 * 1024
 
-// IngestionConfig defines per-repo ingestion settings.
+// IngestionConfig defines the ingestion settings for one language root of a
+// repository. A repository has a config per language root, so a monorepo can
+// index a Go backend and a TypeScript frontend side by side.
 type IngestionConfig struct {
 	ID              int64           `json:"id" db:"id" constraints:"primarykey" description:"Config ID"`
-	RepositoryID    int64           `json:"repository_id" db:"repository_id" constraints:"notnull,unique" references:"repositories(id)" description:"Parent repository"`
+	RepositoryID    int64           `json:"repository_id" db:"repository_id" constraints:"notnull" references:"repositories(id)" description:"Parent repository"`
 	UserID          int64           `json:"user_id" db:"user_id" constraints:"notnull" references:"users(id)" description:"Owning user"`
-	Language        Language        `json:"language" db:"language" constraints:"notnull" description:"Primary language for SCIP indexing" example:"go"`
-	IncludeDocs     bool            `json:"include_docs" db:"include_docs" constraints:"notnull" default:"true" description:"Include markdown documentation"`
-	ExcludePatterns []string        `json:"exclude_patterns" db:"exclude_patterns" description:"Additional glob patterns to exclude"`
+	Language        Language        `json:"language" db:"language" constraints:"notnull" description:"Language for SCIP indexing" example:"go"`
+	Root            string          `json:"root" db:"root" constraints:"notnull" default:"''" description:"Project root within the repository, empty for the repository root" example:"web"`
+	RootPaths       []string        `json:"root_paths" db:"root_paths" description:"Project roots beneath Root, each indexed on its own; empty to index Root itself"`
+	IncludeDocs     bool            `json:"include_docs" db:"include_docs" constraints:"notnull" default:"true" description:"Include documentation and schema files"`
+	ExcludePatterns []string        `json:"exclude_patterns" db:"exclude_patterns" description:"Additional gitignore-style patterns to exclude"`
+	IncludePatterns []string        `json:"include_patterns" db:"include_patterns" description:"Gitignore-style patterns a file must match to be ingested; empty to allow every file"`
 	MaxFileSize     int64           `json:"max_file_size" db:"max_file_size" constraints:"notnull" default:"1048576" description:"Maximum file size in bytes"`
 	LanguageConfig  json.RawMessage `json:"language_config,omitempty" db:"language_config" description:"Language-specific configuration"`
+	ContentFormats  json.RawMessage `json:"content_formats,omitempty" db:"content_formats" description:"Content format per file name suffix, over the defaults"`
 	CreatedAt       time.Time       `json:"created_at" db:"created_at" default:"now()" description:"Creation time"`
 	UpdatedAt       time.Time       `json:"updated_at" db:"updated_at" default:"now()" description:"Last update time"`
 }
@@ -58,6 +147,70 @@ func (c *IngestionConfig) AllExcludePatterns() []string {
 	return patterns
 }
 
+// GetContentFormats parses the config's content formats and returns them
+// over DefaultContentFormats. A suffix mapped to an empty format is not
+// ingested, so a config can drop a default.
+func (c *IngestionConfig) GetContentFormats() (ContentFormatMap, error) {
+	formats := maps.Clone(DefaultContentFormats)
+	if c.ContentFormats == nil {
+		return formats, nil
+	}
+	var custom map[string]ContentFormat
+	if err := json.Unmarshal(c.ContentFormats, &custom); err != nil {
+		return nil, err
+	}
+	for suffix, format := range custom {
+		formats[strings.ToLower(suffix)] = format
+	}
+	return formats, nil
+}
+
+// Contains reports whether a repository path lies under the config's root.
+func (c *IngestionConfig) Contains(p string) bool {
+	return c.Root == "" || p == c.Root || strings.HasPrefix(p, c.Root+"/")
+}
+
+// RelativePath returns a repository path relative to the config's root.
+// Exclude patterns are matched against it.
+func (c *IngestionConfig) RelativePath(p string) string {
+	if c.Root == "" {
+		return p
+	}
+	return strings.TrimPrefix(strings.TrimPrefix(p, c.Root), "/")
+}
+
+// Projects returns one config per project root: a copy rooted at each of
+// RootPaths beneath Root, or the config itself when it has none. Files
+// outside every project root are not ingested.
+func (c *IngestionConfig) Projects() []*IngestionConfig {
+	if len(c.RootPaths) == 0 {
+		return []*IngestionConfig{c}
+	}
+	projects := make([]*IngestionConfig, len(c.RootPaths))
+	for i, root := range c.RootPaths {
+		project := c.Clone()
+		project.Root = CleanRoot(path.Join(c.Root, root))
+		project.RootPaths = nil
+		projects[i] = project
+	}
+	return projects
+}
+
+// ProjectConfigs expands a repository's configs into one per project root.
+func ProjectConfigs(configs []*IngestionConfig) []*IngestionConfig {
+	var projects []*IngestionConfig
+	for _, c := range configs {
+		projects = append(projects, c.Projects()...)
+	}
+	return projects
+}
+
+// CleanRoot normalizes a project root: no leading or trailing slashes, no
+// dot segments, and empty for the repository root.
+func CleanRoot(root string) string {
+	return strings.TrimPrefix(path.Clean("/"+root), "/")
+}
+
 // Clone returns a deep copy of the IngestionConfig.
 func (c *IngestionConfig) Clone() *IngestionConfig {
 	if c == nil {
@@ -68,10 +221,22 @@ func (c *IngestionConfig) Clone() *IngestionConfig {
 		clone.ExcludePatterns = make([]string, len(c.ExcludePatterns))
 		copy(clone.ExcludePatterns, c.ExcludePatterns)
 	}
+	if c.IncludePatterns != nil {
+		clone.IncludePatterns = make([]string, len(c.IncludePatterns))
+		copy(clone.IncludePatterns, c.IncludePatterns)
+	}
+	if c.RootPaths != nil {
+		clone.RootPaths = make([]string, len(c.RootPaths))
+		copy(clone.RootPaths, c.RootPaths)
+	}
 	if c.LanguageConfig != nil {
 		clone.LanguageConfig = make(json.RawMessage, len(c.LanguageConfig))
 		copy(clone.LanguageConfig, c.LanguageConfig)
 	}
+	if c.ContentFormats != nil {
+		clone.ContentFormats = make(json.RawMessage, len(c.ContentFormats))
+		copy(clone.ContentFormats, c.ContentFormats)
+	}
 	return &clone
 }
 
@@ -87,6 +252,13 @@ type TypeScriptConfig struct {
 	IncludeTests bool   `json:"include_tests,omitempty"` // Include .spec.ts, .test.ts
 }
 
+// PythonConfig holds Python-specific ingestion settings.
+type PythonConfig struct {
+	ProjectName    string `json:"project_name,omitempty"`    // Package name used in SCIP symbols
+	ProjectVersion string `json:"project_version,omitempty"` // Package version used in SCIP symbols
+	IncludeTests   bool   `json:"include_tests,omitempty"`   // Include test_*.py, *_test.py
+}
+
 // GetGoConfig parses and returns the Go-specific config.
 func (c *IngestionConfig) GetGoConfig() (*GoConfig, error) {
 	if c.Language != LanguageGo || c.LanguageConfig == nil {
@@ -110,3 +282,15 @@ func (c *IngestionConfig) GetTypeScriptConfig() (*TypeScriptConfig, error) {
 	}
 	return &cfg, nil
 }
+
+// GetPythonConfig parses and returns the Python-specific config.
+func (c *IngestionConfig) GetPythonConfig() (*PythonConfig, error) {
+	if c.Language != LanguagePython || c.LanguageConfig == nil {
+		return &PythonConfig{}, nil
+	}
+	var cfg PythonConfig
+	if err := json.Unmarshal(c.LanguageConfig, &cfg); err != nil {
+		return nil, err
+	}
+	return &cfg, nil
+}
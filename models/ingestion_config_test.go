@@ -2,6 +2,7 @@ package models
 
 import (
 	"encoding/json"
+	"strings"
 	"testing"
 )
 
@@ -38,21 +39,31 @@ func TestIngestionConfigClone(t *testing.T) {
 	orig := &IngestionConfig{
 		ID:              1,
 		ExcludePatterns: []string{"vendor/**"},
+		RootPaths:       []string{"cmd"},
 		LanguageConfig:  json.RawMessage(`{"module_path":"example.com/foo"}`),
+		ContentFormats:  json.RawMessage(`{".txt":"text"}`),
 	}
 	clone := orig.Clone()
 
 	// Modify clone slices
 	clone.ExcludePatterns[0] = "CHANGED"
+	clone.RootPaths[0] = "CHANGED"
 	clone.LanguageConfig[0] = 'X'
+	clone.ContentFormats[0] = 'X'
 
 	// Original should be unaffected
 	if orig.ExcludePatterns[0] != "vendor/**" {
 		t.Error("Clone did not isolate ExcludePatterns")
 	}
+	if orig.RootPaths[0] != "cmd" {
+		t.Error("Clone did not isolate RootPaths")
+	}
 	if orig.LanguageConfig[0] != '{' {
 		t.Error("Clone did not isolate LanguageConfig")
 	}
+	if orig.ContentFormats[0] != '{' {
+		t.Error("Clone did not isolate ContentFormats")
+	}
 }
 
 func TestIngestionConfigClone_Nil(t *testing.T) {
@@ -123,3 +134,123 @@ func TestGetTypeScriptConfig_NilConfig(t *testing.T) {
 		t.Errorf("expected empty TypeScriptConfig for nil config, got TsConfigPath=%q", cfg.TsConfigPath)
 	}
 }
+
+func TestGetPythonConfig(t *testing.T) {
+	c := &IngestionConfig{
+		Language:       LanguagePython,
+		LanguageConfig: json.RawMessage(`{"project_name":"acme","project_version":"2.1.0"}`),
+	}
+	cfg, err := c.GetPythonConfig()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if cfg.ProjectName != "acme" || cfg.ProjectVersion != "2.1.0" {
+		t.Errorf("PythonConfig = %+v, want acme 2.1.0", cfg)
+	}
+}
+
+func TestGetPythonConfig_WrongLanguage(t *testing.T) {
+	c := &IngestionConfig{
+		Language:       LanguageGo,
+		LanguageConfig: json.RawMessage(`{"project_name":"acme"}`),
+	}
+	cfg, err := c.GetPythonConfig()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if cfg.ProjectName != "" {
+		t.Errorf("expected empty PythonConfig for non-Python language, got ProjectName=%q", cfg.ProjectName)
+	}
+}
+
+func TestIngestionConfigRoot(t *testing.T) {
+	repo := &IngestionConfig{}
+	web := &IngestionConfig{Root: "web"}
+
+	for _, p := range []string{"main.go", "web/app.ts"} {
+		if !repo.Contains(p) {
+			t.Errorf("repository root does not contain %q", p)
+		}
+	}
+	if !web.Contains("web/src/app.ts") || web.Contains("webapp/index.ts") || web.Contains("main.go") {
+		t.Error("web root containment is wrong")
+	}
+
+	if got := web.RelativePath("web/src/app.ts"); got != "src/app.ts" {
+		t.Errorf("RelativePath = %q, want src/app.ts", got)
+	}
+	if got := repo.RelativePath("main.go"); got != "main.go" {
+		t.Errorf("RelativePath = %q, want main.go", got)
+	}
+}
+
+func TestIngestionConfigProjects(t *testing.T) {
+	single := &IngestionConfig{Language: LanguageGo, Root: "services"}
+	if got := single.Projects(); len(got) != 1 || got[0] != single {
+		t.Errorf("Projects() = %v, want the config itself", got)
+	}
+
+	multi := &IngestionConfig{Language: LanguageGo, Root: "services", RootPaths: []string{"payments", "billing/", "./ledger"}}
+	var roots []string
+	for _, p := range ProjectConfigs([]*IngestionConfig{multi, single}) {
+		if p.RootPaths != nil {
+			t.Errorf("project %q kept its root paths", p.Root)
+		}
+		roots = append(roots, p.Root)
+	}
+	want := []string{"services/payments", "services/billing", "services/ledger", "services"}
+	if strings.Join(roots, ",") != strings.Join(want, ",") {
+		t.Errorf("roots = %v, want %v", roots, want)
+	}
+	if multi.Root != "services" || len(multi.RootPaths) != 3 {
+		t.Error("Projects modified the config")
+	}
+}
+
+func TestGetContentFormats(t *testing.T) {
+	c := &IngestionConfig{
+		ContentFormats: json.RawMessage(`{".yaml": "openapi", ".TXT": "", "api.sql": "text"}`),
+	}
+	formats, err := c.GetContentFormats()
+	if err != nil {
+		t.Fatalf("GetContentFormats: %v", err)
+	}
+
+	tests := []struct {
+		path string
+		want ContentFormat
+	}{
+		{"README.md", ContentFormatMarkdown},
+		{"docs/Guide.RST", ContentFormatRST},
+		{"deploy/values.yaml", ContentFormatOpenAPI},
+		{"api/openapi.yml", ContentFormatOpenAPI},
+		{"notes.txt", ""},
+		{"db/api.sql", ContentFormatText},
+		{"db/schema.sql", ContentFormatSQL},
+		{"main.go", ""},
+	}
+	for _, tt := range tests {
+		if got := formats.For(tt.path); got != tt.want {
+			t.Errorf("For(%q) = %q, want %q", tt.path, got, tt.want)
+		}
+	}
+	if DefaultContentFormats[".txt"] != ContentFormatText {
+		t.Error("GetContentFormats modified the defaults")
+	}
+
+	if _, err := (&IngestionConfig{ContentFormats: json.RawMessage(`[]`)}).GetContentFormats(); err == nil {
+		t.Error("malformed content formats accepted")
+	}
+}
+
+func TestContentFormatContentType(t *testing.T) {
+	for _, f := range ContentFormats {
+		want := ContentTypeDocs
+		if f == ContentFormatOpenAPI || f == ContentFormatProto || f == ContentFormatSQL {
+			want = ContentTypeSchema
+		}
+		if got := f.ContentType(); got != want {
+			t.Errorf("%s.ContentType() = %q, want %q", f, got, want)
+		}
+	}
+}
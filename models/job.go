@@ -1,6 +1,9 @@
 package models
 
-import "time"
+import (
+	"slices"
+	"time"
+)
 
 // JobStage represents the processing stage of an ingestion job.
 type JobStage string
@@ -14,6 +17,54 @@ const (
 	JobStageStore JobStage = "store"
 )
 
+// JobStages lists the pipeline stages in execution order.
+var JobStages = []JobStage{
+	JobStageFetch,
+	JobStageParse,
+	JobStageChunk,
+	JobStageEmbed,
+	JobStageStore,
+}
+
+// Index returns the stage's position in JobStages, or -1 if unknown.
+func (s JobStage) Index() int {
+	for i, stage := range JobStages {
+		if stage == s {
+			return i
+		}
+	}
+	return -1
+}
+
+// JobKind selects which pipeline stages a job runs.
+type JobKind string
+
+// JobKind values.
+const (
+	// JobKindIngest runs every stage.
+	JobKindIngest JobKind = "ingest"
+	// JobKindIngestUpload runs every stage but parses a SCIP index uploaded
+	// for the version instead of running the indexer.
+	JobKindIngestUpload JobKind = "ingest_upload"
+	// JobKindRebuildSCIP re-derives SCIP data and symbols from the version's
+	// stored SCIP index, without fetching, re-indexing, or re-chunking.
+	JobKindRebuildSCIP JobKind = "rebuild_scip"
+)
+
+// Stages returns the stages a job of this kind runs, in execution order.
+func (k JobKind) Stages() []JobStage {
+	if k == JobKindRebuildSCIP {
+		return []JobStage{JobStageParse, JobStageEmbed, JobStageStore}
+	}
+	return JobStages
+}
+
+// StoredIndex reports whether the parse stage reads the version's stored
+// SCIP index rather than running the indexer.
+func (k JobKind) StoredIndex() bool {
+	return k == JobKindIngestUpload || k == JobKindRebuildSCIP
+}
+
 // JobStatus represents the overall status of a job.
 type JobStatus string
 
@@ -46,6 +97,51 @@ type Job struct {
 	StartedAt      *time.Time `json:"started_at,omitempty" db:"started_at" description:"Processing start time"`
 	CompletedAt    *time.Time `json:"completed_at,omitempty" db:"completed_at" description:"Processing completion time"`
 	UpdatedAt      time.Time  `json:"updated_at" db:"updated_at" default:"now()" description:"Last update time"`
+	LockedBy       *string    `json:"locked_by,omitempty" db:"locked_by" description:"Worker currently holding the lease"`
+	LeaseExpiresAt *time.Time `json:"lease_expires_at,omitempty" db:"lease_expires_at" description:"When the worker lease lapses"`
+	Attempts       int        `json:"attempts" db:"attempts" constraints:"notnull" default:"0" description:"Number of times the job has been claimed"`
+	Checkpoint     *JobStage  `json:"checkpoint,omitempty" db:"checkpoint" description:"Last stage that completed successfully"`
+	Priority       int        `json:"priority" db:"priority" constraints:"notnull" default:"0" description:"Scheduling priority, higher runs first"`
+	Kind           JobKind    `json:"kind" db:"kind" constraints:"notnull" default:"'ingest'" description:"Which pipeline stages the job runs"`
+}
+
+// Job priority bounds.
+const (
+	MinJobPriority = -100
+	MaxJobPriority = 100
+)
+
+// JobLimits caps how many jobs may run at once when claiming work, and how
+// many times a job may be claimed. Zero means unlimited.
+type JobLimits struct {
+	PerUser       int // running jobs per user
+	PerRepository int // running jobs per repository
+	MaxAttempts   int // claims per job
+}
+
+// Completed reports whether the job's checkpoint is at or past the given stage.
+func (j *Job) Completed(stage JobStage) bool {
+	if j.Checkpoint == nil {
+		return false
+	}
+	idx := stage.Index()
+	return idx >= 0 && j.Checkpoint.Index() >= idx
+}
+
+// Runs reports whether the job's kind includes the stage.
+func (j *Job) Runs(stage JobStage) bool {
+	return slices.Contains(j.Kind.Stages(), stage)
+}
+
+// ResumeStage returns the first stage the job runs and has not completed.
+// Returns JobStageStore once every stage is checkpointed.
+func (j *Job) ResumeStage() JobStage {
+	for _, stage := range j.Kind.Stages() {
+		if !j.Completed(stage) {
+			return stage
+		}
+	}
+	return JobStageStore
 }
 
 // Clone returns a deep copy of the Job.
@@ -67,5 +163,17 @@ func (j *Job) Clone() *Job {
 		comp := *j.CompletedAt
 		c.CompletedAt = &comp
 	}
+	if j.LockedBy != nil {
+		l := *j.LockedBy
+		c.LockedBy = &l
+	}
+	if j.LeaseExpiresAt != nil {
+		le := *j.LeaseExpiresAt
+		c.LeaseExpiresAt = &le
+	}
+	if j.Checkpoint != nil {
+		cp := *j.Checkpoint
+		c.Checkpoint = &cp
+	}
 	return &c
 }
@@ -9,12 +9,18 @@ func TestJobClone(t *testing.T) {
 	errMsg := "failed"
 	started := time.Now()
 	completed := started.Add(time.Minute)
+	lockedBy := "worker-1"
+	lease := started.Add(30 * time.Second)
+	checkpoint := JobStageChunk
 
 	orig := &Job{
-		ID:          1,
-		Error:       &errMsg,
-		StartedAt:   &started,
-		CompletedAt: &completed,
+		ID:             1,
+		Error:          &errMsg,
+		StartedAt:      &started,
+		CompletedAt:    &completed,
+		LockedBy:       &lockedBy,
+		LeaseExpiresAt: &lease,
+		Checkpoint:     &checkpoint,
 	}
 	clone := orig.Clone()
 
@@ -22,6 +28,9 @@ func TestJobClone(t *testing.T) {
 	*clone.Error = "CHANGED"
 	*clone.StartedAt = time.Time{}
 	*clone.CompletedAt = time.Time{}
+	*clone.LockedBy = "CHANGED"
+	*clone.LeaseExpiresAt = time.Time{}
+	*clone.Checkpoint = JobStageStore
 
 	if *orig.Error != "failed" {
 		t.Error("Clone did not isolate Error pointer")
@@ -32,13 +41,23 @@ func TestJobClone(t *testing.T) {
 	if orig.CompletedAt.IsZero() {
 		t.Error("Clone did not isolate CompletedAt pointer")
 	}
+	if *orig.LockedBy != "worker-1" {
+		t.Error("Clone did not isolate LockedBy pointer")
+	}
+	if orig.LeaseExpiresAt.IsZero() {
+		t.Error("Clone did not isolate LeaseExpiresAt pointer")
+	}
+	if *orig.Checkpoint != JobStageChunk {
+		t.Error("Clone did not isolate Checkpoint pointer")
+	}
 }
 
 func TestJobClone_NilPointers(t *testing.T) {
 	orig := &Job{ID: 1}
 	clone := orig.Clone()
 
-	if clone.Error != nil || clone.StartedAt != nil || clone.CompletedAt != nil {
+	if clone.Error != nil || clone.StartedAt != nil || clone.CompletedAt != nil ||
+		clone.LockedBy != nil || clone.LeaseExpiresAt != nil || clone.Checkpoint != nil {
 		t.Error("Clone should preserve nil pointers")
 	}
 }
@@ -49,3 +68,90 @@ func TestJobClone_Nil(t *testing.T) {
 		t.Error("Clone of nil should return nil")
 	}
 }
+
+func TestJobCompleted(t *testing.T) {
+	parse := JobStageParse
+	j := &Job{Checkpoint: &parse}
+
+	tests := []struct {
+		stage JobStage
+		want  bool
+	}{
+		{JobStageFetch, true},
+		{JobStageParse, true},
+		{JobStageChunk, false},
+		{JobStageStore, false},
+		{JobStage("unknown"), false},
+	}
+
+	for _, tt := range tests {
+		if got := j.Completed(tt.stage); got != tt.want {
+			t.Errorf("Completed(%q) = %v, want %v", tt.stage, got, tt.want)
+		}
+	}
+
+	if (&Job{}).Completed(JobStageFetch) {
+		t.Error("job without checkpoint should not have completed any stage")
+	}
+}
+
+func TestJobResumeStage(t *testing.T) {
+	embed := JobStageEmbed
+	store := JobStageStore
+
+	tests := []struct {
+		name       string
+		checkpoint *JobStage
+		want       JobStage
+	}{
+		{"no checkpoint", nil, JobStageFetch},
+		{"after embed", &embed, JobStageStore},
+		{"all done", &store, JobStageStore},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			j := &Job{Checkpoint: tt.checkpoint}
+			if got := j.ResumeStage(); got != tt.want {
+				t.Errorf("ResumeStage() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestJobRuns(t *testing.T) {
+	ingest := &Job{Kind: JobKindIngest}
+	rebuild := &Job{Kind: JobKindRebuildSCIP}
+
+	for _, stage := range JobStages {
+		if !ingest.Runs(stage) {
+			t.Errorf("ingest job does not run %q", stage)
+		}
+	}
+	if rebuild.Runs(JobStageFetch) || rebuild.Runs(JobStageChunk) {
+		t.Error("rebuild job runs fetch or chunk")
+	}
+	if !rebuild.Runs(JobStageParse) || !rebuild.Runs(JobStageEmbed) || !rebuild.Runs(JobStageStore) {
+		t.Error("rebuild job skips parse, embed, or store")
+	}
+}
+
+func TestJobResumeStage_Rebuild(t *testing.T) {
+	parse := JobStageParse
+
+	if got := (&Job{Kind: JobKindRebuildSCIP}).ResumeStage(); got != JobStageParse {
+		t.Errorf("ResumeStage() = %q, want parse", got)
+	}
+	if got := (&Job{Kind: JobKindRebuildSCIP, Checkpoint: &parse}).ResumeStage(); got != JobStageEmbed {
+		t.Errorf("ResumeStage() after parse = %q, want embed", got)
+	}
+}
+
+func TestJobKindStoredIndex(t *testing.T) {
+	if JobKindIngest.StoredIndex() {
+		t.Error("ingest jobs should run the indexer")
+	}
+	if !JobKindIngestUpload.StoredIndex() || !JobKindRebuildSCIP.StoredIndex() {
+		t.Error("upload and rebuild jobs should parse the stored index")
+	}
+}
@@ -91,6 +91,14 @@ type SCIPRelationship struct {
 	IsDefinition     bool   `json:"is_definition" db:"is_definition" default:"false" description:"Override definition behavior"`
 }
 
+// SCIPBatch holds the SCIP rows extracted from one document, written
+// together. Relationships[i] belong to Symbols[i] and receive its ID.
+type SCIPBatch struct {
+	Symbols       []*SCIPSymbol
+	Relationships [][]*SCIPRelationship
+	Occurrences   []*SCIPOccurrence
+}
+
 // Clone returns a deep copy of the SCIPSymbol.
 func (s SCIPSymbol) Clone() SCIPSymbol {
 	c := s
@@ -1,6 +1,10 @@
 package models
 
-import "time"
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+)
 
 // VersionStatus represents the ingestion state of a version.
 type VersionStatus string
@@ -15,17 +19,69 @@ const (
 
 // Version represents an ingested snapshot of a repository at a specific tag.
 type Version struct {
-	ID           int64         `json:"id" db:"id" constraints:"primarykey" description:"Internal version ID"`
-	RepositoryID int64         `json:"repository_id" db:"repository_id" constraints:"notnull" references:"repositories(id)" description:"Parent repository"`
-	UserID       int64         `json:"user_id" db:"user_id" constraints:"notnull" references:"users(id)" description:"Owning user"`
-	Owner        string        `json:"owner" db:"owner" constraints:"notnull" description:"GitHub org or user" example:"octocat"`
-	RepoName     string        `json:"repo_name" db:"repo_name" constraints:"notnull" description:"Repository name" example:"hello-world"`
-	Tag          string        `json:"tag" db:"tag" constraints:"notnull" description:"Version tag" example:"v1.0.0"`
-	CommitSHA    string        `json:"commit_sha" db:"commit_sha" constraints:"notnull" description:"Git commit SHA" example:"a1b2c3d4e5f6"`
-	Status       VersionStatus `json:"status" db:"status" constraints:"notnull" default:"'pending'" description:"Ingestion status"`
-	Error        *string       `json:"error,omitempty" db:"error" description:"Ingestion error if failed"`
-	CreatedAt    time.Time     `json:"created_at" db:"created_at" default:"now()" description:"Ingestion start time"`
-	UpdatedAt    time.Time     `json:"updated_at" db:"updated_at" default:"now()" description:"Last status update"`
+	ID                int64           `json:"id" db:"id" constraints:"primarykey" description:"Internal version ID"`
+	RepositoryID      int64           `json:"repository_id" db:"repository_id" constraints:"notnull" references:"repositories(id)" description:"Parent repository"`
+	UserID            int64           `json:"user_id" db:"user_id" constraints:"notnull" references:"users(id)" description:"Owning user"`
+	Owner             string          `json:"owner" db:"owner" constraints:"notnull" description:"GitHub org or user" example:"octocat"`
+	RepoName          string          `json:"repo_name" db:"repo_name" constraints:"notnull" description:"Repository name" example:"hello-world"`
+	Tag               string          `json:"tag" db:"tag" constraints:"notnull" description:"Version tag" example:"v1.0.0"`
+	CommitSHA         string          `json:"commit_sha" db:"commit_sha" constraints:"notnull" description:"Git commit SHA" example:"a1b2c3d4e5f6"`
+	Status            VersionStatus   `json:"status" db:"status" constraints:"notnull" default:"'pending'" description:"Ingestion status"`
+	Error             *string         `json:"error,omitempty" db:"error" description:"Ingestion error if failed"`
+	CreatedAt         time.Time       `json:"created_at" db:"created_at" default:"now()" description:"Ingestion start time"`
+	UpdatedAt         time.Time       `json:"updated_at" db:"updated_at" default:"now()" description:"Last status update"`
+	ReplacesID        *int64          `json:"replaces_id,omitempty" db:"replaces_id" references:"versions(id)" description:"Live version this staging version replaces once ready"`
+	EmbeddingStrategy json.RawMessage `json:"embedding_strategy,omitempty" db:"embedding_strategy" description:"How chunk text was enriched before embedding"`
+}
+
+// GetEmbeddingStrategy parses and returns the recorded embedding strategy.
+// Versions without one were embedded with the raw strategy.
+func (v Version) GetEmbeddingStrategy() (*EmbeddingStrategy, error) {
+	if v.EmbeddingStrategy == nil {
+		return DefaultEmbeddingStrategy(), nil
+	}
+	var s EmbeddingStrategy
+	if err := json.Unmarshal(v.EmbeddingStrategy, &s); err != nil {
+		return nil, err
+	}
+	return &s, nil
+}
+
+// SetEmbeddingStrategy records the embedding strategy on the version.
+func (v *Version) SetEmbeddingStrategy(s *EmbeddingStrategy) error {
+	data, err := json.Marshal(s)
+	if err != nil {
+		return err
+	}
+	v.EmbeddingStrategy = data
+	return nil
+}
+
+// Staging reports whether the version is a re-ingestion being built alongside
+// the live version for its tag.
+func (v Version) Staging() bool {
+	return v.ReplacesID != nil
+}
+
+// DataTag returns the tag the version's documents, chunks, and symbols are
+// written under. Staging versions build under a private tag until promoted.
+func (v Version) DataTag() string {
+	if v.Staging() {
+		return StagingTag(v.Tag, v.ID)
+	}
+	return v.Tag
+}
+
+// StagingTag returns the private tag for a staging version's rows.
+// Git forbids "~" in ref names, so it can never collide with a real tag.
+func StagingTag(tag string, versionID int64) string {
+	return fmt.Sprintf("%s~staging.%d", tag, versionID)
+}
+
+// RebuildTag returns the private tag a SCIP rebuild of a live version writes
+// its SCIP rows and symbols under until they are swapped in.
+func RebuildTag(tag string, versionID int64) string {
+	return fmt.Sprintf("%s~rebuild.%d", tag, versionID)
 }
 
 // Clone returns a deep copy of the Version.
@@ -35,5 +91,13 @@ func (v Version) Clone() Version {
 		e := *v.Error
 		c.Error = &e
 	}
+	if v.ReplacesID != nil {
+		r := *v.ReplacesID
+		c.ReplacesID = &r
+	}
+	if v.EmbeddingStrategy != nil {
+		c.EmbeddingStrategy = make(json.RawMessage, len(v.EmbeddingStrategy))
+		copy(c.EmbeddingStrategy, v.EmbeddingStrategy)
+	}
 	return c
 }
@@ -29,3 +29,67 @@ func TestVersionClone_NilError(t *testing.T) {
 		t.Errorf("ID = %d, want 1", clone.ID)
 	}
 }
+
+func TestVersionClone_ReplacesID(t *testing.T) {
+	live := int64(7)
+	orig := Version{ID: 8, ReplacesID: &live}
+	clone := orig.Clone()
+
+	*clone.ReplacesID = 99
+
+	if *orig.ReplacesID != 7 {
+		t.Error("Clone did not isolate ReplacesID pointer")
+	}
+}
+
+func TestVersionDataTag(t *testing.T) {
+	live := Version{ID: 7, Tag: "v1.0.0"}
+	if live.Staging() {
+		t.Error("live version reported as staging")
+	}
+	if got := live.DataTag(); got != "v1.0.0" {
+		t.Errorf("DataTag() = %q, want %q", got, "v1.0.0")
+	}
+
+	replaces := int64(7)
+	staging := Version{ID: 8, Tag: "v1.0.0", ReplacesID: &replaces}
+	if !staging.Staging() {
+		t.Error("staging version not reported as staging")
+	}
+	if got := staging.DataTag(); got != "v1.0.0~staging.8" {
+		t.Errorf("DataTag() = %q, want %q", got, "v1.0.0~staging.8")
+	}
+}
+
+func TestVersionEmbeddingStrategy(t *testing.T) {
+	var v Version
+
+	got, err := v.GetEmbeddingStrategy()
+	if err != nil {
+		t.Fatalf("GetEmbeddingStrategy: %v", err)
+	}
+	if got.Name != RawEmbeddingStrategy {
+		t.Errorf("unrecorded strategy = %q, want %q", got.Name, RawEmbeddingStrategy)
+	}
+
+	want := &EmbeddingStrategy{Name: "contextual", Templates: map[ContentType]string{ContentTypeCode: "{{.Content}}"}}
+	if err := v.SetEmbeddingStrategy(want); err != nil {
+		t.Fatalf("SetEmbeddingStrategy: %v", err)
+	}
+	got, err = v.GetEmbeddingStrategy()
+	if err != nil {
+		t.Fatalf("GetEmbeddingStrategy: %v", err)
+	}
+	if !got.Equal(want) {
+		t.Errorf("strategy = %+v, want %+v", got, want)
+	}
+	if got.Equal(DefaultEmbeddingStrategy()) {
+		t.Error("contextual strategy equals raw")
+	}
+
+	clone := v.Clone()
+	clone.EmbeddingStrategy[0] = 'X'
+	if v.EmbeddingStrategy[0] == 'X' {
+		t.Error("Clone did not isolate EmbeddingStrategy")
+	}
+}
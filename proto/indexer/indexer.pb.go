@@ -1,6 +1,6 @@
 // Code generated by protoc-gen-go. DO NOT EDIT.
 // versions:
-// 	protoc-gen-go v1.36.6
+// 	protoc-gen-go v1.36.11
 // 	protoc        v6.33.1
 // source: indexer.proto
 
@@ -23,18 +23,29 @@ const (
 
 // IndexRequest contains the information needed to run a SCIP indexer.
 type IndexRequest struct {
-	state         protoimpl.MessageState `protogen:"open.v1"`
-	JobId         int64                  `protobuf:"varint,1,opt,name=job_id,json=jobId,proto3" json:"job_id,omitempty"`
-	RepositoryId  int64                  `protobuf:"varint,2,opt,name=repository_id,json=repositoryId,proto3" json:"repository_id,omitempty"`
-	VersionId     int64                  `protobuf:"varint,3,opt,name=version_id,json=versionId,proto3" json:"version_id,omitempty"`
-	UserId        int64                  `protobuf:"varint,4,opt,name=user_id,json=userId,proto3" json:"user_id,omitempty"`
-	Owner         string                 `protobuf:"bytes,5,opt,name=owner,proto3" json:"owner,omitempty"`
-	RepoName      string                 `protobuf:"bytes,6,opt,name=repo_name,json=repoName,proto3" json:"repo_name,omitempty"`
-	Tag           string                 `protobuf:"bytes,7,opt,name=tag,proto3" json:"tag,omitempty"`
-	CommitSha     string                 `protobuf:"bytes,8,opt,name=commit_sha,json=commitSha,proto3" json:"commit_sha,omitempty"`
-	Language      string                 `protobuf:"bytes,9,opt,name=language,proto3" json:"language,omitempty"`
-	unknownFields protoimpl.UnknownFields
-	sizeCache     protoimpl.SizeCache
+	state        protoimpl.MessageState `protogen:"open.v1"`
+	JobId        int64                  `protobuf:"varint,1,opt,name=job_id,json=jobId,proto3" json:"job_id,omitempty"`
+	RepositoryId int64                  `protobuf:"varint,2,opt,name=repository_id,json=repositoryId,proto3" json:"repository_id,omitempty"`
+	VersionId    int64                  `protobuf:"varint,3,opt,name=version_id,json=versionId,proto3" json:"version_id,omitempty"`
+	UserId       int64                  `protobuf:"varint,4,opt,name=user_id,json=userId,proto3" json:"user_id,omitempty"`
+	Owner        string                 `protobuf:"bytes,5,opt,name=owner,proto3" json:"owner,omitempty"`
+	RepoName     string                 `protobuf:"bytes,6,opt,name=repo_name,json=repoName,proto3" json:"repo_name,omitempty"`
+	Tag          string                 `protobuf:"bytes,7,opt,name=tag,proto3" json:"tag,omitempty"`
+	CommitSha    string                 `protobuf:"bytes,8,opt,name=commit_sha,json=commitSha,proto3" json:"commit_sha,omitempty"`
+	Language     string                 `protobuf:"bytes,9,opt,name=language,proto3" json:"language,omitempty"`
+	// Project root within the repository to run the indexer from; empty for
+	// the repository root. Document paths in the index are relative to it.
+	Root string `protobuf:"bytes,10,opt,name=root,proto3" json:"root,omitempty"`
+	// Language-specific settings from the ingestion config.
+	//
+	// Types that are valid to be assigned to LanguageConfig:
+	//
+	//	*IndexRequest_Go
+	//	*IndexRequest_Typescript
+	//	*IndexRequest_Python
+	LanguageConfig isIndexRequest_LanguageConfig `protobuf_oneof:"language_config"`
+	unknownFields  protoimpl.UnknownFields
+	sizeCache      protoimpl.SizeCache
 }
 
 func (x *IndexRequest) Reset() {
@@ -130,32 +141,271 @@ func (x *IndexRequest) GetLanguage() string {
 	return ""
 }
 
-// IndexResult contains the output from a SCIP indexer.
-type IndexResult struct {
+func (x *IndexRequest) GetRoot() string {
+	if x != nil {
+		return x.Root
+	}
+	return ""
+}
+
+func (x *IndexRequest) GetLanguageConfig() isIndexRequest_LanguageConfig {
+	if x != nil {
+		return x.LanguageConfig
+	}
+	return nil
+}
+
+func (x *IndexRequest) GetGo() *GoConfig {
+	if x != nil {
+		if x, ok := x.LanguageConfig.(*IndexRequest_Go); ok {
+			return x.Go
+		}
+	}
+	return nil
+}
+
+func (x *IndexRequest) GetTypescript() *TypeScriptConfig {
+	if x != nil {
+		if x, ok := x.LanguageConfig.(*IndexRequest_Typescript); ok {
+			return x.Typescript
+		}
+	}
+	return nil
+}
+
+func (x *IndexRequest) GetPython() *PythonConfig {
+	if x != nil {
+		if x, ok := x.LanguageConfig.(*IndexRequest_Python); ok {
+			return x.Python
+		}
+	}
+	return nil
+}
+
+type isIndexRequest_LanguageConfig interface {
+	isIndexRequest_LanguageConfig()
+}
+
+type IndexRequest_Go struct {
+	Go *GoConfig `protobuf:"bytes,11,opt,name=go,proto3,oneof"`
+}
+
+type IndexRequest_Typescript struct {
+	Typescript *TypeScriptConfig `protobuf:"bytes,12,opt,name=typescript,proto3,oneof"`
+}
+
+type IndexRequest_Python struct {
+	Python *PythonConfig `protobuf:"bytes,13,opt,name=python,proto3,oneof"`
+}
+
+func (*IndexRequest_Go) isIndexRequest_LanguageConfig() {}
+
+func (*IndexRequest_Typescript) isIndexRequest_LanguageConfig() {}
+
+func (*IndexRequest_Python) isIndexRequest_LanguageConfig() {}
+
+// GoConfig holds scip-go settings.
+type GoConfig struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// Module path to report instead of the one in go.mod.
+	ModulePath string `protobuf:"bytes,1,opt,name=module_path,json=modulePath,proto3" json:"module_path,omitempty"`
+	// Build tags to load packages with.
+	BuildTags     []string `protobuf:"bytes,2,rep,name=build_tags,json=buildTags,proto3" json:"build_tags,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GoConfig) Reset() {
+	*x = GoConfig{}
+	mi := &file_indexer_proto_msgTypes[1]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GoConfig) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GoConfig) ProtoMessage() {}
+
+func (x *GoConfig) ProtoReflect() protoreflect.Message {
+	mi := &file_indexer_proto_msgTypes[1]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GoConfig.ProtoReflect.Descriptor instead.
+func (*GoConfig) Descriptor() ([]byte, []int) {
+	return file_indexer_proto_rawDescGZIP(), []int{1}
+}
+
+func (x *GoConfig) GetModulePath() string {
+	if x != nil {
+		return x.ModulePath
+	}
+	return ""
+}
+
+func (x *GoConfig) GetBuildTags() []string {
+	if x != nil {
+		return x.BuildTags
+	}
+	return nil
+}
+
+// TypeScriptConfig holds scip-typescript settings.
+type TypeScriptConfig struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// tsconfig.json to index, relative to the project root. Inferred when empty.
+	TsconfigPath string `protobuf:"bytes,1,opt,name=tsconfig_path,json=tsconfigPath,proto3" json:"tsconfig_path,omitempty"`
+	// Index .test and .spec files.
+	IncludeTests  bool `protobuf:"varint,2,opt,name=include_tests,json=includeTests,proto3" json:"include_tests,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *TypeScriptConfig) Reset() {
+	*x = TypeScriptConfig{}
+	mi := &file_indexer_proto_msgTypes[2]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *TypeScriptConfig) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*TypeScriptConfig) ProtoMessage() {}
+
+func (x *TypeScriptConfig) ProtoReflect() protoreflect.Message {
+	mi := &file_indexer_proto_msgTypes[2]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use TypeScriptConfig.ProtoReflect.Descriptor instead.
+func (*TypeScriptConfig) Descriptor() ([]byte, []int) {
+	return file_indexer_proto_rawDescGZIP(), []int{2}
+}
+
+func (x *TypeScriptConfig) GetTsconfigPath() string {
+	if x != nil {
+		return x.TsconfigPath
+	}
+	return ""
+}
+
+func (x *TypeScriptConfig) GetIncludeTests() bool {
+	if x != nil {
+		return x.IncludeTests
+	}
+	return false
+}
+
+// PythonConfig holds scip-python settings.
+type PythonConfig struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// Package name used in SCIP symbols.
+	ProjectName string `protobuf:"bytes,1,opt,name=project_name,json=projectName,proto3" json:"project_name,omitempty"`
+	// Package version used in SCIP symbols.
+	ProjectVersion string `protobuf:"bytes,2,opt,name=project_version,json=projectVersion,proto3" json:"project_version,omitempty"`
+	// Index test_*.py and *_test.py files.
+	IncludeTests  bool `protobuf:"varint,3,opt,name=include_tests,json=includeTests,proto3" json:"include_tests,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *PythonConfig) Reset() {
+	*x = PythonConfig{}
+	mi := &file_indexer_proto_msgTypes[3]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *PythonConfig) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*PythonConfig) ProtoMessage() {}
+
+func (x *PythonConfig) ProtoReflect() protoreflect.Message {
+	mi := &file_indexer_proto_msgTypes[3]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use PythonConfig.ProtoReflect.Descriptor instead.
+func (*PythonConfig) Descriptor() ([]byte, []int) {
+	return file_indexer_proto_rawDescGZIP(), []int{3}
+}
+
+func (x *PythonConfig) GetProjectName() string {
+	if x != nil {
+		return x.ProjectName
+	}
+	return ""
+}
+
+func (x *PythonConfig) GetProjectVersion() string {
+	if x != nil {
+		return x.ProjectVersion
+	}
+	return ""
+}
+
+func (x *PythonConfig) GetIncludeTests() bool {
+	if x != nil {
+		return x.IncludeTests
+	}
+	return false
+}
+
+// IndexChunk carries part of the output from a SCIP indexer. Concatenating
+// the data of every chunk in order yields the raw SCIP index. A chunk with
+// an error ends the stream.
+type IndexChunk struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
 	JobId         int64                  `protobuf:"varint,1,opt,name=job_id,json=jobId,proto3" json:"job_id,omitempty"`
 	VersionId     int64                  `protobuf:"varint,2,opt,name=version_id,json=versionId,proto3" json:"version_id,omitempty"`
-	IndexData     []byte                 `protobuf:"bytes,3,opt,name=index_data,json=indexData,proto3" json:"index_data,omitempty"`
+	Data          []byte                 `protobuf:"bytes,3,opt,name=data,proto3" json:"data,omitempty"`
 	Error         string                 `protobuf:"bytes,4,opt,name=error,proto3" json:"error,omitempty"`
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
 
-func (x *IndexResult) Reset() {
-	*x = IndexResult{}
-	mi := &file_indexer_proto_msgTypes[1]
+func (x *IndexChunk) Reset() {
+	*x = IndexChunk{}
+	mi := &file_indexer_proto_msgTypes[4]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
 
-func (x *IndexResult) String() string {
+func (x *IndexChunk) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*IndexResult) ProtoMessage() {}
+func (*IndexChunk) ProtoMessage() {}
 
-func (x *IndexResult) ProtoReflect() protoreflect.Message {
-	mi := &file_indexer_proto_msgTypes[1]
+func (x *IndexChunk) ProtoReflect() protoreflect.Message {
+	mi := &file_indexer_proto_msgTypes[4]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -166,33 +416,33 @@ func (x *IndexResult) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use IndexResult.ProtoReflect.Descriptor instead.
-func (*IndexResult) Descriptor() ([]byte, []int) {
-	return file_indexer_proto_rawDescGZIP(), []int{1}
+// Deprecated: Use IndexChunk.ProtoReflect.Descriptor instead.
+func (*IndexChunk) Descriptor() ([]byte, []int) {
+	return file_indexer_proto_rawDescGZIP(), []int{4}
 }
 
-func (x *IndexResult) GetJobId() int64 {
+func (x *IndexChunk) GetJobId() int64 {
 	if x != nil {
 		return x.JobId
 	}
 	return 0
 }
 
-func (x *IndexResult) GetVersionId() int64 {
+func (x *IndexChunk) GetVersionId() int64 {
 	if x != nil {
 		return x.VersionId
 	}
 	return 0
 }
 
-func (x *IndexResult) GetIndexData() []byte {
+func (x *IndexChunk) GetData() []byte {
 	if x != nil {
-		return x.IndexData
+		return x.Data
 	}
 	return nil
 }
 
-func (x *IndexResult) GetError() string {
+func (x *IndexChunk) GetError() string {
 	if x != nil {
 		return x.Error
 	}
@@ -203,7 +453,7 @@ var File_indexer_proto protoreflect.FileDescriptor
 
 const file_indexer_proto_rawDesc = "" +
 	"\n" +
-	"\rindexer.proto\x12\aindexer\"\x82\x02\n" +
+	"\rindexer.proto\x12\aindexer\"\xbc\x03\n" +
 	"\fIndexRequest\x12\x15\n" +
 	"\x06job_id\x18\x01 \x01(\x03R\x05jobId\x12#\n" +
 	"\rrepository_id\x18\x02 \x01(\x03R\frepositoryId\x12\x1d\n" +
@@ -215,16 +465,36 @@ const file_indexer_proto_rawDesc = "" +
 	"\x03tag\x18\a \x01(\tR\x03tag\x12\x1d\n" +
 	"\n" +
 	"commit_sha\x18\b \x01(\tR\tcommitSha\x12\x1a\n" +
-	"\blanguage\x18\t \x01(\tR\blanguage\"x\n" +
-	"\vIndexResult\x12\x15\n" +
-	"\x06job_id\x18\x01 \x01(\x03R\x05jobId\x12\x1d\n" +
+	"\blanguage\x18\t \x01(\tR\blanguage\x12\x12\n" +
+	"\x04root\x18\n" +
+	" \x01(\tR\x04root\x12#\n" +
+	"\x02go\x18\v \x01(\v2\x11.indexer.GoConfigH\x00R\x02go\x12;\n" +
 	"\n" +
-	"version_id\x18\x02 \x01(\x03R\tversionId\x12\x1d\n" +
+	"typescript\x18\f \x01(\v2\x19.indexer.TypeScriptConfigH\x00R\n" +
+	"typescript\x12/\n" +
+	"\x06python\x18\r \x01(\v2\x15.indexer.PythonConfigH\x00R\x06pythonB\x11\n" +
+	"\x0flanguage_config\"J\n" +
+	"\bGoConfig\x12\x1f\n" +
+	"\vmodule_path\x18\x01 \x01(\tR\n" +
+	"modulePath\x12\x1d\n" +
 	"\n" +
-	"index_data\x18\x03 \x01(\fR\tindexData\x12\x14\n" +
-	"\x05error\x18\x04 \x01(\tR\x05error2F\n" +
-	"\x0eIndexerService\x124\n" +
-	"\x05Index\x12\x15.indexer.IndexRequest\x1a\x14.indexer.IndexResultB(Z&github.com/zoobzio/vicky/proto/indexerb\x06proto3"
+	"build_tags\x18\x02 \x03(\tR\tbuildTags\"\\\n" +
+	"\x10TypeScriptConfig\x12#\n" +
+	"\rtsconfig_path\x18\x01 \x01(\tR\ftsconfigPath\x12#\n" +
+	"\rinclude_tests\x18\x02 \x01(\bR\fincludeTests\"\x7f\n" +
+	"\fPythonConfig\x12!\n" +
+	"\fproject_name\x18\x01 \x01(\tR\vprojectName\x12'\n" +
+	"\x0fproject_version\x18\x02 \x01(\tR\x0eprojectVersion\x12#\n" +
+	"\rinclude_tests\x18\x03 \x01(\bR\fincludeTests\"l\n" +
+	"\n" +
+	"IndexChunk\x12\x15\n" +
+	"\x06job_id\x18\x01 \x01(\x03R\x05jobId\x12\x1d\n" +
+	"\n" +
+	"version_id\x18\x02 \x01(\x03R\tversionId\x12\x12\n" +
+	"\x04data\x18\x03 \x01(\fR\x04data\x12\x14\n" +
+	"\x05error\x18\x04 \x01(\tR\x05error2G\n" +
+	"\x0eIndexerService\x125\n" +
+	"\x05Index\x12\x15.indexer.IndexRequest\x1a\x13.indexer.IndexChunk0\x01B(Z&github.com/zoobzio/vicky/proto/indexerb\x06proto3"
 
 var (
 	file_indexer_proto_rawDescOnce sync.Once
@@ -238,19 +508,25 @@ func file_indexer_proto_rawDescGZIP() []byte {
 	return file_indexer_proto_rawDescData
 }
 
-var file_indexer_proto_msgTypes = make([]protoimpl.MessageInfo, 2)
+var file_indexer_proto_msgTypes = make([]protoimpl.MessageInfo, 5)
 var file_indexer_proto_goTypes = []any{
-	(*IndexRequest)(nil), // 0: indexer.IndexRequest
-	(*IndexResult)(nil),  // 1: indexer.IndexResult
+	(*IndexRequest)(nil),     // 0: indexer.IndexRequest
+	(*GoConfig)(nil),         // 1: indexer.GoConfig
+	(*TypeScriptConfig)(nil), // 2: indexer.TypeScriptConfig
+	(*PythonConfig)(nil),     // 3: indexer.PythonConfig
+	(*IndexChunk)(nil),       // 4: indexer.IndexChunk
 }
 var file_indexer_proto_depIdxs = []int32{
-	0, // 0: indexer.IndexerService.Index:input_type -> indexer.IndexRequest
-	1, // 1: indexer.IndexerService.Index:output_type -> indexer.IndexResult
-	1, // [1:2] is the sub-list for method output_type
-	0, // [0:1] is the sub-list for method input_type
-	0, // [0:0] is the sub-list for extension type_name
-	0, // [0:0] is the sub-list for extension extendee
-	0, // [0:0] is the sub-list for field type_name
+	1, // 0: indexer.IndexRequest.go:type_name -> indexer.GoConfig
+	2, // 1: indexer.IndexRequest.typescript:type_name -> indexer.TypeScriptConfig
+	3, // 2: indexer.IndexRequest.python:type_name -> indexer.PythonConfig
+	0, // 3: indexer.IndexerService.Index:input_type -> indexer.IndexRequest
+	4, // 4: indexer.IndexerService.Index:output_type -> indexer.IndexChunk
+	4, // [4:5] is the sub-list for method output_type
+	3, // [3:4] is the sub-list for method input_type
+	3, // [3:3] is the sub-list for extension type_name
+	3, // [3:3] is the sub-list for extension extendee
+	0, // [0:3] is the sub-list for field type_name
 }
 
 func init() { file_indexer_proto_init() }
@@ -258,13 +534,18 @@ func file_indexer_proto_init() {
 	if File_indexer_proto != nil {
 		return
 	}
+	file_indexer_proto_msgTypes[0].OneofWrappers = []any{
+		(*IndexRequest_Go)(nil),
+		(*IndexRequest_Typescript)(nil),
+		(*IndexRequest_Python)(nil),
+	}
 	type x struct{}
 	out := protoimpl.TypeBuilder{
 		File: protoimpl.DescBuilder{
 			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
 			RawDescriptor: unsafe.Slice(unsafe.StringData(file_indexer_proto_rawDesc), len(file_indexer_proto_rawDesc)),
 			NumEnums:      0,
-			NumMessages:   2,
+			NumMessages:   5,
 			NumExtensions: 0,
 			NumServices:   1,
 		},
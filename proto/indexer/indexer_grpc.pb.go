@@ -29,8 +29,8 @@ const (
 // IndexerService provides SCIP indexing for source code.
 type IndexerServiceClient interface {
 	// Index runs the SCIP indexer against source files in blob storage
-	// and returns the raw SCIP index data.
-	Index(ctx context.Context, in *IndexRequest, opts ...grpc.CallOption) (*IndexResult, error)
+	// and streams the raw SCIP index data back in chunks.
+	Index(ctx context.Context, in *IndexRequest, opts ...grpc.CallOption) (grpc.ServerStreamingClient[IndexChunk], error)
 }
 
 type indexerServiceClient struct {
@@ -41,16 +41,25 @@ func NewIndexerServiceClient(cc grpc.ClientConnInterface) IndexerServiceClient {
 	return &indexerServiceClient{cc}
 }
 
-func (c *indexerServiceClient) Index(ctx context.Context, in *IndexRequest, opts ...grpc.CallOption) (*IndexResult, error) {
+func (c *indexerServiceClient) Index(ctx context.Context, in *IndexRequest, opts ...grpc.CallOption) (grpc.ServerStreamingClient[IndexChunk], error) {
 	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
-	out := new(IndexResult)
-	err := c.cc.Invoke(ctx, IndexerService_Index_FullMethodName, in, out, cOpts...)
+	stream, err := c.cc.NewStream(ctx, &IndexerService_ServiceDesc.Streams[0], IndexerService_Index_FullMethodName, cOpts...)
 	if err != nil {
 		return nil, err
 	}
-	return out, nil
+	x := &grpc.GenericClientStream[IndexRequest, IndexChunk]{ClientStream: stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
 }
 
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type IndexerService_IndexClient = grpc.ServerStreamingClient[IndexChunk]
+
 // IndexerServiceServer is the server API for IndexerService service.
 // All implementations must embed UnimplementedIndexerServiceServer
 // for forward compatibility.
@@ -58,8 +67,8 @@ func (c *indexerServiceClient) Index(ctx context.Context, in *IndexRequest, opts
 // IndexerService provides SCIP indexing for source code.
 type IndexerServiceServer interface {
 	// Index runs the SCIP indexer against source files in blob storage
-	// and returns the raw SCIP index data.
-	Index(context.Context, *IndexRequest) (*IndexResult, error)
+	// and streams the raw SCIP index data back in chunks.
+	Index(*IndexRequest, grpc.ServerStreamingServer[IndexChunk]) error
 	mustEmbedUnimplementedIndexerServiceServer()
 }
 
@@ -70,8 +79,8 @@ type IndexerServiceServer interface {
 // pointer dereference when methods are called.
 type UnimplementedIndexerServiceServer struct{}
 
-func (UnimplementedIndexerServiceServer) Index(context.Context, *IndexRequest) (*IndexResult, error) {
-	return nil, status.Errorf(codes.Unimplemented, "method Index not implemented")
+func (UnimplementedIndexerServiceServer) Index(*IndexRequest, grpc.ServerStreamingServer[IndexChunk]) error {
+	return status.Errorf(codes.Unimplemented, "method Index not implemented")
 }
 func (UnimplementedIndexerServiceServer) mustEmbedUnimplementedIndexerServiceServer() {}
 func (UnimplementedIndexerServiceServer) testEmbeddedByValue()                        {}
@@ -94,36 +103,30 @@ func RegisterIndexerServiceServer(s grpc.ServiceRegistrar, srv IndexerServiceSer
 	s.RegisterService(&IndexerService_ServiceDesc, srv)
 }
 
-func _IndexerService_Index_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
-	in := new(IndexRequest)
-	if err := dec(in); err != nil {
-		return nil, err
-	}
-	if interceptor == nil {
-		return srv.(IndexerServiceServer).Index(ctx, in)
-	}
-	info := &grpc.UnaryServerInfo{
-		Server:     srv,
-		FullMethod: IndexerService_Index_FullMethodName,
+func _IndexerService_Index_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(IndexRequest)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
 	}
-	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
-		return srv.(IndexerServiceServer).Index(ctx, req.(*IndexRequest))
-	}
-	return interceptor(ctx, in, info, handler)
+	return srv.(IndexerServiceServer).Index(m, &grpc.GenericServerStream[IndexRequest, IndexChunk]{ServerStream: stream})
 }
 
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type IndexerService_IndexServer = grpc.ServerStreamingServer[IndexChunk]
+
 // IndexerService_ServiceDesc is the grpc.ServiceDesc for IndexerService service.
 // It's only intended for direct use with grpc.RegisterService,
 // and not to be introspected or modified (even as a copy)
 var IndexerService_ServiceDesc = grpc.ServiceDesc{
 	ServiceName: "indexer.IndexerService",
 	HandlerType: (*IndexerServiceServer)(nil),
-	Methods: []grpc.MethodDesc{
+	Methods:     []grpc.MethodDesc{},
+	Streams: []grpc.StreamDesc{
 		{
-			MethodName: "Index",
-			Handler:    _IndexerService_Index_Handler,
+			StreamName:    "Index",
+			Handler:       _IndexerService_Index_Handler,
+			ServerStreams: true,
 		},
 	},
-	Streams:  []grpc.StreamDesc{},
 	Metadata: "indexer.proto",
 }
@@ -10,6 +10,7 @@ import (
 	"github.com/zoobzio/chisel/markdown"
 	"github.com/zoobzio/chisel/typescript"
 	"github.com/zoobzio/vicky/chunkers"
+	"github.com/zoobzio/vicky/chunkers/formats"
 )
 
 func main() {
@@ -23,6 +24,15 @@ func main() {
 	defer otel.Shutdown(ctx)
 
 	srv := chunkers.NewServer(
+		[]formats.Provider{
+			formats.NewRST(),
+			formats.NewAsciiDoc(),
+			formats.NewText(),
+			formats.NewOpenAPI(),
+			formats.NewProto(),
+			formats.NewSQL(),
+			formats.NewPython(),
+		},
 		golang.New(),
 		typescript.New(),
 		typescript.NewJavaScript(),
@@ -0,0 +1,48 @@
+package formats
+
+import (
+	"regexp"
+	"strings"
+)
+
+// AsciiDoc chunks AsciiDoc at its section titles.
+type AsciiDoc struct{}
+
+// NewAsciiDoc creates an AsciiDoc provider.
+func NewAsciiDoc() *AsciiDoc { return &AsciiDoc{} }
+
+// Format returns "asciidoc".
+func (p *AsciiDoc) Format() string { return "asciidoc" }
+
+// asciiDocTitle matches a section title: = for the document title, == for
+// level 1 and so on, or the Markdown-style # equivalents.
+var asciiDocTitle = regexp.MustCompile(`^(={1,6}|#{1,6})[ \t]+(\S.*)$`)
+
+// asciiDocDelimiter matches the lines that open and close a delimited block,
+// such as a listing or an example, whose content holds no titles.
+var asciiDocDelimiter = regexp.MustCompile("^(-{4,}|\\.{4,}|={4,}|\\*{4,}|_{4,}|\\+{4,}|/{4,}|\\|===|```.*)$")
+
+// Chunk splits a document into one chunk per section.
+func (p *AsciiDoc) Chunk(filename string, content []byte) []Chunk {
+	lines := splitLines(content)
+
+	var headings []heading
+	var block string // delimiter of the open block, if any
+	for i, line := range lines {
+		line = strings.TrimRight(line, " \t")
+		if block != "" {
+			if line == block || (strings.HasPrefix(block, "```") && line == "```") {
+				block = ""
+			}
+			continue
+		}
+		if asciiDocDelimiter.MatchString(line) {
+			block = line
+			continue
+		}
+		if m := asciiDocTitle.FindStringSubmatch(line); m != nil {
+			headings = append(headings, heading{line: i, level: len(m[1]) - 1, title: strings.TrimSpace(m[2])})
+		}
+	}
+	return sections(lines, headings)
+}
@@ -0,0 +1,128 @@
+// Package formats chunks the formats the chunker does not hand to chisel:
+// reStructuredText, AsciiDoc, plain text, OpenAPI, protobuf, SQL, and
+// Python. Each provider splits a file along the structure of its format,
+// so chunks follow sections, definitions, and statements rather than size.
+package formats
+
+import "strings"
+
+// Kind is the type of a chunk, matching vicky's chunk kinds.
+type Kind string
+
+// Kind values.
+const (
+	KindModule    Kind = "module"
+	KindFunction  Kind = "function"
+	KindMethod    Kind = "method"
+	KindClass     Kind = "class"
+	KindType      Kind = "type"
+	KindEnum      Kind = "enum"
+	KindSection   Kind = "section"
+	KindParagraph Kind = "paragraph"
+	KindMessage   Kind = "message"
+	KindService   Kind = "service"
+	KindEndpoint  Kind = "endpoint"
+	KindSchema    Kind = "schema"
+	KindTable     Kind = "table"
+	KindView      Kind = "view"
+	KindIndex     Kind = "index"
+	KindStatement Kind = "statement"
+)
+
+// Chunk is a segment of a file.
+type Chunk struct {
+	Content   string
+	Symbol    string // section title or definition name, if any
+	Kind      Kind
+	StartLine int      // 1-based, inclusive
+	EndLine   int      // 1-based, inclusive
+	Context   []string // enclosing sections or definitions, outermost first
+}
+
+// Provider chunks the files of one format.
+type Provider interface {
+	// Format is the chunker language the provider serves.
+	Format() string
+
+	// Chunk splits a file into chunks, in file order.
+	Chunk(filename string, content []byte) []Chunk
+}
+
+// splitLines splits content into lines without their line endings.
+func splitLines(content []byte) []string {
+	text := strings.ReplaceAll(string(content), "\r\n", "\n")
+	text = strings.TrimSuffix(text, "\n")
+	if text == "" {
+		return nil
+	}
+	return strings.Split(text, "\n")
+}
+
+// blank reports whether a line holds only whitespace.
+func blank(line string) bool {
+	return strings.TrimSpace(line) == ""
+}
+
+// span builds a chunk of lines[start:end], with surrounding blank lines
+// dropped. It reports false when the lines are all blank.
+func span(lines []string, start, end int, kind Kind, symbol string, context []string) (Chunk, bool) {
+	for start < end && blank(lines[start]) {
+		start++
+	}
+	for end > start && blank(lines[end-1]) {
+		end--
+	}
+	if start == end {
+		return Chunk{}, false
+	}
+	return Chunk{
+		Content:   strings.Join(lines[start:end], "\n"),
+		Symbol:    symbol,
+		Kind:      kind,
+		StartLine: start + 1,
+		EndLine:   end,
+		Context:   context,
+	}, true
+}
+
+// heading is a section title found in a document.
+type heading struct {
+	line  int // index of the heading's first line
+	level int // 0 for the outermost sections
+	title string
+}
+
+// sections chunks a document at its headings. Each section runs from its
+// heading to the next, with the titles of its enclosing sections as its
+// context; text before the first heading is a section of its own.
+func sections(lines []string, headings []heading) []Chunk {
+	var chunks []Chunk
+	end := len(lines)
+	if len(headings) > 0 {
+		end = headings[0].line
+	}
+	if c, ok := span(lines, 0, end, KindSection, "", nil); ok {
+		chunks = append(chunks, c)
+	}
+
+	var stack []heading
+	for i, h := range headings {
+		for len(stack) > 0 && stack[len(stack)-1].level >= h.level {
+			stack = stack[:len(stack)-1]
+		}
+		var context []string
+		for _, parent := range stack {
+			context = append(context, parent.title)
+		}
+		stack = append(stack, h)
+
+		end := len(lines)
+		if i+1 < len(headings) {
+			end = headings[i+1].line
+		}
+		if c, ok := span(lines, h.line, end, KindSection, h.title, context); ok {
+			chunks = append(chunks, c)
+		}
+	}
+	return chunks
+}
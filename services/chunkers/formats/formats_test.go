@@ -0,0 +1,302 @@
+package formats
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+)
+
+// summary renders chunks as "kind symbol start-end [context]" lines.
+func summary(chunks []Chunk) string {
+	var b strings.Builder
+	for _, c := range chunks {
+		fmt.Fprintf(&b, "%s %s %d-%d", c.Kind, c.Symbol, c.StartLine, c.EndLine)
+		if len(c.Context) > 0 {
+			b.WriteString(" [" + strings.Join(c.Context, " > ") + "]")
+		}
+		b.WriteString("\n")
+	}
+	return b.String()
+}
+
+// check compares chunks with a summary, line by line.
+func check(t *testing.T, chunks []Chunk, want ...string) {
+	t.Helper()
+	got := strings.TrimSuffix(summary(chunks), "\n")
+	if got != strings.Join(want, "\n") {
+		t.Errorf("chunks:\n%s\nwant:\n%s", got, strings.Join(want, "\n"))
+	}
+}
+
+func TestRST(t *testing.T) {
+	doc := `Preamble text.
+
+=====
+Guide
+=====
+
+Intro.
+
+Install
+-------
+
+Run it::
+
+    go install ./...
+    ---
+
+Usage
+-----
+
+Flags
+~~~~~
+
+Details.
+
+=========
+Reference
+=========
+`
+	check(t, NewRST().Chunk("guide.rst", []byte(doc)),
+		"section  1-1",
+		"section Guide 3-7",
+		"section Install 9-15 [Guide]",
+		"section Usage 17-18 [Guide]",
+		"section Flags 20-23 [Guide > Usage]",
+		"section Reference 25-27",
+	)
+}
+
+func TestAsciiDoc(t *testing.T) {
+	doc := `= Handbook
+:toc:
+
+== Setup
+
+----
+== not a title
+----
+
+=== Database
+
+== Operations
+`
+	check(t, NewAsciiDoc().Chunk("handbook.adoc", []byte(doc)),
+		"section Handbook 1-2",
+		"section Setup 4-8 [Handbook]",
+		"section Database 10-10 [Handbook > Setup]",
+		"section Operations 12-12 [Handbook]",
+	)
+}
+
+func TestText(t *testing.T) {
+	long := strings.Repeat("word ", textChunkSize/5)
+	doc := "First paragraph.\n\nSecond\nparagraph.\n\n" + long + "\n\nLast.\n"
+
+	chunks := NewText().Chunk("notes.txt", []byte(doc))
+	check(t, chunks,
+		"paragraph  1-4",
+		"paragraph  6-6",
+		"paragraph  8-8",
+	)
+	if chunks[0].Content != "First paragraph.\n\nSecond\nparagraph." {
+		t.Errorf("content = %q", chunks[0].Content)
+	}
+	if got := NewText().Chunk("empty.txt", []byte("\n\n")); len(got) != 0 {
+		t.Errorf("empty file chunks = %d, want 0", len(got))
+	}
+}
+
+func TestProto(t *testing.T) {
+	doc := `syntax = "proto3";
+
+package users.v1;
+
+import "google/protobuf/timestamp.proto";
+
+// User is an account.
+message User {
+  string name = 1; // "}" in a comment
+  message Address {
+    string street = 1;
+  }
+  /* } */
+}
+
+enum Role { ROLE_UNSPECIFIED = 0; }
+
+/*
+ * Users manages accounts.
+ */
+service Users {
+  rpc Get(GetRequest) returns (User) {
+    option (google.api.http) = { get: "/v1/users/{id}" };
+  }
+}
+
+message Empty {}
+`
+	chunks := NewProto().Chunk("users.proto", []byte(doc))
+	check(t, chunks,
+		"module users.v1 1-5",
+		"message User 7-14 [package users.v1]",
+		"enum Role 16-16 [package users.v1]",
+		"service Users 18-25 [package users.v1]",
+		"message Empty 27-27 [package users.v1]",
+	)
+	if !strings.HasPrefix(chunks[1].Content, "// User is an account.") {
+		t.Errorf("message content = %q, want its comment first", chunks[1].Content)
+	}
+}
+
+func TestSQL(t *testing.T) {
+	doc := `-- +goose Up
+-- Users of the service
+CREATE TABLE IF NOT EXISTS "users" (
+    id BIGSERIAL PRIMARY KEY,
+    bio TEXT DEFAULT 'a; b'
+);
+CREATE UNIQUE INDEX idx_users_name ON users(name);
+
+-- +goose StatementBegin
+CREATE OR REPLACE FUNCTION touch() RETURNS trigger AS $$
+BEGIN
+    NEW.updated_at = now();
+    RETURN NEW;
+END;
+$$ LANGUAGE plpgsql;
+-- +goose StatementEnd
+
+/* backfill */ UPDATE users SET bio = '';
+
+-- +goose Down
+DROP TABLE users;
+`
+	check(t, NewSQL().Chunk("001_users.sql", []byte(doc)),
+		"table users 2-6 [migration up]",
+		"index idx_users_name 7-7 [migration up]",
+		"function touch 10-15 [migration up]",
+		"statement  18-18 [migration up]",
+		"statement users 21-21 [migration down]",
+	)
+
+	check(t, NewSQL().Chunk("query.sql", []byte("SELECT 1;\nCREATE VIEW active AS SELECT * FROM users")),
+		"statement  1-1",
+		"view active 2-2",
+	)
+}
+
+func TestOpenAPI(t *testing.T) {
+	yaml := `openapi: 3.0.3
+info:
+  title: Users
+servers:
+  - url: https://api.example.com
+paths:
+  /users:
+    get:
+      summary: List users
+  /users/{id}:
+    get:
+      summary: Get a user
+    delete:
+      summary: Delete a user
+components:
+  schemas:
+    User:
+      type: object
+    Error:
+      type: object
+  securitySchemes:
+    token:
+      type: http
+tags:
+  - name: users
+`
+	check(t, NewOpenAPI().Chunk("openapi.yaml", []byte(yaml)),
+		"section  1-5",
+		"endpoint /users 7-9 [paths]",
+		"endpoint /users/{id} 10-14 [paths]",
+		"schema User 17-18 [components > schemas]",
+		"schema Error 19-20 [components > schemas]",
+		"section securitySchemes 21-23 [components]",
+		"section  24-25",
+	)
+
+	json := `{
+  "swagger": "2.0",
+  "paths": {
+    "/pets": {
+      "get": {}
+    }
+  },
+  "definitions": {
+    "Pet": {
+      "type": "object"
+    }
+  }
+}
+`
+	check(t, NewOpenAPI().Chunk("swagger.json", []byte(json)),
+		"section  2-2",
+		"endpoint /pets 4-7 [paths]",
+		"schema Pet 9-13 [definitions]",
+	)
+
+	check(t, NewOpenAPI().Chunk("openapi.json", []byte(`{"openapi":"3.0.0","paths":{}}`)),
+		"section  1-1",
+	)
+}
+
+func TestPython(t *testing.T) {
+	doc := `"""Users of the service."""
+
+import os
+
+# Shared by every handler.
+DEFAULT = os.environ.get(
+    "DEFAULT",
+)
+
+
+@dataclass
+class User:
+    """An account."""
+
+    name: str
+
+    # Called by the framework.
+    @property
+    def label(self):
+        return f"{self.name} #1"
+
+    class Meta:
+        table = "users"
+
+    def save(self, path="""
+def not_a_function():
+"""):
+        pass
+
+
+async def fetch(url: str) -> User:
+    return await get(url)
+
+
+class Empty: pass
+
+if __name__ == "__main__":
+    fetch(\
+"x")
+`
+	check(t, NewPython().Chunk("app/users.py", []byte(doc)),
+		"module users 1-8",
+		"class User 11-15",
+		"method label 17-20 [User]",
+		"class Meta 22-23 [User]",
+		"method save 25-28 [User]",
+		"function fetch 31-32",
+		"class Empty 35-35",
+		"module users 37-39",
+	)
+}
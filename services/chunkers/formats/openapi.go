@@ -0,0 +1,150 @@
+package formats
+
+import "strings"
+
+// OpenAPI chunks OpenAPI and Swagger specs, in YAML or indented JSON, at
+// their paths and schemas.
+type OpenAPI struct{}
+
+// NewOpenAPI creates an OpenAPI provider.
+func NewOpenAPI() *OpenAPI { return &OpenAPI{} }
+
+// Format returns "openapi".
+func (p *OpenAPI) Format() string { return "openapi" }
+
+// Chunk splits a spec into an endpoint chunk per path, with all its
+// operations, and a schema chunk per schema under components.schemas or
+// Swagger's definitions. Each other group of components is a section, as
+// is each run of remaining top-level keys such as info and servers. The
+// structure is read from indentation, so a spec that is not indented
+// block by block, such as minified JSON, is a single section.
+func (p *OpenAPI) Chunk(filename string, content []byte) []Chunk {
+	lines := splitLines(content)
+
+	top := childBlocks(lines, 0, len(lines), -1)
+	if !isOpenAPI(top) {
+		if c, ok := span(lines, 0, len(lines), KindSection, "", nil); ok {
+			return []Chunk{c}
+		}
+		return nil
+	}
+
+	var chunks []Chunk
+	rest := -1 // first line of the pending run of other top-level keys
+	flush := func(end int) {
+		if rest >= 0 {
+			if c, ok := span(lines, rest, end, KindSection, "", nil); ok {
+				chunks = append(chunks, c)
+			}
+		}
+		rest = -1
+	}
+
+	for _, b := range top {
+		switch b.key {
+		case "paths":
+			flush(b.start)
+			chunks = append(chunks, children(lines, b, KindEndpoint, []string{"paths"})...)
+		case "definitions":
+			flush(b.start)
+			chunks = append(chunks, children(lines, b, KindSchema, []string{"definitions"})...)
+		case "components":
+			flush(b.start)
+			for _, group := range childBlocks(lines, b.start+1, b.end, b.indent) {
+				if group.key == "schemas" {
+					chunks = append(chunks, children(lines, group, KindSchema, []string{"components", "schemas"})...)
+				} else if c, ok := span(lines, group.start, group.end, KindSection, group.key, []string{"components"}); ok {
+					chunks = append(chunks, c)
+				}
+			}
+		default:
+			if rest < 0 {
+				rest = b.start
+			}
+		}
+	}
+	if len(top) > 0 {
+		flush(top[len(top)-1].end)
+	}
+	return chunks
+}
+
+// isOpenAPI reports whether top-level keys are those of a spec.
+func isOpenAPI(top []block) bool {
+	for _, b := range top {
+		if b.key == "openapi" || b.key == "swagger" || b.key == "paths" {
+			return true
+		}
+	}
+	return false
+}
+
+// children returns a chunk per child of a block, named after its key.
+func children(lines []string, parent block, kind Kind, context []string) []Chunk {
+	var chunks []Chunk
+	for _, b := range childBlocks(lines, parent.start+1, parent.end, parent.indent) {
+		if c, ok := span(lines, b.start, b.end, kind, b.key, context); ok {
+			chunks = append(chunks, c)
+		}
+	}
+	return chunks
+}
+
+// block is a key and the lines up to its next sibling.
+type block struct {
+	key        string
+	indent     int
+	start, end int // lines[start:end]
+}
+
+// childBlocks splits lines[start:end] at the keys indented one level deeper
+// than parentIndent: the least indentation of any key deeper than it. Lines
+// before the first key are left out.
+func childBlocks(lines []string, start, end, parentIndent int) []block {
+	indent := -1
+	for i := start; i < end; i++ {
+		if _, n, ok := keyLine(lines[i]); ok && n > parentIndent && (indent < 0 || n < indent) {
+			indent = n
+		}
+	}
+	if indent < 0 {
+		return nil
+	}
+
+	var blocks []block
+	for i := start; i < end; i++ {
+		key, n, ok := keyLine(lines[i])
+		if !ok || n != indent {
+			continue
+		}
+		if len(blocks) > 0 {
+			blocks[len(blocks)-1].end = i
+		}
+		blocks = append(blocks, block{key: key, indent: n, start: i, end: end})
+	}
+	return blocks
+}
+
+// keyLine returns the key a YAML or JSON line opens and its indentation.
+// List items, comments, and closing brackets open no key.
+func keyLine(line string) (string, int, bool) {
+	t := strings.TrimLeft(line, " \t")
+	if t == "" || t[0] == '#' || t[0] == '-' || t[0] == '}' || t[0] == ']' {
+		return "", 0, false
+	}
+	indent := len(line) - len(t)
+
+	if t[0] == '"' || t[0] == '\'' {
+		end := strings.IndexByte(t[1:], t[0])
+		if end < 0 || !strings.HasPrefix(strings.TrimLeft(t[end+2:], " "), ":") {
+			return "", 0, false
+		}
+		return t[1 : end+1], indent, true
+	}
+
+	i := strings.Index(t, ":")
+	if i <= 0 || (i+1 < len(t) && t[i+1] != ' ' && t[i+1] != '\t') {
+		return "", 0, false
+	}
+	return strings.TrimSpace(t[:i]), indent, true
+}
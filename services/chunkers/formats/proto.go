@@ -0,0 +1,146 @@
+package formats
+
+import (
+	"regexp"
+	"strings"
+)
+
+// Proto chunks protobuf definitions at their top-level messages, enums,
+// services, and extensions.
+type Proto struct{}
+
+// NewProto creates a protobuf provider.
+func NewProto() *Proto { return &Proto{} }
+
+// Format returns "proto".
+func (p *Proto) Format() string { return "proto" }
+
+// protoDefinition matches the first line of a top-level definition.
+var protoDefinition = regexp.MustCompile(`^\s*(message|enum|service|extend)\s+([\w.]+)`)
+
+// protoPackage matches the package declaration.
+var protoPackage = regexp.MustCompile(`^\s*package\s+([\w.]+)\s*;`)
+
+// protoKinds map definition keywords to chunk kinds.
+var protoKinds = map[string]Kind{
+	"message": KindMessage,
+	"enum":    KindEnum,
+	"service": KindService,
+	"extend":  KindMessage,
+}
+
+// Chunk splits a file into one chunk per top-level definition, each with
+// the comment above it, nested definitions included. The syntax, package,
+// import, and option statements before the first definition form a module
+// chunk named after the package. Definitions carry the package as context.
+func (p *Proto) Chunk(filename string, content []byte) []Chunk {
+	lines := splitLines(content)
+
+	var chunks []Chunk
+	var pkg string
+	var lex protoLexer
+	header := -1  // end of the header, once the first definition starts
+	comment := -1 // first line of the comment block above the current line
+	def := -1     // first line of the open definition
+	braced := false
+	var kind Kind
+	var name string
+
+	for i, line := range lines {
+		trimmed := strings.TrimSpace(line)
+		if def < 0 {
+			atTop := lex.depth == 0 && !lex.inComment
+			if m := protoPackage.FindStringSubmatch(line); atTop && m != nil {
+				pkg = m[1]
+			}
+			switch m := protoDefinition.FindStringSubmatch(line); {
+			case atTop && m != nil:
+				def, kind, name, braced = i, protoKinds[m[1]], m[2], false
+				if comment >= 0 {
+					def = comment
+				}
+				if header < 0 {
+					header = def
+				}
+			case atTop && (strings.HasPrefix(trimmed, "//") || strings.HasPrefix(trimmed, "/*")):
+				if comment < 0 {
+					comment = i
+				}
+			case lex.inComment:
+				// The block comment above continues
+			default:
+				comment = -1
+			}
+		}
+
+		braced = lex.scan(line) || braced
+		if def >= 0 && braced && lex.depth == 0 {
+			var context []string
+			if pkg != "" {
+				context = []string{"package " + pkg}
+			}
+			if c, ok := span(lines, def, i+1, kind, name, context); ok {
+				chunks = append(chunks, c)
+			}
+			def, comment = -1, -1
+		}
+	}
+	if def >= 0 {
+		// Unterminated definition; keep what there is
+		if c, ok := span(lines, def, len(lines), kind, name, nil); ok {
+			chunks = append(chunks, c)
+		}
+	}
+
+	if header < 0 {
+		header = len(lines)
+	}
+	if c, ok := span(lines, 0, header, KindModule, pkg, nil); ok {
+		chunks = append([]Chunk{c}, chunks...)
+	}
+	return chunks
+}
+
+// protoLexer tracks brace depth across lines, skipping comments and strings.
+type protoLexer struct {
+	depth     int
+	inComment bool // inside a /* */ comment
+}
+
+// scan advances the lexer over one line. It reports whether the line
+// opened a brace.
+func (l *protoLexer) scan(line string) bool {
+	opened := false
+	var quote byte
+	for i := 0; i < len(line); i++ {
+		c := line[i]
+		switch {
+		case l.inComment:
+			if c == '*' && i+1 < len(line) && line[i+1] == '/' {
+				l.inComment = false
+				i++
+			}
+		case quote != 0:
+			if c == '\\' {
+				i++
+			} else if c == quote {
+				quote = 0
+			}
+		case c == '/' && i+1 < len(line) && line[i+1] == '/':
+			return opened
+		case c == '/' && i+1 < len(line) && line[i+1] == '*':
+			l.inComment = true
+			i++
+		case c == '"' || c == '\'':
+			quote = c
+		case c == '{':
+			l.depth++
+			opened = true
+		case c == '}':
+			if l.depth > 0 {
+				l.depth--
+			}
+		}
+	}
+	return opened
+}
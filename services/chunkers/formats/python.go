@@ -0,0 +1,251 @@
+package formats
+
+import (
+	"path"
+	"regexp"
+	"strings"
+)
+
+// Python chunks Python modules at their functions, classes, and methods.
+type Python struct{}
+
+// NewPython creates a Python provider.
+func NewPython() *Python { return &Python{} }
+
+// Format returns "python".
+func (p *Python) Format() string { return "python" }
+
+// pythonDefinition matches the first line of a function or class.
+var pythonDefinition = regexp.MustCompile(`^\s*(?:async\s+)?(def|class)\s+(\w+)`)
+
+// Chunk splits a module into one chunk per top-level function and class,
+// each with its decorators and the comment above it. A class with methods is
+// split further: its header, docstring, and attributes form the class chunk
+// and each method is a chunk with the class as context. Statements between
+// definitions, such as imports and constants, form module chunks named
+// after the file.
+func (p *Python) Chunk(filename string, content []byte) []Chunk {
+	py := newPythonFile(splitLines(content))
+	module := strings.TrimSuffix(path.Base(filename), path.Ext(filename))
+
+	var chunks []Chunk
+	for _, s := range py.segments(0, len(py.lines), 0) {
+		switch s.kind {
+		case "def":
+			if c, ok := span(py.lines, s.start, s.end, KindFunction, s.name, nil); ok {
+				chunks = append(chunks, c)
+			}
+		case "class":
+			chunks = append(chunks, py.class(s, nil)...)
+		default:
+			if c, ok := span(py.lines, s.start, s.end, KindModule, module, nil); ok {
+				chunks = append(chunks, c)
+			}
+		}
+	}
+	return chunks
+}
+
+// pythonFile is a module's lines with the logical structure the chunker
+// needs: which lines start a statement and how far they are indented.
+type pythonFile struct {
+	lines  []string
+	code   []bool // the line starts a statement, outside strings and brackets
+	indent []int
+}
+
+func newPythonFile(lines []string) *pythonFile {
+	f := &pythonFile{
+		lines:  lines,
+		code:   make([]bool, len(lines)),
+		indent: make([]int, len(lines)),
+	}
+	var lex pythonLexer
+	for i, line := range lines {
+		trimmed := strings.TrimLeft(line, " \t")
+		f.code[i] = lex.atStatement() && trimmed != "" && trimmed[0] != '#'
+		f.indent[i] = len(line) - len(trimmed)
+		lex.scan(line)
+	}
+	return f
+}
+
+// pythonSegment is a run of statements at one indentation: a definition,
+// or the other statements between definitions.
+type pythonSegment struct {
+	start, end int    // lines[start:end]
+	kind       string // "def", "class", or empty for other statements
+	name       string
+	line       int // the def or class line
+}
+
+// segments splits lines[from:to] at the definitions indented by indent.
+// Decorators and the comment directly above a definition belong to it;
+// comments after its last statement are left to what follows.
+func (f *pythonFile) segments(from, to, indent int) []pythonSegment {
+	var stmts []int
+	for i := from; i < to; i++ {
+		if f.code[i] && f.indent[i] == indent {
+			stmts = append(stmts, i)
+		}
+	}
+
+	var defs []pythonSegment
+	decorator := -1 // first decorator of the pending definition
+	for k, i := range stmts {
+		if strings.HasPrefix(strings.TrimSpace(f.lines[i]), "@") {
+			if decorator < 0 {
+				decorator = i
+			}
+			continue
+		}
+		m := pythonDefinition.FindStringSubmatch(f.lines[i])
+		if m == nil {
+			decorator = -1
+			continue
+		}
+
+		start := i
+		if decorator >= 0 {
+			start = decorator
+		}
+		decorator = -1
+		for start > from && f.comment(start-1) {
+			start--
+		}
+
+		end := to
+		if k+1 < len(stmts) {
+			end = stmts[k+1]
+		}
+		for end > i+1 && (blank(f.lines[end-1]) || f.comment(end-1)) {
+			end--
+		}
+		defs = append(defs, pythonSegment{start: start, end: end, kind: m[1], name: m[2], line: i})
+	}
+
+	var segments []pythonSegment
+	prev := from
+	for _, d := range defs {
+		if d.start > prev {
+			segments = append(segments, pythonSegment{start: prev, end: d.start})
+		}
+		segments = append(segments, d)
+		prev = d.end
+	}
+	if prev < to {
+		segments = append(segments, pythonSegment{start: prev, end: to})
+	}
+	return segments
+}
+
+// class chunks a class. A class without methods is a single chunk.
+func (f *pythonFile) class(s pythonSegment, context []string) []Chunk {
+	body := -1
+	for i := s.line + 1; i < s.end; i++ {
+		if f.code[i] {
+			body = i
+			break
+		}
+	}
+	if body < 0 || f.indent[body] <= f.indent[s.line] {
+		// A one-line class such as "class Empty: pass"
+		if c, ok := span(f.lines, s.start, s.end, KindClass, s.name, context); ok {
+			return []Chunk{c}
+		}
+		return nil
+	}
+
+	inner := f.segments(s.line+1, s.end, f.indent[body])
+	header := s.end
+	for _, m := range inner {
+		if m.kind != "" {
+			header = m.start
+			break
+		}
+	}
+
+	var chunks []Chunk
+	if c, ok := span(f.lines, s.start, header, KindClass, s.name, context); ok {
+		chunks = append(chunks, c)
+	}
+	members := append(append([]string(nil), context...), s.name)
+	for _, m := range inner {
+		if m.end <= header {
+			continue
+		}
+		switch m.kind {
+		case "def":
+			if c, ok := span(f.lines, m.start, m.end, KindMethod, m.name, members); ok {
+				chunks = append(chunks, c)
+			}
+		case "class":
+			chunks = append(chunks, f.class(m, members)...)
+		default:
+			if c, ok := span(f.lines, m.start, m.end, KindClass, s.name, context); ok {
+				chunks = append(chunks, c)
+			}
+		}
+	}
+	return chunks
+}
+
+// comment reports whether a line is a comment outside any statement.
+func (f *pythonFile) comment(i int) bool {
+	return !f.code[i] && strings.HasPrefix(strings.TrimSpace(f.lines[i]), "#")
+}
+
+// pythonLexer tracks brackets, triple-quoted strings, and backslash
+// continuations across lines, which all continue a statement.
+type pythonLexer struct {
+	depth        int    // open brackets
+	triple       string // closing quotes while inside a triple-quoted string
+	continuation bool   // the last line ended in a backslash
+}
+
+// atStatement reports whether the next line starts a new statement.
+func (l *pythonLexer) atStatement() bool {
+	return l.depth == 0 && l.triple == "" && !l.continuation
+}
+
+// scan advances the lexer over one line.
+func (l *pythonLexer) scan(line string) {
+	l.continuation = false
+	for i := 0; i < len(line); i++ {
+		c := line[i]
+		if l.triple != "" {
+			if c == '\\' {
+				i++
+			} else if strings.HasPrefix(line[i:], l.triple) {
+				i += len(l.triple) - 1
+				l.triple = ""
+			}
+			continue
+		}
+		switch c {
+		case '#':
+			return
+		case '(', '[', '{':
+			l.depth++
+		case ')', ']', '}':
+			if l.depth > 0 {
+				l.depth--
+			}
+		case '"', '\'':
+			if quotes := line[i : i+1]; strings.HasPrefix(line[i:], quotes+quotes+quotes) {
+				l.triple = quotes + quotes + quotes
+				i += 2
+				continue
+			}
+			for i++; i < len(line) && line[i] != c; i++ {
+				if line[i] == '\\' {
+					i++
+				}
+			}
+		case '\\':
+			if i == len(line)-1 {
+				l.continuation = true
+			}
+		}
+	}
+}
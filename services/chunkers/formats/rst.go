@@ -0,0 +1,73 @@
+package formats
+
+import (
+	"slices"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// RST chunks reStructuredText at its section titles.
+type RST struct{}
+
+// NewRST creates a reStructuredText provider.
+func NewRST() *RST { return &RST{} }
+
+// Format returns "rst".
+func (p *RST) Format() string { return "rst" }
+
+// Chunk splits a document into one chunk per section. A title is a line
+// underlined, and optionally overlined, with a run of one punctuation
+// character at least as long as the title. Levels follow the order in
+// which adornment styles first appear, as in docutils.
+func (p *RST) Chunk(filename string, content []byte) []Chunk {
+	lines := splitLines(content)
+
+	var headings []heading
+	var styles []string
+	for i := 0; i+1 < len(lines); i++ {
+		title := lines[i]
+		if blank(title) || adornment(title) != 0 || strings.HasPrefix(title, " ") || strings.HasPrefix(title, "\t") {
+			continue
+		}
+		under := adornment(lines[i+1])
+		if under == 0 || utf8.RuneCountInString(strings.TrimSpace(lines[i+1])) < utf8.RuneCountInString(strings.TrimSpace(title)) {
+			continue
+		}
+
+		start, style := i, string(under)
+		if i > 0 && adornment(lines[i-1]) == under {
+			start, style = i-1, style+"/"+style
+		} else if i > 0 && !blank(lines[i-1]) {
+			continue // titles follow a blank line
+		}
+
+		level := slices.Index(styles, style)
+		if level < 0 {
+			level = len(styles)
+			styles = append(styles, style)
+		}
+		headings = append(headings, heading{line: start, level: level, title: strings.TrimSpace(title)})
+		i++ // skip the underline
+	}
+	return sections(lines, headings)
+}
+
+// adornment returns the character a line of section adornment repeats, or
+// zero when the line is not adornment.
+func adornment(line string) rune {
+	line = strings.TrimRight(line, " \t")
+	if utf8.RuneCountInString(line) < 2 {
+		return 0
+	}
+	first, _ := utf8.DecodeRuneInString(line)
+	if !unicode.IsPunct(first) && !unicode.IsSymbol(first) {
+		return 0
+	}
+	for _, r := range line {
+		if r != first {
+			return 0
+		}
+	}
+	return first
+}
@@ -0,0 +1,254 @@
+package formats
+
+import "strings"
+
+// SQL chunks SQL scripts and migrations by statement.
+type SQL struct{}
+
+// NewSQL creates a SQL provider.
+func NewSQL() *SQL { return &SQL{} }
+
+// Format returns "sql".
+func (p *SQL) Format() string { return "sql" }
+
+// Chunk splits a script into one chunk per statement, each with the
+// comments above it. Semicolons inside strings, quoted identifiers,
+// dollar-quoted bodies, and comments do not end a statement. Goose
+// annotations are honored: statements between StatementBegin and
+// StatementEnd are kept whole, and statements of an Up or Down section
+// carry it as context.
+func (p *SQL) Chunk(filename string, content []byte) []Chunk {
+	lines := splitLines(content)
+
+	var chunks []Chunk
+	var lex sqlLexer
+	var context []string
+	start := -1      // first line of the pending statement
+	hasCode := false // the pending statement holds more than comments
+	grouped := false // inside a goose StatementBegin block
+
+	emit := func(end int) {
+		if start >= 0 && hasCode {
+			if c, ok := span(lines, start, end, KindStatement, "", context); ok {
+				c.Kind, c.Symbol = classifySQL(c.Content)
+				chunks = append(chunks, c)
+			}
+		}
+		start, hasCode = -1, false
+	}
+
+	for i, line := range lines {
+		if !lex.inside() {
+			if annotation, ok := strings.CutPrefix(strings.TrimSpace(line), "-- +goose "); ok {
+				annotation, _, _ = strings.Cut(annotation, " ")
+				switch annotation {
+				case "Up":
+					emit(i)
+					context = []string{"migration up"}
+				case "Down":
+					emit(i)
+					context = []string{"migration down"}
+				case "StatementBegin":
+					emit(i)
+					grouped = true
+				case "StatementEnd":
+					emit(i)
+					grouped = false
+				}
+				continue
+			}
+		}
+
+		for _, ev := range lex.scan(line) {
+			switch ev {
+			case sqlText:
+				if start < 0 {
+					start = i
+				}
+			case sqlCode:
+				if start < 0 {
+					start = i
+				}
+				hasCode = true
+			case sqlEnd:
+				if !grouped {
+					emit(i + 1)
+				}
+			}
+		}
+	}
+	emit(len(lines))
+	return chunks
+}
+
+// sqlEvent is something the lexer saw on a line.
+type sqlEvent int
+
+const (
+	sqlText sqlEvent = iota // a comment
+	sqlCode                 // statement text
+	sqlEnd                  // a semicolon ending a statement
+)
+
+// sqlLexer tracks strings, quoted identifiers, dollar quotes, and block
+// comments across lines.
+type sqlLexer struct {
+	quote   byte   // ' or " while inside a quoted string or identifier
+	dollar  string // closing tag while inside a dollar-quoted body
+	comment bool   // inside a /* */ comment
+}
+
+// inside reports whether the lexer is within a multi-line token.
+func (l *sqlLexer) inside() bool {
+	return l.quote != 0 || l.dollar != "" || l.comment
+}
+
+// scan advances the lexer over one line and returns what it saw, in order.
+func (l *sqlLexer) scan(line string) []sqlEvent {
+	var events []sqlEvent
+	for i := 0; i < len(line); i++ {
+		c := line[i]
+		switch {
+		case l.comment:
+			if strings.HasPrefix(line[i:], "*/") {
+				l.comment = false
+				i++
+			}
+		case l.quote != 0:
+			if c == l.quote {
+				l.quote = 0
+			}
+		case l.dollar != "":
+			if strings.HasPrefix(line[i:], l.dollar) {
+				i += len(l.dollar) - 1
+				l.dollar = ""
+			}
+		case strings.HasPrefix(line[i:], "--"):
+			return append(events, sqlText)
+		case strings.HasPrefix(line[i:], "/*"):
+			events = append(events, sqlText)
+			l.comment = true
+			i++
+		case c == '\'' || c == '"':
+			events = append(events, sqlCode)
+			l.quote = c
+		case c == '$':
+			events = append(events, sqlCode)
+			if tag := dollarTag(line[i:]); tag != "" {
+				l.dollar = tag
+				i += len(tag) - 1
+			}
+		case c == ';':
+			events = append(events, sqlEnd)
+		case c != ' ' && c != '\t':
+			events = append(events, sqlCode)
+		}
+	}
+	return events
+}
+
+// dollarTag returns the $tag$ opening a dollar-quoted string at the start
+// of s, or empty when s does not start with one.
+func dollarTag(s string) string {
+	for i := 1; i < len(s); i++ {
+		c := s[i]
+		if c == '$' {
+			return s[:i+1]
+		}
+		if c != '_' && !('a' <= c && c <= 'z') && !('A' <= c && c <= 'Z') && !('0' <= c && c <= '9' && i > 1) {
+			return ""
+		}
+	}
+	return ""
+}
+
+// sqlObjects map the object a CREATE statement defines to its chunk kind.
+var sqlObjects = map[string]Kind{
+	"TABLE":     KindTable,
+	"VIEW":      KindView,
+	"INDEX":     KindIndex,
+	"FUNCTION":  KindFunction,
+	"PROCEDURE": KindFunction,
+	"TYPE":      KindType,
+}
+
+// sqlModifiers may appear between a statement's verb and its object.
+var sqlModifiers = map[string]bool{
+	"OR": true, "REPLACE": true, "TEMP": true, "TEMPORARY": true, "UNLOGGED": true,
+	"MATERIALIZED": true, "UNIQUE": true, "RECURSIVE": true, "GLOBAL": true, "LOCAL": true,
+}
+
+// sqlQualifiers may appear between a statement's object and its name.
+var sqlQualifiers = map[string]bool{
+	"IF": true, "NOT": true, "EXISTS": true, "ONLY": true, "CONCURRENTLY": true,
+}
+
+// classifySQL returns the kind of a statement and the name of the object it
+// creates, alters, or drops. CREATE statements for tables, views, indexes,
+// functions, and types get their own kinds; every other statement is a
+// plain statement.
+func classifySQL(statement string) (Kind, string) {
+	words := sqlWords(statement, 12)
+	if len(words) < 2 {
+		return KindStatement, ""
+	}
+	verb := strings.ToUpper(words[0])
+	if verb != "CREATE" && verb != "ALTER" && verb != "DROP" {
+		return KindStatement, ""
+	}
+
+	i := 1
+	for i < len(words) && sqlModifiers[strings.ToUpper(words[i])] {
+		i++
+	}
+	if i >= len(words) {
+		return KindStatement, ""
+	}
+	object := strings.ToUpper(words[i])
+	i++
+	for i < len(words) && sqlQualifiers[strings.ToUpper(words[i])] {
+		i++
+	}
+
+	var name string
+	if i < len(words) && strings.ToUpper(words[i]) != "ON" {
+		name = strings.ReplaceAll(words[i], `"`, "")
+	}
+
+	kind, ok := sqlObjects[object]
+	if !ok || verb != "CREATE" {
+		kind = KindStatement
+	}
+	return kind, name
+}
+
+// sqlWords returns up to n leading words of a statement, skipping comments
+// and splitting at whitespace, parentheses, commas, and semicolons.
+func sqlWords(statement string, n int) []string {
+	for {
+		open := strings.Index(statement, "/*")
+		if open < 0 {
+			break
+		}
+		end := strings.Index(statement[open:], "*/")
+		if end < 0 {
+			statement = statement[:open]
+			break
+		}
+		statement = statement[:open] + " " + statement[open+end+2:]
+	}
+
+	var words []string
+	for _, line := range strings.Split(statement, "\n") {
+		if i := strings.Index(line, "--"); i >= 0 {
+			line = line[:i]
+		}
+		words = append(words, strings.FieldsFunc(line, func(r rune) bool {
+			return r == ' ' || r == '\t' || r == '(' || r == ')' || r == ',' || r == ';'
+		})...)
+		if len(words) >= n {
+			return words[:n]
+		}
+	}
+	return words
+}
@@ -0,0 +1,55 @@
+package formats
+
+// textChunkSize is the size in bytes at which paragraphs of plain text stop
+// being merged into one chunk.
+const textChunkSize = 1500
+
+// Text chunks plain text by paragraph.
+type Text struct{}
+
+// NewText creates a plain text provider.
+func NewText() *Text { return &Text{} }
+
+// Format returns "text".
+func (p *Text) Format() string { return "text" }
+
+// Chunk splits a file at blank lines and merges consecutive paragraphs
+// until a chunk reaches textChunkSize. A longer paragraph is a chunk of its
+// own.
+func (p *Text) Chunk(filename string, content []byte) []Chunk {
+	lines := splitLines(content)
+
+	var chunks []Chunk
+	start, size := -1, 0
+	flush := func(end int) {
+		if start < 0 {
+			return
+		}
+		if c, ok := span(lines, start, end, KindParagraph, "", nil); ok {
+			chunks = append(chunks, c)
+		}
+		start, size = -1, 0
+	}
+
+	for i := 0; i < len(lines); i++ {
+		if blank(lines[i]) {
+			continue
+		}
+		// Measure the paragraph starting here
+		end, n := i, 0
+		for end < len(lines) && !blank(lines[end]) {
+			n += len(lines[end]) + 1
+			end++
+		}
+		if start >= 0 && size+n > textChunkSize {
+			flush(i)
+		}
+		if start < 0 {
+			start = i
+		}
+		size += n
+		i = end
+	}
+	flush(len(lines))
+	return chunks
+}
@@ -7,6 +7,7 @@ import (
 	"net"
 
 	"github.com/zoobzio/chisel"
+	"github.com/zoobzio/vicky/chunkers/formats"
 	pb "github.com/zoobzio/vicky/proto/chunker"
 	"google.golang.org/grpc"
 )
@@ -15,25 +16,56 @@ import (
 type Server struct {
 	pb.UnimplementedChunkerServiceServer
 	chunker *chisel.Chunker
+	formats map[string]formats.Provider
 }
 
-// NewServer creates a new chunker gRPC server.
-func NewServer(providers ...chisel.Provider) *Server {
-	return &Server{
-		chunker: chisel.New(providers...),
+// NewServer creates a new chunker gRPC server. Requests for a language one
+// of the format providers serves go to it; the rest go to chisel.
+func NewServer(providers []formats.Provider, languages ...chisel.Provider) *Server {
+	s := &Server{
+		chunker: chisel.New(languages...),
+		formats: make(map[string]formats.Provider, len(providers)),
 	}
+	for _, p := range providers {
+		s.formats[p.Format()] = p
+	}
+	return s
 }
 
 // Chunk handles a chunking request.
 func (s *Server) Chunk(ctx context.Context, req *pb.ChunkRequest) (*pb.ChunkResponse, error) {
 	log.Printf("chunk request: file=%s lang=%s bytes=%d", req.Filename, req.Language, len(req.Content))
 
-	chunks, err := s.chunker.Chunk(ctx, chisel.Language(req.Language), req.Filename, req.Content)
-	if err != nil {
-		log.Printf("chunk error: file=%s err=%v", req.Filename, err)
-		return nil, fmt.Errorf("chunk %s: %w", req.Filename, err)
+	var results []*pb.ChunkResult
+	if p, ok := s.formats[req.Language]; ok {
+		results = formatResults(p.Chunk(req.Filename, req.Content))
+	} else {
+		chunks, err := s.chunker.Chunk(ctx, chisel.Language(req.Language), req.Filename, req.Content)
+		if err != nil {
+			log.Printf("chunk error: file=%s err=%v", req.Filename, err)
+			return nil, fmt.Errorf("chunk %s: %w", req.Filename, err)
+		}
+
+		results = make([]*pb.ChunkResult, len(chunks))
+		for i, ch := range chunks {
+			results[i] = &pb.ChunkResult{
+				Content:   ch.Content,
+				Symbol:    ch.Symbol,
+				Kind:      string(ch.Kind),
+				StartLine: int32(ch.StartLine),
+				EndLine:   int32(ch.EndLine),
+				Context:   ch.Context,
+			}
+		}
 	}
 
+	log.Printf("chunk completed: file=%s chunks=%d", req.Filename, len(results))
+
+	return &pb.ChunkResponse{Chunks: results}, nil
+}
+
+// formatResults converts the chunks of a format provider.
+func formatResults(chunks []formats.Chunk) []*pb.ChunkResult {
 	results := make([]*pb.ChunkResult, len(chunks))
 	for i, ch := range chunks {
 		results[i] = &pb.ChunkResult{
@@ -45,10 +77,7 @@ func (s *Server) Chunk(ctx context.Context, req *pb.ChunkRequest) (*pb.ChunkResp
 			Context:   ch.Context,
 		}
 	}
-
-	log.Printf("chunk completed: file=%s chunks=%d", req.Filename, len(results))
-
-	return &pb.ChunkResponse{Chunks: results}, nil
+	return results
 }
 
 // ListenAndServe starts the gRPC server on the given address.
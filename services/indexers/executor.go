@@ -2,62 +2,264 @@ package indexers
 
 import (
 	"context"
+	"encoding/json"
 	"fmt"
+	"io/fs"
 	"os"
 	"os/exec"
 	"path/filepath"
+	"strconv"
+	"strings"
+
+	pb "github.com/zoobzio/vicky/proto/indexer"
 )
 
-// Executor runs a SCIP indexer CLI and returns the raw index data.
+// Executor runs a SCIP indexer CLI and returns the path of the index it wrote.
+// repoDir holds the fetched repository and projectDir the root to index
+// from, which is repoDir itself or a directory beneath it.
 type Executor interface {
-	Execute(ctx context.Context, workDir string) ([]byte, error)
+	Execute(ctx context.Context, req *pb.IndexRequest, repoDir, projectDir string) (string, error)
 }
 
 // GoExecutor runs scip-go against a Go project.
 type GoExecutor struct{}
 
-// Execute runs scip-go and returns the SCIP index bytes.
-func (e *GoExecutor) Execute(ctx context.Context, workDir string) ([]byte, error) {
-	outputPath := filepath.Join(workDir, "index.scip")
+// Execute runs scip-go and returns the path of the SCIP index.
+func (e *GoExecutor) Execute(ctx context.Context, req *pb.IndexRequest, repoDir, projectDir string) (string, error) {
+	outputPath := filepath.Join(projectDir, "index.scip")
 
-	cmd := exec.CommandContext(ctx, "scip-go",
-		"--project-root", workDir,
-		"--output", outputPath,
-	)
-	cmd.Dir = workDir
+	cmd := e.command(ctx, req.GetGo(), projectDir, outputPath)
 	cmd.Stdout = os.Stdout
 	cmd.Stderr = os.Stderr
 
 	if err := cmd.Run(); err != nil {
-		return nil, fmt.Errorf("scip-go: %w", err)
+		return "", fmt.Errorf("scip-go: %w", err)
 	}
 
-	return os.ReadFile(outputPath)
+	return outputPath, nil
+}
+
+// command builds the scip-go invocation. Build tags reach the package
+// loader through GOFLAGS, as scip-go has no flag of its own for them.
+func (e *GoExecutor) command(ctx context.Context, cfg *pb.GoConfig, projectDir, outputPath string) *exec.Cmd {
+	args := []string{"--project-root", projectDir, "--output", outputPath}
+	if cfg.GetModulePath() != "" {
+		args = append(args, "--module-name", cfg.GetModulePath())
+	}
+
+	cmd := exec.CommandContext(ctx, "scip-go", args...)
+	cmd.Dir = projectDir
+	if tags := cfg.GetBuildTags(); len(tags) > 0 {
+		cmd.Env = append(os.Environ(), "GOFLAGS=-tags="+strings.Join(tags, ","))
+	}
+	return cmd
 }
 
 // TypeScriptExecutor runs scip-typescript against a TypeScript/JavaScript project.
 type TypeScriptExecutor struct{}
 
-// Execute runs scip-typescript and returns the SCIP index bytes.
-func (e *TypeScriptExecutor) Execute(ctx context.Context, workDir string) ([]byte, error) {
-	outputPath := filepath.Join(workDir, "index.scip")
+// typeScriptTests match the test files left out unless a config includes them.
+var typeScriptTests = []string{
+	"*.test.ts", "*.spec.ts", "*.test.tsx", "*.spec.tsx",
+	"*.test.js", "*.spec.js", "*.test.jsx", "*.spec.jsx",
+}
+
+// Execute runs scip-typescript and returns the path of the SCIP index.
+func (e *TypeScriptExecutor) Execute(ctx context.Context, req *pb.IndexRequest, repoDir, projectDir string) (string, error) {
+	outputPath := filepath.Join(projectDir, "index.scip")
+	cfg := req.GetTypescript()
+
+	// Tests go before dependencies are installed, so node_modules is untouched
+	if !cfg.GetIncludeTests() {
+		if err := removeTests(projectDir, typeScriptTests); err != nil {
+			return "", fmt.Errorf("remove tests: %w", err)
+		}
+	}
+
+	pm := detectPackageManager(repoDir, projectDir)
+
+	// Install dependencies for type resolution
+	install := exec.CommandContext(ctx, pm.install[0], pm.install[1:]...)
+	install.Dir = projectDir
+	_ = install.Run() // best-effort; some repos may not need it
+
+	cmd, err := e.command(ctx, cfg, pm, projectDir, outputPath)
+	if err != nil {
+		return "", err
+	}
+	cmd.Stdout = os.Stdout
+	cmd.Stderr = os.Stderr
+
+	if err := cmd.Run(); err != nil {
+		return "", fmt.Errorf("scip-typescript: %w", err)
+	}
+
+	return outputPath, nil
+}
+
+// command builds the scip-typescript invocation. An explicit tsconfig is
+// indexed as given; otherwise one is inferred and workspaces are followed.
+func (e *TypeScriptExecutor) command(ctx context.Context, cfg *pb.TypeScriptConfig, pm packageManager, projectDir, outputPath string) (*exec.Cmd, error) {
+	args := []string{"index", "--output", outputPath}
+	if tsconfig := cfg.GetTsconfigPath(); tsconfig != "" {
+		if !filepath.IsLocal(tsconfig) {
+			return nil, fmt.Errorf("tsconfig %q is outside the project", tsconfig)
+		}
+		if _, err := os.Stat(filepath.Join(projectDir, filepath.FromSlash(tsconfig))); err != nil {
+			return nil, fmt.Errorf("tsconfig: %w", err)
+		}
+		args = append(args, tsconfig)
+	} else {
+		args = append(args, "--infer-tsconfig")
+		if pm.workspaces {
+			args = append(args, "--"+pm.name+"-workspaces")
+		}
+	}
+
+	cmd := exec.CommandContext(ctx, "scip-typescript", args...)
+	cmd.Dir = projectDir
+	return cmd, nil
+}
+
+// packageManager is the tool a JavaScript project installs its
+// dependencies with.
+type packageManager struct {
+	name       string   // npm, pnpm, or yarn
+	install    []string // install command, with lifecycle scripts disabled
+	workspaces bool     // the project is a pnpm or yarn workspace root
+}
+
+// lockfiles name the package manager that writes each lockfile.
+var lockfiles = []struct{ file, name string }{
+	{"pnpm-lock.yaml", "pnpm"},
+	{"yarn.lock", "yarn"},
+	{"package-lock.json", "npm"},
+}
+
+// detectPackageManager picks the project's package manager from the
+// packageManager field of its package.json, then from the nearest lockfile
+// between projectDir and repoDir, falling back to npm.
+func detectPackageManager(repoDir, projectDir string) packageManager {
+	var manifest struct {
+		PackageManager string          `json:"packageManager"`
+		Workspaces     json.RawMessage `json:"workspaces"`
+	}
+	if data, err := os.ReadFile(filepath.Join(projectDir, "package.json")); err == nil {
+		_ = json.Unmarshal(data, &manifest)
+	}
+
+	name, version, _ := strings.Cut(manifest.PackageManager, "@")
+	if name != "npm" && name != "pnpm" && name != "yarn" {
+		name, version = lockfileManager(repoDir, projectDir), ""
+	}
+
+	pm := packageManager{name: name}
+	switch name {
+	case "pnpm":
+		pm.install = []string{"pnpm", "install", "--ignore-scripts"}
+		pm.workspaces = exists(filepath.Join(projectDir, "pnpm-workspace.yaml"))
+	case "yarn":
+		pm.install = []string{"yarn", "install", "--ignore-scripts"}
+		if yarnBerry(version, projectDir) {
+			pm.install = []string{"yarn", "install", "--mode=skip-build"}
+		}
+		pm.workspaces = len(manifest.Workspaces) > 0
+	default:
+		pm.install = []string{"npm", "install", "--ignore-scripts"}
+	}
+	return pm
+}
+
+// lockfileManager names the package manager of the first lockfile found
+// walking up from projectDir to repoDir, or npm when there is none.
+func lockfileManager(repoDir, projectDir string) string {
+	dir := projectDir
+	for {
+		for _, lock := range lockfiles {
+			if exists(filepath.Join(dir, lock.file)) {
+				return lock.name
+			}
+		}
+		if rel, err := filepath.Rel(repoDir, dir); err != nil || rel == "." || !filepath.IsLocal(rel) {
+			return "npm"
+		}
+		dir = filepath.Dir(dir)
+	}
+}
+
+// yarnBerry reports whether a project uses Yarn 2 or later, which replaced
+// --ignore-scripts with --mode=skip-build.
+func yarnBerry(version, projectDir string) bool {
+	if major, _, _ := strings.Cut(version, "."); major != "" {
+		n, err := strconv.Atoi(major)
+		return err == nil && n >= 2
+	}
+	return exists(filepath.Join(projectDir, ".yarnrc.yml"))
+}
+
+// PythonExecutor runs scip-python against a Python project.
+type PythonExecutor struct{}
 
-	// Attempt npm install for dependency resolution
-	npmInstall := exec.CommandContext(ctx, "npm", "install", "--ignore-scripts")
-	npmInstall.Dir = workDir
-	_ = npmInstall.Run() // best-effort; some repos may not need it
+// pythonTests match the test files left out unless a config includes them.
+var pythonTests = []string{"test_*.py", "*_test.py"}
 
-	cmd := exec.CommandContext(ctx, "scip-typescript", "index",
-		"--output", outputPath,
-		"--infer-tsconfig",
-	)
-	cmd.Dir = workDir
+// Execute runs scip-python and returns the path of the SCIP index.
+// Dependencies are not installed, so symbols from third-party packages
+// are left unresolved rather than running arbitrary setup code.
+func (e *PythonExecutor) Execute(ctx context.Context, req *pb.IndexRequest, repoDir, projectDir string) (string, error) {
+	outputPath := filepath.Join(projectDir, "index.scip")
+	cfg := req.GetPython()
+
+	if !cfg.GetIncludeTests() {
+		if err := removeTests(projectDir, pythonTests); err != nil {
+			return "", fmt.Errorf("remove tests: %w", err)
+		}
+	}
+
+	cmd := e.command(ctx, cfg, projectDir, outputPath)
 	cmd.Stdout = os.Stdout
 	cmd.Stderr = os.Stderr
 
 	if err := cmd.Run(); err != nil {
-		return nil, fmt.Errorf("scip-typescript: %w", err)
+		return "", fmt.Errorf("scip-python: %w", err)
+	}
+
+	return outputPath, nil
+}
+
+// command builds the scip-python invocation.
+func (e *PythonExecutor) command(ctx context.Context, cfg *pb.PythonConfig, projectDir, outputPath string) *exec.Cmd {
+	args := []string{"index", ".", "--output", outputPath}
+	if cfg.GetProjectName() != "" {
+		args = append(args, "--project-name", cfg.GetProjectName())
 	}
+	if cfg.GetProjectVersion() != "" {
+		args = append(args, "--project-version", cfg.GetProjectVersion())
+	}
+
+	cmd := exec.CommandContext(ctx, "scip-python", args...)
+	cmd.Dir = projectDir
+	return cmd
+}
+
+// removeTests deletes the files under dir whose names match any of
+// patterns, so the indexer never sees them.
+func removeTests(dir string, patterns []string) error {
+	return filepath.WalkDir(dir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil || d.IsDir() {
+			return err
+		}
+		for _, pattern := range patterns {
+			if ok, _ := filepath.Match(pattern, d.Name()); ok {
+				return os.Remove(path)
+			}
+		}
+		return nil
+	})
+}
 
-	return os.ReadFile(outputPath)
+// exists reports whether path names an existing file or directory.
+func exists(path string) bool {
+	_, err := os.Stat(path)
+	return err == nil
 }
@@ -0,0 +1,148 @@
+package indexers
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+
+	pb "github.com/zoobzio/vicky/proto/indexer"
+)
+
+// writeFiles creates files under dir.
+func writeFiles(t *testing.T, dir string, files map[string]string) {
+	t.Helper()
+	for path, content := range files {
+		full := filepath.Join(dir, path)
+		if err := os.MkdirAll(filepath.Dir(full), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(full, []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+func TestGoExecutor_Command(t *testing.T) {
+	e := &GoExecutor{}
+
+	cmd := e.command(context.Background(), nil, "/src", "/src/index.scip")
+	if got := strings.Join(cmd.Args[1:], " "); got != "--project-root /src --output /src/index.scip" {
+		t.Errorf("args = %q, want the bare invocation", got)
+	}
+	if cmd.Env != nil {
+		t.Errorf("env = %v, want the inherited environment", cmd.Env)
+	}
+
+	cmd = e.command(context.Background(), &pb.GoConfig{
+		ModulePath: "example.com/app",
+		BuildTags:  []string{"integration", "linux"},
+	}, "/src", "/src/index.scip")
+	if !slices.Contains(cmd.Args, "--module-name") || cmd.Args[len(cmd.Args)-1] != "example.com/app" {
+		t.Errorf("args = %v, want --module-name example.com/app", cmd.Args)
+	}
+	if !slices.Contains(cmd.Env, "GOFLAGS=-tags=integration,linux") {
+		t.Error("build tags not passed through GOFLAGS")
+	}
+}
+
+func TestTypeScriptExecutor_Command(t *testing.T) {
+	e := &TypeScriptExecutor{}
+	dir := t.TempDir()
+	writeFiles(t, dir, map[string]string{"tsconfig.build.json": "{}"})
+
+	cmd, err := e.command(context.Background(), nil, packageManager{name: "pnpm", workspaces: true}, dir, "out")
+	if err != nil {
+		t.Fatalf("command: %v", err)
+	}
+	if got := strings.Join(cmd.Args[1:], " "); got != "index --output out --infer-tsconfig --pnpm-workspaces" {
+		t.Errorf("args = %q, want an inferred tsconfig across workspaces", got)
+	}
+
+	cmd, err = e.command(context.Background(), &pb.TypeScriptConfig{TsconfigPath: "tsconfig.build.json"}, packageManager{name: "yarn", workspaces: true}, dir, "out")
+	if err != nil {
+		t.Fatalf("command: %v", err)
+	}
+	if got := strings.Join(cmd.Args[1:], " "); got != "index --output out tsconfig.build.json" {
+		t.Errorf("args = %q, want the explicit tsconfig", got)
+	}
+
+	for _, path := range []string{"../tsconfig.json", "/etc/tsconfig.json", "missing.json"} {
+		if _, err := e.command(context.Background(), &pb.TypeScriptConfig{TsconfigPath: path}, packageManager{name: "npm"}, dir, "out"); err == nil {
+			t.Errorf("tsconfig %q accepted", path)
+		}
+	}
+}
+
+func TestDetectPackageManager(t *testing.T) {
+	tests := []struct {
+		name    string
+		files   map[string]string
+		project string
+		want    string
+		install string
+		spaces  bool
+	}{
+		{"default", nil, "", "npm", "npm install --ignore-scripts", false},
+		{"field", map[string]string{"package.json": `{"packageManager": "pnpm@9.1.0"}`, "yarn.lock": ""}, "", "pnpm", "pnpm install --ignore-scripts", false},
+		{"pnpm workspace", map[string]string{"pnpm-lock.yaml": "", "pnpm-workspace.yaml": ""}, "", "pnpm", "pnpm install --ignore-scripts", true},
+		{"yarn classic", map[string]string{"package.json": `{"workspaces": ["packages/*"]}`, "yarn.lock": ""}, "", "yarn", "yarn install --ignore-scripts", true},
+		{"yarn berry", map[string]string{"package.json": `{"packageManager": "yarn@4.0.2"}`}, "", "yarn", "yarn install --mode=skip-build", false},
+		{"lockfile above root", map[string]string{"yarn.lock": "", "web/package.json": "{}"}, "web", "yarn", "yarn install --ignore-scripts", false},
+		{"unknown field", map[string]string{"package.json": `{"packageManager": "bun@1.0.0"}`, "package-lock.json": ""}, "", "npm", "npm install --ignore-scripts", false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := t.TempDir()
+			writeFiles(t, dir, tt.files)
+
+			pm := detectPackageManager(dir, filepath.Join(dir, tt.project))
+			if pm.name != tt.want {
+				t.Errorf("name = %q, want %q", pm.name, tt.want)
+			}
+			if got := strings.Join(pm.install, " "); got != tt.install {
+				t.Errorf("install = %q, want %q", got, tt.install)
+			}
+			if pm.workspaces != tt.spaces {
+				t.Errorf("workspaces = %v, want %v", pm.workspaces, tt.spaces)
+			}
+		})
+	}
+}
+
+func TestPythonExecutor_Command(t *testing.T) {
+	cmd := (&PythonExecutor{}).command(context.Background(), &pb.PythonConfig{
+		ProjectName:    "app",
+		ProjectVersion: "1.2.0",
+	}, "/src", "out")
+	if got := strings.Join(cmd.Args[1:], " "); got != "index . --output out --project-name app --project-version 1.2.0" {
+		t.Errorf("args = %q", got)
+	}
+}
+
+func TestRemoveTests(t *testing.T) {
+	dir := t.TempDir()
+	writeFiles(t, dir, map[string]string{
+		"src/app.ts":           "",
+		"src/app.test.ts":      "",
+		"src/ui/view.spec.tsx": "",
+		"src/contest.ts":       "",
+	})
+
+	if err := removeTests(dir, typeScriptTests); err != nil {
+		t.Fatalf("removeTests: %v", err)
+	}
+
+	for path, want := range map[string]bool{
+		"src/app.ts":           true,
+		"src/contest.ts":       true,
+		"src/app.test.ts":      false,
+		"src/ui/view.spec.tsx": false,
+	} {
+		if got := exists(filepath.Join(dir, path)); got != want {
+			t.Errorf("%s exists = %v, want %v", path, got, want)
+		}
+	}
+}
@@ -3,24 +3,31 @@ package indexers
 import (
 	"context"
 	"fmt"
+	"io"
 	"log"
 	"net"
 	"os"
+	"path/filepath"
 
 	pb "github.com/zoobzio/vicky/proto/indexer"
 	"google.golang.org/grpc"
 )
 
+// BlobFetcher writes a version's source blobs into a directory.
+type BlobFetcher interface {
+	FetchBlobs(ctx context.Context, userID int64, owner, repo, tag, destDir string) (int, error)
+}
+
 // Server implements the IndexerService gRPC server.
 type Server struct {
 	pb.UnimplementedIndexerServiceServer
-	storage  *Storage
+	storage  BlobFetcher
 	executor Executor
 	language string
 }
 
 // NewServer creates a new indexer gRPC server.
-func NewServer(storage *Storage, executor Executor, language string) *Server {
+func NewServer(storage BlobFetcher, executor Executor, language string) *Server {
 	return &Server{
 		storage:  storage,
 		executor: executor,
@@ -28,52 +35,100 @@ func NewServer(storage *Storage, executor Executor, language string) *Server {
 	}
 }
 
-// Index handles an indexing request.
-func (s *Server) Index(ctx context.Context, req *pb.IndexRequest) (*pb.IndexResult, error) {
-	log.Printf("index request: job=%d owner=%s repo=%s tag=%s lang=%s",
-		req.JobId, req.Owner, req.RepoName, req.Tag, req.Language)
+// indexChunkSize is the amount of index data sent per stream message,
+// well under gRPC's default 4MB message limit.
+const indexChunkSize = 1 << 20
+
+// Index handles an indexing request, streaming the SCIP index back in chunks.
+// Failures are reported in-band as a single chunk carrying the error.
+func (s *Server) Index(req *pb.IndexRequest, stream pb.IndexerService_IndexServer) error {
+	ctx := stream.Context()
+
+	log.Printf("index request: job=%d owner=%s repo=%s tag=%s lang=%s root=%q",
+		req.JobId, req.Owner, req.RepoName, req.Tag, req.Language, req.Root)
 
-	result := &pb.IndexResult{
-		JobId:     req.JobId,
-		VersionId: req.VersionId,
+	fail := func(format string, err error) error {
+		log.Printf("index error: job=%d err=%v", req.JobId, err)
+		return stream.Send(&pb.IndexChunk{
+			JobId:     req.JobId,
+			VersionId: req.VersionId,
+			Error:     fmt.Sprintf(format, err),
+		})
 	}
 
 	// Create temp work directory
 	workDir, err := os.MkdirTemp("", fmt.Sprintf("indexer-%d-*", req.JobId))
 	if err != nil {
-		log.Printf("index error: job=%d err=%v", req.JobId, err)
-		result.Error = fmt.Sprintf("create work dir: %v", err)
-		return result, nil
+		return fail("create work dir: %v", err)
 	}
 	defer os.RemoveAll(workDir)
 
 	// Fetch blobs from minio
 	count, err := s.storage.FetchBlobs(ctx, req.UserId, req.Owner, req.RepoName, req.Tag, workDir)
 	if err != nil {
-		log.Printf("index error: job=%d err=%v", req.JobId, err)
-		result.Error = fmt.Sprintf("fetch blobs: %v", err)
-		return result, nil
+		return fail("fetch blobs: %v", err)
 	}
 
 	log.Printf("index fetched: job=%d count=%d workdir=%s", req.JobId, count, workDir)
 
 	if count == 0 {
-		result.Error = "no files found in blob storage"
-		return result, nil
+		return stream.Send(&pb.IndexChunk{
+			JobId:     req.JobId,
+			VersionId: req.VersionId,
+			Error:     "no files found in blob storage",
+		})
+	}
+
+	// Index from the project root; document paths are relative to it
+	projectDir := workDir
+	if req.Root != "" {
+		if !filepath.IsLocal(req.Root) {
+			return fail("project root: %v", fmt.Errorf("%q is outside the repository", req.Root))
+		}
+		projectDir = filepath.Join(workDir, filepath.FromSlash(req.Root))
+		if _, err := os.Stat(projectDir); err != nil {
+			return fail("project root: %v", err)
+		}
 	}
 
 	// Run SCIP indexer
-	indexData, err := s.executor.Execute(ctx, workDir)
+	indexPath, err := s.executor.Execute(ctx, req, workDir, projectDir)
 	if err != nil {
-		log.Printf("index error: job=%d err=%v", req.JobId, err)
-		result.Error = fmt.Sprintf("execute indexer: %v", err)
-		return result, nil
+		return fail("execute indexer: %v", err)
+	}
+
+	f, err := os.Open(indexPath)
+	if err != nil {
+		return fail("open index: %v", err)
+	}
+	defer f.Close()
+
+	// Stream the index from disk so it is never held in memory whole
+	var sent int64
+	buf := make([]byte, indexChunkSize)
+	for {
+		n, err := f.Read(buf)
+		if n > 0 {
+			if err := stream.Send(&pb.IndexChunk{
+				JobId:     req.JobId,
+				VersionId: req.VersionId,
+				Data:      buf[:n],
+			}); err != nil {
+				return fmt.Errorf("send index chunk: %w", err)
+			}
+			sent += int64(n)
+		}
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return fmt.Errorf("read index: %w", err)
+		}
 	}
 
-	log.Printf("index completed: job=%d bytes=%d", req.JobId, len(indexData))
+	log.Printf("index completed: job=%d bytes=%d", req.JobId, sent)
 
-	result.IndexData = indexData
-	return result, nil
+	return nil
 }
 
 // ListenAndServe starts the gRPC server on the given address.
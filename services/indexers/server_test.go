@@ -0,0 +1,172 @@
+package indexers
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	pb "github.com/zoobzio/vicky/proto/indexer"
+	"google.golang.org/grpc"
+)
+
+// fakeBlobs writes fixed files into the work directory.
+type fakeBlobs struct {
+	files map[string]string
+}
+
+func (f *fakeBlobs) FetchBlobs(_ context.Context, _ int64, _, _, _, destDir string) (int, error) {
+	for path, content := range f.files {
+		full := filepath.Join(destDir, path)
+		if err := os.MkdirAll(filepath.Dir(full), 0o755); err != nil {
+			return 0, err
+		}
+		if err := os.WriteFile(full, []byte(content), 0o644); err != nil {
+			return 0, err
+		}
+	}
+	return len(f.files), nil
+}
+
+// fakeExecutor stands in for an indexer CLI, writing a fixed index.
+type fakeExecutor struct {
+	index []byte
+	err   error
+	seen  []string
+}
+
+func (f *fakeExecutor) Execute(_ context.Context, _ *pb.IndexRequest, _, workDir string) (string, error) {
+	entries, err := os.ReadDir(workDir)
+	if err != nil {
+		return "", err
+	}
+	for _, e := range entries {
+		f.seen = append(f.seen, e.Name())
+	}
+	if f.err != nil {
+		return "", f.err
+	}
+	path := filepath.Join(workDir, "index.scip")
+	return path, os.WriteFile(path, f.index, 0o644)
+}
+
+// captureStream collects the chunks a server sends.
+type captureStream struct {
+	grpc.ServerStream
+	chunks []*pb.IndexChunk
+}
+
+func (s *captureStream) Context() context.Context { return context.Background() }
+
+func (s *captureStream) Send(chunk *pb.IndexChunk) error {
+	s.chunks = append(s.chunks, chunk)
+	return nil
+}
+
+func TestServerIndex_Python(t *testing.T) {
+	index := bytes.Repeat([]byte{0x0a}, indexChunkSize+10)
+	exec := &fakeExecutor{index: index}
+	blobs := &fakeBlobs{files: map[string]string{"app/main.py": "print('hi')\n"}}
+	srv := NewServer(blobs, exec, "python")
+
+	stream := &captureStream{}
+	if err := srv.Index(&pb.IndexRequest{JobId: 1, VersionId: 2, Language: "python"}, stream); err != nil {
+		t.Fatalf("Index: %v", err)
+	}
+
+	if len(exec.seen) != 1 || exec.seen[0] != "app" {
+		t.Errorf("executor saw %v, want the fetched sources", exec.seen)
+	}
+	if len(stream.chunks) != 2 {
+		t.Fatalf("sent %d chunks, want 2", len(stream.chunks))
+	}
+	var got []byte
+	for _, c := range stream.chunks {
+		if c.Error != "" {
+			t.Fatalf("unexpected error chunk: %s", c.Error)
+		}
+		if c.JobId != 1 || c.VersionId != 2 {
+			t.Errorf("chunk ids = %d/%d, want 1/2", c.JobId, c.VersionId)
+		}
+		got = append(got, c.Data...)
+	}
+	if !bytes.Equal(got, index) {
+		t.Errorf("streamed %d bytes, want the %d-byte index", len(got), len(index))
+	}
+}
+
+func TestServerIndex_ExecutorError(t *testing.T) {
+	exec := &fakeExecutor{err: errors.New("scip-python: exit status 1")}
+	blobs := &fakeBlobs{files: map[string]string{"main.py": ""}}
+	srv := NewServer(blobs, exec, "python")
+
+	stream := &captureStream{}
+	if err := srv.Index(&pb.IndexRequest{JobId: 1}, stream); err != nil {
+		t.Fatalf("Index: %v", err)
+	}
+
+	if len(stream.chunks) != 1 || !strings.Contains(stream.chunks[0].Error, "scip-python") {
+		t.Errorf("chunks = %v, want one error chunk", stream.chunks)
+	}
+}
+
+func TestServerIndex_NoFiles(t *testing.T) {
+	exec := &fakeExecutor{}
+	srv := NewServer(&fakeBlobs{}, exec, "python")
+
+	stream := &captureStream{}
+	if err := srv.Index(&pb.IndexRequest{JobId: 1}, stream); err != nil {
+		t.Fatalf("Index: %v", err)
+	}
+
+	if exec.seen != nil {
+		t.Error("executor ran without sources")
+	}
+	if len(stream.chunks) != 1 || stream.chunks[0].Error == "" {
+		t.Errorf("chunks = %v, want one error chunk", stream.chunks)
+	}
+}
+
+func TestServerIndex_Root(t *testing.T) {
+	exec := &fakeExecutor{index: []byte{0x0a, 0x00}}
+	blobs := &fakeBlobs{files: map[string]string{
+		"go.mod":           "module example.com/api\n",
+		"web/package.json": "{}",
+		"web/src/app.ts":   "export {}\n",
+	}}
+	srv := NewServer(blobs, exec, "typescript")
+
+	stream := &captureStream{}
+	if err := srv.Index(&pb.IndexRequest{JobId: 1, Language: "typescript", Root: "web"}, stream); err != nil {
+		t.Fatalf("Index: %v", err)
+	}
+
+	if len(stream.chunks) != 1 || stream.chunks[0].Error != "" {
+		t.Fatalf("chunks = %v, want the index", stream.chunks)
+	}
+	if strings.Join(exec.seen, ",") != "package.json,src" {
+		t.Errorf("executor saw %v, want the project root's contents", exec.seen)
+	}
+}
+
+func TestServerIndex_RootOutsideRepository(t *testing.T) {
+	exec := &fakeExecutor{}
+	blobs := &fakeBlobs{files: map[string]string{"main.go": ""}}
+	srv := NewServer(blobs, exec, "go")
+
+	for _, root := range []string{"../other", "/etc", "missing"} {
+		stream := &captureStream{}
+		if err := srv.Index(&pb.IndexRequest{JobId: 1, Root: root}, stream); err != nil {
+			t.Fatalf("Index(%q): %v", root, err)
+		}
+		if len(stream.chunks) != 1 || !strings.Contains(stream.chunks[0].Error, "project root") {
+			t.Errorf("root %q: chunks = %v, want one project root error", root, stream.chunks)
+		}
+	}
+	if exec.seen != nil {
+		t.Error("executor ran outside the project root")
+	}
+}
@@ -3,6 +3,7 @@ package stores
 import (
 	"context"
 	"fmt"
+	"strings"
 
 	"github.com/zoobzio/grub"
 	"github.com/zoobzio/vicky/models"
@@ -37,11 +38,39 @@ func (s *Blobs) DeleteByPath(ctx context.Context, userID int64, owner, repo, tag
 	return s.bucket.Delete(ctx, blobKey(userID, owner, repo, tag, path))
 }
 
+// DeleteByVersion removes every blob in a version.
+func (s *Blobs) DeleteByVersion(ctx context.Context, userID int64, owner, repo, tag string) error {
+	infos, err := s.bucket.List(ctx, versionPrefix(userID, owner, repo, tag), 0)
+	if err != nil {
+		return err
+	}
+	for _, info := range infos {
+		if err := s.bucket.Delete(ctx, info.Key); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
 // ListByVersion returns object info for all blobs in a version.
 func (s *Blobs) ListByVersion(ctx context.Context, userID int64, owner, repo, tag string, limit int) ([]grub.ObjectInfo, error) {
 	return s.bucket.List(ctx, versionPrefix(userID, owner, repo, tag), limit)
 }
 
+// ListPathsByVersion returns the path of every blob in a version.
+func (s *Blobs) ListPathsByVersion(ctx context.Context, userID int64, owner, repo, tag string) ([]string, error) {
+	prefix := versionPrefix(userID, owner, repo, tag)
+	infos, err := s.bucket.List(ctx, prefix, 0)
+	if err != nil {
+		return nil, err
+	}
+	paths := make([]string, 0, len(infos))
+	for _, info := range infos {
+		paths = append(paths, strings.TrimPrefix(info.Key, prefix))
+	}
+	return paths, nil
+}
+
 // ListByRepo returns object info for all blobs in a repository.
 func (s *Blobs) ListByRepo(ctx context.Context, userID int64, owner, repo string, limit int) ([]grub.ObjectInfo, error) {
 	return s.bucket.List(ctx, repoPrefix(userID, owner, repo), limit)
@@ -0,0 +1,106 @@
+package stores
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
+)
+
+// copyRows streams rows into table with COPY inside tx. Each row holds one
+// value per column; nil values are written as NULL.
+func copyRows(ctx context.Context, tx *sqlx.Tx, table string, columns []string, rows [][]any) error {
+	if len(rows) == 0 {
+		return nil
+	}
+	stmt, err := tx.PrepareContext(ctx, pq.CopyIn(table, columns...))
+	if err != nil {
+		return fmt.Errorf("copy %s: %w", table, err)
+	}
+	for _, row := range rows {
+		if _, err := stmt.ExecContext(ctx, row...); err != nil {
+			_ = stmt.Close()
+			return fmt.Errorf("copy %s: %w", table, err)
+		}
+	}
+	if _, err := stmt.ExecContext(ctx); err != nil {
+		_ = stmt.Close()
+		return fmt.Errorf("copy %s: %w", table, err)
+	}
+	return stmt.Close()
+}
+
+// reserveIDs draws n ids from table's id sequence, so rows can be copied
+// with their primary keys known up front.
+func reserveIDs(ctx context.Context, tx *sqlx.Tx, table string, n int) ([]int64, error) {
+	ids := make([]int64, 0, n)
+	if n == 0 {
+		return ids, nil
+	}
+	err := tx.SelectContext(ctx, &ids,
+		`SELECT nextval(pg_get_serial_sequence($1, 'id')) FROM generate_series(1, $2::int)`,
+		table, n,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("reserve %s ids: %w", table, err)
+	}
+	return ids, nil
+}
+
+// vectorText renders a vector for COPY, or nil for no vector.
+func vectorText(v []float32) any {
+	if len(v) == 0 {
+		return nil
+	}
+	return vectorLiteral(v)
+}
+
+// vectorLiteral renders a vector in pgvector's text form.
+func vectorLiteral(v []float32) string {
+	var b strings.Builder
+	b.WriteByte('[')
+	for i, f := range v {
+		if i > 0 {
+			b.WriteByte(',')
+		}
+		b.WriteString(strconv.FormatFloat(float64(f), 'f', -1, 32))
+	}
+	b.WriteByte(']')
+	return b.String()
+}
+
+// jsonText passes JSON to COPY as text; raw bytes would be sent as bytea.
+func jsonText(data []byte) any {
+	if data == nil {
+		return nil
+	}
+	return string(data)
+}
+
+// textArray passes a string slice as a Postgres array, or nil for none.
+func textArray(s []string) any {
+	if s == nil {
+		return nil
+	}
+	return pq.StringArray(s)
+}
+
+// vectorUpdate bulk-writes vectors to table's rows by id with one statement.
+func vectorUpdate(ctx context.Context, db *sqlx.DB, table string, ids []int64, vectors []string) error {
+	if len(ids) == 0 {
+		return nil
+	}
+	_, err := db.ExecContext(ctx,
+		`UPDATE `+table+` AS t SET vector = v.vector::vector
+		FROM unnest($1::bigint[], $2::text[]) AS v(id, vector)
+		WHERE t.id = v.id`,
+		pq.Int64Array(ids), pq.StringArray(vectors),
+	)
+	if err != nil {
+		return fmt.Errorf("update %s vectors: %w", table, err)
+	}
+	return nil
+}
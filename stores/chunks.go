@@ -3,6 +3,7 @@ package stores
 
 import (
 	"context"
+	"fmt"
 
 	"github.com/jmoiron/sqlx"
 	"github.com/zoobzio/astql"
@@ -13,6 +14,7 @@ import (
 // Chunks provides database and vector access for chunk records.
 type Chunks struct {
 	*sum.Database[models.Chunk]
+	db *sqlx.DB
 }
 
 // NewChunks creates a new chunks store.
@@ -21,7 +23,7 @@ func NewChunks(db *sqlx.DB, renderer astql.Renderer) (*Chunks, error) {
 	if err != nil {
 		return nil, err
 	}
-	return &Chunks{Database: database}, nil
+	return &Chunks{Database: database, db: db}, nil
 }
 
 // ListByUserRepoAndTag retrieves all chunks for a version.
@@ -82,3 +84,54 @@ func (s *Chunks) SearchByKind(ctx context.Context, userID int64, owner, repoName
 			"query_vec": vector,
 		})
 }
+
+// DeleteByDocument removes all chunks of a document.
+func (s *Chunks) DeleteByDocument(ctx context.Context, documentID int64) error {
+	_, err := s.Remove().
+		Where("document_id", "=", "document_id").
+		Exec(ctx, map[string]any{"document_id": documentID})
+	return err
+}
+
+// chunkColumns are the columns written when chunks are copied in bulk.
+var chunkColumns = []string{
+	"document_id", "user_id", "owner", "repo_name", "tag", "path", "kind",
+	"start_line", "end_line", "symbol", "context", "content", "vector",
+}
+
+// ReplaceByDocument swaps a document's chunks for the given ones in a single
+// transaction, copying them in bulk. Chunk IDs are not populated.
+func (s *Chunks) ReplaceByDocument(ctx context.Context, documentID int64, chunks []*models.Chunk) error {
+	tx, err := s.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	if _, err := tx.ExecContext(ctx, `DELETE FROM chunks WHERE document_id = $1`, documentID); err != nil {
+		return fmt.Errorf("clear chunks: %w", err)
+	}
+
+	rows := make([][]any, len(chunks))
+	for i, c := range chunks {
+		rows[i] = []any{
+			documentID, c.UserID, c.Owner, c.RepoName, c.Tag, c.Path, string(c.Kind),
+			c.StartLine, c.EndLine, c.Symbol, textArray(c.Context), c.Content, vectorText(c.Vector),
+		}
+	}
+	if err := copyRows(ctx, tx, "chunks", chunkColumns, rows); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+// UpdateVectors writes the vectors of the given chunks with one statement.
+func (s *Chunks) UpdateVectors(ctx context.Context, chunks []*models.Chunk) error {
+	ids := make([]int64, len(chunks))
+	vectors := make([]string, len(chunks))
+	for i, c := range chunks {
+		ids[i] = c.ID
+		vectors[i] = vectorLiteral(c.Vector)
+	}
+	return vectorUpdate(ctx, s.db, "chunks", ids, vectors)
+}
@@ -74,3 +74,12 @@ func (s *Documents) FindSimilarInVersion(ctx context.Context, userID int64, owne
 			"query_vec": vector,
 		})
 }
+
+// DeleteByVersion removes every document of a version.
+// Chunks and SCIP rows cascade with their documents.
+func (s *Documents) DeleteByVersion(ctx context.Context, versionID int64) error {
+	_, err := s.Remove().
+		Where("version_id", "=", "version_id").
+		Exec(ctx, map[string]any{"version_id": versionID})
+	return err
+}
@@ -0,0 +1,65 @@
+package stores
+
+import (
+	"context"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
+	"github.com/zoobzio/astql"
+	"github.com/zoobzio/sum"
+	"github.com/zoobzio/vicky/models"
+)
+
+// EmbeddingCache provides database access for cached embeddings.
+type EmbeddingCache struct {
+	*sum.Database[models.EmbeddingCacheEntry]
+	db *sqlx.DB
+}
+
+// NewEmbeddingCache creates a new embedding cache store.
+func NewEmbeddingCache(db *sqlx.DB, renderer astql.Renderer) (*EmbeddingCache, error) {
+	database, err := sum.NewDatabase[models.EmbeddingCacheEntry](db, "embedding_cache", renderer)
+	if err != nil {
+		return nil, err
+	}
+	return &EmbeddingCache{Database: database, db: db}, nil
+}
+
+// GetMany retrieves the cached vectors for the given keys, keyed by cache
+// key. Keys without an entry are absent from the result.
+func (s *EmbeddingCache) GetMany(ctx context.Context, keys []string) (map[string][]float32, error) {
+	if len(keys) == 0 {
+		return map[string][]float32{}, nil
+	}
+	entries, err := s.Query().
+		Where("key", "IN", "keys").
+		Exec(ctx, map[string]any{"keys": pq.StringArray(keys)})
+	if err != nil {
+		return nil, err
+	}
+	vectors := make(map[string][]float32, len(entries))
+	for _, e := range entries {
+		vectors[e.Key] = e.Vector
+	}
+	return vectors, nil
+}
+
+// PutMany stores entries in a single transaction. An existing entry for the
+// same key is overwritten; it holds the same embedding.
+func (s *EmbeddingCache) PutMany(ctx context.Context, entries []*models.EmbeddingCacheEntry) error {
+	if len(entries) == 0 {
+		return nil
+	}
+	tx, err := s.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	for _, e := range entries {
+		if err := s.SetTx(ctx, tx, e.Key, e); err != nil {
+			return err
+		}
+	}
+	return tx.Commit()
+}
@@ -0,0 +1,62 @@
+package stores
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/zoobzio/astql"
+	"github.com/zoobzio/sum"
+	"github.com/zoobzio/vicky/models"
+)
+
+// FileReports provides database access for per-file ingestion report entries.
+type FileReports struct {
+	*sum.Database[models.FileReport]
+	db *sqlx.DB
+}
+
+// NewFileReports creates a new file reports store.
+func NewFileReports(db *sqlx.DB, renderer astql.Renderer) (*FileReports, error) {
+	database, err := sum.NewDatabase[models.FileReport](db, "file_reports", renderer)
+	if err != nil {
+		return nil, err
+	}
+	return &FileReports{Database: database, db: db}, nil
+}
+
+// ListByJob retrieves every report entry for a job, ordered by path.
+func (s *FileReports) ListByJob(ctx context.Context, jobID int64) ([]*models.FileReport, error) {
+	return s.Query().
+		Where("job_id", "=", "job_id").
+		OrderBy("path", "ASC").
+		Exec(ctx, map[string]any{"job_id": jobID})
+}
+
+// fileReportColumns are the columns written when report entries are copied in bulk.
+var fileReportColumns = []string{
+	"job_id", "user_id", "stage", "path", "outcome", "rule", "reason", "size",
+}
+
+// ReplaceByJobAndStage swaps a stage's report entries for the given ones in a
+// single transaction, copying them in bulk. Entry IDs are not populated.
+func (s *FileReports) ReplaceByJobAndStage(ctx context.Context, jobID int64, stage models.JobStage, reports []*models.FileReport) error {
+	tx, err := s.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	if _, err := tx.ExecContext(ctx, `DELETE FROM file_reports WHERE job_id = $1 AND stage = $2`, jobID, string(stage)); err != nil {
+		return fmt.Errorf("clear file reports: %w", err)
+	}
+
+	rows := make([][]any, len(reports))
+	for i, r := range reports {
+		rows[i] = []any{jobID, r.UserID, string(stage), r.Path, string(r.Outcome), r.Rule, r.Reason, r.Size}
+	}
+	if err := copyRows(ctx, tx, "file_reports", fileReportColumns, rows); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
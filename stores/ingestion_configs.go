@@ -23,9 +23,10 @@ func NewIngestionConfigs(db *sqlx.DB, renderer astql.Renderer) (*IngestionConfig
 	return &IngestionConfigs{Database: database}, nil
 }
 
-// GetByRepositoryID retrieves the config for a repository.
-func (s *IngestionConfigs) GetByRepositoryID(ctx context.Context, repositoryID int64) (*models.IngestionConfig, error) {
-	return s.Select().
+// ListByRepositoryID retrieves a repository's configs, one per language root.
+func (s *IngestionConfigs) ListByRepositoryID(ctx context.Context, repositoryID int64) ([]*models.IngestionConfig, error) {
+	return s.Query().
 		Where("repository_id", "=", "repository_id").
+		OrderBy("root", "ASC").
 		Exec(ctx, map[string]any{"repository_id": repositoryID})
 }
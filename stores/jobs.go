@@ -2,6 +2,8 @@ package stores
 
 import (
 	"context"
+	"database/sql"
+	"errors"
 	"fmt"
 	"time"
 
@@ -14,6 +16,7 @@ import (
 // Jobs provides database access for job records.
 type Jobs struct {
 	*sum.Database[models.Job]
+	db *sqlx.DB
 }
 
 // JobFilter defines filtering options for job queries.
@@ -32,7 +35,7 @@ func NewJobs(db *sqlx.DB, renderer astql.Renderer) (*Jobs, error) {
 	if err != nil {
 		return nil, err
 	}
-	return &Jobs{Database: database}, nil
+	return &Jobs{Database: database, db: db}, nil
 }
 
 // ListByVersionID retrieves all jobs for a version (job history).
@@ -68,11 +71,12 @@ func (s *Jobs) ListByStatus(ctx context.Context, userID int64, status models.Job
 		Exec(ctx, map[string]any{"user_id": userID, "status": status})
 }
 
-// UpdateProgress updates the job's stage, progress percentage, and items processed.
-func (s *Jobs) UpdateProgress(ctx context.Context, id int64, stage models.JobStage, progress int, itemsProcessed int) error {
+// UpdateProgress updates the job's stage, progress percentage, and item counts.
+func (s *Jobs) UpdateProgress(ctx context.Context, id int64, stage models.JobStage, progress int, itemsTotal int, itemsProcessed int) error {
 	_, err := s.Modify().
 		Set("stage", "stage").
 		Set("progress", "progress").
+		Set("items_total", "items_total").
 		Set("items_processed", "items_processed").
 		Set("updated_at", "updated_at").
 		Where("id", "=", "id").
@@ -80,12 +84,27 @@ func (s *Jobs) UpdateProgress(ctx context.Context, id int64, stage models.JobSta
 			"id":              id,
 			"stage":           stage,
 			"progress":        progress,
+			"items_total":     itemsTotal,
 			"items_processed": itemsProcessed,
 			"updated_at":      time.Now(),
 		})
 	return err
 }
 
+// Checkpoint records the last stage the job completed.
+func (s *Jobs) Checkpoint(ctx context.Context, id int64, stage models.JobStage) error {
+	_, err := s.Modify().
+		Set("checkpoint", "checkpoint").
+		Set("updated_at", "updated_at").
+		Where("id", "=", "id").
+		Exec(ctx, map[string]any{
+			"id":         id,
+			"checkpoint": &stage,
+			"updated_at": time.Now(),
+		})
+	return err
+}
+
 // Start marks the job as running and sets the started timestamp.
 func (s *Jobs) Start(ctx context.Context, id int64) error {
 	now := time.Now()
@@ -138,6 +157,165 @@ func (s *Jobs) MarkCompleted(ctx context.Context, id int64) error {
 	return err
 }
 
+// claimOrder ranks claimable jobs for fair scheduling. Higher priority runs
+// first; within a priority, users with fewer running jobs go first and each
+// user's jobs interleave round-robin with everyone else's, so one user's
+// backlog cannot starve the queue.
+const claimOrder = `priority DESC, user_active, user_rank, created_at, id`
+
+// claimCandidates scores claimable jobs for claimOrder. $1 is the current time.
+// Pending jobs and jobs whose lease has lapsed are claimable; only jobs with a
+// live lease count as running.
+const claimCandidates = `active AS (
+		SELECT user_id, repository_id FROM jobs
+		WHERE status IN ('running', 'cancelling') AND lease_expires_at >= $1
+	),
+	candidates AS (
+		SELECT id, priority, created_at,
+			(SELECT count(*) FROM active a WHERE a.user_id = j.user_id) AS user_active,
+			(SELECT count(*) FROM active a WHERE a.repository_id = j.repository_id) AS repo_active,
+			row_number() OVER (PARTITION BY user_id ORDER BY priority DESC, created_at, id) AS user_rank
+		FROM jobs j
+		WHERE status = 'pending'
+			OR (status IN ('running', 'cancelling')
+				AND (lease_expires_at IS NULL OR lease_expires_at < $1))
+	)`
+
+// claimLockKey serializes claims across workers so concurrency limits are
+// counted against a consistent view of running jobs.
+const claimLockKey = 7340712
+
+// Claim leases the next claimable job to the given worker in fair-scheduling
+// order, skipping jobs whose user or repository is already at its limit.
+// Running jobs whose lease lapsed after limits.MaxAttempts claims are failed
+// instead of reclaimed, since every worker that ran them died or wedged.
+// SKIP LOCKED lets a claim pass over rows another transaction holds.
+// Returns nil when no job is available.
+func (s *Jobs) Claim(ctx context.Context, workerID string, lease time.Duration, limits models.JobLimits) (*models.Job, error) {
+	query := `WITH ` + claimCandidates + `
+		UPDATE jobs SET
+			status = CASE WHEN status = 'cancelling' THEN status ELSE 'running' END,
+			locked_by = $2,
+			lease_expires_at = $3,
+			attempts = attempts + 1,
+			started_at = COALESCE(started_at, $1),
+			updated_at = $1
+		WHERE id = (
+			SELECT j.id FROM jobs j
+			JOIN candidates c ON c.id = j.id
+			WHERE ($4 = 0 OR c.user_active < $4)
+				AND ($5 = 0 OR c.repo_active < $5)
+			ORDER BY ` + claimOrder + `
+			LIMIT 1
+			FOR UPDATE OF j SKIP LOCKED
+		)
+		RETURNING *`
+
+	tx, err := s.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	if _, err := tx.ExecContext(ctx, `SELECT pg_advisory_xact_lock($1)`, claimLockKey); err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	if limits.MaxAttempts > 0 {
+		exhausted := `UPDATE jobs SET
+				status = 'failed',
+				error = $3,
+				locked_by = NULL,
+				lease_expires_at = NULL,
+				updated_at = $1
+			WHERE status = 'running'
+				AND (lease_expires_at IS NULL OR lease_expires_at < $1)
+				AND attempts >= $2`
+		errMsg := fmt.Sprintf("abandoned after %d attempts: every worker that claimed the job stopped renewing its lease", limits.MaxAttempts)
+		if _, err := tx.ExecContext(ctx, exhausted, now, limits.MaxAttempts, errMsg); err != nil {
+			return nil, err
+		}
+	}
+
+	var job models.Job
+	err = tx.QueryRowxContext(ctx, query, now, workerID, now.Add(lease), limits.PerUser, limits.PerRepository).StructScan(&job)
+	if errors.Is(err, sql.ErrNoRows) {
+		// Nothing to claim, but exhausted jobs must still be failed
+		return nil, tx.Commit()
+	}
+	if err != nil {
+		return nil, err
+	}
+	if err := tx.Commit(); err != nil {
+		return nil, err
+	}
+	return &job, nil
+}
+
+// ListQueue retrieves pending jobs in the order Claim would pick them,
+// ignoring concurrency limits. The index of a job is its queue position.
+func (s *Jobs) ListQueue(ctx context.Context) ([]*models.Job, error) {
+	query := `WITH ` + claimCandidates + `
+		SELECT j.* FROM jobs j
+		JOIN candidates c ON c.id = j.id
+		WHERE j.status = 'pending'
+		ORDER BY ` + claimOrder
+	var jobs []*models.Job
+	if err := s.db.SelectContext(ctx, &jobs, query, time.Now()); err != nil {
+		return nil, err
+	}
+	return jobs, nil
+}
+
+// SetPriority changes the scheduling priority of a pending job.
+// Returns false if the job is not pending.
+func (s *Jobs) SetPriority(ctx context.Context, id int64, priority int) (bool, error) {
+	query := `UPDATE jobs SET priority = $2, updated_at = $3
+		WHERE id = $1 AND status = 'pending'`
+	res, err := s.db.ExecContext(ctx, query, id, priority, time.Now())
+	if err != nil {
+		return false, err
+	}
+	n, err := res.RowsAffected()
+	if err != nil {
+		return false, err
+	}
+	return n > 0, nil
+}
+
+// Heartbeat extends the lease on a job held by the given worker.
+// Returns false if the worker no longer holds the lease.
+func (s *Jobs) Heartbeat(ctx context.Context, id int64, workerID string, lease time.Duration) (bool, error) {
+	query := `UPDATE jobs SET lease_expires_at = $3, updated_at = $4
+		WHERE id = $1 AND locked_by = $2 AND status IN ('running', 'cancelling')`
+	now := time.Now()
+	res, err := s.db.ExecContext(ctx, query, id, workerID, now.Add(lease), now)
+	if err != nil {
+		return false, err
+	}
+	n, err := res.RowsAffected()
+	if err != nil {
+		return false, err
+	}
+	return n > 0, nil
+}
+
+// Release returns a job held by the given worker to the queue.
+// Used on shutdown so another worker can pick the job up immediately; the
+// claim is not counted against the job's attempts.
+func (s *Jobs) Release(ctx context.Context, id int64, workerID string) error {
+	query := `UPDATE jobs SET
+			status = CASE WHEN status = 'cancelling' THEN status ELSE 'pending' END,
+			locked_by = NULL,
+			lease_expires_at = NULL,
+			attempts = GREATEST(attempts - 1, 0),
+			updated_at = $3
+		WHERE id = $1 AND locked_by = $2 AND status IN ('running', 'cancelling')`
+	_, err := s.db.ExecContext(ctx, query, id, workerID, time.Now())
+	return err
+}
+
 // RequestCancellation marks a job for cancellation.
 // Returns error if job is already completed, failed, or cancelled.
 func (s *Jobs) RequestCancellation(ctx context.Context, id int64) error {
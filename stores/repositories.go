@@ -2,8 +2,10 @@ package stores
 
 import (
 	"context"
+	"encoding/json"
 
 	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
 	"github.com/zoobzio/astql"
 	"github.com/zoobzio/sum"
 	"github.com/zoobzio/vicky/models"
@@ -12,6 +14,7 @@ import (
 // Repositories provides database access for repository records.
 type Repositories struct {
 	*sum.Database[models.Repository]
+	db *sqlx.DB
 }
 
 // NewRepositories creates a new repositories store.
@@ -20,7 +23,53 @@ func NewRepositories(db *sqlx.DB, renderer astql.Renderer) (*Repositories, error
 	if err != nil {
 		return nil, err
 	}
-	return &Repositories{Database: database}, nil
+	return &Repositories{Database: database, db: db}, nil
+}
+
+// Register creates a repository and its ingestion configs in a single
+// transaction, so a repository is never left registered without its configs.
+// IDs and timestamps are populated on the given records.
+func (s *Repositories) Register(ctx context.Context, repo *models.Repository, configs []*models.IngestionConfig) error {
+	tx, err := s.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	query := `INSERT INTO repositories (github_id, user_id, owner, name, full_name, description, default_branch, private, html_url)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)
+		RETURNING id, created_at, updated_at`
+	if err := tx.QueryRowxContext(ctx, query,
+		repo.GitHubID, repo.UserID, repo.Owner, repo.Name, repo.FullName,
+		repo.Description, repo.DefaultBranch, repo.Private, repo.HTMLURL,
+	).Scan(&repo.ID, &repo.CreatedAt, &repo.UpdatedAt); err != nil {
+		return err
+	}
+
+	query = `INSERT INTO ingestion_configs (repository_id, user_id, language, root, root_paths, include_docs,
+			exclude_patterns, include_patterns, max_file_size, language_config, content_formats)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11)
+		RETURNING id, created_at, updated_at`
+	for _, config := range configs {
+		config.RepositoryID = repo.ID
+		if err := tx.QueryRowxContext(ctx, query,
+			config.RepositoryID, config.UserID, string(config.Language), config.Root, pq.Array(config.RootPaths), config.IncludeDocs,
+			pq.Array(config.ExcludePatterns), pq.Array(config.IncludePatterns), config.MaxFileSize,
+			jsonOrNull(config.LanguageConfig), jsonOrNull(config.ContentFormats),
+		).Scan(&config.ID, &config.CreatedAt, &config.UpdatedAt); err != nil {
+			return err
+		}
+	}
+
+	return tx.Commit()
+}
+
+// jsonOrNull binds an empty JSON document as NULL rather than as invalid JSONB.
+func jsonOrNull(raw json.RawMessage) any {
+	if len(raw) == 0 {
+		return nil
+	}
+	return []byte(raw)
 }
 
 // ListByUserID retrieves all repositories registered by a user.
@@ -0,0 +1,86 @@
+package stores
+
+import (
+	"context"
+	"fmt"
+	"io"
+
+	"github.com/minio/minio-go/v7"
+	"github.com/zoobzio/grub"
+)
+
+// SCIPIndexes keeps each version's raw SCIP index in object storage. Unlike
+// Blobs it talks to minio directly, so indexes are streamed in and out
+// rather than held in memory.
+type SCIPIndexes struct {
+	client *minio.Client
+	bucket string
+}
+
+// NewSCIPIndexes creates a new SCIP index store.
+func NewSCIPIndexes(client *minio.Client, bucket string) *SCIPIndexes {
+	return &SCIPIndexes{client: client, bucket: bucket}
+}
+
+// Put stores a version's raw SCIP index read from r, replacing any earlier
+// one. A negative size streams an index of unknown length.
+func (s *SCIPIndexes) Put(ctx context.Context, userID int64, owner, repo string, versionID int64, r io.Reader, size int64) error {
+	_, err := s.client.PutObject(ctx, s.bucket, scipIndexKey(userID, owner, repo, versionID), r, size,
+		minio.PutObjectOptions{ContentType: "application/octet-stream"},
+	)
+	if err != nil {
+		return fmt.Errorf("put scip index: %w", err)
+	}
+	return nil
+}
+
+// Open returns a reader over a version's raw SCIP index, which the caller
+// must close. Returns grub.ErrNotFound if the version has no stored index.
+func (s *SCIPIndexes) Open(ctx context.Context, userID int64, owner, repo string, versionID int64) (io.ReadSeekCloser, error) {
+	obj, err := s.client.GetObject(ctx, s.bucket, scipIndexKey(userID, owner, repo, versionID), minio.GetObjectOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("get scip index: %w", err)
+	}
+
+	// GetObject is lazy; stat so a missing index is reported here
+	if _, err := obj.Stat(); err != nil {
+		_ = obj.Close()
+		if isNoSuchKey(err) {
+			return nil, grub.ErrNotFound
+		}
+		return nil, fmt.Errorf("stat scip index: %w", err)
+	}
+	return obj, nil
+}
+
+// Exists reports whether a version has a stored raw SCIP index.
+func (s *SCIPIndexes) Exists(ctx context.Context, userID int64, owner, repo string, versionID int64) (bool, error) {
+	_, err := s.client.StatObject(ctx, s.bucket, scipIndexKey(userID, owner, repo, versionID), minio.StatObjectOptions{})
+	if isNoSuchKey(err) {
+		return false, nil
+	}
+	if err != nil {
+		return false, fmt.Errorf("stat scip index: %w", err)
+	}
+	return true, nil
+}
+
+// Delete removes a version's raw SCIP index. Missing indexes are ignored.
+func (s *SCIPIndexes) Delete(ctx context.Context, userID int64, owner, repo string, versionID int64) error {
+	err := s.client.RemoveObject(ctx, s.bucket, scipIndexKey(userID, owner, repo, versionID), minio.RemoveObjectOptions{})
+	if err != nil && !isNoSuchKey(err) {
+		return fmt.Errorf("delete scip index: %w", err)
+	}
+	return nil
+}
+
+// scipIndexKey places a version's index beside the repository's source
+// blobs. Git refs cannot start with a dot, so ".scip" never collides with a
+// tag's blob prefix.
+func scipIndexKey(userID int64, owner, repo string, versionID int64) string {
+	return fmt.Sprintf("%s.scip/%d.scip", repoPrefix(userID, owner, repo), versionID)
+}
+
+func isNoSuchKey(err error) bool {
+	return minio.ToErrorResponse(err).Code == "NoSuchKey"
+}
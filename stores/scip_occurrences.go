@@ -84,3 +84,14 @@ func (s *SCIPOccurrences) ListReferences(ctx context.Context, userID int64, owne
 	}
 	return references, nil
 }
+
+// DeleteByUserRepoAndTag removes all SCIP occurrences for a version.
+func (s *SCIPOccurrences) DeleteByUserRepoAndTag(ctx context.Context, userID int64, owner, repoName, tag string) error {
+	_, err := s.Remove().
+		Where("user_id", "=", "user_id").
+		Where("owner", "=", "owner").
+		Where("repo_name", "=", "repo_name").
+		Where("tag", "=", "tag").
+		Exec(ctx, map[string]any{"user_id": userID, "owner": owner, "repo_name": repoName, "tag": tag})
+	return err
+}
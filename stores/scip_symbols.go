@@ -2,8 +2,10 @@ package stores
 
 import (
 	"context"
+	"fmt"
 
 	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
 	"github.com/zoobzio/astql"
 	"github.com/zoobzio/sum"
 	"github.com/zoobzio/vicky/models"
@@ -12,6 +14,7 @@ import (
 // SCIPSymbols provides database access for SCIP symbol records.
 type SCIPSymbols struct {
 	*sum.Database[models.SCIPSymbol]
+	db *sqlx.DB
 }
 
 // NewSCIPSymbols creates a new SCIP symbols store.
@@ -20,7 +23,7 @@ func NewSCIPSymbols(db *sqlx.DB, renderer astql.Renderer) (*SCIPSymbols, error)
 	if err != nil {
 		return nil, err
 	}
-	return &SCIPSymbols{Database: database}, nil
+	return &SCIPSymbols{Database: database, db: db}, nil
 }
 
 // ListByUserRepoAndTag retrieves all SCIP symbols for a version.
@@ -72,3 +75,91 @@ func (s *SCIPSymbols) ListByEnclosingSymbol(ctx context.Context, userID int64, o
 		Where("enclosing_symbol", "=", "enclosing_symbol").
 		Exec(ctx, map[string]any{"user_id": userID, "owner": owner, "repo_name": repoName, "tag": tag, "enclosing_symbol": enclosingSymbol})
 }
+
+// DeleteByUserRepoAndTag removes all SCIP symbols for a version. Relationships cascade with their symbols.
+func (s *SCIPSymbols) DeleteByUserRepoAndTag(ctx context.Context, userID int64, owner, repoName, tag string) error {
+	_, err := s.Remove().
+		Where("user_id", "=", "user_id").
+		Where("owner", "=", "owner").
+		Where("repo_name", "=", "repo_name").
+		Where("tag", "=", "tag").
+		Exec(ctx, map[string]any{"user_id": userID, "owner": owner, "repo_name": repoName, "tag": tag})
+	return err
+}
+
+// Columns written when SCIP rows are copied in bulk.
+var (
+	scipSymbolColumns = []string{
+		"id", "document_id", "user_id", "owner", "repo_name", "tag", "symbol", "kind",
+		"display_name", "documentation", "enclosing_symbol", "signature_documentation",
+	}
+	scipRelationshipColumns = []string{
+		"scip_symbol_id", "target_symbol", "is_reference", "is_implementation", "is_type_definition", "is_definition",
+	}
+	scipOccurrenceColumns = []string{
+		"document_id", "user_id", "owner", "repo_name", "tag", "symbol", "symbol_roles",
+		"start_line", "start_col", "end_line", "end_col", "syntax_kind", "enclosing_range",
+	}
+)
+
+// InsertBatch copies a document's SCIP symbols, relationships, and
+// occurrences in a single transaction. Symbol IDs are reserved up front so
+// relationships can reference them; they are set on the batch's symbols.
+func (s *SCIPSymbols) InsertBatch(ctx context.Context, batch *models.SCIPBatch) error {
+	tx, err := s.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	ids, err := reserveIDs(ctx, tx, "scip_symbols", len(batch.Symbols))
+	if err != nil {
+		return err
+	}
+
+	symbols := make([][]any, len(batch.Symbols))
+	var relationships [][]any
+	for i, sym := range batch.Symbols {
+		sym.ID = ids[i]
+		symbols[i] = []any{
+			sym.ID, sym.DocumentID, sym.UserID, sym.Owner, sym.RepoName, sym.Tag, sym.Symbol, int(sym.Kind),
+			sym.DisplayName, textArray(sym.Documentation), sym.EnclosingSymbol, jsonText(sym.SignatureDocumentation),
+		}
+		if i >= len(batch.Relationships) {
+			continue
+		}
+		for _, rel := range batch.Relationships[i] {
+			rel.SCIPSymbolID = sym.ID
+			relationships = append(relationships, []any{
+				rel.SCIPSymbolID, rel.TargetSymbol, rel.IsReference, rel.IsImplementation, rel.IsTypeDefinition, rel.IsDefinition,
+			})
+		}
+	}
+
+	occurrences := make([][]any, len(batch.Occurrences))
+	for i, occ := range batch.Occurrences {
+		var syntaxKind any
+		if occ.SyntaxKind != nil {
+			syntaxKind = int(*occ.SyntaxKind)
+		}
+		var enclosingRange any
+		if occ.EnclosingRange != nil {
+			enclosingRange = pq.Array(occ.EnclosingRange)
+		}
+		occurrences[i] = []any{
+			occ.DocumentID, occ.UserID, occ.Owner, occ.RepoName, occ.Tag, occ.Symbol, int(occ.SymbolRoles),
+			occ.StartLine, occ.StartCol, occ.EndLine, occ.EndCol, syntaxKind, enclosingRange,
+		}
+	}
+
+	if err := copyRows(ctx, tx, "scip_symbols", scipSymbolColumns, symbols); err != nil {
+		return fmt.Errorf("symbols: %w", err)
+	}
+	if err := copyRows(ctx, tx, "scip_relationships", scipRelationshipColumns, relationships); err != nil {
+		return fmt.Errorf("relationships: %w", err)
+	}
+	if err := copyRows(ctx, tx, "scip_occurrences", scipOccurrenceColumns, occurrences); err != nil {
+		return fmt.Errorf("occurrences: %w", err)
+	}
+	return tx.Commit()
+}
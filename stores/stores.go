@@ -22,6 +22,8 @@ type Stores struct {
 	Sessions          *Sessions
 	Blobs             *Blobs
 	Keys              *Keys
+	FileReports       *FileReports
+	EmbeddingCache    *EmbeddingCache
 }
 
 // New creates all stores with the given database connection.
@@ -89,6 +91,16 @@ func New(db *sqlx.DB, renderer astql.Renderer, bucket grub.BucketProvider) (*Sto
 		return nil, err
 	}
 
+	fileReports, err := NewFileReports(db, renderer)
+	if err != nil {
+		return nil, err
+	}
+
+	embeddingCache, err := NewEmbeddingCache(db, renderer)
+	if err != nil {
+		return nil, err
+	}
+
 	return &Stores{
 		Users:             users,
 		Repositories:      repositories,
@@ -104,5 +116,7 @@ func New(db *sqlx.DB, renderer astql.Renderer, bucket grub.BucketProvider) (*Sto
 		Sessions:          sessions,
 		Blobs:             blobs,
 		Keys:              keys,
+		FileReports:       fileReports,
+		EmbeddingCache:    embeddingCache,
 	}, nil
 }
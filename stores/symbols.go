@@ -12,6 +12,7 @@ import (
 // Symbols provides database and vector access for symbol records.
 type Symbols struct {
 	*sum.Database[models.Symbol]
+	db *sqlx.DB
 }
 
 // NewSymbols creates a new symbols store.
@@ -20,7 +21,7 @@ func NewSymbols(db *sqlx.DB, renderer astql.Renderer) (*Symbols, error) {
 	if err != nil {
 		return nil, err
 	}
-	return &Symbols{Database: database}, nil
+	return &Symbols{Database: database, db: db}, nil
 }
 
 // ListByUserRepoAndTag retrieves all symbols for a version.
@@ -83,3 +84,25 @@ func (s *Symbols) FindRelatedExported(ctx context.Context, userID int64, owner,
 			"query_vec": docVector,
 		})
 }
+
+// DeleteByUserRepoAndTag removes all symbols for a version.
+func (s *Symbols) DeleteByUserRepoAndTag(ctx context.Context, userID int64, owner, repoName, tag string) error {
+	_, err := s.Remove().
+		Where("user_id", "=", "user_id").
+		Where("owner", "=", "owner").
+		Where("repo_name", "=", "repo_name").
+		Where("tag", "=", "tag").
+		Exec(ctx, map[string]any{"user_id": userID, "owner": owner, "repo_name": repoName, "tag": tag})
+	return err
+}
+
+// UpdateVectors writes the vectors of the given symbols with one statement.
+func (s *Symbols) UpdateVectors(ctx context.Context, symbols []*models.Symbol) error {
+	ids := make([]int64, len(symbols))
+	vectors := make([]string, len(symbols))
+	for i, sym := range symbols {
+		ids[i] = sym.ID
+		vectors[i] = vectorLiteral(sym.Vector)
+	}
+	return vectorUpdate(ctx, s.db, "symbols", ids, vectors)
+}
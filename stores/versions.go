@@ -2,6 +2,8 @@ package stores
 
 import (
 	"context"
+	"encoding/json"
+	"fmt"
 	"time"
 
 	"github.com/jmoiron/sqlx"
@@ -13,6 +15,7 @@ import (
 // Versions provides database access for version records.
 type Versions struct {
 	*sum.Database[models.Version]
+	db *sqlx.DB
 }
 
 // NewVersions creates a new versions store.
@@ -21,28 +24,138 @@ func NewVersions(db *sqlx.DB, renderer astql.Renderer) (*Versions, error) {
 	if err != nil {
 		return nil, err
 	}
-	return &Versions{Database: database}, nil
+	return &Versions{Database: database, db: db}, nil
 }
 
-// ListByUserAndRepo retrieves all versions for a user's repository.
+// ListByUserAndRepo retrieves all live versions for a user's repository.
+// Staging versions are excluded until they are promoted.
 func (s *Versions) ListByUserAndRepo(ctx context.Context, userID int64, owner, repoName string) ([]*models.Version, error) {
 	return s.Query().
 		Where("user_id", "=", "user_id").
 		Where("owner", "=", "owner").
 		Where("repo_name", "=", "repo_name").
+		WhereNull("replaces_id").
 		Exec(ctx, map[string]any{"user_id": userID, "owner": owner, "repo_name": repoName})
 }
 
-// GetByUserRepoAndTag retrieves a specific version by natural identifiers.
+// GetByUserRepoAndTag retrieves the live version by natural identifiers.
 func (s *Versions) GetByUserRepoAndTag(ctx context.Context, userID int64, owner, repoName, tag string) (*models.Version, error) {
 	return s.Select().
 		Where("user_id", "=", "user_id").
 		Where("owner", "=", "owner").
 		Where("repo_name", "=", "repo_name").
 		Where("tag", "=", "tag").
+		WhereNull("replaces_id").
 		Exec(ctx, map[string]any{"user_id": userID, "owner": owner, "repo_name": repoName, "tag": tag})
 }
 
+// GetStaging retrieves the staging version replacing a live version.
+// Returns nil if the live version has no staging version.
+func (s *Versions) GetStaging(ctx context.Context, liveID int64) (*models.Version, error) {
+	staged, err := s.Query().
+		Where("replaces_id", "=", "replaces_id").
+		Exec(ctx, map[string]any{"replaces_id": liveID})
+	if err != nil || len(staged) == 0 {
+		return nil, err
+	}
+	return staged[0], nil
+}
+
+// Promote swaps a staging version in for the live version it replaces.
+// Within one transaction the old version is deleted (its documents, chunks,
+// symbols, and SCIP rows cascade with it), the staging rows are retagged
+// under the real tag, and job history moves to the promoted version, so
+// readers never see both generations or neither.
+func (s *Versions) Promote(ctx context.Context, id int64) (*models.Version, error) {
+	tx, err := s.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	var staging models.Version
+	if err := tx.QueryRowxContext(ctx, `SELECT * FROM versions WHERE id = $1 FOR UPDATE`, id).StructScan(&staging); err != nil {
+		return nil, err
+	}
+	if !staging.Staging() {
+		return nil, fmt.Errorf("version %d is not staging", id)
+	}
+
+	dataTag := staging.DataTag()
+	steps := []struct {
+		query string
+		args  []any
+	}{
+		{`UPDATE jobs SET version_id = $1 WHERE version_id = $2`, []any{id, *staging.ReplacesID}},
+		{`DELETE FROM versions WHERE id = $1`, []any{*staging.ReplacesID}},
+		{`UPDATE documents SET tag = $1 WHERE version_id = $2 AND tag = $3`, []any{staging.Tag, id, dataTag}},
+		{`UPDATE chunks SET tag = $1 WHERE document_id IN (SELECT id FROM documents WHERE version_id = $2) AND tag = $3`, []any{staging.Tag, id, dataTag}},
+		{`UPDATE scip_symbols SET tag = $1 WHERE document_id IN (SELECT id FROM documents WHERE version_id = $2) AND tag = $3`, []any{staging.Tag, id, dataTag}},
+		{`UPDATE scip_occurrences SET tag = $1 WHERE document_id IN (SELECT id FROM documents WHERE version_id = $2) AND tag = $3`, []any{staging.Tag, id, dataTag}},
+		{`UPDATE symbols SET tag = $1 WHERE version_id = $2 AND tag = $3`, []any{staging.Tag, id, dataTag}},
+		{`UPDATE jobs SET tag = $1 WHERE version_id = $2 AND tag = $3`, []any{staging.Tag, id, dataTag}},
+	}
+	for _, step := range steps {
+		if _, err := tx.ExecContext(ctx, step.query, step.args...); err != nil {
+			return nil, err
+		}
+	}
+
+	// Deleting the old version cleared replaces_id; the staging version is now live
+	var promoted models.Version
+	query := `UPDATE versions SET replaces_id = NULL, status = $2, error = NULL, updated_at = $3
+		WHERE id = $1
+		RETURNING *`
+	if err := tx.QueryRowxContext(ctx, query, id, models.VersionStatusReady, time.Now()).StructScan(&promoted); err != nil {
+		return nil, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, err
+	}
+	return &promoted, nil
+}
+
+// ReplaceSCIP swaps the SCIP rows and symbols a rebuild wrote under dataTag
+// in for a live version's current ones. Within one transaction the current
+// rows are deleted and the rebuilt ones retagged under the real tag, so
+// readers never see both sets or neither.
+func (s *Versions) ReplaceSCIP(ctx context.Context, id int64, dataTag string) error {
+	tx, err := s.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	var version models.Version
+	if err := tx.QueryRowxContext(ctx, `SELECT * FROM versions WHERE id = $1 FOR UPDATE`, id).StructScan(&version); err != nil {
+		return err
+	}
+	if version.Staging() {
+		return fmt.Errorf("version %d is staging", id)
+	}
+
+	steps := []struct {
+		query string
+		args  []any
+	}{
+		{`DELETE FROM scip_symbols WHERE document_id IN (SELECT id FROM documents WHERE version_id = $1) AND tag = $2`, []any{id, version.Tag}},
+		{`DELETE FROM scip_occurrences WHERE document_id IN (SELECT id FROM documents WHERE version_id = $1) AND tag = $2`, []any{id, version.Tag}},
+		{`DELETE FROM symbols WHERE version_id = $1 AND tag = $2`, []any{id, version.Tag}},
+		{`UPDATE scip_symbols SET tag = $1 WHERE document_id IN (SELECT id FROM documents WHERE version_id = $2) AND tag = $3`, []any{version.Tag, id, dataTag}},
+		{`UPDATE scip_occurrences SET tag = $1 WHERE document_id IN (SELECT id FROM documents WHERE version_id = $2) AND tag = $3`, []any{version.Tag, id, dataTag}},
+		{`UPDATE symbols SET tag = $1 WHERE version_id = $2 AND tag = $3`, []any{version.Tag, id, dataTag}},
+		{`UPDATE jobs SET tag = $1 WHERE version_id = $2 AND tag = $3`, []any{version.Tag, id, dataTag}},
+	}
+	for _, step := range steps {
+		if _, err := tx.ExecContext(ctx, step.query, step.args...); err != nil {
+			return err
+		}
+	}
+
+	return tx.Commit()
+}
+
 // UpdateStatus updates the ingestion status of a version.
 func (s *Versions) UpdateStatus(ctx context.Context, id int64, status models.VersionStatus, versionErr *string) (*models.Version, error) {
 	return s.Modify().
@@ -57,3 +170,21 @@ func (s *Versions) UpdateStatus(ctx context.Context, id int64, status models.Ver
 			"updated_at": time.Now(),
 		})
 }
+
+// UpdateEmbeddingStrategy records how the version's chunks are enriched before embedding.
+func (s *Versions) UpdateEmbeddingStrategy(ctx context.Context, id int64, strategy *models.EmbeddingStrategy) error {
+	data, err := json.Marshal(strategy)
+	if err != nil {
+		return err
+	}
+	_, err = s.Modify().
+		Set("embedding_strategy", "embedding_strategy").
+		Set("updated_at", "updated_at").
+		Where("id", "=", "id").
+		Exec(ctx, map[string]any{
+			"id":                 id,
+			"embedding_strategy": string(data),
+			"updated_at":         time.Now(),
+		})
+	return err
+}
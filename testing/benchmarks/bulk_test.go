@@ -0,0 +1,267 @@
+//go:build testing
+
+package benchmarks
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"testing"
+
+	"github.com/jmoiron/sqlx"
+	_ "github.com/lib/pq"
+	"github.com/zoobzio/astql/postgres"
+	"github.com/zoobzio/sum"
+	"github.com/zoobzio/vicky/models"
+	"github.com/zoobzio/vicky/stores"
+)
+
+// Write-path benchmarks compare one round-trip per row against the bulk
+// paths the parse, chunk, and embed stages use. They need a migrated
+// database:
+//
+//	VICKY_DB_DSN=postgres://... go test -tags testing -bench=Writes -benchmem ./testing/benchmarks/...
+
+// rowsPerDocument approximates a large source file.
+var rowsPerDocument = []int{100, 1000}
+
+// fixture is a document to write rows under.
+type fixture struct {
+	db         *sqlx.DB
+	stores     *stores.Stores
+	userID     int64
+	documentID int64
+}
+
+// setupFixture connects to VICKY_DB_DSN and creates a user, repository,
+// version, and document. Everything is removed with the user afterwards.
+func setupFixture(b *testing.B) *fixture {
+	b.Helper()
+	dsn := os.Getenv("VICKY_DB_DSN")
+	if dsn == "" {
+		b.Skip("VICKY_DB_DSN not set")
+	}
+
+	db, err := sqlx.Connect("postgres", dsn)
+	if err != nil {
+		b.Fatalf("connect: %v", err)
+	}
+	b.Cleanup(func() { _ = db.Close() })
+
+	sum.New()
+	b.Cleanup(sum.Reset)
+	all, err := stores.New(db, postgres.New(), nil)
+	if err != nil {
+		b.Fatalf("stores: %v", err)
+	}
+
+	ctx := context.Background()
+	f := &fixture{db: db, stores: all, userID: 900000 + int64(os.Getpid())}
+	login := fmt.Sprintf("bench-%d", f.userID)
+
+	if _, err := db.ExecContext(ctx,
+		`INSERT INTO users (id, login, email, access_token) VALUES ($1, $2, $2, '')`,
+		f.userID, login); err != nil {
+		b.Fatalf("create user: %v", err)
+	}
+	b.Cleanup(func() { _, _ = db.Exec(`DELETE FROM users WHERE id = $1`, f.userID) })
+
+	var repoID, versionID int64
+	if err := db.QueryRowxContext(ctx,
+		`INSERT INTO repositories (github_id, user_id, owner, name, full_name, html_url)
+		VALUES (0, $1, $2, 'bench', $2 || '/bench', '') RETURNING id`,
+		f.userID, login).Scan(&repoID); err != nil {
+		b.Fatalf("create repository: %v", err)
+	}
+	if err := db.QueryRowxContext(ctx,
+		`INSERT INTO versions (repository_id, user_id, owner, repo_name, tag, commit_sha)
+		VALUES ($1, $2, $3, 'bench', 'v1', 'sha') RETURNING id`,
+		repoID, f.userID, login).Scan(&versionID); err != nil {
+		b.Fatalf("create version: %v", err)
+	}
+	if err := db.QueryRowxContext(ctx,
+		`INSERT INTO documents (version_id, user_id, owner, repo_name, tag, path, content_type, content_hash)
+		VALUES ($1, $2, $3, 'bench', 'v1', 'main.go', 'code', 'hash') RETURNING id`,
+		versionID, f.userID, login).Scan(&f.documentID); err != nil {
+		b.Fatalf("create document: %v", err)
+	}
+	return f
+}
+
+// reserve draws ids for per-row writes, which carry their primary key.
+func (f *fixture) reserve(b *testing.B, table string, n int) []int64 {
+	b.Helper()
+	var ids []int64
+	if err := f.db.Select(&ids,
+		`SELECT nextval(pg_get_serial_sequence($1, 'id')) FROM generate_series(1, $2::int)`, table, n); err != nil {
+		b.Fatalf("reserve ids: %v", err)
+	}
+	return ids
+}
+
+func (f *fixture) chunks(n int) []*models.Chunk {
+	chunks := make([]*models.Chunk, n)
+	for i := range chunks {
+		chunks[i] = &models.Chunk{
+			DocumentID: f.documentID,
+			UserID:     f.userID,
+			Owner:      fmt.Sprintf("bench-%d", f.userID),
+			RepoName:   "bench",
+			Tag:        "v1",
+			Path:       "main.go",
+			Kind:       models.ChunkKindFunction,
+			StartLine:  i * 10,
+			EndLine:    i*10 + 9,
+			Context:    []string{"package main"},
+			Content:    fmt.Sprintf("func f%d() {}", i),
+		}
+	}
+	return chunks
+}
+
+func (f *fixture) scipBatch(n int) *models.SCIPBatch {
+	owner := fmt.Sprintf("bench-%d", f.userID)
+	batch := &models.SCIPBatch{
+		Symbols:       make([]*models.SCIPSymbol, n),
+		Relationships: make([][]*models.SCIPRelationship, n),
+		Occurrences:   make([]*models.SCIPOccurrence, 4*n),
+	}
+	for i := range batch.Symbols {
+		symbol := fmt.Sprintf("scip-go gomod bench v1 f%d().", i)
+		batch.Symbols[i] = &models.SCIPSymbol{
+			DocumentID: f.documentID, UserID: f.userID, Owner: owner, RepoName: "bench", Tag: "v1",
+			Symbol: symbol, Kind: models.SCIPSymbolKindFunction,
+		}
+		batch.Relationships[i] = []*models.SCIPRelationship{{TargetSymbol: symbol, IsReference: true}}
+		for j := 0; j < 4; j++ {
+			batch.Occurrences[4*i+j] = &models.SCIPOccurrence{
+				DocumentID: f.documentID, UserID: f.userID, Owner: owner, RepoName: "bench", Tag: "v1",
+				Symbol: symbol, StartLine: i, EndLine: i, EndCol: 10,
+			}
+		}
+	}
+	return batch
+}
+
+func vector() []float32 {
+	v := make([]float32, 1536)
+	for i := range v {
+		v[i] = float32(i) / 1536
+	}
+	return v
+}
+
+func BenchmarkChunkWrites(b *testing.B) {
+	for _, n := range rowsPerDocument {
+		b.Run(fmt.Sprintf("PerRow/%d", n), func(b *testing.B) {
+			f := setupFixture(b)
+			ctx := context.Background()
+			for b.Loop() {
+				chunks := f.chunks(n)
+				ids := f.reserve(b, "chunks", n)
+				if err := f.stores.Chunks.DeleteByDocument(ctx, f.documentID); err != nil {
+					b.Fatal(err)
+				}
+				for i, c := range chunks {
+					c.ID = ids[i]
+					if err := f.stores.Chunks.Set(ctx, "", c); err != nil {
+						b.Fatal(err)
+					}
+				}
+			}
+		})
+		b.Run(fmt.Sprintf("Bulk/%d", n), func(b *testing.B) {
+			f := setupFixture(b)
+			ctx := context.Background()
+			for b.Loop() {
+				if err := f.stores.Chunks.ReplaceByDocument(ctx, f.documentID, f.chunks(n)); err != nil {
+					b.Fatal(err)
+				}
+			}
+		})
+	}
+}
+
+func BenchmarkSCIPWrites(b *testing.B) {
+	for _, n := range rowsPerDocument {
+		b.Run(fmt.Sprintf("PerRow/%d", n), func(b *testing.B) {
+			f := setupFixture(b)
+			ctx := context.Background()
+			for b.Loop() {
+				batch := f.scipBatch(n)
+				symbolIDs := f.reserve(b, "scip_symbols", len(batch.Symbols))
+				relationshipIDs := f.reserve(b, "scip_relationships", len(batch.Symbols))
+				occurrenceIDs := f.reserve(b, "scip_occurrences", len(batch.Occurrences))
+				for i, sym := range batch.Symbols {
+					sym.ID = symbolIDs[i]
+					if err := f.stores.SCIPSymbols.Set(ctx, "", sym); err != nil {
+						b.Fatal(err)
+					}
+					for _, rel := range batch.Relationships[i] {
+						rel.ID = relationshipIDs[i]
+						rel.SCIPSymbolID = sym.ID
+						if err := f.stores.SCIPRelationships.Set(ctx, "", rel); err != nil {
+							b.Fatal(err)
+						}
+					}
+				}
+				for i, occ := range batch.Occurrences {
+					occ.ID = occurrenceIDs[i]
+					if err := f.stores.SCIPOccurrences.Set(ctx, "", occ); err != nil {
+						b.Fatal(err)
+					}
+				}
+			}
+		})
+		b.Run(fmt.Sprintf("Bulk/%d", n), func(b *testing.B) {
+			f := setupFixture(b)
+			ctx := context.Background()
+			for b.Loop() {
+				if err := f.stores.SCIPSymbols.InsertBatch(ctx, f.scipBatch(n)); err != nil {
+					b.Fatal(err)
+				}
+			}
+		})
+	}
+}
+
+func BenchmarkVectorWrites(b *testing.B) {
+	for _, n := range rowsPerDocument {
+		setup := func(b *testing.B) (*fixture, []*models.Chunk) {
+			f := setupFixture(b)
+			ctx := context.Background()
+			if err := f.stores.Chunks.ReplaceByDocument(ctx, f.documentID, f.chunks(n)); err != nil {
+				b.Fatal(err)
+			}
+			chunks, err := f.stores.Chunks.ListByUserRepoTagAndPath(ctx, f.userID, fmt.Sprintf("bench-%d", f.userID), "bench", "v1", "main.go")
+			if err != nil {
+				b.Fatal(err)
+			}
+			v := vector()
+			for _, c := range chunks {
+				c.Vector = v
+			}
+			return f, chunks
+		}
+		b.Run(fmt.Sprintf("PerRow/%d", n), func(b *testing.B) {
+			f, chunks := setup(b)
+			ctx := context.Background()
+			for b.Loop() {
+				for _, c := range chunks {
+					if err := f.stores.Chunks.Set(ctx, fmt.Sprint(c.ID), c); err != nil {
+						b.Fatal(err)
+					}
+				}
+			}
+		})
+		b.Run(fmt.Sprintf("Bulk/%d", n), func(b *testing.B) {
+			f, chunks := setup(b)
+			ctx := context.Background()
+			for b.Loop() {
+				if err := f.stores.Chunks.UpdateVectors(ctx, chunks); err != nil {
+					b.Fatal(err)
+				}
+			}
+		})
+	}
+}
@@ -3,8 +3,10 @@
 package testing
 
 import (
+	"bytes"
 	"context"
 	"fmt"
+	"io"
 	"net/http"
 	"testing"
 
@@ -28,6 +30,7 @@ func NewJob(t *testing.T) *models.Job {
 		Owner:        "testorg",
 		RepoName:     "testrepo",
 		Tag:          "v1.0.0",
+		Kind:         models.JobKindIngest,
 		Stage:        models.JobStageFetch,
 		Status:       models.JobStatusRunning,
 	}
@@ -116,6 +119,18 @@ func NewDocument(t *testing.T, id int64, path string) *models.Document {
 	}
 }
 
+// indexReader serves SCIP index bytes from memory in place of a spooled index.
+type indexReader struct {
+	*bytes.Reader
+}
+
+func (r *indexReader) Close() error { return nil }
+
+// NewIndexReader wraps raw SCIP index bytes as an indexer Result's Index.
+func NewIndexReader(data []byte) io.ReadSeekCloser {
+	return &indexReader{Reader: bytes.NewReader(data)}
+}
+
 // RegistryOption configures mock registrations for a test registry.
 type RegistryOption func(k sum.Key)
 
@@ -182,6 +197,13 @@ func WithIndexer(i contracts.Indexer) RegistryOption {
 	}
 }
 
+// WithSCIPIndexes registers a SCIPIndexes implementation.
+func WithSCIPIndexes(s contracts.SCIPIndexes) RegistryOption {
+	return func(k sum.Key) {
+		sum.Register[contracts.SCIPIndexes](k, s)
+	}
+}
+
 // WithSCIPSymbols registers a SCIPSymbols implementation.
 func WithSCIPSymbols(s contracts.SCIPSymbols) RegistryOption {
 	return func(k sum.Key) {
@@ -231,6 +253,20 @@ func WithSymbols(s contracts.Symbols) RegistryOption {
 	}
 }
 
+// WithFileReports registers a FileReports implementation.
+func WithFileReports(r contracts.FileReports) RegistryOption {
+	return func(k sum.Key) {
+		sum.Register[contracts.FileReports](k, r)
+	}
+}
+
+// WithEmbeddingCache registers an EmbeddingCache implementation.
+func WithEmbeddingCache(c contracts.EmbeddingCache) RegistryOption {
+	return func(k sum.Key) {
+		sum.Register[contracts.EmbeddingCache](k, c)
+	}
+}
+
 // NewKey creates a test Key with sensible defaults.
 // The KeyHash and KeyPrefix are set to plausible test values.
 func NewKey(t *testing.T) *models.Key {
@@ -0,0 +1,106 @@
+//go:build testing
+
+package integration
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+	_ "github.com/lib/pq"
+	"github.com/zoobzio/astql/postgres"
+	"github.com/zoobzio/sum"
+	"github.com/zoobzio/vicky/models"
+	"github.com/zoobzio/vicky/stores"
+)
+
+// Store tests need a migrated database and are skipped unless VICKY_DB_DSN
+// is set:
+//
+//	VICKY_DB_DSN=postgres://... go test -tags testing ./testing/integration/...
+
+// setupStores connects to VICKY_DB_DSN and creates a user, repository, and
+// version to attach rows to. Everything is removed with the user afterwards.
+func setupStores(t *testing.T) (*sqlx.DB, *stores.Stores, *models.Version) {
+	t.Helper()
+	dsn := os.Getenv("VICKY_DB_DSN")
+	if dsn == "" {
+		t.Skip("VICKY_DB_DSN not set")
+	}
+
+	db, err := sqlx.Connect("postgres", dsn)
+	if err != nil {
+		t.Fatalf("connect: %v", err)
+	}
+	t.Cleanup(func() { _ = db.Close() })
+
+	sum.New()
+	t.Cleanup(sum.Reset)
+	all, err := stores.New(db, postgres.New(), nil)
+	if err != nil {
+		t.Fatalf("stores: %v", err)
+	}
+
+	ctx := context.Background()
+	userID := 910000 + int64(os.Getpid())
+	login := fmt.Sprintf("itest-%d", userID)
+
+	if _, err := db.ExecContext(ctx,
+		`INSERT INTO users (id, login, email, access_token) VALUES ($1, $2, $2, '')`,
+		userID, login); err != nil {
+		t.Fatalf("create user: %v", err)
+	}
+	t.Cleanup(func() { _, _ = db.Exec(`DELETE FROM users WHERE id = $1`, userID) })
+
+	version := &models.Version{UserID: userID, Owner: login, RepoName: "itest", Tag: "v1"}
+	if err := db.QueryRowxContext(ctx,
+		`INSERT INTO repositories (github_id, user_id, owner, name, full_name, html_url)
+		VALUES (0, $1, $2, 'itest', $2 || '/itest', '') RETURNING id`,
+		userID, login).Scan(&version.RepositoryID); err != nil {
+		t.Fatalf("create repository: %v", err)
+	}
+	if err := db.QueryRowxContext(ctx,
+		`INSERT INTO versions (repository_id, user_id, owner, repo_name, tag, commit_sha)
+		VALUES ($1, $2, $3, 'itest', 'v1', 'sha') RETURNING id`,
+		version.RepositoryID, userID, login).Scan(&version.ID); err != nil {
+		t.Fatalf("create version: %v", err)
+	}
+	return db, all, version
+}
+
+func TestJobsClaim_FailsExhaustedJobWhenIdle(t *testing.T) {
+	db, all, version := setupStores(t)
+	ctx := context.Background()
+
+	// A running job whose last worker died after its final attempt
+	var jobID int64
+	if err := db.QueryRowxContext(ctx,
+		`INSERT INTO jobs (version_id, repository_id, user_id, owner, repo_name, tag, stage, status,
+			attempts, locked_by, lease_expires_at)
+		VALUES ($1, $2, $3, $4, 'itest', 'v1', 'parse', 'running', 3, 'dead-worker', $5)
+		RETURNING id`,
+		version.ID, version.RepositoryID, version.UserID, version.Owner, time.Now().Add(-time.Minute)).Scan(&jobID); err != nil {
+		t.Fatalf("create job: %v", err)
+	}
+
+	// No other job for the user is claimable, so the claim usually finds nothing
+	claimed, err := all.Jobs.Claim(ctx, "itest-worker", time.Minute, models.JobLimits{MaxAttempts: 3})
+	if err != nil {
+		t.Fatalf("Claim: %v", err)
+	}
+	if claimed != nil && claimed.ID == jobID {
+		t.Fatalf("exhausted job %d reclaimed", jobID)
+	}
+
+	var status string
+	var lockedBy *string
+	if err := db.QueryRowxContext(ctx, `SELECT status, locked_by FROM jobs WHERE id = $1`, jobID).Scan(&status, &lockedBy); err != nil {
+		t.Fatalf("read job: %v", err)
+	}
+	if status != string(models.JobStatusFailed) || lockedBy != nil {
+		t.Errorf("job status = %s locked_by = %v, want failed and unlocked", status, lockedBy)
+	}
+}
@@ -0,0 +1,38 @@
+//go:build testing
+
+package integration
+
+import (
+	"context"
+	"testing"
+
+	"github.com/zoobzio/vicky/models"
+)
+
+func TestRepositoriesRegister(t *testing.T) {
+	_, all, version := setupStores(t)
+	ctx := context.Background()
+
+	repo := &models.Repository{
+		GitHubID: 1, UserID: version.UserID, Owner: version.Owner, Name: "registered",
+		FullName: version.Owner + "/registered", DefaultBranch: "main", HTMLURL: "https://github.com/x/registered",
+	}
+	configs := []*models.IngestionConfig{
+		{UserID: version.UserID, Language: models.LanguageGo, IncludeDocs: true, MaxFileSize: 1 << 20},
+		{UserID: version.UserID, Language: models.LanguageTypeScript, Root: "web", RootPaths: []string{"app", "lib"}, MaxFileSize: 1 << 20},
+	}
+	if err := all.Repositories.Register(ctx, repo, configs); err != nil {
+		t.Fatalf("Register: %v", err)
+	}
+	if repo.ID == 0 {
+		t.Fatal("repository ID not populated")
+	}
+
+	stored, err := all.IngestionConfigs.ListByRepositoryID(ctx, repo.ID)
+	if err != nil {
+		t.Fatalf("ListByRepositoryID: %v", err)
+	}
+	if len(stored) != 2 || stored[1].Root != "web" || len(stored[1].RootPaths) != 2 {
+		t.Errorf("stored configs = %+v, want both language roots", stored)
+	}
+}
@@ -4,6 +4,8 @@ package testing
 
 import (
 	"context"
+	"io"
+	"time"
 
 	"github.com/zoobzio/grub"
 	"github.com/zoobzio/vicky/external/chunker"
@@ -19,7 +21,11 @@ type MockVersions struct {
 	OnSet                 func(ctx context.Context, key string, version *models.Version) error
 	OnListByUserAndRepo   func(ctx context.Context, userID int64, owner, repoName string) ([]*models.Version, error)
 	OnGetByUserRepoAndTag func(ctx context.Context, userID int64, owner, repoName, tag string) (*models.Version, error)
+	OnGetStaging          func(ctx context.Context, liveID int64) (*models.Version, error)
+	OnPromote             func(ctx context.Context, id int64) (*models.Version, error)
+	OnReplaceSCIP         func(ctx context.Context, id int64, dataTag string) error
 	OnUpdateStatus        func(ctx context.Context, id int64, status models.VersionStatus, versionErr *string) (*models.Version, error)
+	OnUpdateEmbeddingStrategy func(ctx context.Context, id int64, strategy *models.EmbeddingStrategy) error
 }
 
 func (m *MockVersions) Get(ctx context.Context, key string) (*models.Version, error) {
@@ -50,6 +56,27 @@ func (m *MockVersions) GetByUserRepoAndTag(ctx context.Context, userID int64, ow
 	return &models.Version{}, nil
 }
 
+func (m *MockVersions) GetStaging(ctx context.Context, liveID int64) (*models.Version, error) {
+	if m.OnGetStaging != nil {
+		return m.OnGetStaging(ctx, liveID)
+	}
+	return nil, nil
+}
+
+func (m *MockVersions) Promote(ctx context.Context, id int64) (*models.Version, error) {
+	if m.OnPromote != nil {
+		return m.OnPromote(ctx, id)
+	}
+	return &models.Version{ID: id, Status: models.VersionStatusReady}, nil
+}
+
+func (m *MockVersions) ReplaceSCIP(ctx context.Context, id int64, dataTag string) error {
+	if m.OnReplaceSCIP != nil {
+		return m.OnReplaceSCIP(ctx, id, dataTag)
+	}
+	return nil
+}
+
 func (m *MockVersions) UpdateStatus(ctx context.Context, id int64, status models.VersionStatus, versionErr *string) (*models.Version, error) {
 	if m.OnUpdateStatus != nil {
 		return m.OnUpdateStatus(ctx, id, status, versionErr)
@@ -57,11 +84,20 @@ func (m *MockVersions) UpdateStatus(ctx context.Context, id int64, status models
 	return &models.Version{ID: id, Status: status}, nil
 }
 
+func (m *MockVersions) UpdateEmbeddingStrategy(ctx context.Context, id int64, strategy *models.EmbeddingStrategy) error {
+	if m.OnUpdateEmbeddingStrategy != nil {
+		return m.OnUpdateEmbeddingStrategy(ctx, id, strategy)
+	}
+	return nil
+}
+
 // MockEmbedder implements contracts.Embedder with function-field overrides.
 type MockEmbedder struct {
 	OnEmbed      func(ctx context.Context, texts []string) ([][]float32, error)
 	OnEmbedQuery func(ctx context.Context, texts []string) ([][]float32, error)
 	OnDimensions func() int
+	OnProvider   func() string
+	OnModel      func() string
 }
 
 func (m *MockEmbedder) Embed(ctx context.Context, texts []string) ([][]float32, error) {
@@ -93,12 +129,29 @@ func (m *MockEmbedder) Dimensions() int {
 	return 3
 }
 
+func (m *MockEmbedder) Provider() string {
+	if m.OnProvider != nil {
+		return m.OnProvider()
+	}
+	return "mock"
+}
+
+func (m *MockEmbedder) Model() string {
+	if m.OnModel != nil {
+		return m.OnModel()
+	}
+	return "mock-model"
+}
+
 // MockChunks implements contracts.Chunks with function-field overrides.
 type MockChunks struct {
 	OnGet                      func(ctx context.Context, key string) (*models.Chunk, error)
 	OnSet                      func(ctx context.Context, key string, chunk *models.Chunk) error
 	OnListByUserRepoAndTag     func(ctx context.Context, userID int64, owner, repoName, tag string) ([]*models.Chunk, error)
 	OnListByUserRepoTagAndPath func(ctx context.Context, userID int64, owner, repoName, tag, path string) ([]*models.Chunk, error)
+	OnDeleteByDocument         func(ctx context.Context, documentID int64) error
+	OnReplaceByDocument        func(ctx context.Context, documentID int64, chunks []*models.Chunk) error
+	OnUpdateVectors            func(ctx context.Context, chunks []*models.Chunk) error
 	OnSearch                   func(ctx context.Context, userID int64, owner, repoName, tag string, vector []float32, limit int) ([]*models.Chunk, error)
 	OnSearchByKind             func(ctx context.Context, userID int64, owner, repoName, tag string, kind models.ChunkKind, vector []float32, limit int) ([]*models.Chunk, error)
 }
@@ -131,6 +184,27 @@ func (m *MockChunks) ListByUserRepoTagAndPath(ctx context.Context, userID int64,
 	return nil, nil
 }
 
+func (m *MockChunks) DeleteByDocument(ctx context.Context, documentID int64) error {
+	if m.OnDeleteByDocument != nil {
+		return m.OnDeleteByDocument(ctx, documentID)
+	}
+	return nil
+}
+
+func (m *MockChunks) ReplaceByDocument(ctx context.Context, documentID int64, chunks []*models.Chunk) error {
+	if m.OnReplaceByDocument != nil {
+		return m.OnReplaceByDocument(ctx, documentID, chunks)
+	}
+	return nil
+}
+
+func (m *MockChunks) UpdateVectors(ctx context.Context, chunks []*models.Chunk) error {
+	if m.OnUpdateVectors != nil {
+		return m.OnUpdateVectors(ctx, chunks)
+	}
+	return nil
+}
+
 func (m *MockChunks) Search(ctx context.Context, userID int64, owner, repoName, tag string, vector []float32, limit int) ([]*models.Chunk, error) {
 	if m.OnSearch != nil {
 		return m.OnSearch(ctx, userID, owner, repoName, tag, vector, limit)
@@ -202,6 +276,7 @@ type MockGitHub struct {
 	OnGetTree             func(ctx context.Context, token, owner, repo, ref string) ([]github.TreeEntry, error)
 	OnGetFileContent      func(ctx context.Context, token, owner, repo, path, ref string) (*github.FileContent, error)
 	OnGetFileContentBatch func(ctx context.Context, token, owner, repo, ref string, paths []string) ([]*github.FileContent, error)
+	OnStreamArchive       func(ctx context.Context, token, owner, repo, ref string, include func(path string, size int64) bool, fn func(*github.FileContent) error) error
 }
 
 func (m *MockGitHub) GetTree(ctx context.Context, token, owner, repo, ref string) ([]github.TreeEntry, error) {
@@ -229,11 +304,18 @@ func (m *MockGitHub) GetFileContentBatch(ctx context.Context, token, owner, repo
 	return result, nil
 }
 
+func (m *MockGitHub) StreamArchive(ctx context.Context, token, owner, repo, ref string, include func(path string, size int64) bool, fn func(*github.FileContent) error) error {
+	if m.OnStreamArchive != nil {
+		return m.OnStreamArchive(ctx, token, owner, repo, ref, include, fn)
+	}
+	return nil
+}
+
 // MockIngestionConfigs implements contracts.IngestionConfigs with function-field overrides.
 type MockIngestionConfigs struct {
 	OnGet                func(ctx context.Context, key string) (*models.IngestionConfig, error)
 	OnSet                func(ctx context.Context, key string, config *models.IngestionConfig) error
-	OnGetByRepositoryID  func(ctx context.Context, repositoryID int64) (*models.IngestionConfig, error)
+	OnListByRepositoryID func(ctx context.Context, repositoryID int64) ([]*models.IngestionConfig, error)
 }
 
 func (m *MockIngestionConfigs) Get(ctx context.Context, key string) (*models.IngestionConfig, error) {
@@ -255,26 +337,28 @@ func (m *MockIngestionConfigs) Set(ctx context.Context, key string, config *mode
 	return nil
 }
 
-func (m *MockIngestionConfigs) GetByRepositoryID(ctx context.Context, repositoryID int64) (*models.IngestionConfig, error) {
-	if m.OnGetByRepositoryID != nil {
-		return m.OnGetByRepositoryID(ctx, repositoryID)
+func (m *MockIngestionConfigs) ListByRepositoryID(ctx context.Context, repositoryID int64) ([]*models.IngestionConfig, error) {
+	if m.OnListByRepositoryID != nil {
+		return m.OnListByRepositoryID(ctx, repositoryID)
 	}
-	return &models.IngestionConfig{
+	return []*models.IngestionConfig{{
 		ID:           1,
 		RepositoryID: repositoryID,
 		Language:     models.LanguageGo,
 		IncludeDocs:  true,
 		MaxFileSize:  models.DefaultMaxFileSize,
-	}, nil
+	}}, nil
 }
 
 // MockBlobs implements contracts.Blobs with function-field overrides.
 type MockBlobs struct {
-	OnGetByPath      func(ctx context.Context, userID int64, owner, repo, tag, path string) (*grub.Object[models.Blob], error)
-	OnPutBlob        func(ctx context.Context, userID int64, blob *models.Blob) error
-	OnDeleteByPath   func(ctx context.Context, userID int64, owner, repo, tag, path string) error
-	OnListByVersion  func(ctx context.Context, userID int64, owner, repo, tag string, limit int) ([]grub.ObjectInfo, error)
-	OnListByRepo     func(ctx context.Context, userID int64, owner, repo string, limit int) ([]grub.ObjectInfo, error)
+	OnGetByPath          func(ctx context.Context, userID int64, owner, repo, tag, path string) (*grub.Object[models.Blob], error)
+	OnPutBlob            func(ctx context.Context, userID int64, blob *models.Blob) error
+	OnDeleteByPath       func(ctx context.Context, userID int64, owner, repo, tag, path string) error
+	OnDeleteByVersion    func(ctx context.Context, userID int64, owner, repo, tag string) error
+	OnListByVersion      func(ctx context.Context, userID int64, owner, repo, tag string, limit int) ([]grub.ObjectInfo, error)
+	OnListPathsByVersion func(ctx context.Context, userID int64, owner, repo, tag string) ([]string, error)
+	OnListByRepo         func(ctx context.Context, userID int64, owner, repo string, limit int) ([]grub.ObjectInfo, error)
 }
 
 func (m *MockBlobs) GetByPath(ctx context.Context, userID int64, owner, repo, tag, path string) (*grub.Object[models.Blob], error) {
@@ -301,6 +385,13 @@ func (m *MockBlobs) DeleteByPath(ctx context.Context, userID int64, owner, repo,
 	return nil
 }
 
+func (m *MockBlobs) DeleteByVersion(ctx context.Context, userID int64, owner, repo, tag string) error {
+	if m.OnDeleteByVersion != nil {
+		return m.OnDeleteByVersion(ctx, userID, owner, repo, tag)
+	}
+	return nil
+}
+
 func (m *MockBlobs) ListByVersion(ctx context.Context, userID int64, owner, repo, tag string, limit int) ([]grub.ObjectInfo, error) {
 	if m.OnListByVersion != nil {
 		return m.OnListByVersion(ctx, userID, owner, repo, tag, limit)
@@ -308,6 +399,13 @@ func (m *MockBlobs) ListByVersion(ctx context.Context, userID int64, owner, repo
 	return nil, nil
 }
 
+func (m *MockBlobs) ListPathsByVersion(ctx context.Context, userID int64, owner, repo, tag string) ([]string, error) {
+	if m.OnListPathsByVersion != nil {
+		return m.OnListPathsByVersion(ctx, userID, owner, repo, tag)
+	}
+	return nil, nil
+}
+
 func (m *MockBlobs) ListByRepo(ctx context.Context, userID int64, owner, repo string, limit int) ([]grub.ObjectInfo, error) {
 	if m.OnListByRepo != nil {
 		return m.OnListByRepo(ctx, userID, owner, repo, limit)
@@ -321,6 +419,7 @@ type MockDocuments struct {
 	OnSet                      func(ctx context.Context, key string, doc *models.Document) error
 	OnListByUserRepoAndTag     func(ctx context.Context, userID int64, owner, repoName, tag string) ([]*models.Document, error)
 	OnGetByUserRepoTagAndPath  func(ctx context.Context, userID int64, owner, repoName, tag, path string) (*models.Document, error)
+	OnDeleteByVersion          func(ctx context.Context, versionID int64) error
 	OnFindSimilar              func(ctx context.Context, userID int64, vector []float32, limit int) ([]*models.Document, error)
 	OnFindSimilarInVersion     func(ctx context.Context, userID int64, owner, repoName, tag string, vector []float32, limit int) ([]*models.Document, error)
 }
@@ -353,6 +452,13 @@ func (m *MockDocuments) GetByUserRepoTagAndPath(ctx context.Context, userID int6
 	return &models.Document{}, nil
 }
 
+func (m *MockDocuments) DeleteByVersion(ctx context.Context, versionID int64) error {
+	if m.OnDeleteByVersion != nil {
+		return m.OnDeleteByVersion(ctx, versionID)
+	}
+	return nil
+}
+
 func (m *MockDocuments) FindSimilar(ctx context.Context, userID int64, vector []float32, limit int) ([]*models.Document, error) {
 	if m.OnFindSimilar != nil {
 		return m.OnFindSimilar(ctx, userID, vector, limit)
@@ -377,7 +483,7 @@ func (m *MockIndexer) Index(ctx context.Context, req indexer.Request) (*indexer.
 	if m.OnIndex != nil {
 		return m.OnIndex(ctx, req)
 	}
-	return &indexer.Result{JobID: req.JobID, VersionID: req.VersionID}, nil
+	return &indexer.Result{JobID: req.JobID, VersionID: req.VersionID, Index: NewIndexReader(nil)}, nil
 }
 
 func (m *MockIndexer) Supports(language models.Language) bool {
@@ -387,11 +493,41 @@ func (m *MockIndexer) Supports(language models.Language) bool {
 	return true
 }
 
+// MockSCIPIndexes implements contracts.SCIPIndexes with function-field overrides.
+type MockSCIPIndexes struct {
+	OnPut    func(ctx context.Context, userID int64, owner, repo string, versionID int64, r io.Reader, size int64) error
+	OnOpen   func(ctx context.Context, userID int64, owner, repo string, versionID int64) (io.ReadSeekCloser, error)
+	OnDelete func(ctx context.Context, userID int64, owner, repo string, versionID int64) error
+}
+
+func (m *MockSCIPIndexes) Put(ctx context.Context, userID int64, owner, repo string, versionID int64, r io.Reader, size int64) error {
+	if m.OnPut != nil {
+		return m.OnPut(ctx, userID, owner, repo, versionID, r, size)
+	}
+	return nil
+}
+
+func (m *MockSCIPIndexes) Open(ctx context.Context, userID int64, owner, repo string, versionID int64) (io.ReadSeekCloser, error) {
+	if m.OnOpen != nil {
+		return m.OnOpen(ctx, userID, owner, repo, versionID)
+	}
+	return nil, grub.ErrNotFound
+}
+
+func (m *MockSCIPIndexes) Delete(ctx context.Context, userID int64, owner, repo string, versionID int64) error {
+	if m.OnDelete != nil {
+		return m.OnDelete(ctx, userID, owner, repo, versionID)
+	}
+	return nil
+}
+
 // MockSCIPSymbols implements contracts.SCIPSymbols with function-field overrides.
 type MockSCIPSymbols struct {
 	OnGet                    func(ctx context.Context, key string) (*models.SCIPSymbol, error)
 	OnSet                    func(ctx context.Context, key string, symbol *models.SCIPSymbol) error
+	OnInsertBatch            func(ctx context.Context, batch *models.SCIPBatch) error
 	OnListByUserRepoAndTag   func(ctx context.Context, userID int64, owner, repoName, tag string) ([]*models.SCIPSymbol, error)
+	OnDeleteByUserRepoAndTag func(ctx context.Context, userID int64, owner, repoName, tag string) error
 	OnListByDocument         func(ctx context.Context, documentID int64) ([]*models.SCIPSymbol, error)
 	OnGetBySymbol            func(ctx context.Context, userID int64, owner, repoName, tag, symbol string) (*models.SCIPSymbol, error)
 	OnListByKind             func(ctx context.Context, userID int64, owner, repoName, tag string, kind models.SCIPSymbolKind) ([]*models.SCIPSymbol, error)
@@ -412,6 +548,13 @@ func (m *MockSCIPSymbols) Set(ctx context.Context, key string, symbol *models.SC
 	return nil
 }
 
+func (m *MockSCIPSymbols) InsertBatch(ctx context.Context, batch *models.SCIPBatch) error {
+	if m.OnInsertBatch != nil {
+		return m.OnInsertBatch(ctx, batch)
+	}
+	return nil
+}
+
 func (m *MockSCIPSymbols) ListByUserRepoAndTag(ctx context.Context, userID int64, owner, repoName, tag string) ([]*models.SCIPSymbol, error) {
 	if m.OnListByUserRepoAndTag != nil {
 		return m.OnListByUserRepoAndTag(ctx, userID, owner, repoName, tag)
@@ -419,6 +562,13 @@ func (m *MockSCIPSymbols) ListByUserRepoAndTag(ctx context.Context, userID int64
 	return nil, nil
 }
 
+func (m *MockSCIPSymbols) DeleteByUserRepoAndTag(ctx context.Context, userID int64, owner, repoName, tag string) error {
+	if m.OnDeleteByUserRepoAndTag != nil {
+		return m.OnDeleteByUserRepoAndTag(ctx, userID, owner, repoName, tag)
+	}
+	return nil
+}
+
 func (m *MockSCIPSymbols) ListByDocument(ctx context.Context, documentID int64) ([]*models.SCIPSymbol, error) {
 	if m.OnListByDocument != nil {
 		return m.OnListByDocument(ctx, documentID)
@@ -449,13 +599,14 @@ func (m *MockSCIPSymbols) ListByEnclosingSymbol(ctx context.Context, userID int6
 
 // MockSCIPOccurrences implements contracts.SCIPOccurrences with function-field overrides.
 type MockSCIPOccurrences struct {
-	OnGet                  func(ctx context.Context, key string) (*models.SCIPOccurrence, error)
-	OnSet                  func(ctx context.Context, key string, occurrence *models.SCIPOccurrence) error
-	OnListByUserRepoAndTag func(ctx context.Context, userID int64, owner, repoName, tag string) ([]*models.SCIPOccurrence, error)
-	OnListByDocument       func(ctx context.Context, documentID int64) ([]*models.SCIPOccurrence, error)
-	OnListBySymbol         func(ctx context.Context, userID int64, owner, repoName, tag, symbol string) ([]*models.SCIPOccurrence, error)
-	OnListDefinitions      func(ctx context.Context, userID int64, owner, repoName, tag, symbol string) ([]*models.SCIPOccurrence, error)
-	OnListReferences       func(ctx context.Context, userID int64, owner, repoName, tag, symbol string) ([]*models.SCIPOccurrence, error)
+	OnGet                    func(ctx context.Context, key string) (*models.SCIPOccurrence, error)
+	OnSet                    func(ctx context.Context, key string, occurrence *models.SCIPOccurrence) error
+	OnListByUserRepoAndTag   func(ctx context.Context, userID int64, owner, repoName, tag string) ([]*models.SCIPOccurrence, error)
+	OnDeleteByUserRepoAndTag func(ctx context.Context, userID int64, owner, repoName, tag string) error
+	OnListByDocument         func(ctx context.Context, documentID int64) ([]*models.SCIPOccurrence, error)
+	OnListBySymbol           func(ctx context.Context, userID int64, owner, repoName, tag, symbol string) ([]*models.SCIPOccurrence, error)
+	OnListDefinitions        func(ctx context.Context, userID int64, owner, repoName, tag, symbol string) ([]*models.SCIPOccurrence, error)
+	OnListReferences         func(ctx context.Context, userID int64, owner, repoName, tag, symbol string) ([]*models.SCIPOccurrence, error)
 }
 
 func (m *MockSCIPOccurrences) Get(ctx context.Context, key string) (*models.SCIPOccurrence, error) {
@@ -479,6 +630,13 @@ func (m *MockSCIPOccurrences) ListByUserRepoAndTag(ctx context.Context, userID i
 	return nil, nil
 }
 
+func (m *MockSCIPOccurrences) DeleteByUserRepoAndTag(ctx context.Context, userID int64, owner, repoName, tag string) error {
+	if m.OnDeleteByUserRepoAndTag != nil {
+		return m.OnDeleteByUserRepoAndTag(ctx, userID, owner, repoName, tag)
+	}
+	return nil
+}
+
 func (m *MockSCIPOccurrences) ListByDocument(ctx context.Context, documentID int64) ([]*models.SCIPOccurrence, error) {
 	if m.OnListByDocument != nil {
 		return m.OnListByDocument(ctx, documentID)
@@ -579,7 +737,12 @@ type MockJobs struct {
 	OnLatestByVersionID func(ctx context.Context, versionID int64) (*models.Job, error)
 	OnListByUser       func(ctx context.Context, userID int64) ([]*models.Job, error)
 	OnListByStatus     func(ctx context.Context, userID int64, status models.JobStatus) ([]*models.Job, error)
-	OnUpdateProgress   func(ctx context.Context, id int64, stage models.JobStage, progress int, itemsProcessed int) error
+	OnUpdateProgress   func(ctx context.Context, id int64, stage models.JobStage, progress int, itemsTotal int, itemsProcessed int) error
+	OnRequestCancellation func(ctx context.Context, id int64) error
+	OnClaim            func(ctx context.Context, workerID string, lease time.Duration, limits models.JobLimits) (*models.Job, error)
+	OnHeartbeat        func(ctx context.Context, id int64, workerID string, lease time.Duration) (bool, error)
+	OnRelease          func(ctx context.Context, id int64, workerID string) error
+	OnCheckpoint       func(ctx context.Context, id int64, stage models.JobStage) error
 	OnStart            func(ctx context.Context, id int64) error
 	OnMarkFailed       func(ctx context.Context, id int64, errMsg string) error
 	OnMarkCompleted    func(ctx context.Context, id int64) error
@@ -629,9 +792,44 @@ func (m *MockJobs) ListByStatus(ctx context.Context, userID int64, status models
 	return nil, nil
 }
 
-func (m *MockJobs) UpdateProgress(ctx context.Context, id int64, stage models.JobStage, progress int, itemsProcessed int) error {
+func (m *MockJobs) UpdateProgress(ctx context.Context, id int64, stage models.JobStage, progress int, itemsTotal int, itemsProcessed int) error {
 	if m.OnUpdateProgress != nil {
-		return m.OnUpdateProgress(ctx, id, stage, progress, itemsProcessed)
+		return m.OnUpdateProgress(ctx, id, stage, progress, itemsTotal, itemsProcessed)
+	}
+	return nil
+}
+
+func (m *MockJobs) RequestCancellation(ctx context.Context, id int64) error {
+	if m.OnRequestCancellation != nil {
+		return m.OnRequestCancellation(ctx, id)
+	}
+	return nil
+}
+
+func (m *MockJobs) Claim(ctx context.Context, workerID string, lease time.Duration, limits models.JobLimits) (*models.Job, error) {
+	if m.OnClaim != nil {
+		return m.OnClaim(ctx, workerID, lease, limits)
+	}
+	return nil, nil
+}
+
+func (m *MockJobs) Heartbeat(ctx context.Context, id int64, workerID string, lease time.Duration) (bool, error) {
+	if m.OnHeartbeat != nil {
+		return m.OnHeartbeat(ctx, id, workerID, lease)
+	}
+	return true, nil
+}
+
+func (m *MockJobs) Release(ctx context.Context, id int64, workerID string) error {
+	if m.OnRelease != nil {
+		return m.OnRelease(ctx, id, workerID)
+	}
+	return nil
+}
+
+func (m *MockJobs) Checkpoint(ctx context.Context, id int64, stage models.JobStage) error {
+	if m.OnCheckpoint != nil {
+		return m.OnCheckpoint(ctx, id, stage)
 	}
 	return nil
 }
@@ -675,6 +873,7 @@ func (m *MockJobs) IsCancelling(ctx context.Context, id int64) (bool, error) {
 type MockRepositories struct {
 	OnGet                    func(ctx context.Context, key string) (*models.Repository, error)
 	OnSet                    func(ctx context.Context, key string, repo *models.Repository) error
+	OnRegister               func(ctx context.Context, repo *models.Repository, configs []*models.IngestionConfig) error
 	OnListByUserID           func(ctx context.Context, userID int64) ([]*models.Repository, error)
 	OnGetByUserAndGitHubID   func(ctx context.Context, userID, githubID int64) (*models.Repository, error)
 	OnGetByUserOwnerAndName  func(ctx context.Context, userID int64, owner, name string) (*models.Repository, error)
@@ -694,6 +893,13 @@ func (m *MockRepositories) Set(ctx context.Context, key string, repo *models.Rep
 	return nil
 }
 
+func (m *MockRepositories) Register(ctx context.Context, repo *models.Repository, configs []*models.IngestionConfig) error {
+	if m.OnRegister != nil {
+		return m.OnRegister(ctx, repo, configs)
+	}
+	return nil
+}
+
 func (m *MockRepositories) ListByUserID(ctx context.Context, userID int64) ([]*models.Repository, error) {
 	if m.OnListByUserID != nil {
 		return m.OnListByUserID(ctx, userID)
@@ -772,6 +978,8 @@ type MockSymbols struct {
 	OnGet                          func(ctx context.Context, key string) (*models.Symbol, error)
 	OnSet                          func(ctx context.Context, key string, symbol *models.Symbol) error
 	OnListByUserRepoAndTag         func(ctx context.Context, userID int64, owner, repoName, tag string) ([]*models.Symbol, error)
+	OnDeleteByUserRepoAndTag       func(ctx context.Context, userID int64, owner, repoName, tag string) error
+	OnUpdateVectors                func(ctx context.Context, symbols []*models.Symbol) error
 	OnListExportedByUserRepoAndTag func(ctx context.Context, userID int64, owner, repoName, tag string) ([]*models.Symbol, error)
 	OnFindRelated                  func(ctx context.Context, userID int64, owner, repoName, tag string, docVector []float32, limit int) ([]*models.Symbol, error)
 	OnFindRelatedExported          func(ctx context.Context, userID int64, owner, repoName, tag string, docVector []float32, limit int) ([]*models.Symbol, error)
@@ -798,6 +1006,20 @@ func (m *MockSymbols) ListByUserRepoAndTag(ctx context.Context, userID int64, ow
 	return nil, nil
 }
 
+func (m *MockSymbols) DeleteByUserRepoAndTag(ctx context.Context, userID int64, owner, repoName, tag string) error {
+	if m.OnDeleteByUserRepoAndTag != nil {
+		return m.OnDeleteByUserRepoAndTag(ctx, userID, owner, repoName, tag)
+	}
+	return nil
+}
+
+func (m *MockSymbols) UpdateVectors(ctx context.Context, symbols []*models.Symbol) error {
+	if m.OnUpdateVectors != nil {
+		return m.OnUpdateVectors(ctx, symbols)
+	}
+	return nil
+}
+
 func (m *MockSymbols) ListExportedByUserRepoAndTag(ctx context.Context, userID int64, owner, repoName, tag string) ([]*models.Symbol, error) {
 	if m.OnListExportedByUserRepoAndTag != nil {
 		return m.OnListExportedByUserRepoAndTag(ctx, userID, owner, repoName, tag)
@@ -818,3 +1040,51 @@ func (m *MockSymbols) FindRelatedExported(ctx context.Context, userID int64, own
 	}
 	return nil, nil
 }
+
+// MockFileReports implements contracts.FileReports with function-field overrides.
+type MockFileReports struct {
+	OnSet                  func(ctx context.Context, key string, report *models.FileReport) error
+	OnListByJob            func(ctx context.Context, jobID int64) ([]*models.FileReport, error)
+	OnReplaceByJobAndStage func(ctx context.Context, jobID int64, stage models.JobStage, reports []*models.FileReport) error
+}
+
+func (m *MockFileReports) Set(ctx context.Context, key string, report *models.FileReport) error {
+	if m.OnSet != nil {
+		return m.OnSet(ctx, key, report)
+	}
+	return nil
+}
+
+func (m *MockFileReports) ListByJob(ctx context.Context, jobID int64) ([]*models.FileReport, error) {
+	if m.OnListByJob != nil {
+		return m.OnListByJob(ctx, jobID)
+	}
+	return nil, nil
+}
+
+func (m *MockFileReports) ReplaceByJobAndStage(ctx context.Context, jobID int64, stage models.JobStage, reports []*models.FileReport) error {
+	if m.OnReplaceByJobAndStage != nil {
+		return m.OnReplaceByJobAndStage(ctx, jobID, stage, reports)
+	}
+	return nil
+}
+
+// MockEmbeddingCache implements contracts.EmbeddingCache with function-field overrides.
+type MockEmbeddingCache struct {
+	OnGetMany func(ctx context.Context, keys []string) (map[string][]float32, error)
+	OnPutMany func(ctx context.Context, entries []*models.EmbeddingCacheEntry) error
+}
+
+func (m *MockEmbeddingCache) GetMany(ctx context.Context, keys []string) (map[string][]float32, error) {
+	if m.OnGetMany != nil {
+		return m.OnGetMany(ctx, keys)
+	}
+	return map[string][]float32{}, nil
+}
+
+func (m *MockEmbeddingCache) PutMany(ctx context.Context, entries []*models.EmbeddingCacheEntry) error {
+	if m.OnPutMany != nil {
+		return m.OnPutMany(ctx, entries)
+	}
+	return nil
+}